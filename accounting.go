@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Accounting Integration
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerExporter("accounting", ExporterFunc(postVouchers))
+}
+
+// AccountingConfig selects the accounting system the documents of a report
+// are posted to as vouchers.
+type AccountingConfig struct {
+	Provider   string            `yaml:"provider,omitempty"`   // sevdesk, lexoffice or buchhaltungsbutler
+	APIKey     string            `yaml:"apiKey,omitempty"`     // API token
+	APIClient  string            `yaml:"apiClient,omitempty"`  // BuchhaltungsButler API client
+	APISecret  string            `yaml:"apiSecret,omitempty"`  // BuchhaltungsButler API secret
+	Category   string            `yaml:"category,omitempty"`   // booking category of all vouchers (ID or account of the system)
+	Categories map[string]string `yaml:"categories,omitempty"` // booking category by document title, e.g. Verpflegungsmehraufwand
+}
+
+// category returns the booking category of the document with the given title.
+func (a AccountingConfig) category(title string) string {
+	if c, ok := a.Categories[title]; ok {
+		return c
+	}
+	return a.Category
+}
+
+// Voucher is a document of a report posted to the accounting system.
+type Voucher struct {
+	DocID    string    // Beleg-Nr., used as voucher number
+	Title    string    // document type, e.g. Kilometergelderstattung
+	Date     time.Time // last day of the period
+	Amount   float64   // gross amount in EUR; travel allowances carry no VAT
+	Category string    // booking category of the configured system
+	File     Attachment
+}
+
+// AccountingDriver posts vouchers to one accounting system, selected by
+// accounting.provider.
+type AccountingDriver interface {
+	PostVoucher(cfg *Config, v Voucher) error
+}
+
+// AccountingFunc adapts a function to the AccountingDriver interface.
+type AccountingFunc func(cfg *Config, v Voucher) error
+
+func (f AccountingFunc) PostVoucher(cfg *Config, v Voucher) error { return f(cfg, v) }
+
+var accountingDrivers = map[string]AccountingDriver{}
+
+// registerAccounting makes an accounting system available as
+// accounting.provider. Drivers register themselves from an init function in
+// their own file.
+func registerAccounting(name string, d AccountingDriver) {
+	if _, ok := accountingDrivers[name]; ok {
+		panic("accounting driver registered twice: " + name)
+	}
+	accountingDrivers[name] = d
+}
+
+// voucherTypes are the documents posted as vouchers. Hours sheets carry no
+// amount and XRechnungen are outgoing invoices.
+var voucherTypes = map[string]bool{
+	"Kilometergelderstattung": true,
+	"Verpflegungsmehraufwand": true,
+	"Reisenebenkosten":        true,
+	"Reisekostenformular":     true,
+}
+
+// reportVouchers returns the vouchers of a report's expense documents.
+func reportVouchers(cfg *Config, p Period, report *Report) []Voucher {
+	var vouchers []Voucher
+	for i, d := range newRunSummary(cfg, p, report, nil).Documents {
+		if !voucherTypes[d.Type] || d.Amount == 0 {
+			continue
+		}
+		vouchers = append(vouchers, Voucher{
+			DocID:    d.ID,
+			Title:    d.Type,
+			Date:     p.End(),
+			Amount:   d.Amount,
+			Category: cfg.Accounting.category(d.Type),
+			File:     report.Attachments[i],
+		})
+	}
+	return vouchers
+}
+
+// postVouchers posts the expense documents of a delivered report to the
+// configured accounting system. It does nothing without accounting.provider,
+// or with delivery api, where posting is the delivery itself.
+func postVouchers(cfg *Config, p Period, report *Report) error {
+	if cfg.Accounting.Provider == "" || cfg.DeliveryMode() == deliveryAPI {
+		return nil
+	}
+	return postReportVouchers(cfg, p, report)
+}
+
+// postReportVouchers posts the expense documents of a report to the
+// configured accounting system.
+func postReportVouchers(cfg *Config, p Period, report *Report) error {
+	d, ok := accountingDrivers[cfg.Accounting.Provider]
+	if !ok {
+		return fmt.Errorf("unknown accounting provider %q (use %s)", cfg.Accounting.Provider, registryNames(accountingDrivers))
+	}
+	for _, v := range reportVouchers(cfg, p, report) {
+		if err := d.PostVoucher(cfg, v); err != nil {
+			return fmt.Errorf("%s: voucher %s: %w", cfg.Accounting.Provider, v.DocID, err)
+		}
+		slog.Info("voucher posted", "provider", cfg.Accounting.Provider, "document", v.Title, "id", v.DocID, "amount", formatAmount(v.Amount))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostVouchers(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Timesheet: true},
+		},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// No provider configured: nothing is posted
+	if err := postVouchers(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("postVouchers() error = %v", err)
+	}
+
+	var requests []string
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path)
+		bodies = append(bodies, string(body))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/uploadTempFile"):
+			w.Write([]byte(`{"objects":{"filename":"tmp123.pdf"}}`))
+		case strings.HasSuffix(r.URL.Path, "/saveVoucher"):
+			w.Write([]byte(`{"objects":{"voucher":{"id":"1"}}}`))
+		case r.URL.Path == "/vouchers":
+			w.Write([]byte(`{"id":"v-1"}`))
+		default:
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer srv.Close()
+	defer func(s, l, b string) { sevdeskAPI, lexofficeAPI, buchhaltungsbutlerAPI = s, l, b }(sevdeskAPI, lexofficeAPI, buchhaltungsbutlerAPI)
+	sevdeskAPI, lexofficeAPI, buchhaltungsbutlerAPI = srv.URL, srv.URL, srv.URL
+
+	cfg.Accounting = AccountingConfig{Provider: "sevdesk", APIKey: "token", Category: "17",
+		Categories: map[string]string{"Verpflegungsmehraufwand": "18"}}
+	if err := postVouchers(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("postVouchers(sevdesk) error = %v", err)
+	}
+	// Two documents with amounts; the hours sheet is no voucher
+	if got := strings.Join(requests, ","); got != "/Voucher/Factory/uploadTempFile,/Voucher/Factory/saveVoucher,/Voucher/Factory/uploadTempFile,/Voucher/Factory/saveVoucher" {
+		t.Fatalf("sevdesk requests = %s", got)
+	}
+	var save sevdeskSaveVoucher
+	json.Unmarshal([]byte(bodies[3]), &save)
+	if save.Filename != "tmp123.pdf" || save.Voucher.Description != report.VerpDocID || save.Voucher.VoucherDate != "28.02.2026" ||
+		save.VoucherPosSave[0].VoucherPos.SumGross != 280 || save.VoucherPosSave[0].VoucherPos.AccountingType.ID != 18 {
+		t.Errorf("saveVoucher = %s", bodies[3])
+	}
+
+	requests, bodies = nil, nil
+	cfg.Accounting = AccountingConfig{Provider: "lexoffice", APIKey: "token", Category: "16d04a28-2c8f-4f9e-9b1a-5a1d4c3b2a10"}
+	if err := postVouchers(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("postVouchers(lexoffice) error = %v", err)
+	}
+	if len(requests) != 4 || requests[1] != "/vouchers/v-1/files" || !strings.Contains(bodies[0], `"totalGrossAmount":600`) {
+		t.Errorf("lexoffice requests = %v, first body = %s", requests, bodies[0])
+	}
+
+	requests, bodies = nil, nil
+	cfg.Accounting = AccountingConfig{Provider: "buchhaltungsbutler", APIKey: "key", APIClient: "client", APISecret: "secret", Category: "4660"}
+	if err := postVouchers(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("postVouchers(buchhaltungsbutler) error = %v", err)
+	}
+	if len(requests) != 2 || requests[0] != "/receipts/add" || !strings.Contains(bodies[0], "amount=600.00") || !strings.Contains(bodies[0], "postingaccount=4660") {
+		t.Errorf("buchhaltungsbutler requests = %v, first body = %.200s", requests, bodies[0])
+	}
+
+	cfg.Accounting.Provider = "datev"
+	if err := postVouchers(cfg, monthPeriod(2026, 2), report); err == nil || !strings.Contains(err.Error(), "buchhaltungsbutler, lexoffice or sevdesk") {
+		t.Errorf("postVouchers(datev) error = %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ---------------------------------------------------------------------------
+// Fillable Forms (AcroForm)
+// ---------------------------------------------------------------------------
+
+var (
+	pdfObjectRegex    = regexp.MustCompile(`(?s)(?:^|[\r\n])(\d+) (\d+) obj\s*(.*?)\s*endobj`)
+	pdfFieldNameRegex = regexp.MustCompile(`/T\s*\(((?:\\.|[^\\)])*)\)`)
+	pdfParentRegex    = regexp.MustCompile(`/Parent\s+(\d+)\s+\d+\s+R`)
+	pdfValueRegex     = regexp.MustCompile(`\s*/V(\s*\((?:\\.|[^\\)])*\)|\s*<[0-9A-Fa-f\s]*>|\s*/[^\s/<>\[\]()]*)`)
+	pdfNeedAppRegex   = regexp.MustCompile(`\s*/NeedAppearances\s*(true|false)`)
+	pdfAcroFormRegex  = regexp.MustCompile(`/AcroForm\s+(\d+)\s+\d+\s+R`)
+	pdfInlineAcroForm = regexp.MustCompile(`/AcroForm\s*<<\s*`)
+	pdfPlainTrailer   = regexp.MustCompile(`(?s)^trailer\s*<<(.*?)>>\s*startxref\s+(\d+)\s+%%EOF\s*$`)
+	pdfPrevRegex      = regexp.MustCompile(`/Prev\s+\d+`)
+	pdfRootRegex      = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R`)
+	pdfSizeRegex      = regexp.MustCompile(`/Size\s+(\d+)`)
+)
+
+// pdfObject is an indirect object of a PDF file without stream.
+type pdfObject struct {
+	gen  int
+	body string // the dictionary, << ... >>
+}
+
+// pdfTextString encodes a value of a form field: ASCII as literal string,
+// anything else as UTF-16 with byte order mark, so umlauts survive.
+func pdfTextString(s string) string {
+	for _, r := range s {
+		if r > 0x7e {
+			buf := []byte{0xfe, 0xff}
+			for _, u := range utf16.Encode([]rune(s)) {
+				buf = append(buf, byte(u>>8), byte(u))
+			}
+			return "<" + strings.ToUpper(hex.EncodeToString(buf)) + ">"
+		}
+	}
+	return pdfString(s)
+}
+
+// pdfUnescape decodes the escapes of a literal string.
+func pdfUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\(`, "(", `\)`, ")").Replace(s)
+}
+
+// pdfObjects returns the current version of every dictionary object: a later
+// incremental update replaces an earlier definition.
+func pdfObjects(data []byte) map[int]pdfObject {
+	objects := map[int]pdfObject{}
+	for _, m := range pdfObjectRegex.FindAllSubmatch(data, -1) {
+		if !bytes.HasPrefix(m[3], []byte("<<")) || bytes.Contains(m[3], []byte("stream")) {
+			continue
+		}
+		n, _ := strconv.Atoi(string(m[1]))
+		gen, _ := strconv.Atoi(string(m[2]))
+		objects[n] = pdfObject{gen: gen, body: string(m[3])}
+	}
+	return objects
+}
+
+// acroFields returns the object of every named form field by its fully
+// qualified name, e.g. Reise.Datum.1 for a field below Reise and Datum.
+func acroFields(objects map[int]pdfObject) map[string]int {
+	var fullName func(n, depth int) string
+	fullName = func(n, depth int) string {
+		o := objects[n]
+		name := ""
+		if m := pdfFieldNameRegex.FindStringSubmatch(o.body); m != nil {
+			name = pdfUnescape(m[1])
+		}
+		if m := pdfParentRegex.FindStringSubmatch(o.body); m != nil && depth < 32 {
+			parent, _ := strconv.Atoi(m[1])
+			if p := fullName(parent, depth+1); p != "" {
+				if name == "" {
+					return p
+				}
+				return p + "." + name
+			}
+		}
+		return name
+	}
+	fields := map[string]int{}
+	for n, o := range objects {
+		if pdfFieldNameRegex.MatchString(o.body) {
+			fields[fullName(n, 0)] = n
+		}
+	}
+	return fields
+}
+
+// fillAcroForm sets the form fields of a fillable PDF to the given values
+// (field name -> text) as an incremental update, leaving the template's own
+// objects unchanged. NeedAppearances makes viewers draw the filled values.
+// Cross-reference streams and encrypted files are not supported.
+func fillAcroForm(data []byte, values map[string]string) ([]byte, error) {
+	var trailer [][]byte
+	if i := bytes.LastIndex(data, []byte("trailer")); i >= 0 {
+		trailer = pdfPlainTrailer.FindSubmatch(data[i:])
+	}
+	if trailer == nil {
+		return nil, fmt.Errorf("unsupported PDF: no plain cross-reference table (save the form as PDF 1.4)")
+	}
+	if bytes.Contains(trailer[1], []byte("/Encrypt")) {
+		return nil, fmt.Errorf("unsupported PDF: the form is encrypted")
+	}
+	root, size := pdfRootRegex.FindSubmatch(trailer[1]), pdfSizeRegex.FindSubmatch(trailer[1])
+	if root == nil || size == nil {
+		return nil, fmt.Errorf("unsupported PDF: trailer without /Root or /Size")
+	}
+	catalog, _ := strconv.Atoi(string(root[1]))
+	objects := pdfObjects(data)
+	fields := acroFields(objects)
+
+	changed := map[int]string{}
+	var missing []string
+	for name, value := range values {
+		n, ok := fields[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		body := pdfValueRegex.ReplaceAllString(objects[n].body, "")
+		changed[n] = "<</V " + pdfTextString(value) + " " + strings.TrimLeft(strings.TrimPrefix(body, "<<"), " ")
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("form has no field %s", strings.Join(missing, ", "))
+	}
+
+	cat, ok := objects[catalog]
+	switch m := pdfAcroFormRegex.FindStringSubmatch(cat.body); {
+	case !ok:
+		return nil, fmt.Errorf("catalog %d not found", catalog)
+	case m != nil:
+		n, _ := strconv.Atoi(m[1])
+		body := pdfNeedAppRegex.ReplaceAllString(objects[n].body, "")
+		changed[n] = "<</NeedAppearances true " + strings.TrimLeft(strings.TrimPrefix(body, "<<"), " ")
+	case strings.Contains(cat.body, "/AcroForm"):
+		body := pdfNeedAppRegex.ReplaceAllString(cat.body, "")
+		changed[catalog] = pdfInlineAcroForm.ReplaceAllString(body, "/AcroForm <</NeedAppearances true ")
+	default:
+		return nil, fmt.Errorf("the PDF has no form fields")
+	}
+
+	out := bytes.NewBuffer(append([]byte(nil), data...))
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	nums := make([]int, 0, len(changed))
+	for n := range changed {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+	offsets := map[int]int{}
+	for _, n := range nums {
+		offsets[n] = out.Len()
+		fmt.Fprintf(out, "%d %d obj\n%s\nendobj\n", n, objects[n].gen, changed[n])
+	}
+
+	// Cross-reference section of the changed objects
+	xref := out.Len()
+	out.WriteString("xref\n")
+	for i := 0; i < len(nums); {
+		last := i
+		for last+1 < len(nums) && nums[last+1] == nums[last]+1 {
+			last++
+		}
+		fmt.Fprintf(out, "%d %d\n", nums[i], last-i+1)
+		for ; i <= last; i++ {
+			fmt.Fprintf(out, "%010d %05d n \n", offsets[nums[i]], objects[nums[i]].gen)
+		}
+	}
+	fmt.Fprintf(out, "trailer\n<<%s/Prev %s\n>>\nstartxref\n%d\n%%%%EOF\n",
+		pdfPrevRegex.ReplaceAll(trailer[1], nil), trailer[2], xref)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// fillablePDF returns a one-page PDF with the text fields Name, Summe (with
+// a value) and Datum.1 and Datum.2 below the parent field Datum.
+func fillablePDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R] /NeedAppearances false >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Annots [4 0 R 5 0 R 7 0 R 8 0 R] >>",
+		"<< /FT /Tx /T (Name) /Subtype /Widget /Rect [50 700 250 720] /P 3 0 R >>",
+		"<< /FT /Tx /T (Summe) /V (0,00) /Subtype /Widget /Rect [400 100 550 120] /P 3 0 R >>",
+		"<< /FT /Tx /T (Datum) /Kids [7 0 R 8 0 R] >>",
+		"<< /T (1) /Parent 6 0 R /Subtype /Widget /Rect [50 600 150 620] /P 3 0 R >>",
+		"<< /T (2) /Parent 6 0 R /Subtype /Widget /Rect [50 580 150 600] /P 3 0 R >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestFillAcroForm(t *testing.T) {
+	template := fillablePDF()
+	data, err := fillAcroForm(template, map[string]string{"Name": "Jörg (Außendienst)", "Summe": "880,00", "Datum.2": "27.02.2026"})
+	if err != nil {
+		t.Fatalf("fillAcroForm() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, template) {
+		t.Errorf("template changed, want an incremental update")
+	}
+	objects := pdfObjects(data)
+	for n, want := range map[int]string{
+		1: "/AcroForm <</NeedAppearances true ",
+		4: "/V <FEFF004A00F60072006700200028",
+		5: "/V (880,00) /FT /Tx /T (Summe)",
+		8: "/V (27.02.2026)",
+	} {
+		if !strings.Contains(objects[n].body, want) {
+			t.Errorf("object %d = %s, want %s", n, objects[n].body, want)
+		}
+	}
+	if strings.Contains(objects[1].body, "false") || strings.Contains(objects[5].body, "(0,00)") {
+		t.Errorf("old values kept: %s %s", objects[1].body, objects[5].body)
+	}
+	if !regexp.MustCompile(`trailer\n<< /Size 9 /Root 1 0 R /Prev \d+\n>>\nstartxref\n\d+\n%%EOF\n$`).Match(data) {
+		t.Errorf("trailer of the update:\n%s", data[bytes.LastIndex(data, []byte("xref")):])
+	}
+
+	// The update can be filled again
+	if data, err = fillAcroForm(data, map[string]string{"Summe": "900,00"}); err != nil || !strings.Contains(pdfObjects(data)[5].body, "/V (900,00) /FT") {
+		t.Errorf("second fillAcroForm() = %s, %v", pdfObjects(data)[5].body, err)
+	}
+
+	if _, err := fillAcroForm(template, map[string]string{"Datum.3": "x", "Ort": "y"}); err == nil || !strings.Contains(err.Error(), "no field Datum.3, Ort") {
+		t.Errorf("fillAcroForm() with unknown fields error = %v", err)
+	}
+}
+
+func TestCreateAcroForm(t *testing.T) {
+	path := writeFormTemplate(t, "pdf: formular.pdf\nacroFields:\n  Name: name\n  Summe: total\n")
+	os.WriteFile(filepath.Join(filepath.Dir(path), "formular.pdf"), fillablePDF(), 0644)
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Form:      FormConfig{Template: path},
+		Signature: SignatureConfig{Name: "Max Mustermann"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	objects := pdfObjects(report.Attachments[0].Data)
+	if !strings.Contains(objects[4].body, "/V (Max Mustermann)") || !strings.Contains(objects[5].body, "/V ("+formatAmount(report.Total())+")") {
+		t.Errorf("fields = %s, %s", objects[4].body, objects[5].body)
+	}
+
+	// The form has date fields for two trips, February has 20
+	os.WriteFile(path, []byte("pdf: formular.pdf\nacroFields:\n  \"Datum.{n}\": date\n"), 0644)
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), "no field Datum.10") {
+		t.Errorf("generateReport() with too few fields error = %v", err)
+	}
+
+	for data, wantErr := range map[string]string{
+		"pdf: formular.pdf\nacroFields:\n  \"Datum.{n}\": total\n":                 `acroFields.Datum.{n}: unknown value "total"`,
+		"acroFields:\n  Name: name\n":                                              "acroFields: requires pdf",
+		"pdf: formular.pdf\nbackground: formular.png\nacroFields:\n  Name: name\n": "not used with a fillable PDF",
+	} {
+		os.WriteFile(path, []byte(data), 0644)
+		if _, err := loadFormTemplate(path); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("loadFormTemplate(%q) error = %v, want %q", data, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Actual Vehicle Costs (tatsaechliche Kosten)
+// ---------------------------------------------------------------------------
+
+// ActualCosts are a vehicle's annual costs. Together with the fuel and
+// charging receipts of the override files they give an individual km rate
+// that replaces the flat rate of the vehicle.
+type ActualCosts struct {
+	Km    int     `yaml:"km"`              // total km driven with the vehicle per year
+	Costs float64 `yaml:"costs,omitempty"` // annual fixed costs: depreciation (AfA), insurance, tax, maintenance
+}
+
+// FuelReceipt is a fuel or charging receipt of a vehicle with actual costs.
+type FuelReceipt struct {
+	Date    string  `yaml:"date"`              // YYYY-MM-DD
+	Amount  float64 `yaml:"amount"`            // EUR
+	Vehicle string  `yaml:"vehicle,omitempty"` // car (default), bike or ebike
+	Note    string  `yaml:"note,omitempty"`    // e.g. the station
+}
+
+// vehicle returns the vehicle of the receipt, the car by default.
+func (f FuelReceipt) vehicle() string {
+	if f.Vehicle != "" {
+		return f.Vehicle
+	}
+	return vehicleCar
+}
+
+// actualRate is the individual km rate of a vehicle with its calculation.
+type actualRate struct {
+	vehicle string
+	costs   float64 // annual fixed costs
+	fuel    float64 // fuel and charging receipts of the year
+	km      int
+	rate    float64 // (costs + fuel) / km, rounded to cents
+}
+
+// actualRates returns the individual km rates of the vehicles with actual
+// costs in year, from the fuel and charging receipts of all the year's
+// override files. It returns nil if no vehicle uses actual costs.
+func (c *Config) actualRates(year int) ([]actualRate, error) {
+	if len(c.ActualCosts) == 0 {
+		return nil, nil
+	}
+	fuel := make(map[string]float64)
+	for m := time.January; m <= time.December; m++ {
+		ov, err := loadMonthOverride(c, year, m)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range ov.Fuel {
+			fuel[f.vehicle()] += f.Amount
+		}
+	}
+	var rates []actualRate
+	for vehicle, a := range c.ActualCosts {
+		r := actualRate{vehicle: vehicle, costs: a.Costs, fuel: roundCents(fuel[vehicle]), km: a.Km}
+		if a.Km > 0 {
+			r.rate = roundCents((r.costs + r.fuel) / float64(a.Km))
+		}
+		rates = append(rates, r)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].vehicle < rates[j].vehicle })
+	return rates, nil
+}
+
+// String formats the calculation for --explain.
+func (r actualRate) String() string {
+	return fmt.Sprintf("(%s + %s EUR Kraftstoff/Strom) / %d km = %s EUR/km",
+		formatAmount(r.costs), formatAmount(r.fuel), r.km, formatAmount(r.rate))
+}
+
+// actualRatesFor returns the individual rates that apply to the customer's
+// trips by vehicle: a kmRate agreed with the customer takes precedence over
+// the car's actual costs.
+func actualRatesFor(c Customer, rates []actualRate) map[string]float64 {
+	var m map[string]float64
+	for _, r := range rates {
+		if r.vehicle == vehicleCar && c.KmRate > 0 {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]float64)
+		}
+		m[r.vehicle] = r.rate
+	}
+	return m
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestActualCosts(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-01.yaml"), []byte("fuel:\n  - date: 2026-01-12\n    amount: 600\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte(`fuel:
+  - date: 2026-02-09
+    amount: 600
+  - date: 2026-02-10
+    amount: 50
+    vehicle: ebike
+vehicles:
+  2026-02-03: ebike
+`), 0644)
+	cfg := &Config{
+		Overrides: dir,
+		BikeRate:  0.05,
+		ActualCosts: map[string]ActualCosts{
+			vehicleCar:   {Km: 20000, Costs: 6000},
+			vehicleEBike: {Km: 1000, Costs: 50},
+		},
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{Weekdays: []string{"mon", "tue"}}},
+			{ID: "2", Name: "Vertrag", Distance: 10, Province: "BW", KmRate: 0.50, Schedule: Schedule{Weekdays: []string{"wed", "thu", "fri"}}},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Car: (6000 + 1200) / 20000 = 0.36; e-bike: (50 + 50) / 1000 = 0.10
+	if len(report.ActualRates) != 2 || report.ActualRates[0].rate != 0.36 || report.ActualRates[1].rate != 0.10 {
+		t.Fatalf("ActualRates = %+v", report.ActualRates)
+	}
+	acme, contract := report.Customers[0], report.Customers[1]
+	if acme.KmAmount != 7*36+10 {
+		t.Errorf("Acme KmAmount = %v", acme.KmAmount)
+	}
+	// The agreed kmRate takes precedence over the car's actual costs
+	if contract.KmRate != 0.50 || contract.KmAmount != 12*5 {
+		t.Errorf("Vertrag KmRate = %v, KmAmount = %v", contract.KmRate, contract.KmAmount)
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Fahrkosten \\(100 km x 0,36 EUR\\)", "Fahrkosten \\(100 km x 0,10 EUR\\)"} {
+		if !strings.Contains(km, want) {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+	if s := newRunSummary(cfg, p, report, nil); s.Customers[0].rate(0) != 0.36 || s.Customers[0].ActualRates[vehicleEBike] != 0.10 {
+		t.Errorf("summary rates = %v and %v", s.Customers[0].rate(0), s.Customers[0].ActualRates)
+	}
+
+	var out strings.Builder
+	if err := writeExplanation(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	if want := "(6000,00 + 1200,00 EUR Kraftstoff/Strom) / 20000 km = 0,36 EUR/km"; !strings.Contains(out.String(), want) {
+		t.Errorf("explanation misses %q:\n%s", want, out.String())
+	}
+}
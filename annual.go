@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Annual Report
+// ---------------------------------------------------------------------------
+
+// annualChartWidth is the maximum bar length of the monthly chart.
+const annualChartWidth = 36
+
+// annualCustomer aggregates one customer over a year.
+type annualCustomer struct {
+	ID, Name   string
+	Days       int
+	Km         int
+	KmAmount   float64
+	VerpAmount float64
+}
+
+// annualSummary aggregates the archived months of a year.
+type annualSummary struct {
+	Year      int
+	Months    []runSummary
+	Missing   []string // MM/YYYY of months without an archived report
+	Customers []annualCustomer
+	Workdays  int
+	Km        int
+	KmTotal   float64
+	VerpTotal float64
+	Expenses  float64
+}
+
+// Total returns the sum of all reimbursements of the year.
+func (a *annualSummary) Total() float64 {
+	return a.KmTotal + a.VerpTotal + a.Expenses
+}
+
+// newAnnualSummary aggregates the monthly summaries of a year. Customers are
+// listed in order of first appearance.
+func newAnnualSummary(year int, months []runSummary) *annualSummary {
+	a := &annualSummary{Year: year, Months: months}
+	index := make(map[string]int)
+
+	for _, m := range months {
+		a.Workdays += m.Workdays
+		a.KmTotal += m.KmTotal
+		a.VerpTotal += m.VerpflegungTotal
+		a.Expenses += m.ExpensesTotal
+
+		for _, c := range m.Customers {
+			i, ok := index[c.ID]
+			if !ok {
+				i = len(a.Customers)
+				index[c.ID] = i
+				a.Customers = append(a.Customers, annualCustomer{ID: c.ID, Name: c.Name})
+			}
+			a.Customers[i].Days += c.Days
+			a.Customers[i].Km += c.Km
+			a.Customers[i].KmAmount += c.KmAmount
+			a.Customers[i].VerpAmount += c.VerpflegungAmount
+			a.Km += c.Km
+		}
+	}
+
+	archived := archivedMonths(months)
+	for month := time.January; month <= time.December; month++ {
+		if !archived[periodKey(year, month)] {
+			a.Missing = append(a.Missing, fmt.Sprintf("%02d/%d", month, year))
+		}
+	}
+	return a
+}
+
+// periodLabel turns a period key into its label, e.g. "2025-03" into
+// "03/2025" or "2025-Q1" into "Q1/2025".
+func periodLabel(key string) string {
+	if p, ok := parsePeriodKey(key); ok {
+		return p.Label()
+	}
+	return key
+}
+
+// archivedMonths returns the keys (YYYY-MM) of the calendar months covered
+// by archived reports of any period kind.
+func archivedMonths(summaries []runSummary) map[string]bool {
+	covered := make(map[string]bool)
+	for _, s := range summaries {
+		if p, ok := parsePeriodKey(s.Period); ok {
+			for _, m := range p.months() {
+				covered[m.Key()] = true
+			}
+		}
+	}
+	return covered
+}
+
+// buildAnnualHeader creates the title block of the annual report.
+func buildAnnualHeader(a *annualSummary) string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("REISEKOSTEN JAHRESUEBERSICHT %d", a.Year)
+	padding := (lineWidth - len(header)) / 2
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", padding), header))
+	b.WriteString(lineDouble + "\n\n")
+
+	b.WriteString(fmt.Sprintf("Zeitraum:             01/%d - 12/%d\n", a.Year, a.Year))
+	b.WriteString(fmt.Sprintf("Erfasste Monate:      %d von 12\n", len(a.Months)))
+	if len(a.Missing) > 0 {
+		b.WriteString(fmt.Sprintf("Fehlende Monate:      %s\n", strings.Join(a.Missing, ", ")))
+	}
+	b.WriteString(fmt.Sprintf("Reisetage:            %d\n", a.Workdays))
+	b.WriteString(fmt.Sprintf("Gefahrene Kilometer:  %d\n", a.Km))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// buildSectionHeader creates a titled separator block.
+func buildSectionHeader(title string) string {
+	return lineSingle + "\n" + title + "\n" + lineSingle + "\n\n"
+}
+
+// buildAnnualMonthTable creates the per-month table.
+func buildAnnualMonthTable(a *annualSummary) string {
+	var b strings.Builder
+
+	b.WriteString(buildSectionHeader("Monatsuebersicht"))
+	row := func(label string, days, km int, kmAmount, verp, expenses, total float64) {
+		b.WriteString(fmt.Sprintf("%-10s%4d%8d%14s%13s%12s%13s\n", label, days, km,
+			formatAmount(kmAmount), formatAmount(verp), formatAmount(expenses), formatAmount(total)))
+	}
+	b.WriteString(fmt.Sprintf("%-10s%4s%8s%14s%13s%12s%13s\n", "Zeitraum", "Tage", "km", "Kilometergeld", "Verpflegung", "Nebenkost.", "Gesamt"))
+	for _, m := range a.Months {
+		km := 0
+		for _, c := range m.Customers {
+			km += c.Km
+		}
+		row(periodLabel(m.Period), m.Workdays, km, m.KmTotal, m.VerpflegungTotal, m.ExpensesTotal, m.Total)
+	}
+	b.WriteString(strings.Repeat("-", lineWidth) + "\n")
+	row("Summe", a.Workdays, a.Km, a.KmTotal, a.VerpTotal, a.Expenses, a.Total())
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// buildAnnualChart creates a bar chart of the monthly totals.
+func buildAnnualChart(a *annualSummary) string {
+	var b strings.Builder
+
+	b.WriteString(buildSectionHeader("Monatsverlauf (Gesamtbetrag in EUR)"))
+	var max float64
+	for _, m := range a.Months {
+		if m.Total > max {
+			max = m.Total
+		}
+	}
+	for _, m := range a.Months {
+		bar := 0
+		if max > 0 {
+			bar = int(m.Total / max * annualChartWidth)
+		}
+		b.WriteString(fmt.Sprintf("%-10s %-*s %12s\n", periodLabel(m.Period), annualChartWidth, strings.Repeat("#", bar), formatAmount(m.Total)))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// buildAnnualCustomerTable creates the per-customer breakdown.
+func buildAnnualCustomerTable(a *annualSummary) string {
+	var b strings.Builder
+
+	b.WriteString(buildSectionHeader("Aufteilung nach Kunden"))
+	b.WriteString(fmt.Sprintf("%-30s%5s%9s%16s%15s\n", "Kunde", "Tage", "km", "Kilometergeld", "Verpflegung"))
+	for _, c := range a.Customers {
+		name := fmt.Sprintf("%s) %s", c.ID, c.Name)
+		if len(name) > 29 {
+			name = name[:29]
+		}
+		b.WriteString(fmt.Sprintf("%-30s%5d%9d%16s%15s\n", name, c.Days, c.Km, formatAmount(c.KmAmount), formatAmount(c.VerpAmount)))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// annualCSV renders one row per month and customer, plus one row per month
+// with additional expenses. Amounts use a decimal comma for German spreadsheets.
+func annualCSV(a *annualSummary) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	w.Write([]string{"Monat", "Kunden-ID", "Kunde", "Projekt", "Kostenstelle", "Tage", "Kilometer", "Kilometergeld", "Verpflegungsmehraufwand", "Reisenebenkosten", "Gesamt"})
+	for _, m := range a.Months {
+		label := periodLabel(m.Period)
+		for _, c := range m.Customers {
+			w.Write([]string{label, c.ID, c.Name, c.Project, c.CostCenter, strconv.Itoa(c.Days), strconv.Itoa(c.Km),
+				formatAmount(c.KmAmount), formatAmount(c.VerpflegungAmount), formatAmount(0),
+				formatAmount(c.KmAmount + c.VerpflegungAmount)})
+		}
+		if m.ExpensesTotal > 0 {
+			w.Write([]string{label, "", "Reisenebenkosten", "", "", "0", "0", formatAmount(0), formatAmount(0),
+				formatAmount(m.ExpensesTotal), formatAmount(m.ExpensesTotal)})
+		}
+	}
+	w.Write([]string{"Summe", "", "", "", "", strconv.Itoa(a.Workdays), strconv.Itoa(a.Km), formatAmount(a.KmTotal),
+		formatAmount(a.VerpTotal), formatAmount(a.Expenses), formatAmount(a.Total())})
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// generateAnnualReport aggregates the archived months of a year into a PDF
+// and a CSV file in the output directory. It returns the written file paths.
+func generateAnnualReport(cfg *Config, year int) ([]string, error) {
+	months, err := loadArchive(cfg, year)
+	if err != nil {
+		return nil, err
+	}
+	if len(months) == 0 {
+		return nil, fmt.Errorf("no archived reports for %d in %s", year, cfg.ArchiveDir())
+	}
+
+	a := newAnnualSummary(year, months)
+	if len(a.Missing) > 0 {
+		slog.Warn("months missing from the archive", "months", strings.Join(a.Missing, ", "))
+	}
+
+	blocks := []string{buildAnnualMonthTable(a), buildAnnualChart(a), buildAnnualCustomerTable(a)}
+	pdfData, err := createPDF(buildAnnualHeader(a), blocks, buildDocumentFooter(a.Total()))
+	if err != nil {
+		return nil, err
+	}
+	csvData, err := annualCSV(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, out := range []Attachment{
+		{Filename: fmt.Sprintf("%d_Reisekosten_Jahresuebersicht.pdf", year), Data: pdfData},
+		{Filename: fmt.Sprintf("%d_Reisekosten_Jahresuebersicht.csv", year), Data: csvData},
+	} {
+		path, err := cfg.Output.path(newOutputFile(year, Period{}, out.Filename, ""))
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.Output.write(path, out.Data); err != nil {
+			return nil, fmt.Errorf("failed to write annual report: %w", err)
+		}
+		files = append(files, path)
+	}
+	slog.Info("annual report written", "year", year, "months", len(months),
+		"total", formatAmount(a.Total()), "pdf", files[0], "csv", files[1])
+	return files, nil
+}
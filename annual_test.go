@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunArchivesReport(t *testing.T) {
+	fakeSendGrid(t, 0)
+	noSleep(t)
+	dir := t.TempDir()
+	cfg := retryTestConfig(filepath.Join(dir, "outbox"))
+	cfg.State = filepath.Join(dir, "state.json")
+	cfg.Archive = filepath.Join(dir, "archive")
+	cfg.Overrides = filepath.Join(dir, "overrides")
+	cfg.Customers = []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}
+
+	if _, err := run(cfg, monthPeriod(2026, 2)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	months, err := loadArchive(cfg, 2026)
+	if err != nil {
+		t.Fatalf("loadArchive() error = %v", err)
+	}
+	if len(months) != 1 || months[0].Period != "2026-02" || months[0].Delivery.Status != "sent" || months[0].Total != 880 {
+		t.Errorf("archive = %+v", months)
+	}
+}
+
+func TestGenerateAnnualReport(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Output: OutputConfig{Dir: dir}, Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Project: "P-100", CostCenter: "4100"},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+	}}
+
+	if _, err := generateAnnualReport(cfg, 2025); err == nil {
+		t.Error("generateAnnualReport() expected error without archive")
+	}
+
+	for _, month := range []time.Month{time.January, time.February} {
+		report, err := generateReport(cfg, monthPeriod(2025, month))
+		if err != nil {
+			t.Fatalf("generateReport() error = %v", err)
+		}
+		if err := archiveReport(cfg, newRunSummary(cfg, monthPeriod(2025, month), report, nil), report.Attachments); err != nil {
+			t.Fatalf("archiveReport() error = %v", err)
+		}
+	}
+
+	files, err := generateAnnualReport(cfg, 2025)
+	if err != nil {
+		t.Fatalf("generateAnnualReport() error = %v", err)
+	}
+	pdf, _ := os.ReadFile(files[0])
+	if !strings.HasPrefix(string(pdf), "%PDF") {
+		t.Error("annual report is not a PDF")
+	}
+
+	// January 2025 has 21 workdays in BW (New Year and Epiphany), February 20
+	data, _ := os.ReadFile(files[1])
+	csv := string(data)
+	for _, want := range []string{
+		"Monat;Kunden-ID;Kunde;Projekt;Kostenstelle;Tage;Kilometer;Kilometergeld;Verpflegungsmehraufwand;Reisenebenkosten;Gesamt\n",
+		"01/2025;1;Acme;P-100;4100;11;1100;330,00;154,00;0,00;484,00\n",
+		"02/2025;2;Globex;;;10;500;150,00;140,00;0,00;290,00\n",
+		"Summe;;;;;41;3100;930,00;574,00;0,00;1504,00\n",
+	} {
+		if !strings.Contains(csv, want) {
+			t.Errorf("CSV missing %q:\n%s", want, csv)
+		}
+	}
+
+	a := newAnnualSummary(2025, mustLoadArchive(t, cfg, 2025))
+	if len(a.Missing) != 10 || a.Missing[0] != "03/2025" || len(a.Customers) != 2 || a.Customers[0].Days != 21 {
+		t.Errorf("annual summary = %+v", a)
+	}
+	if table := buildAnnualMonthTable(a); !strings.Contains(table, "Summe       41    3100") {
+		t.Errorf("month table:\n%s", table)
+	}
+}
+
+func mustLoadArchive(t *testing.T, cfg *Config, year int) []runSummary {
+	t.Helper()
+	months, err := loadArchive(cfg, year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return months
+}
+
+func TestLoadArchiveQuarters(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+
+	for _, p := range []Period{{Kind: periodQuarter, Year: 2026, Num: 1}, monthPeriod(2026, time.April), {Kind: periodWeek, Year: 2026, Num: 19}} {
+		report, err := generateReport(cfg, p)
+		if err != nil {
+			t.Fatalf("generateReport(%s) error = %v", p.Label(), err)
+		}
+		if err := archiveReport(cfg, newRunSummary(cfg, p, report, nil), report.Attachments); err != nil {
+			t.Fatalf("archiveReport(%s) error = %v", p.Label(), err)
+		}
+	}
+
+	summaries := mustLoadArchive(t, cfg, 2026)
+	var periods []string
+	for _, s := range summaries {
+		periods = append(periods, periodLabel(s.Period))
+	}
+	if got := strings.Join(periods, " "); got != "Q1/2026 04/2026 KW 19/2026" {
+		t.Errorf("archived periods = %s", got)
+	}
+	a := newAnnualSummary(2026, summaries)
+	if len(a.Missing) != 7 || a.Missing[0] != "06/2026" || a.Workdays != summaries[0].Workdays+summaries[1].Workdays+summaries[2].Workdays {
+		t.Errorf("annual summary = %+v", a)
+	}
+	if table := buildAnnualMonthTable(a); !strings.Contains(table, "Q1/2026     62    6200") || !strings.Contains(table, "KW 19/2026") {
+		t.Errorf("month table:\n%s", table)
+	}
+}
@@ -0,0 +1,949 @@
+// gRPC interface of serve mode. Regenerate the Go code after changes with
+// protoc --go_out=. --go_opt=paths=source_relative \
+//        --go-grpc_out=. --go-grpc_opt=paths=source_relative api/reisekosten.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.3
+// source: api/reisekosten.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GenerateReportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period    string   `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`                     // YYYY-MM, YYYY-Qn or YYYY-Wnn
+	Deliver   bool     `protobuf:"varint,2,opt,name=deliver,proto3" json:"deliver,omitempty"`                  // send, archive and record the report
+	SkipDays  []string `protobuf:"bytes,3,rep,name=skip_days,json=skipDays,proto3" json:"skip_days,omitempty"` // days without trips (YYYY-MM-DD)
+	OnlyDays  []string `protobuf:"bytes,4,rep,name=only_days,json=onlyDays,proto3" json:"only_days,omitempty"` // if set, trips only on these days (YYYY-MM-DD)
+	Customers []string `protobuf:"bytes,5,rep,name=customers,proto3" json:"customers,omitempty"`               // if set, only these customer IDs get days
+}
+
+func (x *GenerateReportRequest) Reset() {
+	*x = GenerateReportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateReportRequest) ProtoMessage() {}
+
+func (x *GenerateReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateReportRequest.ProtoReflect.Descriptor instead.
+func (*GenerateReportRequest) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GenerateReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GenerateReportRequest) GetDeliver() bool {
+	if x != nil {
+		return x.Deliver
+	}
+	return false
+}
+
+func (x *GenerateReportRequest) GetSkipDays() []string {
+	if x != nil {
+		return x.SkipDays
+	}
+	return nil
+}
+
+func (x *GenerateReportRequest) GetOnlyDays() []string {
+	if x != nil {
+		return x.OnlyDays
+	}
+	return nil
+}
+
+func (x *GenerateReportRequest) GetCustomers() []string {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+type ListReportsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+}
+
+func (x *ListReportsRequest) Reset() {
+	*x = ListReportsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsRequest) ProtoMessage() {}
+
+func (x *ListReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsRequest.ProtoReflect.Descriptor instead.
+func (*ListReportsRequest) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListReportsRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type ListReportsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reports []*Report `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"` // in calendar order
+}
+
+func (x *ListReportsResponse) Reset() {
+	*x = ListReportsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListReportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsResponse) ProtoMessage() {}
+
+func (x *ListReportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsResponse.ProtoReflect.Descriptor instead.
+func (*ListReportsResponse) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListReportsResponse) GetReports() []*Report {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type GetPDFRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period   string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`     // YYYY-MM, YYYY-Qn or YYYY-Wnn
+	Filename string `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"` // e.g. 02_2026_Reisekosten_Kilometergelderstattung.pdf
+}
+
+func (x *GetPDFRequest) Reset() {
+	*x = GetPDFRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPDFRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPDFRequest) ProtoMessage() {}
+
+func (x *GetPDFRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPDFRequest.ProtoReflect.Descriptor instead.
+func (*GetPDFRequest) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPDFRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetPDFRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+// Report mirrors the --json run summary.
+type Report struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period           string      `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Workdays         int32       `protobuf:"varint,2,opt,name=workdays,proto3" json:"workdays,omitempty"`
+	OfficeDays       int32       `protobuf:"varint,3,opt,name=office_days,json=officeDays,proto3" json:"office_days,omitempty"`
+	Warnings         []string    `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Customers        []*Customer `protobuf:"bytes,5,rep,name=customers,proto3" json:"customers,omitempty"`
+	KmTotal          float64     `protobuf:"fixed64,6,opt,name=km_total,json=kmTotal,proto3" json:"km_total,omitempty"`
+	VerpflegungTotal float64     `protobuf:"fixed64,7,opt,name=verpflegung_total,json=verpflegungTotal,proto3" json:"verpflegung_total,omitempty"`
+	ExpensesTotal    float64     `protobuf:"fixed64,8,opt,name=expenses_total,json=expensesTotal,proto3" json:"expenses_total,omitempty"`
+	Total            float64     `protobuf:"fixed64,9,opt,name=total,proto3" json:"total,omitempty"`
+	Documents        []*Document `protobuf:"bytes,10,rep,name=documents,proto3" json:"documents,omitempty"`
+	Delivery         *Delivery   `protobuf:"bytes,11,opt,name=delivery,proto3" json:"delivery,omitempty"` // only set for delivered reports
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Report) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Report) GetWorkdays() int32 {
+	if x != nil {
+		return x.Workdays
+	}
+	return 0
+}
+
+func (x *Report) GetOfficeDays() int32 {
+	if x != nil {
+		return x.OfficeDays
+	}
+	return 0
+}
+
+func (x *Report) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Report) GetCustomers() []*Customer {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+func (x *Report) GetKmTotal() float64 {
+	if x != nil {
+		return x.KmTotal
+	}
+	return 0
+}
+
+func (x *Report) GetVerpflegungTotal() float64 {
+	if x != nil {
+		return x.VerpflegungTotal
+	}
+	return 0
+}
+
+func (x *Report) GetExpensesTotal() float64 {
+	if x != nil {
+		return x.ExpensesTotal
+	}
+	return 0
+}
+
+func (x *Report) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Report) GetDocuments() []*Document {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+func (x *Report) GetDelivery() *Delivery {
+	if x != nil {
+		return x.Delivery
+	}
+	return nil
+}
+
+type Customer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Days              int32    `protobuf:"varint,3,opt,name=days,proto3" json:"days,omitempty"`
+	Dates             []string `protobuf:"bytes,4,rep,name=dates,proto3" json:"dates,omitempty"`
+	DistanceKm        int32    `protobuf:"varint,5,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	Km                int32    `protobuf:"varint,6,opt,name=km,proto3" json:"km,omitempty"`
+	KmRate            float64  `protobuf:"fixed64,7,opt,name=km_rate,json=kmRate,proto3" json:"km_rate,omitempty"`
+	KmAmount          float64  `protobuf:"fixed64,8,opt,name=km_amount,json=kmAmount,proto3" json:"km_amount,omitempty"`
+	VerpflegungRate   float64  `protobuf:"fixed64,9,opt,name=verpflegung_rate,json=verpflegungRate,proto3" json:"verpflegung_rate,omitempty"`
+	VerpflegungAmount float64  `protobuf:"fixed64,10,opt,name=verpflegung_amount,json=verpflegungAmount,proto3" json:"verpflegung_amount,omitempty"`
+	Project           string   `protobuf:"bytes,11,opt,name=project,proto3" json:"project,omitempty"`
+	CostCenter        string   `protobuf:"bytes,12,opt,name=cost_center,json=costCenter,proto3" json:"cost_center,omitempty"`
+}
+
+func (x *Customer) Reset() {
+	*x = Customer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Customer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Customer) ProtoMessage() {}
+
+func (x *Customer) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Customer.ProtoReflect.Descriptor instead.
+func (*Customer) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Customer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Customer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Customer) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *Customer) GetDates() []string {
+	if x != nil {
+		return x.Dates
+	}
+	return nil
+}
+
+func (x *Customer) GetDistanceKm() int32 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+func (x *Customer) GetKm() int32 {
+	if x != nil {
+		return x.Km
+	}
+	return 0
+}
+
+func (x *Customer) GetKmRate() float64 {
+	if x != nil {
+		return x.KmRate
+	}
+	return 0
+}
+
+func (x *Customer) GetKmAmount() float64 {
+	if x != nil {
+		return x.KmAmount
+	}
+	return 0
+}
+
+func (x *Customer) GetVerpflegungRate() float64 {
+	if x != nil {
+		return x.VerpflegungRate
+	}
+	return 0
+}
+
+func (x *Customer) GetVerpflegungAmount() float64 {
+	if x != nil {
+		return x.VerpflegungAmount
+	}
+	return 0
+}
+
+func (x *Customer) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Customer) GetCostCenter() string {
+	if x != nil {
+		return x.CostCenter
+	}
+	return ""
+}
+
+type Document struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type     string  `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id       string  `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"` // Beleg-Nr.
+	Filename string  `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	Bytes    int64   `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Amount   float64 `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Data     []byte  `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"` // PDF, set by GenerateReport and GetPDF
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Document) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Document) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Document) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Document) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *Document) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Document) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Delivery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status   string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // sent, queued or failed
+	Provider string `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	Outbox   string `protobuf:"bytes,3,opt,name=outbox,proto3" json:"outbox,omitempty"`
+	Error    string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *Delivery) Reset() {
+	*x = Delivery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_reisekosten_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Delivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Delivery) ProtoMessage() {}
+
+func (x *Delivery) ProtoReflect() protoreflect.Message {
+	mi := &file_api_reisekosten_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Delivery.ProtoReflect.Descriptor instead.
+func (*Delivery) Descriptor() ([]byte, []int) {
+	return file_api_reisekosten_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Delivery) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Delivery) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Delivery) GetOutbox() string {
+	if x != nil {
+		return x.Outbox
+	}
+	return ""
+}
+
+func (x *Delivery) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_api_reisekosten_proto protoreflect.FileDescriptor
+
+var file_api_reisekosten_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f,
+	0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31, 0x22, 0xa1, 0x01, 0x0a, 0x15, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c,
+	0x69, 0x76, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x69,
+	0x76, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x64, 0x61, 0x79, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x6b, 0x69, 0x70, 0x44, 0x61, 0x79, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x6e, 0x6c, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x73, 0x22, 0x28, 0x0a, 0x12, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x79, 0x65, 0x61, 0x72, 0x22, 0x47, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x07,
+	0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x07, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x22, 0x43,
+	0x0a, 0x0d, 0x47, 0x65, 0x74, 0x50, 0x44, 0x46, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x22, 0xa4, 0x03, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x61,
+	0x79, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x66, 0x66, 0x69, 0x63, 0x65, 0x5f, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6f, 0x66, 0x66, 0x69, 0x63, 0x65, 0x44,
+	0x61, 0x79, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12,
+	0x36, 0x0a, 0x09, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x52, 0x09, 0x63, 0x75,
+	0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x6d, 0x5f, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6b, 0x6d, 0x54, 0x6f, 0x74,
+	0x61, 0x6c, 0x12, 0x2b, 0x0a, 0x11, 0x76, 0x65, 0x72, 0x70, 0x66, 0x6c, 0x65, 0x67, 0x75, 0x6e,
+	0x67, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x76,
+	0x65, 0x72, 0x70, 0x66, 0x6c, 0x65, 0x67, 0x75, 0x6e, 0x67, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12,
+	0x25, 0x0a, 0x0e, 0x65, 0x78, 0x70, 0x65, 0x6e, 0x73, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x6e, 0x73, 0x65,
+	0x73, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x36, 0x0a, 0x09,
+	0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x08, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f,
+	0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79,
+	0x52, 0x08, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x22, 0xd4, 0x02, 0x0a, 0x08, 0x43,
+	0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
+	0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x5f, 0x6b, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x4b, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x6b, 0x6d, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x6b, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x6b, 0x6d, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6b, 0x6d, 0x52, 0x61, 0x74, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x6b, 0x6d, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x08, 0x6b, 0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10,
+	0x76, 0x65, 0x72, 0x70, 0x66, 0x6c, 0x65, 0x67, 0x75, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x74, 0x65,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x76, 0x65, 0x72, 0x70, 0x66, 0x6c, 0x65, 0x67,
+	0x75, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x76, 0x65, 0x72, 0x70, 0x66,
+	0x6c, 0x65, 0x67, 0x75, 0x6e, 0x67, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x11, 0x76, 0x65, 0x72, 0x70, 0x66, 0x6c, 0x65, 0x67, 0x75, 0x6e, 0x67,
+	0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63,
+	0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74,
+	0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x73, 0x74, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x65, 0x72, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x73, 0x74, 0x43, 0x65, 0x6e, 0x74, 0x65,
+	0x72, 0x22, 0x8c, 0x01, 0x0a, 0x08, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x6c, 0x0a, 0x08, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x32, 0xf9,
+	0x01, 0x0a, 0x0b, 0x52, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x12, 0x4f,
+	0x0a, 0x0e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x25, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b,
+	0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x56, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x22,
+	0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x23, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x50, 0x44,
+	0x46, 0x12, 0x1d, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x44, 0x46, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x18, 0x2e, 0x72, 0x65, 0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x11, 0x5a, 0x0f, 0x72, 0x65,
+	0x69, 0x73, 0x65, 0x6b, 0x6f, 0x73, 0x74, 0x65, 0x6e, 0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_reisekosten_proto_rawDescOnce sync.Once
+	file_api_reisekosten_proto_rawDescData = file_api_reisekosten_proto_rawDesc
+)
+
+func file_api_reisekosten_proto_rawDescGZIP() []byte {
+	file_api_reisekosten_proto_rawDescOnce.Do(func() {
+		file_api_reisekosten_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_reisekosten_proto_rawDescData)
+	})
+	return file_api_reisekosten_proto_rawDescData
+}
+
+var file_api_reisekosten_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_reisekosten_proto_goTypes = []any{
+	(*GenerateReportRequest)(nil), // 0: reisekosten.v1.GenerateReportRequest
+	(*ListReportsRequest)(nil),    // 1: reisekosten.v1.ListReportsRequest
+	(*ListReportsResponse)(nil),   // 2: reisekosten.v1.ListReportsResponse
+	(*GetPDFRequest)(nil),         // 3: reisekosten.v1.GetPDFRequest
+	(*Report)(nil),                // 4: reisekosten.v1.Report
+	(*Customer)(nil),              // 5: reisekosten.v1.Customer
+	(*Document)(nil),              // 6: reisekosten.v1.Document
+	(*Delivery)(nil),              // 7: reisekosten.v1.Delivery
+}
+var file_api_reisekosten_proto_depIdxs = []int32{
+	4, // 0: reisekosten.v1.ListReportsResponse.reports:type_name -> reisekosten.v1.Report
+	5, // 1: reisekosten.v1.Report.customers:type_name -> reisekosten.v1.Customer
+	6, // 2: reisekosten.v1.Report.documents:type_name -> reisekosten.v1.Document
+	7, // 3: reisekosten.v1.Report.delivery:type_name -> reisekosten.v1.Delivery
+	0, // 4: reisekosten.v1.Reisekosten.GenerateReport:input_type -> reisekosten.v1.GenerateReportRequest
+	1, // 5: reisekosten.v1.Reisekosten.ListReports:input_type -> reisekosten.v1.ListReportsRequest
+	3, // 6: reisekosten.v1.Reisekosten.GetPDF:input_type -> reisekosten.v1.GetPDFRequest
+	4, // 7: reisekosten.v1.Reisekosten.GenerateReport:output_type -> reisekosten.v1.Report
+	2, // 8: reisekosten.v1.Reisekosten.ListReports:output_type -> reisekosten.v1.ListReportsResponse
+	6, // 9: reisekosten.v1.Reisekosten.GetPDF:output_type -> reisekosten.v1.Document
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_api_reisekosten_proto_init() }
+func file_api_reisekosten_proto_init() {
+	if File_api_reisekosten_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_reisekosten_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GenerateReportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ListReportsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ListReportsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetPDFRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Report); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Customer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Document); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_reisekosten_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*Delivery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_reisekosten_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_reisekosten_proto_goTypes,
+		DependencyIndexes: file_api_reisekosten_proto_depIdxs,
+		MessageInfos:      file_api_reisekosten_proto_msgTypes,
+	}.Build()
+	File_api_reisekosten_proto = out.File
+	file_api_reisekosten_proto_rawDesc = nil
+	file_api_reisekosten_proto_goTypes = nil
+	file_api_reisekosten_proto_depIdxs = nil
+}
@@ -0,0 +1,202 @@
+// gRPC interface of serve mode. Regenerate the Go code after changes with
+// protoc --go_out=. --go_opt=paths=source_relative \
+//        --go-grpc_out=. --go-grpc_opt=paths=source_relative api/reisekosten.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.27.3
+// source: api/reisekosten.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Reisekosten_GenerateReport_FullMethodName = "/reisekosten.v1.Reisekosten/GenerateReport"
+	Reisekosten_ListReports_FullMethodName    = "/reisekosten.v1.Reisekosten/ListReports"
+	Reisekosten_GetPDF_FullMethodName         = "/reisekosten.v1.Reisekosten/GetPDF"
+)
+
+// ReisekostenClient is the client API for Reisekosten service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Reisekosten generates travel expense reports with the same core as the CLI.
+type ReisekostenClient interface {
+	// GenerateReport generates the report of a period and optionally delivers
+	// it like a command line run.
+	GenerateReport(ctx context.Context, in *GenerateReportRequest, opts ...grpc.CallOption) (*Report, error)
+	// ListReports returns the archived (delivered) reports of a year.
+	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error)
+	// GetPDF returns a document of an archived report (needs archiveDocuments).
+	GetPDF(ctx context.Context, in *GetPDFRequest, opts ...grpc.CallOption) (*Document, error)
+}
+
+type reisekostenClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReisekostenClient(cc grpc.ClientConnInterface) ReisekostenClient {
+	return &reisekostenClient{cc}
+}
+
+func (c *reisekostenClient) GenerateReport(ctx context.Context, in *GenerateReportRequest, opts ...grpc.CallOption) (*Report, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Report)
+	err := c.cc.Invoke(ctx, Reisekosten_GenerateReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reisekostenClient) ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReportsResponse)
+	err := c.cc.Invoke(ctx, Reisekosten_ListReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reisekostenClient) GetPDF(ctx context.Context, in *GetPDFRequest, opts ...grpc.CallOption) (*Document, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Document)
+	err := c.cc.Invoke(ctx, Reisekosten_GetPDF_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReisekostenServer is the server API for Reisekosten service.
+// All implementations must embed UnimplementedReisekostenServer
+// for forward compatibility
+//
+// Reisekosten generates travel expense reports with the same core as the CLI.
+type ReisekostenServer interface {
+	// GenerateReport generates the report of a period and optionally delivers
+	// it like a command line run.
+	GenerateReport(context.Context, *GenerateReportRequest) (*Report, error)
+	// ListReports returns the archived (delivered) reports of a year.
+	ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error)
+	// GetPDF returns a document of an archived report (needs archiveDocuments).
+	GetPDF(context.Context, *GetPDFRequest) (*Document, error)
+	mustEmbedUnimplementedReisekostenServer()
+}
+
+// UnimplementedReisekostenServer must be embedded to have forward compatible implementations.
+type UnimplementedReisekostenServer struct {
+}
+
+func (UnimplementedReisekostenServer) GenerateReport(context.Context, *GenerateReportRequest) (*Report, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateReport not implemented")
+}
+func (UnimplementedReisekostenServer) ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
+}
+func (UnimplementedReisekostenServer) GetPDF(context.Context, *GetPDFRequest) (*Document, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPDF not implemented")
+}
+func (UnimplementedReisekostenServer) mustEmbedUnimplementedReisekostenServer() {}
+
+// UnsafeReisekostenServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReisekostenServer will
+// result in compilation errors.
+type UnsafeReisekostenServer interface {
+	mustEmbedUnimplementedReisekostenServer()
+}
+
+func RegisterReisekostenServer(s grpc.ServiceRegistrar, srv ReisekostenServer) {
+	s.RegisterService(&Reisekosten_ServiceDesc, srv)
+}
+
+func _Reisekosten_GenerateReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReisekostenServer).GenerateReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reisekosten_GenerateReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReisekostenServer).GenerateReport(ctx, req.(*GenerateReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reisekosten_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReisekostenServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reisekosten_ListReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReisekostenServer).ListReports(ctx, req.(*ListReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reisekosten_GetPDF_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPDFRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReisekostenServer).GetPDF(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reisekosten_GetPDF_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReisekostenServer).GetPDF(ctx, req.(*GetPDFRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Reisekosten_ServiceDesc is the grpc.ServiceDesc for Reisekosten service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Reisekosten_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reisekosten.v1.Reisekosten",
+	HandlerType: (*ReisekostenServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateReport",
+			Handler:    _Reisekosten_GenerateReport_Handler,
+		},
+		{
+			MethodName: "ListReports",
+			Handler:    _Reisekosten_ListReports_Handler,
+		},
+		{
+			MethodName: "GetPDF",
+			Handler:    _Reisekosten_GetPDF_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/reisekosten.proto",
+}
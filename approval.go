@@ -0,0 +1,302 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Approval Workflow
+// ---------------------------------------------------------------------------
+
+// draftDocumentID replaces the Beleg-Nr. in previews sent for approval.
+const draftDocumentID = "ENTWURF"
+
+// ApprovalConfig enables a preview mail to an approver before the report is
+// generated with its official Beleg-Nr. and delivered.
+type ApprovalConfig struct {
+	To  string `yaml:"to,omitempty"`  // approver address, enables the approval step
+	URL string `yaml:"url,omitempty"` // public base URL of serve mode for approve/reject links
+}
+
+// pendingApproval is a report waiting for the approver's decision, with what
+// the preview showed to check the final report against.
+type pendingApproval struct {
+	Period    string    `json:"period"` // period key
+	Filter    RunFilter `json:"filter"`
+	Requested time.Time `json:"requested"`
+	Total     float64   `json:"total"`
+	Dates     []string  `json:"dates,omitempty"` // trip dates, sorted
+	Hash      string    `json:"hash,omitempty"`  // SHA-256 of the previewed content, see approvalHash
+}
+
+// newPendingApproval records the preview of a report for approval.
+func newPendingApproval(cfg *Config, p Period, report *Report) pendingApproval {
+	s := newRunSummary(cfg, p, report, nil)
+	return pendingApproval{
+		Period:    p.Key(),
+		Filter:    cfg.Filter,
+		Requested: time.Now(),
+		Total:     s.Total,
+		Dates:     tripDates(s),
+		Hash:      approvalHash(s),
+	}
+}
+
+// tripDates returns the sorted trip dates of a run summary.
+func tripDates(s runSummary) []string {
+	var dates []string
+	for _, c := range s.Customers {
+		dates = append(dates, c.Dates...)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// approvalHash returns the SHA-256 of the content of a report: its summary
+// without the Beleg-Nrn., file sizes and hashes, which differ between the
+// preview and the final documents, and without warnings, budget and delivery.
+func approvalHash(s runSummary) string {
+	s.Warnings, s.Budget, s.Delivery = nil, nil, deliverySummary{}
+	docs := make([]documentSummary, len(s.Documents))
+	for i, d := range s.Documents {
+		docs[i] = documentSummary{Type: d.Type, Filename: d.Filename, Amount: d.Amount}
+	}
+	s.Documents = docs
+	data, _ := json.Marshal(s)
+	return sha256Hex(data)
+}
+
+// check returns an error if the final report differs from the approved
+// preview. Approvals requested by older versions carry no hash and are not
+// checked.
+func (a pendingApproval) check(cfg *Config, p Period, report *Report) error {
+	if a.Hash == "" {
+		return nil
+	}
+	s := newRunSummary(cfg, p, report, nil)
+	var diff string
+	switch {
+	case s.Total != a.Total:
+		diff = fmt.Sprintf("total %s EUR instead of %s EUR", formatAmount(s.Total), formatAmount(a.Total))
+	case strings.Join(tripDates(s), ",") != strings.Join(a.Dates, ","):
+		diff = "other trip dates"
+	case approvalHash(s) != a.Hash:
+		diff = "other content"
+	default:
+		return nil
+	}
+	return fmt.Errorf("report %s differs from the approved preview (%s), run it again for a new approval", p.Label(), diff)
+}
+
+// documentID returns a new Beleg-Nr. for the period, or draftDocumentID
+// while generating a preview.
+func (c *Config) documentID(p Period) string {
+	if c.Draft {
+		return draftDocumentID
+	}
+	return documentID(p)
+}
+
+// newApprovalToken returns a random token identifying a pending approval.
+func newApprovalToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestApproval generates a preview of the period's report and mails it to
+// the approver together with an approve/reject token. An earlier pending
+// approval of the same period is replaced.
+func requestApproval(cfg *Config, p Period) (*Report, error) {
+	draft := *cfg
+	draft.Draft = true
+	report, err := generateReport(&draft, p)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return report, err
+	}
+	for token, a := range state.Approvals {
+		if a.Period == p.Key() {
+			delete(state.Approvals, token)
+		}
+	}
+	if state.Approvals == nil {
+		state.Approvals = make(map[string]pendingApproval)
+	}
+	token := newApprovalToken()
+	state.Approvals[token] = newPendingApproval(&draft, p, report)
+
+	attachments, err := encryptAttachments(cfg, []string{cfg.Approval.To}, report.Attachments)
+	if err != nil {
+		return report, err
+	}
+	subject, err := reportSubject(cfg, p, report)
+	if err != nil {
+		return report, err
+	}
+	mail := Mail{
+		To:          cfg.Approval.To,
+		Subject:     "Freigabe: " + subject,
+		Body:        approvalBody(cfg, report, token),
+		Attachments: attachments,
+	}
+	// Keep the token of a queued preview, flush delivers it later
+	err = deliver(cfg, mail)
+	var queued *QueuedError
+	if err != nil && !errors.As(err, &queued) {
+		return report, err
+	}
+	slog.Info("approval requested", "period", p.Label(), "approver", cfg.Approval.To)
+	if serr := state.save(cfg.StateFile()); serr != nil {
+		return report, serr
+	}
+	return report, err
+}
+
+// approvalBody renders the preview mail with the approve/reject instructions.
+func approvalBody(cfg *Config, report *Report, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bitte pruefe die Reisekostenabrechnung %s (Gesamtbetrag %s EUR).<br><br>",
+		report.Period.Label(), formatAmount(report.Total()))
+	if cfg.Approval.URL != "" {
+		base := strings.TrimSuffix(cfg.Approval.URL, "/")
+		fmt.Fprintf(&b, `<a href="%s/approve?token=%s">Freigeben</a> | <a href="%s/reject?token=%s">Ablehnen</a><br><br>`,
+			html.EscapeString(base), token, html.EscapeString(base), token)
+	}
+	fmt.Fprintf(&b, "Freigeben: <code>reisekosten approve %s</code><br>", token)
+	fmt.Fprintf(&b, "Ablehnen: <code>reisekosten reject %s</code><br>", token)
+	return b.String()
+}
+
+// takeApproval looks up a pending approval by token.
+func takeApproval(cfg *Config, token string) (*State, pendingApproval, Period, error) {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return nil, pendingApproval{}, Period{}, err
+	}
+	a, ok := state.Approvals[token]
+	if !ok {
+		return nil, pendingApproval{}, Period{}, fmt.Errorf("no pending approval for token %q", token)
+	}
+	p, ok := parsePeriodKey(a.Period)
+	if !ok {
+		return nil, pendingApproval{}, Period{}, fmt.Errorf("invalid period %q in pending approval", a.Period)
+	}
+	return state, a, p, nil
+}
+
+// approve generates the final report of a pending approval with its official
+// Beleg-Nr. and delivers it, unless it differs from the preview, e.g. after
+// the config or an override was changed. The approval is removed once the
+// report is sent or queued.
+func approve(cfg *Config, token string) (Period, *Report, error) {
+	_, a, p, err := takeApproval(cfg, token)
+	if err != nil {
+		return Period{}, nil, err
+	}
+
+	final := *cfg
+	final.Filter = a.Filter
+	report, err := generateReport(&final, p)
+	if err != nil {
+		return p, nil, err
+	}
+	if err := a.check(&final, p, report); err != nil {
+		return p, nil, err
+	}
+	err = deliverReport(&final, p, report)
+	var queued *QueuedError
+	if err != nil && !errors.As(err, &queued) {
+		return p, report, err
+	}
+
+	// deliverReport has updated the state file
+	state, serr := loadState(cfg.StateFile())
+	if serr != nil {
+		return p, report, serr
+	}
+	delete(state.Approvals, token)
+	slog.Info("report approved and delivered", "period", p.Label())
+	audit(cfg, "approve", p, nil, "")
+	if serr := state.save(cfg.StateFile()); serr != nil {
+		return p, report, serr
+	}
+	return p, report, err
+}
+
+// reject discards a pending approval.
+func reject(cfg *Config, token string) (Period, error) {
+	state, _, p, err := takeApproval(cfg, token)
+	if err != nil {
+		return Period{}, err
+	}
+	delete(state.Approvals, token)
+	slog.Info("report rejected", "period", p.Label())
+	audit(cfg, "reject", p, nil, "")
+	return p, state.save(cfg.StateFile())
+}
+
+// pendingFor reports whether a report of the period waits for approval.
+func (s *State) pendingFor(p Period) bool {
+	for _, a := range s.Approvals {
+		if a.Period == p.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalHandler serves the approve/reject links of the preview mail. GET
+// shows a confirmation form, so link scanners of mail gateways cannot
+// trigger the action; POST performs it.
+func approvalHandler(cfg *Config, action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.FormValue("token")
+		label := map[string]string{"approve": "Freigeben", "reject": "Ablehnen"}[action]
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, `<form method="post"><input type="hidden" name="token" value="%s"><button>%s</button></form>`,
+				html.EscapeString(token), label)
+			return
+		}
+
+		lock, err := acquireLock(cfg.LockFile())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		defer lock.release()
+
+		var p Period
+		if action == "approve" {
+			var report *Report
+			p, report, err = approve(cfg, token)
+			if p.Year != 0 {
+				notifyRun(cfg, p, report, err)
+			}
+		} else {
+			p, err = reject(cfg, token)
+		}
+		if err != nil {
+			slog.Error("approval failed", "action", action, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%s: %s\n", p.Label(), label)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func approvalTestConfig(t *testing.T) (*Config, string) {
+	dir := t.TempDir()
+	return &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Approval:  ApprovalConfig{To: "approver@example.com", URL: "https://rk.example.com/"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}, dir
+}
+
+func TestApproval(t *testing.T) {
+	cfg, dir := approvalTestConfig(t)
+	p := monthPeriod(2026, 2)
+
+	report, err := run(cfg, p)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if report.KmDocID != draftDocumentID {
+		t.Errorf("preview KmDocID = %q, want %q", report.KmDocID, draftDocumentID)
+	}
+	state, _ := loadState(cfg.StateFile())
+	if len(state.Approvals) != 1 || !state.pendingFor(p) || state.MessageIDs[p.Key()] != "" {
+		t.Fatalf("state = %+v", state)
+	}
+	var token string
+	for token = range state.Approvals {
+	}
+	mails, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml"))
+	if len(mails) != 1 {
+		t.Fatalf("mails = %v, want preview only", mails)
+	}
+	data, _ := os.ReadFile(mails[0])
+	if !strings.Contains(string(data), "To: approver@example.com") {
+		t.Errorf("preview mail not sent to approver:\n%s", data)
+	}
+	if body := approvalBody(cfg, report, token); !strings.Contains(body, `href="https://rk.example.com/approve?token=`+token+`"`) {
+		t.Errorf("approvalBody() = %q, want approve link", body)
+	}
+
+	got, final, err := approve(cfg, token)
+	if err != nil || got != p {
+		t.Fatalf("approve() = %v, %v", got, err)
+	}
+	if !strings.HasPrefix(final.KmDocID, "RK-2026-02-") {
+		t.Errorf("final KmDocID = %q", final.KmDocID)
+	}
+	state, _ = loadState(cfg.StateFile())
+	if len(state.Approvals) != 0 || state.MessageIDs[p.Key()] == "" {
+		t.Errorf("state after approval = %+v", state)
+	}
+	if _, _, err := approve(cfg, token); err == nil {
+		t.Error("approve() expected error for used token")
+	}
+}
+
+func TestApprovalChanged(t *testing.T) {
+	cfg, dir := approvalTestConfig(t)
+	p := monthPeriod(2026, 2)
+	if _, err := requestApproval(cfg, p); err != nil {
+		t.Fatalf("requestApproval() error = %v", err)
+	}
+	state, _ := loadState(cfg.StateFile())
+	var token string
+	for token = range state.Approvals {
+	}
+	if a := state.Approvals[token]; a.Total != 880 || len(a.Dates) != 20 || a.Hash == "" {
+		t.Errorf("pending approval = %+v", a)
+	}
+
+	// The final report must be the one the approver saw
+	for change, want := range map[string]string{
+		"distance": "total 1000,00 EUR instead of 880,00 EUR",
+		"name":     "other content",
+	} {
+		changed := *cfg
+		changed.Customers = []Customer{cfg.Customers[0]}
+		if change == "distance" {
+			changed.Customers[0].Distance = 120
+		} else {
+			changed.Customers[0].Name = "Acme GmbH"
+		}
+		if _, _, err := approve(&changed, token); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("approve() with changed %s error = %v, want %q", change, err, want)
+		}
+	}
+	if mails, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml")); len(mails) != 1 {
+		t.Errorf("mails = %v, want the preview only", mails)
+	}
+	if state, _ := loadState(cfg.StateFile()); !state.pendingFor(p) {
+		t.Error("approval removed by a refused approve")
+	}
+	if _, _, err := approve(cfg, token); err != nil {
+		t.Errorf("approve() unchanged error = %v", err)
+	}
+}
+
+func TestApprovalHandler(t *testing.T) {
+	cfg, _ := approvalTestConfig(t)
+	if _, err := requestApproval(cfg, monthPeriod(2026, 2)); err != nil {
+		t.Fatalf("requestApproval() error = %v", err)
+	}
+	state, _ := loadState(cfg.StateFile())
+	var token string
+	for token = range state.Approvals {
+	}
+
+	h := approvalHandler(cfg, "reject")
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/reject?token="+token, nil))
+	if !strings.Contains(rec.Body.String(), `<form method="post">`) {
+		t.Errorf("GET body = %q, want confirmation form", rec.Body.String())
+	}
+	if state, _ := loadState(cfg.StateFile()); len(state.Approvals) != 1 {
+		t.Fatal("GET must not reject")
+	}
+
+	form := url.Values{"token": {token}}
+	req := httptest.NewRequest(http.MethodPost, "/reject", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("POST status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if state, _ := loadState(cfg.StateFile()); len(state.Approvals) != 0 {
+		t.Errorf("approvals after reject = %+v", state.Approvals)
+	}
+
+	rec = httptest.NewRecorder()
+	h(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST unknown token status = %d", rec.Code)
+	}
+}
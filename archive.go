@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Report Archive
+// ---------------------------------------------------------------------------
+
+const defaultArchiveDir = "archive"
+
+// ArchiveDir returns the directory holding the summaries of delivered reports.
+// Profiles get their own subdirectory by default.
+func (c *Config) ArchiveDir() string {
+	if c.Archive != "" {
+		return c.Archive
+	}
+	if c.Profile != "" {
+		return filepath.Join(defaultArchiveDir, c.Profile)
+	}
+	return defaultArchiveDir
+}
+
+// archiveReport stores the summary of a delivered (or queued) report as
+// <archive>/YYYY-MM.json, replacing an earlier run of the same month. With
+// archiveDocuments the unencrypted PDFs are kept in <archive>/YYYY-MM/.
+func archiveReport(cfg *Config, s runSummary, documents []Attachment) error {
+	dir := cfg.ArchiveDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, s.Period+".json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	slog.Debug("report archived", "path", path)
+
+	if p, ok := parsePeriodKey(s.Period); ok {
+		if err := archiveSnapshot(cfg, p); err != nil {
+			return err
+		}
+	}
+
+	if !cfg.ArchiveDocuments {
+		return nil
+	}
+	docDir := filepath.Join(dir, s.Period)
+	if err := os.RemoveAll(docDir); err != nil {
+		return fmt.Errorf("failed to replace archived documents: %w", err)
+	}
+	if err := os.MkdirAll(docDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	for _, a := range documents {
+		if err := os.WriteFile(filepath.Join(docDir, a.Filename), a.Data, 0o600); err != nil {
+			return fmt.Errorf("failed to write archived document: %w", err)
+		}
+	}
+	manifest, err := manifestJSON(s, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(docDir, manifestFilename), manifest, 0o600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	slog.Debug("documents archived", "dir", docDir, "files", len(documents))
+	return nil
+}
+
+// loadArchive returns the archived summaries of all reports of a year,
+// months, quarters and weeks, in the order of their start.
+func loadArchive(cfg *Config, year int) ([]runSummary, error) {
+	_, summaries, err := loadArchivedPeriods(cfg, year)
+	return summaries, err
+}
+
+// loadArchivedPeriods returns the archived summaries of all periods of a
+// year, months, quarters and weeks, with their periods, in the order of their
+// start.
+func loadArchivedPeriods(cfg *Config, year int) ([]Period, []runSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(cfg.ArchiveDir(), fmt.Sprintf("%d-*.json", year)))
+	if err != nil {
+		return nil, nil, err
+	}
+	var periods []Period
+	for _, path := range paths {
+		if p, ok := parsePeriodKey(strings.TrimSuffix(filepath.Base(path), ".json")); ok && p.Year == year {
+			periods = append(periods, p)
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		if !periods[i].Start().Equal(periods[j].Start()) {
+			return periods[i].Start().Before(periods[j].Start())
+		}
+		return periods[i].End().Before(periods[j].End())
+	})
+
+	summaries := make([]runSummary, 0, len(periods))
+	for _, p := range periods {
+		s, err := loadArchivedPeriod(cfg, p.Key())
+		if err != nil {
+			return nil, nil, err
+		}
+		summaries = append(summaries, *s)
+	}
+	return periods, summaries, nil
+}
+
+// loadArchivedMonth returns the archived summary of a month, or nil if the
+// month has not been archived.
+func loadArchivedMonth(cfg *Config, year int, month time.Month) (*runSummary, error) {
+	return loadArchivedPeriod(cfg, periodKey(year, month))
+}
+
+// loadArchivedPeriod returns the archived summary of a period key, or nil if
+// the period has not been archived.
+func loadArchivedPeriod(cfg *Config, key string) (*runSummary, error) {
+	path := filepath.Join(cfg.ArchiveDir(), key+".json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	var s runSummary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse archive %s: %w", path, err)
+	}
+	return &s, nil
+}
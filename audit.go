@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Audit Log
+// ---------------------------------------------------------------------------
+
+const defaultAuditFile = "reisekosten-audit.jsonl"
+
+// auditEntry is one line of the append-only audit log.
+type auditEntry struct {
+	Time       time.Time       `json:"time"`
+	Action     string          `json:"action"`           // generate, send, correction, resend, approve, reject, close, reopen, prune, backup, restore or config
+	Period     string          `json:"period,omitempty"` // YYYY-MM, YYYY-Qn or YYYY-Wnn
+	Profile    string          `json:"profile,omitempty"`
+	ConfigHash string          `json:"configHash"` // SHA-256 of the resolved configuration
+	Documents  []auditDocument `json:"documents,omitempty"`
+	Detail     string          `json:"detail,omitempty"` // e.g. delivery status or error
+}
+
+type auditDocument struct {
+	ID     string `json:"id"`
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// auditMu serializes appends, e.g. of concurrently generated backfill months.
+var auditMu sync.Mutex
+
+// AuditFile returns the path of the audit log, by default next to the state
+// file. Profiles get their own log by default.
+func (c *Config) AuditFile() string {
+	if c.Audit != "" {
+		return c.Audit
+	}
+	name := defaultAuditFile
+	if c.Profile != "" {
+		name = strings.TrimSuffix(defaultAuditFile, ".jsonl") + "-" + c.Profile + ".jsonl"
+	}
+	return filepath.Join(filepath.Dir(c.StateFile()), name)
+}
+
+// configHash returns the SHA-256 of the resolved configuration, so an entry
+// names the exact configuration it was made with.
+func configHash(cfg *Config) string {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	return sha256Hex(data)
+}
+
+// audit appends an entry to the audit log. A changed configuration is
+// recorded first as its own config entry. Failures are logged, they never
+// stop a run.
+func audit(cfg *Config, action string, p Period, report *Report, detail string) {
+	e := auditEntry{Time: time.Now(), Action: action, Profile: cfg.Profile, ConfigHash: configHash(cfg), Detail: detail}
+	if p.Year != 0 {
+		e.Period = p.Key()
+	}
+	if report != nil {
+		for _, d := range newRunSummary(cfg, p, report, nil).Documents {
+			e.Documents = append(e.Documents, auditDocument{ID: d.ID, File: d.Filename, SHA256: d.SHA256})
+		}
+	}
+	if err := appendAudit(cfg.AuditFile(), e); err != nil {
+		slog.Warn("failed to write audit log", "error", err)
+	}
+}
+
+// auditIssued records the generation of documents that were delivered,
+// stored or streamed. Drafts and simulations issue no documents.
+func auditIssued(cfg *Config, p Period, report *Report, detail string) {
+	if cfg.Draft || cfg.Simulation {
+		return
+	}
+	audit(cfg, "generate", p, report, detail)
+}
+
+// appendAudit appends an entry, preceded by a config entry if the
+// configuration differs from the one of the previous entry.
+func appendAudit(path string, e auditEntry) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	entries, err := readAudit(path)
+	if err != nil {
+		return err
+	}
+	var lines []auditEntry
+	if len(entries) == 0 || entries[len(entries)-1].ConfigHash != e.ConfigHash {
+		lines = append(lines, auditEntry{Time: e.Time, Action: "config", Profile: e.Profile, ConfigHash: e.ConfigHash})
+	}
+	lines = append(lines, e)
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, l := range lines {
+		if err := enc.Encode(l); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// readAudit reads all entries of the audit log. A missing log has none.
+func readAudit(path string) ([]auditEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		var e auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log %s:%d: %w", path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// printAudit prints the entries whose period starts with prefix (e.g. 2026
+// or 2026-02; empty for all), as a table or as JSON lines.
+func printAudit(cfg *Config, prefix string, asJSON bool, w io.Writer) error {
+	entries, err := readAudit(cfg.AuditFile())
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		if prefix != "" && !strings.HasPrefix(e.Period, prefix) {
+			continue
+		}
+		if asJSON {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+			continue
+		}
+		var ids []string
+		for _, d := range e.Documents {
+			ids = append(ids, d.ID)
+		}
+		hash := e.ConfigHash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, e.Period, hash, strings.Join(ids, ","), e.Detail)
+	}
+	return tw.Flush()
+}
+
+// deliveryDetail describes the outcome of a delivery for the audit log.
+func deliveryDetail(to string, err error) string {
+	var queued *QueuedError
+	switch {
+	case errors.As(err, &queued):
+		return "queued for " + to + ": " + queued.Err.Error()
+	case err != nil:
+		return "failed: " + err.Error()
+	}
+	return "sent to " + to
+}
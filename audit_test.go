@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain runs the tests in a temporary directory, so files at their
+// default paths (audit log, state, outbox) never end up in the source tree.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "reisekosten-test")
+	if err != nil {
+		panic(err)
+	}
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	code := m.Run()
+	os.Chdir(wd)
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestAudit(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, time.February)
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	cfg.Customers[0].Distance = 120
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if err := closeMonth(cfg, p, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readAudit(filepath.Join(dir, defaultAuditFile))
+	if err != nil {
+		t.Fatalf("readAudit() error = %v", err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if got := strings.Join(actions, ","); got != "config,generate,send,config,generate,correction,close" {
+		t.Fatalf("actions = %s", got)
+	}
+	send := entries[2]
+	if send.Period != "2026-02" || len(send.Documents) != 2 || send.Documents[0].SHA256 == "" || send.Detail != "sent to boss@example.com" {
+		t.Errorf("send entry = %+v", send)
+	}
+	if entries[0].ConfigHash == entries[3].ConfigHash || entries[3].ConfigHash != entries[6].ConfigHash {
+		t.Error("config change not recorded")
+	}
+
+	var out bytes.Buffer
+	if err := printAudit(cfg, "2026-02", false, &out); err != nil {
+		t.Fatalf("printAudit() error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 5 || !strings.Contains(lines[4], "close") {
+		t.Errorf("printAudit() =\n%s", out.String())
+	}
+}
+
+func TestAuditIssuedOnly(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, time.February)
+
+	// Explaining and generating without delivery issue no documents
+	draft := *cfg
+	draft.Draft = true
+	for _, c := range []*Config{&draft, cfg} {
+		if _, err := generateReport(c, p); err != nil {
+			t.Fatalf("generateReport() error = %v", err)
+		}
+	}
+	if entries, err := readAudit(filepath.Join(dir, defaultAuditFile)); err != nil || len(entries) != 0 {
+		t.Errorf("audit = %+v, %v, want no entries", entries, err)
+	}
+
+	var out bytes.Buffer
+	if err := streamDocument(cfg, p, "", &out); err != nil {
+		t.Fatalf("streamDocument() error = %v", err)
+	}
+	entries, _ := readAudit(filepath.Join(dir, defaultAuditFile))
+	if len(entries) != 2 || entries[1].Action != "generate" || entries[1].Detail != "streamed 02_2026_Reisekosten_Kilometergelderstattung.pdf" {
+		t.Errorf("audit after streaming = %+v", entries)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+)
+
+// ---------------------------------------------------------------------------
+// Backup and Restore
+// ---------------------------------------------------------------------------
+
+// BackupConfig configures the encrypted remote backup of archive, config and
+// state.
+type BackupConfig struct {
+	Type       string   `yaml:"type,omitempty"`       // webdav or rclone
+	URL        string   `yaml:"url,omitempty"`        // WebDAV collection URL or rclone remote path (e.g. s3:bucket/reisekosten)
+	Username   string   `yaml:"username,omitempty"`   // WebDAV user
+	Password   string   `yaml:"password,omitempty"`   // WebDAV password
+	Recipients []string `yaml:"recipients,omitempty"` // age public keys (age1...)
+	Identity   string   `yaml:"identity,omitempty"`   // age identity file for restore
+	Passphrase string   `yaml:"passphrase,omitempty"` // instead of keys: encrypt with a passphrase
+}
+
+// backupLatest is the object name of the most recent backup. Every backup is
+// also kept under a name with its timestamp.
+const backupLatest = "latest"
+
+// rcloneCommand runs rclone (variable for tests).
+var rcloneCommand = func(stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("rclone", args...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("rclone %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// backupName returns the object name of a backup, e.g.
+// reisekosten-gmbh-20261015T060000Z.tar.gz.age.
+func backupName(cfg *Config, stamp string) string {
+	prefix := "reisekosten"
+	if cfg.Profile != "" {
+		prefix += "-" + cfg.Profile
+	}
+	return prefix + "-" + stamp + ".tar.gz.age"
+}
+
+// putBackup uploads a backup object.
+func putBackup(b BackupConfig, name string, data []byte) error {
+	if b.Type == "rclone" {
+		_, err := rcloneCommand(bytes.NewReader(data), "rcat", strings.TrimSuffix(b.URL, "/")+"/"+name)
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, strings.TrimSuffix(b.URL, "/")+"/"+name, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	_, err = doWebDAV(b, req)
+	return err
+}
+
+// getBackup downloads a backup object.
+func getBackup(b BackupConfig, name string) ([]byte, error) {
+	if b.Type == "rclone" {
+		return rcloneCommand(nil, "cat", strings.TrimSuffix(b.URL, "/")+"/"+name)
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(b.URL, "/")+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doWebDAV(b, req)
+}
+
+// doWebDAV sends a request with the configured credentials and returns the
+// response body.
+func doWebDAV(b BackupConfig, req *http.Request) ([]byte, error) {
+	if b.Username != "" {
+		req.SetBasicAuth(b.Username, b.Password)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s: HTTP %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	return body, nil
+}
+
+// backupFiles returns the files to back up, mapped from their path in the
+// backup to their path on disk: the config file and its overlays, the state
+// file and the archive.
+func backupFiles(cfg *Config) (map[string]string, error) {
+	files := make(map[string]string)
+	for i, src := range cfg.Sources {
+		name := filepath.Base(src)
+		if i > 0 {
+			name = path.Join(filepath.Base(overlayDir(cfg.Sources[0])), name)
+		}
+		files[name] = src
+	}
+	for _, f := range []string{cfg.StateFile(), cfg.AuditFile()} {
+		if _, err := os.Stat(f); err == nil {
+			files[filepath.Base(f)] = f
+		}
+	}
+
+	root := cfg.ArchiveDir()
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == root {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files[path.Join("archive", filepath.ToSlash(rel))] = p
+		return nil
+	})
+	return files, err
+}
+
+// backupRecipients returns the age recipients of the backup.
+func backupRecipients(b BackupConfig) ([]age.Recipient, error) {
+	if b.Passphrase != "" {
+		r, err := age.NewScryptRecipient(b.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	}
+	var recipients []age.Recipient
+	for _, s := range b.Recipients {
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup recipient: %w", err)
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// backupIdentities returns the age identities decrypting a backup.
+func backupIdentities(b BackupConfig) ([]age.Identity, error) {
+	if b.Passphrase != "" {
+		id, err := age.NewScryptIdentity(b.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{id}, nil
+	}
+	if b.Identity == "" {
+		return nil, errors.New("backup.identity is required to restore a key-encrypted backup")
+	}
+	f, err := os.Open(b.Identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup identity: %w", err)
+	}
+	defer f.Close()
+	return age.ParseIdentities(f)
+}
+
+// backup packs config, state, audit log and archive into an age-encrypted tar.gz and
+// uploads it under a timestamped name and as the latest backup. It returns
+// the timestamped name.
+func backup(cfg *Config, now time.Time) (string, error) {
+	files, err := backupFiles(cfg)
+	if err != nil {
+		return "", err
+	}
+	recipients, err := backupRecipients(cfg.Backup)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	enc, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return "", err
+	}
+	gz := gzip.NewWriter(enc)
+	tw := tar.NewWriter(gz)
+	for name, src := range files {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", src, err)
+		}
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return "", err
+		}
+	}
+	for _, c := range []io.Closer{tw, gz, enc} {
+		if err := c.Close(); err != nil {
+			return "", err
+		}
+	}
+
+	name := backupName(cfg, now.UTC().Format("20060102T150405Z"))
+	for _, n := range []string{name, backupName(cfg, backupLatest)} {
+		if err := putBackup(cfg.Backup, n, buf.Bytes()); err != nil {
+			return "", fmt.Errorf("failed to upload backup: %w", err)
+		}
+	}
+	slog.Info("backup uploaded", "name", name, "files", len(files), "bytes", buf.Len())
+	audit(cfg, "backup", Period{}, nil, name)
+	return name, nil
+}
+
+// restore downloads a backup (default: the latest), decrypts it and unpacks
+// it into dir. Existing files are only replaced with --overwrite.
+// It returns the number of restored files.
+func restore(cfg *Config, name, dir string) (int, error) {
+	if name == "" {
+		name = backupName(cfg, backupLatest)
+	}
+	data, err := getBackup(cfg.Backup, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download backup: %w", err)
+	}
+	identities, err := backupIdentities(cfg.Backup)
+	if err != nil {
+		return 0, err
+	}
+	dec, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt backup: %w", err)
+	}
+	gz, err := gzip.NewReader(dec)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	out := OutputConfig{Overwrite: cfg.Output.Overwrite}
+	tr := tar.NewReader(gz)
+	n := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("failed to read backup: %w", err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !filepath.IsLocal(filepath.FromSlash(hdr.Name)) {
+			return n, fmt.Errorf("invalid path %q in backup", hdr.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return n, fmt.Errorf("failed to read backup: %w", err)
+		}
+		if err := out.write(target, content); err != nil {
+			return n, err
+		}
+		n++
+	}
+	slog.Info("backup restored", "name", name, "dir", dir, "files", n)
+	audit(cfg, "restore", Period{}, nil, name)
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackupRestore(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if user, pass, _ := r.BasicAuth(); user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	os.WriteFile(configFile, []byte("customers: []\n"), 0o600)
+	os.MkdirAll(filepath.Join(dir, "config.d"), 0o700)
+	os.WriteFile(filepath.Join(dir, "config.d", "smtp.yaml"), []byte("smtp: {}\n"), 0o600)
+	os.MkdirAll(filepath.Join(dir, "archive", "2026-02"), 0o700)
+	os.WriteFile(filepath.Join(dir, "archive", "2026-02.json"), []byte(`{"period":"2026-02"}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "archive", "2026-02", "a.pdf"), []byte("%PDF"), 0o600)
+	os.WriteFile(filepath.Join(dir, "state.json"), []byte("{}"), 0o600)
+
+	cfg := &Config{
+		Archive: filepath.Join(dir, "archive"),
+		State:   filepath.Join(dir, "state.json"),
+		Sources: []string{configFile, filepath.Join(dir, "config.d", "smtp.yaml")},
+		Backup:  BackupConfig{Type: "webdav", URL: srv.URL + "/dav/", Username: "me", Password: "secret", Passphrase: "correct horse"},
+	}
+	name, err := backup(cfg, time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("backup() error = %v", err)
+	}
+	if name != "reisekosten-20260301T060000Z.tar.gz.age" || objects["/dav/"+name] == nil || objects["/dav/reisekosten-latest.tar.gz.age"] == nil {
+		t.Fatalf("backup() = %q, uploaded %d objects", name, len(objects))
+	}
+	if strings.Contains(string(objects["/dav/"+name]), "2026-02") {
+		t.Error("backup is not encrypted")
+	}
+
+	target := t.TempDir()
+	n, err := restore(cfg, "", target)
+	if err != nil || n != 5 {
+		t.Fatalf("restore() = %d, %v", n, err)
+	}
+	for _, f := range []string{"config.yaml", "config.d/smtp.yaml", "state.json", "archive/2026-02.json", "archive/2026-02/a.pdf"} {
+		if _, err := os.Stat(filepath.Join(target, f)); err != nil {
+			t.Errorf("restore() missing %s", f)
+		}
+	}
+	if _, err := restore(cfg, name, target); err == nil || !strings.Contains(err.Error(), "--overwrite") {
+		t.Errorf("restore() over existing files error = %v", err)
+	}
+
+	cfg.Backup.Passphrase = "wrong"
+	if _, err := restore(cfg, name, t.TempDir()); err == nil {
+		t.Error("restore() expected error with wrong passphrase")
+	}
+}
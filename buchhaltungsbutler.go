@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// BuchhaltungsButler Accounting
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerAccounting("buchhaltungsbutler", AccountingFunc(postBuchhaltungsButler))
+}
+
+// buchhaltungsbutlerAPI is the base URL of the BuchhaltungsButler API
+// (variable for tests).
+var buchhaltungsbutlerAPI = "https://app.buchhaltungsbutler.de/api/v1"
+
+// postBuchhaltungsButler uploads the document as an inbound receipt with its
+// amount, number and date. The category is used as the receipt's booking
+// account.
+func postBuchhaltungsButler(cfg *Config, v Voucher) error {
+	form := url.Values{
+		"api_key":       {cfg.Accounting.APIKey},
+		"type":          {"inbound"},
+		"filename":      {v.File.Filename},
+		"file":          {base64.StdEncoding.EncodeToString(v.File.Data)},
+		"date":          {v.Date.Format(isoDate)},
+		"amount":        {fmt.Sprintf("%.2f", v.Amount)},
+		"currency":      {"EUR"},
+		"invoicenumber": {v.DocID},
+		"counterparty":  {"Reisekosten"},
+		"comment":       {"Reisekosten - " + v.Title},
+	}
+	if v.Category != "" {
+		form.Set("postingaccount", v.Category)
+	}
+	req, err := http.NewRequest(http.MethodPost, buchhaltungsbutlerAPI+"/receipts/add", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(cfg.Accounting.APIClient, cfg.Accounting.APISecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("upload failed (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	// Errors are also reported with HTTP 200
+	var result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &result); err == nil && !result.Success {
+		return fmt.Errorf("upload failed: %s", result.Message)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ---------------------------------------------------------------------------
+// Annual Budget
+// ---------------------------------------------------------------------------
+
+// defaultBudgetWarnAt is the percentage of a target that triggers a warning.
+const defaultBudgetWarnAt = 100
+
+// BudgetConfig sets annual targets that every report compares the
+// year-to-date totals against.
+type BudgetConfig struct {
+	Km      int     `yaml:"km,omitempty"`      // business km per year (0 = no target)
+	PerDiem float64 `yaml:"perDiem,omitempty"` // EUR Verpflegungsmehraufwand per year (0 = no target)
+	WarnAt  int     `yaml:"warnAt,omitempty"`  // percent of a target that triggers a warning (default 100)
+}
+
+// enabled reports whether a target is set.
+func (b BudgetConfig) enabled() bool {
+	return b.Km > 0 || b.PerDiem > 0
+}
+
+// warnAt returns the warning threshold in percent.
+func (b BudgetConfig) warnAt() int {
+	if b.WarnAt > 0 {
+		return b.WarnAt
+	}
+	return defaultBudgetWarnAt
+}
+
+// budgetStatus is the year-to-date usage of the annual targets, including
+// the current report.
+type budgetStatus struct {
+	Year          int     `json:"year"`
+	Km            int     `json:"km"`
+	KmTarget      int     `json:"kmTarget,omitempty"`
+	PerDiem       float64 `json:"perDiem"`
+	PerDiemTarget float64 `json:"perDiemTarget,omitempty"`
+}
+
+// percent returns used as a share of target, rounded down.
+func percent(used, target float64) int {
+	return int(used / target * 100)
+}
+
+// String formats the status for the mail, e.g.
+// "12000 von 20000 km (60%), Verpflegung 1400,00 von 3000,00 EUR (46%)".
+func (s budgetStatus) String() string {
+	var text string
+	if s.KmTarget > 0 {
+		text = fmt.Sprintf("%d von %d km (%d%%)", s.Km, s.KmTarget, percent(float64(s.Km), float64(s.KmTarget)))
+	}
+	if s.PerDiemTarget > 0 {
+		if text != "" {
+			text += ", "
+		}
+		text += fmt.Sprintf("Verpflegung %s von %s EUR (%d%%)", formatAmount(s.PerDiem), formatAmount(s.PerDiemTarget), percent(s.PerDiem, s.PerDiemTarget))
+	}
+	return text
+}
+
+// yearToDate sums the km and meal allowances of the archived periods of the
+// period's year that end before it, months, quarters and weeks, and of the
+// report. An unreadable archive is skipped.
+func yearToDate(cfg *Config, p Period, report *Report) *budgetStatus {
+	s := &budgetStatus{Year: p.Year, KmTarget: cfg.Budget.Km, PerDiemTarget: cfg.Budget.PerDiem}
+	periods, archived, err := loadArchivedPeriods(cfg, p.Year)
+	if err != nil {
+		slog.Warn("budget: archive skipped", "year", p.Year, "error", err)
+	}
+	for i, m := range archived {
+		if !periods[i].End().Before(p.Start()) {
+			continue
+		}
+		for _, c := range m.Customers {
+			s.Km += c.Km
+		}
+		s.PerDiem += m.VerpflegungTotal
+	}
+	for _, c := range report.Customers {
+		s.Km += c.km()
+	}
+	s.PerDiem = roundCents(s.PerDiem + report.VerpTotal)
+	return s
+}
+
+// budgetWarnings returns a warning for each target whose year-to-date usage
+// reached the threshold.
+func budgetWarnings(b BudgetConfig, s *budgetStatus) []string {
+	var warnings []string
+	if s.KmTarget > 0 {
+		if pct := percent(float64(s.Km), float64(s.KmTarget)); pct >= b.warnAt() {
+			warnings = append(warnings, fmt.Sprintf("%d km in %d reach %d%% of the annual budget of %d km", s.Km, s.Year, pct, s.KmTarget))
+		}
+	}
+	if s.PerDiemTarget > 0 {
+		if pct := percent(s.PerDiem, s.PerDiemTarget); pct >= b.warnAt() {
+			warnings = append(warnings, fmt.Sprintf("Verpflegungsmehraufwand of %s EUR in %d reaches %d%% of the annual budget of %s EUR",
+				formatAmount(s.PerDiem), s.Year, pct, formatAmount(s.PerDiemTarget)))
+		}
+	}
+	return warnings
+}
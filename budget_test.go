@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+	jan := monthPeriod(2026, 1)
+	report, err := generateReport(cfg, jan)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Budget != nil {
+		t.Errorf("Budget without targets = %+v", report.Budget)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, jan, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+
+	// January: 20 days, February: 20 days of 100 km and 14 EUR
+	cfg.Budget = BudgetConfig{Km: 5000, PerDiem: 500, WarnAt: 80}
+	feb := monthPeriod(2026, 2)
+	if report, err = generateReport(cfg, feb); err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	want := budgetStatus{Year: 2026, Km: 4000, KmTarget: 5000, PerDiem: 560, PerDiemTarget: 500}
+	if report.Budget == nil || *report.Budget != want {
+		t.Fatalf("Budget = %+v", report.Budget)
+	}
+	if len(report.Warnings) != 2 || !strings.Contains(report.Warnings[0], "80% of the annual budget of 5000 km") || !strings.Contains(report.Warnings[1], "112%") {
+		t.Errorf("Warnings = %q", report.Warnings)
+	}
+	body := reportBody(newRunSummary(cfg, feb, report, nil))
+	if want := "Jahresbudget 2026: 4000 von 5000 km (80%), Verpflegung 560,00 von 500,00 EUR (112%)"; !strings.Contains(body, want) {
+		t.Errorf("body misses %q:\n%s", want, body)
+	}
+}
+
+func TestBudgetQuarters(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+	q1 := Period{Kind: periodQuarter, Year: 2026, Num: 1}
+	report, err := generateReport(cfg, q1)
+	if err != nil {
+		t.Fatalf("generateReport(Q1) error = %v", err)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, q1, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+	q1Km := report.Workdays * 100
+
+	// The archived quarter counts towards April, a month report
+	cfg.Budget = BudgetConfig{Km: 50000}
+	if report, err = generateReport(cfg, monthPeriod(2026, 4)); err != nil {
+		t.Fatalf("generateReport(04/2026) error = %v", err)
+	}
+	if report.Budget == nil || report.Budget.Km != q1Km+report.Workdays*100 {
+		t.Errorf("Budget = %+v, want %d km of Q1 included", report.Budget, q1Km)
+	}
+	if !chainedPeriods(cfg) {
+		t.Error("chainedPeriods() = false with a budget")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ---------------------------------------------------------------------------
+// Reimbursement Cap
+// ---------------------------------------------------------------------------
+
+// CapConfig limits the reimbursement of a single report.
+type CapConfig struct {
+	Total       float64 `yaml:"total,omitempty"`       // EUR for all documents combined
+	Kilometer   float64 `yaml:"kilometer,omitempty"`   // EUR for the Kilometergelderstattung
+	Verpflegung float64 `yaml:"verpflegung,omitempty"` // EUR for the Verpflegungsmehraufwand
+	OnExceed    string  `yaml:"onExceed,omitempty"`    // fail (default) or trim
+}
+
+// tripDay is a trip assigned to a customer (index into the run's customers).
+type tripDay struct {
+	customer   int
+	date       string    // DD.MM.YYYY
+	distance   int       // km driven there and back, including the detour
+	fromOffice bool      // the trip starts at the office
+	detour     Detour    // extra km of the trip, zero if none
+	halfDay    bool      // under 8 hours, no meal allowance
+	meals      bool      // meals provided, no meal allowance
+	vehicle    string    // car, bike or ebike
+	times      TripTimes // departure and return
+	tracks     []string  // GPX files of the driven distance, nil if configured
+	late       bool      // Nachtrag of an earlier month, printed in its own section
+	lateNote   string    // entry line of a Nachtrag
+	lateClaim  string    // period key of the report that dropped the trip, "" if not an unclaimed day
+}
+
+// perDiem reports whether the trip claims the meal allowance: no half day,
+// no provided meals and an absence of more than 8 hours.
+func (t tripDay) perDiem() bool {
+	return !t.halfDay && !t.meals && t.times.perDiemEligible()
+}
+
+// apply checks the trips against the caps. With onExceed: trim the latest
+// trips are dropped until all caps hold; otherwise an exceeded cap is an
+// error. Additional expenses count towards the total but are never trimmed.
+func (c CapConfig) apply(trips []tripDay, customers []Customer, rates Rates, expenses []Expense) ([]tripDay, error) {
+	var km, verp, extra float64
+	for _, t := range trips {
+		cust := customers[t.customer]
+		km += t.kmAmount(cust, rates)
+		if t.perDiem() {
+			verp += cust.perDiemRate(rates)
+		}
+	}
+	for _, e := range expenses {
+		extra += e.Amount
+	}
+
+	exceeded := func() string {
+		switch {
+		case c.Kilometer > 0 && km > c.Kilometer+0.005:
+			return fmt.Sprintf("Kilometergelderstattung %s EUR exceeds cap.kilometer %s EUR", formatAmount(km), formatAmount(c.Kilometer))
+		case c.Verpflegung > 0 && verp > c.Verpflegung+0.005:
+			return fmt.Sprintf("Verpflegungsmehraufwand %s EUR exceeds cap.verpflegung %s EUR", formatAmount(verp), formatAmount(c.Verpflegung))
+		case c.Total > 0 && km+verp+extra > c.Total+0.005:
+			return fmt.Sprintf("total %s EUR exceeds cap.total %s EUR", formatAmount(km+verp+extra), formatAmount(c.Total))
+		}
+		return ""
+	}
+
+	msg := exceeded()
+	if msg == "" {
+		return trips, nil
+	}
+	if c.OnExceed != "trim" {
+		return nil, fmt.Errorf("%s (set cap.onExceed: trim to drop the latest days instead)", msg)
+	}
+
+	trimmed := 0
+	for ; msg != "" && len(trips) > 0; msg = exceeded() {
+		last := trips[len(trips)-1]
+		cust := customers[last.customer]
+		km -= last.kmAmount(cust, rates)
+		if last.perDiem() {
+			verp -= cust.perDiemRate(rates)
+		}
+		trips = trips[:len(trips)-1]
+		trimmed++
+	}
+	if msg != "" {
+		return nil, fmt.Errorf("%s even without any trips", msg)
+	}
+	slog.Warn("days trimmed to stay within the cap", "days", trimmed, "total", formatAmount(km+verp+extra))
+	return trips, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateReportCap(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Overrides: dir, Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	// 20 workdays at 30 EUR km + 14 EUR Verpflegung = 880 EUR
+	cfg.Cap = CapConfig{Total: 500}
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), "total 880,00 EUR exceeds cap.total 500,00 EUR") {
+		t.Errorf("generateReport() error = %v, want cap error", err)
+	}
+
+	cfg.Cap.OnExceed = "trim"
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	dates := report.Customers[0].Dates
+	if report.Workdays != 11 || report.Total() != 484 || dates[len(dates)-1] != "16.02.2026" {
+		t.Errorf("Workdays = %d, Total = %v, last = %s", report.Workdays, report.Total(), dates[len(dates)-1])
+	}
+
+	cfg.Cap = CapConfig{Kilometer: 300, OnExceed: "trim"}
+	report, err = generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 10 || report.KmTotal != 300 {
+		t.Errorf("Workdays = %d, KmTotal = %v", report.Workdays, report.KmTotal)
+	}
+
+	// Expenses are never trimmed
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("expenses:\n  - date: 2026-02-03\n    description: Hotel\n    amount: 600\n"), 0644)
+	cfg.Cap = CapConfig{Total: 500, OnExceed: "trim"}
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), "even without any trips") {
+		t.Errorf("generateReport() error = %v, want cap error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Month Close
+// ---------------------------------------------------------------------------
+
+// closeMonth marks a month as finalized. Reports of a closed month, and of
+// quarters and weeks overlapping it, are no longer generated until the month
+// is reopened.
+func closeMonth(cfg *Config, p Period, now time.Time) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	if at, ok := state.Closed[p.Key()]; ok {
+		return fmt.Errorf("%s is already closed since %s", p.Label(), at.Format("02.01.2006 15:04"))
+	}
+	if state.pendingFor(p) {
+		return fmt.Errorf("%s has a report waiting for approval, approve or reject it first", p.Label())
+	}
+	if state.Closed == nil {
+		state.Closed = make(map[string]time.Time)
+	}
+	state.Closed[p.Key()] = now
+	slog.Info("month closed", "period", p.Label())
+	audit(cfg, "close", p, nil, "")
+	return state.save(cfg.StateFile())
+}
+
+// reopenMonth allows reports of a closed month again.
+func reopenMonth(cfg *Config, p Period) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	if _, ok := state.Closed[p.Key()]; !ok {
+		return fmt.Errorf("%s is not closed", p.Label())
+	}
+	delete(state.Closed, p.Key())
+	slog.Info("month reopened", "period", p.Label())
+	audit(cfg, "reopen", p, nil, "")
+	return state.save(cfg.StateFile())
+}
+
+// checkOpen returns an error if the period overlaps a closed month. It guards
+// the paths that issue documents; explaining, simulating and previewing a
+// closed month stay possible.
+func checkOpen(cfg *Config, p Period) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	for _, m := range p.months() {
+		if at, ok := state.Closed[m.Key()]; ok {
+			return fmt.Errorf("%s is closed since %s (use reopen %s to change it)", m.Label(), at.Format("02.01.2006 15:04"), m.Label())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCloseMonth(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	feb := monthPeriod(2026, time.February)
+	now := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	if err := closeMonth(cfg, feb, now); err != nil {
+		t.Fatalf("closeMonth() error = %v", err)
+	}
+	if err := closeMonth(cfg, feb, now); err == nil {
+		t.Error("closeMonth() expected error for a closed month")
+	}
+	for _, p := range []Period{feb, {Kind: periodQuarter, Year: 2026, Num: 1}, {Kind: periodWeek, Year: 2026, Num: 9}} {
+		if _, err := run(cfg, p); err == nil || !strings.Contains(err.Error(), "02/2026 is closed since 02.03.2026 09:30") {
+			t.Errorf("run(%s) error = %v", p.Label(), err)
+		}
+	}
+	if _, err := run(cfg, monthPeriod(2026, time.March)); err != nil {
+		t.Errorf("run(03/2026) error = %v", err)
+	}
+
+	// A closed month can still be explained and previewed
+	report, err := generateReport(cfg, feb)
+	if err != nil {
+		t.Fatalf("generateReport() of a closed month error = %v", err)
+	}
+	if err := deliverReport(cfg, feb, report); err == nil || !strings.Contains(err.Error(), "is closed") {
+		t.Errorf("deliverReport() of a closed month error = %v", err)
+	}
+
+	if err := reopenMonth(cfg, feb); err != nil {
+		t.Fatalf("reopenMonth() error = %v", err)
+	}
+	if _, err := run(cfg, feb); err != nil {
+		t.Errorf("run() after reopen error = %v", err)
+	}
+	if err := reopenMonth(cfg, feb); err == nil {
+		t.Error("reopenMonth() expected error for an open month")
+	}
+}
+
+func TestParseArgsClose(t *testing.T) {
+	if a := parseArgs([]string{"close", "2/2026"}); a.Command != "close" || a.Year != 2026 || a.Month != time.February {
+		t.Errorf("parseArgs(close 2/2026) = %+v", a)
+	}
+	if a := parseArgs([]string{"reopen"}); a.Year != 0 {
+		t.Errorf("parseArgs(reopen) defaulted to %d/%d", a.Month, a.Year)
+	}
+}
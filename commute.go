@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Commuting (Entfernungspauschale)
+// ---------------------------------------------------------------------------
+
+// commuteNearKm is the one-way distance up to which the lower commuting rate
+// applies; every further kilometer gets commuteRateFar.
+const commuteNearKm = 20
+
+// commuteRates returns the commuting rates per one-way km: up to 20 km and
+// from the 21st km. Config rates without them fall back to the flat 0.30 EUR.
+func (r Rates) commuteRates() (near, far float64) {
+	near, far = r.CommuteRate, r.CommuteRateFar
+	if near == 0 {
+		near = kmRatePerKm
+	}
+	if far == 0 {
+		far = near
+	}
+	return near, far
+}
+
+// commuteAmount returns the Entfernungspauschale of one day for a one-way
+// distance of km full kilometers.
+func (r Rates) commuteAmount(km int) float64 {
+	near, far := r.commuteRates()
+	nearKm := min(km, commuteNearKm)
+	return roundCents(float64(nearKm)*near + float64(km-nearKm)*far)
+}
+
+// commuteDistance returns the one-way distance to a first place of work in
+// full kilometers: the customer's distance, halved unless it is configured
+// as one-way.
+func (c *Config) commuteDistance(customer Customer) int {
+	if c.DistanceMode == distanceOneWay {
+		return customer.Distance
+	}
+	return customer.Distance / 2
+}
+
+// CommuteReport holds the days of a customer that is the first place of
+// work (firstPlaceOfWork). They are commuting, not Reisekosten, and are
+// reported in their own document.
+type CommuteReport struct {
+	Customer Customer
+	Dates    []string // DD.MM.YYYY
+	Distance int      // one-way km
+	Amount   float64
+}
+
+// splitCommutes separates the trips to customers that are a first place of
+// work from the business trips.
+func splitCommutes(trips []tripDay, customers []Customer) (travel, commutes []tripDay) {
+	for _, t := range trips {
+		if customers[t.customer].FirstPlaceOfWork {
+			commutes = append(commutes, t)
+		} else {
+			travel = append(travel, t)
+		}
+	}
+	return travel, commutes
+}
+
+// buildCommuteEntry creates a single commuting entry for the one-way
+// distance, followed by the calculation with the rates up to and beyond
+// 20 km. Non-empty details are printed below.
+func buildCommuteEntry(dateString string, km int, r Rates, details ...string) string {
+	var b strings.Builder
+
+	near, far := r.commuteRates()
+	nearKm := min(km, commuteNearKm)
+	amountStr := formatAmount(r.commuteAmount(km)) + " EUR"
+	label := fmt.Sprintf("Entfernungspauschale (%d km)", km)
+	calc := fmt.Sprintf("%d km x %s EUR", nearKm, formatAmount(near))
+	if km > commuteNearKm {
+		calc += fmt.Sprintf(" + %d km x %s EUR", km-nearKm, formatAmount(far))
+	}
+
+	b.WriteString(fmt.Sprintf("  %s  (einfache Entfernung)\n", dateString))
+	b.WriteString(fmt.Sprintf("    %s%s\n", label, rightAlign(amountStr, 45-len(label))))
+	writeEntryDetails(&b, append([]string{calc}, details...))
+
+	return b.String()
+}
+
+// addCommuteDocument builds the Entfernungspauschale document of the
+// commuting days, grouped by customer like the travel documents, and adds
+// it to the report.
+func addCommuteDocument(cfg *Config, p Period, report *Report, commutes []tripDay, customers []Customer, rates Rates, overrides periodOverrides) error {
+	byCustomer := make(map[int][]tripDay)
+	for _, t := range commutes {
+		byCustomer[t.customer] = append(byCustomer[t.customer], t)
+	}
+
+	var blocks []string
+	for i, customer := range customers {
+		days := byCustomer[i]
+		if len(days) == 0 {
+			continue
+		}
+		blocks = append(blocks, buildCustomerHeader(customer))
+		c := CommuteReport{Customer: customer, Distance: cfg.commuteDistance(customer)}
+		for _, t := range days {
+			c.Dates = append(c.Dates, t.date)
+			c.Amount += rates.commuteAmount(c.Distance)
+			blocks = append(blocks, buildCommuteEntry(withWeekday(t.date, cfg.Language), c.Distance, rates, customer.booking(), overrides.note(t.date)))
+		}
+		c.Amount = roundCents(c.Amount)
+		report.Commutes = append(report.Commutes, c)
+		report.CommuteTotal += c.Amount
+	}
+
+	first, last := commutes[0].date, commutes[len(commutes)-1].date
+	report.CommuteDocID = cfg.documentID(p)
+	header := buildDocumentHeader(report.CommuteDocID, p.Label(), last, first, last, "Entfernungspauschale",
+		"Wege zur ersten Taetigkeitsstaette, Werbungskosten (Anlage N), keine Reisekosten", cfg.SevDesk)
+	data, err := createPDF(header, blocks, buildDocumentFooter(report.CommuteTotal)+buildSignatureBlock(cfg.Signature, last))
+	if err != nil {
+		return err
+	}
+	report.Attachments = append(report.Attachments, Attachment{
+		Filename: p.filePrefix() + "_Entfernungspauschale.pdf",
+		Data:     data,
+		Type:     "Entfernungspauschale",
+		DocID:    report.CommuteDocID,
+		Amount:   report.CommuteTotal,
+	})
+	slog.Info("commuting document generated", "days", len(commutes), "total", formatAmount(report.CommuteTotal))
+	return nil
+}
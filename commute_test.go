@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommuteAmount(t *testing.T) {
+	r, _ := (&Config{}).ratesFor(2026)
+	tests := []struct {
+		km   int
+		want float64
+	}{
+		{15, 4.50},
+		{20, 6.00},
+		{35, 6.00 + 15*0.38},
+	}
+	for _, tt := range tests {
+		if got := r.commuteAmount(tt.km); got != roundCents(tt.want) {
+			t.Errorf("commuteAmount(%d) = %v, want %v", tt.km, got, tt.want)
+		}
+	}
+	if old, _ := (&Config{}).ratesFor(2020); old.commuteAmount(35) != 10.50 {
+		t.Errorf("commuteAmount(35) in 2020 = %v, want 10.50", old.commuteAmount(35))
+	}
+	if got := (Rates{}).commuteAmount(10); got != 3.00 {
+		t.Errorf("commuteAmount without commute rates = %v, want 3.00", got)
+	}
+}
+
+func TestFirstPlaceOfWork(t *testing.T) {
+	cfg := &Config{
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{Weekdays: []string{"mon", "tue", "wed"}}},
+			{ID: "2", Name: "Stammsitz", Distance: 70, Province: "BW", FirstPlaceOfWork: true, Schedule: Schedule{Weekdays: []string{"thu", "fri"}}},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// 12 trips to Acme are Reisekosten, the 8 days at the first place of
+	// work are commuting over 35 km one-way
+	if len(report.Customers) != 1 || report.Workdays != 12 || report.KmTotal != 360 {
+		t.Errorf("customers = %d, workdays = %d, KmTotal = %v", len(report.Customers), report.Workdays, report.KmTotal)
+	}
+	if len(report.Commutes) != 1 || report.Commutes[0].Distance != 35 || report.CommuteTotal != 8*11.70 {
+		t.Errorf("commutes = %+v, total = %v", report.Commutes, report.CommuteTotal)
+	}
+	if report.Total() != 360+12*verpflegungRate {
+		t.Errorf("Total() = %v includes the commuting", report.Total())
+	}
+	if len(report.Attachments) != 3 || !strings.HasSuffix(report.Attachments[2].Filename, "_Entfernungspauschale.pdf") {
+		t.Fatalf("attachments = %d, want the Entfernungspauschale as third", len(report.Attachments))
+	}
+	text := pdfText(t, report.Attachments[2].Data)
+	for _, want := range []string{"Do, 05.02.2026  \\(einfache Entfernung\\)", "Entfernungspauschale \\(35 km\\)", "20 km x 0,30 EUR + 15 km x 0,38 EUR"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Entfernungspauschale misses %q", want)
+		}
+	}
+	if strings.Contains(pdfText(t, report.Attachments[0].Data), "Stammsitz") {
+		t.Errorf("Kilometergelderstattung lists the first place of work")
+	}
+	if s := newRunSummary(cfg, p, report, nil); s.CommuteTotal != 93.60 || s.Documents[2].Type != "Entfernungspauschale" {
+		t.Errorf("summary commuteTotal = %v, documents = %+v", s.CommuteTotal, s.Documents)
+	}
+}
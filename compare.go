@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// ---------------------------------------------------------------------------
+// Month Comparison
+// ---------------------------------------------------------------------------
+
+// compareMonths prints the totals and per-customer days and km of two
+// archived months side by side with their differences.
+func compareMonths(cfg *Config, from, to Period, w io.Writer) error {
+	a, err := loadArchivedPeriod(cfg, from.Key())
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return fmt.Errorf("no archived report for %s", from.Label())
+	}
+	b, err := loadArchivedPeriod(cfg, to.Key())
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("no archived report for %s", to.Label())
+	}
+
+	return writeComparison(w, from.Label(), to.Label(), a, b)
+}
+
+// writeComparison prints the totals and per-customer days and km of two
+// summaries side by side with their differences.
+func writeComparison(w io.Writer, fromLabel, toLabel string, a, b *runSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t%s\t\t\n", fromLabel, toLabel)
+	fmt.Fprintf(tw, "Workdays\t%d\t%d\t%+d\t\n", a.Workdays, b.Workdays, b.Workdays-a.Workdays)
+	if a.OfficeDays > 0 || b.OfficeDays > 0 {
+		fmt.Fprintf(tw, "Office days\t%d\t%d\t%+d\t\n", a.OfficeDays, b.OfficeDays, b.OfficeDays-a.OfficeDays)
+	}
+	for _, t := range []totalChange{
+		{"Kilometergeld", a.KmTotal, b.KmTotal},
+		{"Verpflegungsmehraufwand", a.VerpflegungTotal, b.VerpflegungTotal},
+		{"Reisenebenkosten", a.ExpensesTotal, b.ExpensesTotal},
+		{"Gesamt", a.Total, b.Total},
+	} {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.Name, formatAmount(t.Old), formatAmount(t.New), signedAmount(t.New-t.Old), percentChange(t.Old, t.New))
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range append(append([]customerSummary{}, a.Customers...), b.Customers...) {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		o, n := a.customer(c.ID), b.customer(c.ID)
+		fmt.Fprintf(tw, "%s days\t%d\t%d\t%+d\t\n", c.Name, o.Days, n.Days, n.Days-o.Days)
+		fmt.Fprintf(tw, "%s km\t%d\t%d\t%+d\t%s\n", c.Name, o.Km, n.Km, n.Km-o.Km, percentChange(float64(o.Km), float64(n.Km)))
+	}
+	return tw.Flush()
+}
+
+// signedAmount formats an amount difference with its sign, e.g. +12,50.
+func signedAmount(amount float64) string {
+	if roundCents(amount) >= 0 {
+		return "+" + formatAmount(amount)
+	}
+	return "-" + formatAmount(-amount)
+}
+
+// percentChange returns the relative change from old to new, e.g. -12%, or
+// "" if old is zero.
+func percentChange(old, new float64) string {
+	if old == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%+.0f%%", (new-old)/old*100)
+}
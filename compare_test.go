@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareMonths(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: dir}
+	for _, s := range []runSummary{
+		{Period: "2026-01", Workdays: 20, KmTotal: 300, Total: 440, Customers: []customerSummary{{ID: "1", Name: "Acme", Days: 10, Km: 1000}}},
+		{Period: "2026-02", Workdays: 19, KmTotal: 270, Total: 396, Customers: []customerSummary{{ID: "1", Name: "Acme", Days: 9, Km: 900}, {ID: "2", Name: "Beta", Days: 1, Km: 50}}},
+	} {
+		data, _ := json.Marshal(s)
+		os.WriteFile(filepath.Join(dir, s.Period+".json"), data, 0o600)
+	}
+
+	var out bytes.Buffer
+	if err := compareMonths(cfg, monthPeriod(2026, time.January), monthPeriod(2026, time.February), &out); err != nil {
+		t.Fatalf("compareMonths() error = %v", err)
+	}
+	for _, want := range []string{"01/2026", "Workdays", "-1", "300,00", "270,00", "-30,00", "-10%", "Acme km", "1000", "900", "Beta days", "+1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := compareMonths(cfg, monthPeriod(2026, time.January), monthPeriod(2026, time.March), &out); err == nil || !strings.Contains(err.Error(), "03/2026") {
+		t.Errorf("compareMonths() error = %v, want missing 03/2026", err)
+	}
+}
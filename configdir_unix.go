@@ -0,0 +1,25 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// userConfigDir returns $XDG_CONFIG_HOME (default ~/.config), or "" if the
+// home directory is unknown.
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config")
+	}
+	return ""
+}
+
+// systemConfigDir returns the system-wide config directory.
+func systemConfigDir() string {
+	return "/etc/reisekosten"
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// userConfigDir returns %APPDATA% (the roaming application data of the
+// user), or "" if it is not set.
+func userConfigDir() string {
+	return os.Getenv("APPDATA")
+}
+
+// systemConfigDir returns the system-wide config directory
+// %ProgramData%\reisekosten.
+func systemConfigDir() string {
+	dir := os.Getenv("ProgramData")
+	if dir == "" {
+		dir = `C:\ProgramData`
+	}
+	return filepath.Join(dir, "reisekosten")
+}
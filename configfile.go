@@ -0,0 +1,271 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Config Files and Overlays
+// ---------------------------------------------------------------------------
+
+// configSource is one YAML document contributing to the configuration.
+type configSource struct {
+	Path string
+	Data []byte
+}
+
+// typeErrorRegex splits the messages of a yaml.TypeError into line and text.
+var typeErrorRegex = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// readConfigFile reads a config file, decrypting it if it is SOPS-encrypted.
+func readConfigFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if isSOPSFile(data) {
+		if data, err = sopsDecrypt(path); err != nil {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %w", path, err)
+		}
+		slog.Debug("config decrypted with sops", "path", path)
+	}
+	return data, nil
+}
+
+// overlayDir returns the overlay directory belonging to a config file:
+// config.yaml -> config.d next to it.
+func overlayDir(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".d"
+}
+
+// overlayFiles returns the *.yaml and *.yml files of the overlay directory in
+// lexical order. A missing directory yields no files.
+func overlayFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay directory: %w", err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readConfigSources reads the config file followed by its overlays.
+func readConfigSources(path string) ([]configSource, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sources := []configSource{{Path: path, Data: data}}
+
+	overlays, err := overlayFiles(overlayDir(path))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range overlays {
+		data, err := readConfigFile(f)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, configSource{Path: f, Data: data})
+		slog.Debug("config overlay loaded", "path", f)
+	}
+	return sources, nil
+}
+
+// parseConfig parses a single config document. See parseConfigSources.
+func parseConfig(path string, data []byte, profile string) (*Config, error) {
+	return parseConfigSources([]configSource{{Path: path, Data: data}}, profile)
+}
+
+// parseConfigSources merges the config documents in order (later documents
+// win), applies the selected profile, resolves secret references and
+// validates the result. All problems are reported together as *ConfigErrors.
+func parseConfigSources(sources []configSource, profile string) (*Config, error) {
+	v := &validator{root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, origins: make(map[*yaml.Node]string)}
+	profiles := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for _, src := range sources {
+		var root yaml.Node
+		if err := yaml.Unmarshal(src.Data, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", src.Path, err)
+		}
+		doc := documentMapping(&root)
+		if doc == nil {
+			return nil, fmt.Errorf("failed to parse config file %s: top level must be a mapping", src.Path)
+		}
+		recordOrigin(v.origins, doc, src.Path)
+
+		// Profiles are partial configs merged over the top level
+		if p := removeKey(doc, "profiles"); p != nil {
+			for i := 0; i+1 < len(p.Content); i += 2 {
+				v.checkTypes(p.Content[i+1], src.Path)
+			}
+			mergeNodes(profiles, p)
+		}
+		v.checkTypes(doc, src.Path)
+		mergeNodes(v.root, doc)
+	}
+
+	if profile != "" {
+		p := mappingValue(profiles, profile)
+		if p == nil {
+			return nil, fmt.Errorf("profile %q not found in config file (available: %s)", profile, strings.Join(mappingKeys(profiles), ", "))
+		}
+		mergeNodes(v.root, p)
+	}
+
+	var cfg Config
+	if err := v.root.Decode(&cfg); err != nil {
+		// Type errors were already reported per file by checkTypes
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+	cfg.Profile = profile
+	for _, src := range sources {
+		cfg.Sources = append(cfg.Sources, src.Path)
+	}
+
+	// Resolve secret references before checking the values
+	if len(v.problems) == 0 {
+		if err := resolveSecrets(&cfg); err != nil {
+			return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+		}
+	}
+
+	v.validate(&cfg)
+	if len(v.problems) > 0 {
+		return nil, &ConfigErrors{Path: sources[0].Path, Problems: v.problems}
+	}
+	return &cfg, nil
+}
+
+// checkTypes reports unknown keys and values of the wrong type in a single
+// document, before it is merged with the others.
+func (v *validator) checkTypes(doc *yaml.Node, file string) {
+	v.checkKnownKeys(doc, reflect.TypeOf(Config{}))
+
+	var cfg Config
+	var typeErr *yaml.TypeError
+	if err := doc.Decode(&cfg); errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			p := configProblem{File: file, Msg: msg}
+			if m := typeErrorRegex.FindStringSubmatch(msg); m != nil {
+				p.Line, _ = strconv.Atoi(m[1])
+				p.Msg = m[2]
+			}
+			v.problems = append(v.problems, p)
+		}
+	}
+}
+
+// recordOrigin maps node and all its descendants to file.
+func recordOrigin(origins map[*yaml.Node]string, node *yaml.Node, file string) {
+	origins[node] = file
+	for _, child := range node.Content {
+		recordOrigin(origins, child, file)
+	}
+}
+
+// documentMapping returns the top-level mapping of a parsed document. An
+// empty document yields an empty mapping, anything else nil.
+func documentMapping(root *yaml.Node) *yaml.Node {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	switch root.Kind {
+	case yaml.MappingNode:
+		return root
+	case 0, yaml.DocumentNode:
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKeys returns the keys of a mapping node.
+func mappingKeys(node *yaml.Node) []string {
+	var keys []string
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keys = append(keys, node.Content[i].Value)
+		}
+	}
+	return keys
+}
+
+// removeKey deletes key from a mapping node and returns its value, or nil.
+func removeKey(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// mergeNodes merges the mapping src into the mapping dst: nested mappings are
+// merged key by key, all other values (scalars and lists) in src replace those
+// in dst. Nodes are moved, not copied, so they keep their original position.
+func mergeNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		j := mappingIndex(dst, key.Value)
+		switch {
+		case j < 0:
+			dst.Content = append(dst.Content, key, value)
+		case dst.Content[j+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNodes(dst.Content[j+1], value)
+		default:
+			dst.Content[j], dst.Content[j+1] = key, value
+		}
+	}
+}
+
+// mappingIndex returns the index of key in a mapping node, or -1.
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+func TestLoadConfigOverlays(t *testing.T) {
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml": `email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Old
+    distance: 10
+    province: BW
+`,
+		"config.d/10-customers.yaml": `customers:
+  - id: "2"
+    name: Globex
+    distance: 50
+    province: BY
+`,
+		"config.d/20-email.yml": `email:
+  to: buchhaltung@example.com
+  headers:
+    X-Kostenstelle: "4711"
+`,
+		"config.d/README.md": "ignored",
+	})
+
+	cfg, err := loadConfig("config.yaml", path, "")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if len(cfg.Customers) != 1 || cfg.Customers[0].Name != "Globex" {
+		t.Errorf("Customers = %+v, want list replaced by overlay", cfg.Customers)
+	}
+	if cfg.Email.From != "me@example.com" || cfg.Email.To != "buchhaltung@example.com" || cfg.Email.Headers["X-Kostenstelle"] != "4711" {
+		t.Errorf("Email = %+v, want base merged with overlay", cfg.Email)
+	}
+}
+
+func TestLoadConfigOverlayProblems(t *testing.T) {
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml": `email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BW
+`,
+		"config.d/50-override.yaml": `retry:
+  attempts: many
+customers:
+  - id: "1"
+    name: Acme
+    distance: 0
+    province: BW
+`,
+	})
+
+	_, err := loadConfig("config.yaml", path, "")
+	if err == nil {
+		t.Fatal("loadConfig() expected error")
+	}
+	overlay := filepath.Join(filepath.Dir(path), "config.d", "50-override.yaml")
+	for _, want := range []string{
+		overlay + " line 2: cannot unmarshal !!str `many` into int",
+		overlay + " line 6: customers[0].distance: must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestMergeNodesPrecedence(t *testing.T) {
+	// Profiles are merged after all overlays, so they take precedence
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml": `email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - {id: "1", name: Acme, distance: 10, province: BW}
+profiles:
+  gmbh:
+    email:
+      to: gmbh@example.com
+`,
+		"config.d/10.yaml": `email:
+  to: overlay@example.com
+`,
+	})
+
+	cfg, err := loadConfig("config.yaml", path, "gmbh")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Email.To != "gmbh@example.com" {
+		t.Errorf("To = %q, want profile value", cfg.Email.To)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Correction Mail
+// ---------------------------------------------------------------------------
+
+// correctionPrefix is prepended to the subject of a regenerated report.
+const correctionPrefix = "Korrektur: "
+
+// reportDiff lists what changed between two summaries of a period.
+type reportDiff struct {
+	Days      []dayChange      // days added, removed or moved to another customer
+	Customers []customerChange // customers whose days or km changed
+	Totals    []totalChange    // changed totals
+}
+
+// dayChange is a day assigned to a different customer. From is empty for an
+// added day, To for a removed one.
+type dayChange struct {
+	Date     string // DD.MM.YYYY
+	From, To string // customer names
+}
+
+type customerChange struct {
+	Name             string
+	OldDays, NewDays int
+	OldKm, NewKm     int
+}
+
+type totalChange struct {
+	Name     string
+	Old, New float64
+}
+
+// empty reports whether nothing changed.
+func (d reportDiff) empty() bool {
+	return len(d.Days) == 0 && len(d.Customers) == 0 && len(d.Totals) == 0
+}
+
+// diffSummaries compares a report with an earlier version of it.
+func diffSummaries(old, new runSummary) reportDiff {
+	var d reportDiff
+
+	// Days by date, with the customer they are assigned to
+	oldDays, newDays := summaryDays(old), summaryDays(new)
+	for date, from := range oldDays {
+		if to := newDays[date]; to != from {
+			d.Days = append(d.Days, dayChange{Date: date, From: from, To: to})
+		}
+	}
+	for date, to := range newDays {
+		if _, ok := oldDays[date]; !ok {
+			d.Days = append(d.Days, dayChange{Date: date, To: to})
+		}
+	}
+	sort.Slice(d.Days, func(i, j int) bool { return dayOrder(d.Days[i].Date) < dayOrder(d.Days[j].Date) })
+
+	d.Customers = diffCustomers(old, new)
+
+	for _, t := range []totalChange{
+		{"Kilometergeld", old.KmTotal, new.KmTotal},
+		{"Verpflegungsmehraufwand", old.VerpflegungTotal, new.VerpflegungTotal},
+		{"Reisenebenkosten", old.ExpensesTotal, new.ExpensesTotal},
+		{"Gesamt", old.Total, new.Total},
+	} {
+		if roundCents(t.Old) != roundCents(t.New) {
+			d.Totals = append(d.Totals, t)
+		}
+	}
+	return d
+}
+
+// diffCustomers returns the customers whose days or km differ, in the order
+// of new followed by customers only in old.
+func diffCustomers(old, new runSummary) []customerChange {
+	var changes []customerChange
+	seen := make(map[string]bool)
+	for _, c := range append(append([]customerSummary{}, new.Customers...), old.Customers...) {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		o, n := old.customer(c.ID), new.customer(c.ID)
+		if o.Days != n.Days || o.Km != n.Km {
+			changes = append(changes, customerChange{Name: c.Name, OldDays: o.Days, NewDays: n.Days, OldKm: o.Km, NewKm: n.Km})
+		}
+	}
+	return changes
+}
+
+// summaryDays maps the dates of a summary to the customer names.
+func summaryDays(s runSummary) map[string]string {
+	days := make(map[string]string)
+	for _, c := range s.Customers {
+		for _, date := range c.Dates {
+			days[date] = c.Name
+		}
+	}
+	return days
+}
+
+// dayOrder returns a sortable form of a DD.MM.YYYY date.
+func dayOrder(date string) string {
+	t, err := time.Parse("02.01.2006", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("2006-01-02")
+}
+
+// correctionBody returns the HTML body of a correction mail: the changes
+// against the previously delivered version followed by the usual body.
+func correctionBody(old, new runSummary) string {
+	d := diffSummaries(old, new)
+	var b strings.Builder
+	b.WriteString("Korrigierte Fassung der bereits versendeten Abrechnung.<br>")
+	if d.empty() {
+		b.WriteString("Keine inhaltlichen Aenderungen, nur neue Belege.<br>")
+	}
+	if len(d.Days) > 0 {
+		b.WriteString("<br>Geaenderte Tage:<br>")
+		for _, c := range d.Days {
+			switch {
+			case c.From == "":
+				fmt.Fprintf(&b, "%s: neu (%s)<br>", c.Date, html.EscapeString(c.To))
+			case c.To == "":
+				fmt.Fprintf(&b, "%s: entfaellt (%s)<br>", c.Date, html.EscapeString(c.From))
+			default:
+				fmt.Fprintf(&b, "%s: %s -> %s<br>", c.Date, html.EscapeString(c.From), html.EscapeString(c.To))
+			}
+		}
+	}
+	if len(d.Customers) > 0 {
+		b.WriteString("<br>Geaenderte Kunden:<br>")
+		for _, c := range d.Customers {
+			fmt.Fprintf(&b, "%s: %d -> %d Tage, %d -> %d km<br>", html.EscapeString(c.Name), c.OldDays, c.NewDays, c.OldKm, c.NewKm)
+		}
+	}
+	if len(d.Totals) > 0 {
+		b.WriteString("<br>Geaenderte Summen:<br>")
+		for _, t := range d.Totals {
+			fmt.Fprintf(&b, "%s: %s -> %s EUR<br>", t.Name, formatAmount(t.Old), formatAmount(t.New))
+		}
+	}
+	b.WriteString("<br>")
+	b.WriteString(reportBody(new))
+	return b.String()
+}
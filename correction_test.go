@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSummaries(t *testing.T) {
+	old := runSummary{
+		Customers: []customerSummary{
+			{ID: "1", Name: "Acme", Days: 2, Dates: []string{"02.02.2026", "10.02.2026"}, Km: 200},
+			{ID: "2", Name: "Beta", Days: 1, Dates: []string{"03.02.2026"}, Km: 50},
+		},
+		KmTotal: 75, Total: 117,
+	}
+	new := runSummary{
+		Customers: []customerSummary{
+			{ID: "1", Name: "Acme", Days: 2, Dates: []string{"02.02.2026", "03.02.2026"}, Km: 200},
+			{ID: "3", Name: "Gamma", Days: 1, Dates: []string{"11.02.2026"}, Km: 10},
+		},
+		KmTotal: 63, Total: 105,
+	}
+
+	d := diffSummaries(old, new)
+	want := []dayChange{{"03.02.2026", "Beta", "Acme"}, {"10.02.2026", "Acme", ""}, {"11.02.2026", "", "Gamma"}}
+	if len(d.Days) != len(want) {
+		t.Fatalf("Days = %v, want %v", d.Days, want)
+	}
+	for i := range want {
+		if d.Days[i] != want[i] {
+			t.Errorf("Days[%d] = %v, want %v", i, d.Days[i], want[i])
+		}
+	}
+	if len(d.Customers) != 2 || d.Customers[0].Name != "Gamma" || d.Customers[1] != (customerChange{"Beta", 1, 0, 50, 0}) {
+		t.Errorf("Customers = %v", d.Customers)
+	}
+	if len(d.Totals) != 2 || d.Totals[0].Name != "Kilometergeld" || d.Totals[1].Name != "Gesamt" {
+		t.Errorf("Totals = %v", d.Totals)
+	}
+	if !diffSummaries(new, new).empty() {
+		t.Error("diffSummaries() of equal summaries is not empty")
+	}
+}
+
+func TestCorrectionMail(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, time.February)
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	cfg.Filter = RunFilter{SkipDays: []string{"2026-02-13"}}
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() correction error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml"))
+	var corrections int
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "Subject: "+correctionPrefix) {
+			corrections++
+		}
+	}
+	if len(files) != 2 || corrections != 1 {
+		t.Errorf("%d mails, %d corrections, want 2 and 1", len(files), corrections)
+	}
+}
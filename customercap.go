@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ---------------------------------------------------------------------------
+// Customer Caps
+// ---------------------------------------------------------------------------
+
+// What happens when a customer's costs exceed the contractual cap
+const (
+	capWarn  = "warn"  // keep all trips and warn (default)
+	capTrim  = "trim"  // drop the customer's latest trips
+	capCarry = "carry" // drop the customer's latest trips and claim them in the next month
+)
+
+// CustomerCap limits the re-billable travel costs of a customer per report,
+// as agreed in the contract.
+type CustomerCap struct {
+	Total       float64 `yaml:"total,omitempty"`       // EUR of km, meal allowances and the customer's expenses
+	Kilometer   float64 `yaml:"kilometer,omitempty"`   // EUR of km
+	Verpflegung float64 `yaml:"verpflegung,omitempty"` // EUR of meal allowances
+	OnExceed    string  `yaml:"onExceed,omitempty"`    // warn (default), trim or carry
+}
+
+// active reports whether a cap is set.
+func (c CustomerCap) active() bool {
+	return c.Total > 0 || c.Kilometer > 0 || c.Verpflegung > 0
+}
+
+// carryDescription is the expense claiming the overflow of the previous month.
+const carryDescription = "Uebertrag aus %s (Kostendeckel)"
+
+// customerCaps is the outcome of the customer caps of a report.
+type customerCaps struct {
+	trips    []tripDay          // trips within the caps
+	carried  []Expense          // overflow of the previous month claimed in this report
+	carry    map[string]float64 // customer ID -> EUR over the cap carried to the next month
+	warnings []string
+}
+
+// applyCustomerCaps checks the trips of each customer with a cap. With
+// onExceed: warn an exceeded cap only adds a warning; with trim the
+// customer's latest trips are dropped until the cap holds; with carry the
+// dropped trips' amount is carried to the next month, where it is claimed as
+// an expense of the customer before the new trips, as far as total allows.
+// carriedIn holds the carry of the previous month by customer ID.
+func applyCustomerCaps(trips []tripDay, customers []Customer, rates Rates, expenses []Expense, carriedIn map[string]float64, p Period, previous string) customerCaps {
+	out := customerCaps{trips: trips}
+	dropped := make(map[int]bool)
+	for ci, cust := range customers {
+		c := cust.Cap
+		if !c.active() {
+			continue
+		}
+		var km, verp, extra float64
+		var own []int
+		for i, t := range trips {
+			if t.customer != ci {
+				continue
+			}
+			own = append(own, i)
+			km += t.kmAmount(cust, rates)
+			if t.perDiem() {
+				verp += cust.perDiemRate(rates)
+			}
+		}
+		for _, e := range expenses {
+			if e.Customer == cust.ID {
+				extra += e.Amount
+			}
+		}
+
+		// The previous month's overflow comes first, up to the total
+		carry := 0.0
+		if in := carriedIn[cust.ID]; in > 0 && c.OnExceed == capCarry {
+			claim := in
+			if c.Total > 0 {
+				claim = max(0, min(in, c.Total-extra))
+			}
+			if claim > 0 {
+				out.carried = append(out.carried, Expense{Date: p.Start().Format(isoDate), Description: fmt.Sprintf(carryDescription, previous), Amount: roundCents(claim), Customer: cust.ID})
+				extra += claim
+			}
+			carry = roundCents(in - claim)
+		}
+
+		exceeded := func() string {
+			switch {
+			case c.Kilometer > 0 && km > c.Kilometer+0.005:
+				return fmt.Sprintf("Kilometergeld %s EUR exceeds cap.kilometer %s EUR", formatAmount(km), formatAmount(c.Kilometer))
+			case c.Verpflegung > 0 && verp > c.Verpflegung+0.005:
+				return fmt.Sprintf("Verpflegungsmehraufwand %s EUR exceeds cap.verpflegung %s EUR", formatAmount(verp), formatAmount(c.Verpflegung))
+			case c.Total > 0 && km+verp+extra > c.Total+0.005:
+				return fmt.Sprintf("total %s EUR exceeds cap.total %s EUR", formatAmount(km+verp+extra), formatAmount(c.Total))
+			}
+			return ""
+		}
+		msg := exceeded()
+		if msg != "" && (c.OnExceed == "" || c.OnExceed == capWarn) {
+			out.warnings = append(out.warnings, fmt.Sprintf("customer %s (%s): %s", cust.ID, cust.Name, msg))
+			continue
+		}
+
+		n := 0
+		for ; msg != "" && n < len(own); msg = exceeded() {
+			last := trips[own[len(own)-1-n]]
+			amount := last.kmAmount(cust, rates)
+			km -= amount
+			if last.perDiem() {
+				verp -= cust.perDiemRate(rates)
+				amount += cust.perDiemRate(rates)
+			}
+			carry += amount
+			dropped[own[len(own)-1-n]] = true
+			n++
+		}
+		if msg != "" {
+			out.warnings = append(out.warnings, fmt.Sprintf("customer %s (%s): %s even without any trips", cust.ID, cust.Name, msg))
+		}
+		if n > 0 {
+			slog.Warn("days dropped to stay within the customer's cap", "customer", cust.ID, "days", n, "onExceed", c.OnExceed)
+		}
+		if c.OnExceed == capCarry && roundCents(carry) > 0 {
+			if out.carry == nil {
+				out.carry = make(map[string]float64)
+			}
+			out.carry[cust.ID] = roundCents(carry)
+		}
+	}
+
+	if len(dropped) > 0 {
+		out.trips = make([]tripDay, 0, len(trips)-len(dropped))
+		for i, t := range trips {
+			if !dropped[i] {
+				out.trips = append(out.trips, t)
+			}
+		}
+	}
+	return out
+}
+
+// carriedOver returns the overflow of the customers' caps carried from the
+// archived report of period p.
+func carriedOver(cfg *Config, p Period) map[string]float64 {
+	s, err := loadArchivedPeriod(cfg, p.Key())
+	if err != nil {
+		slog.Warn("carry over of the customer caps skipped", "period", p.Key(), "error", err)
+		return nil
+	}
+	if s == nil {
+		return nil
+	}
+	return s.CarryOver
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomerCap(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Cap: CustomerCap{Total: 500}},
+	}}
+
+	// 20 days of 30 EUR km and 14 EUR meal allowance exceed the cap: a warning only
+	feb := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, feb)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 20 || len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "total 880,00 EUR exceeds cap.total 500,00 EUR") {
+		t.Errorf("warn: workdays = %d, warnings = %q", report.Workdays, report.Warnings)
+	}
+
+	cfg.Customers[0].Cap.OnExceed = capTrim
+	if report, err = generateReport(cfg, feb); err != nil {
+		t.Fatalf("generateReport(trim) error = %v", err)
+	}
+	if report.Workdays != 11 || report.CarryOver != nil {
+		t.Errorf("trim: workdays = %d, carry = %v", report.Workdays, report.CarryOver)
+	}
+
+	// The 9 dropped days are claimed in March before its own trips
+	cfg.Customers[0].Cap.OnExceed = capCarry
+	if report, err = generateReport(cfg, feb); err != nil {
+		t.Fatalf("generateReport(carry) error = %v", err)
+	}
+	if report.Workdays != 11 || report.CarryOver["1"] != 9*44 {
+		t.Fatalf("carry: workdays = %d, carry = %v", report.Workdays, report.CarryOver)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, feb, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+	mar := monthPeriod(2026, 3)
+	if report, err = generateReport(cfg, mar); err != nil {
+		t.Fatalf("generateReport(March) error = %v", err)
+	}
+	if report.Workdays != 2 || report.ExpenseTotal != 396 || report.Total() != 484 || report.CarryOver["1"] != 20*44 {
+		t.Errorf("March: workdays = %d, expenses = %v, total = %v, carry = %v", report.Workdays, report.ExpenseTotal, report.Total(), report.CarryOver)
+	}
+	expenses := pdfText(t, report.Attachments[2].Data)
+	if want := "Uebertrag aus 02/2026 \\(Kostendeckel\\)"; !strings.Contains(expenses, want) {
+		t.Errorf("Reisenebenkosten misses %q", want)
+	}
+}
+
+func TestCustomerCapCarryQuarter(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Cap: CustomerCap{Total: 500, OnExceed: capCarry}},
+	}}
+	q1 := Period{periodQuarter, 2026, 1}
+	report, err := generateReport(cfg, q1)
+	if err != nil {
+		t.Fatalf("generateReport(Q1) error = %v", err)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, q1, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+
+	// The carry comes from the archived Q1, not from the month before April
+	if report, err = generateReport(cfg, Period{periodQuarter, 2026, 2}); err != nil {
+		t.Fatalf("generateReport(Q2) error = %v", err)
+	}
+	if report.ExpenseTotal != 500 {
+		t.Errorf("Q2: expenses = %v, want the carry up to the cap", report.ExpenseTotal)
+	}
+	expenses := pdfText(t, report.Attachments[2].Data)
+	if want := "Uebertrag aus Q1/2026 \\(Kostendeckel\\)"; !strings.Contains(expenses, want) {
+		t.Errorf("Reisenebenkosten misses %q", want)
+	}
+}
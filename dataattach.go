@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ---------------------------------------------------------------------------
+// Data Attachments
+// ---------------------------------------------------------------------------
+
+// dataAttachments render the machine-readable data of a report mail, by
+// email.attachData format: the trips as CSV, in the columns of the GDPdU
+// export, or the run summary as printed by --json.
+var dataAttachments = map[string]func(s runSummary, prefix string) (Attachment, error){
+	"csv": func(s runSummary, prefix string) (Attachment, error) {
+		data, err := gdpduTrips([]runSummary{s}).csv()
+		return Attachment{Filename: prefix + "_Reisekosten_Fahrten.csv", Data: data}, err
+	},
+	"json": func(s runSummary, prefix string) (Attachment, error) {
+		data, err := json.MarshalIndent(s, "", "  ")
+		return Attachment{Filename: prefix + "_Reisekosten.json", Data: data}, err
+	},
+}
+
+// reportDataAttachments returns the data files configured by
+// email.attachData, attached next to the PDFs of the report mail.
+func reportDataAttachments(cfg *Config, p Period, report *Report) ([]Attachment, error) {
+	if len(cfg.Email.AttachData) == 0 {
+		return nil, nil
+	}
+	summary := newRunSummary(cfg, p, report, nil)
+	var files []Attachment
+	for _, format := range cfg.Email.AttachData {
+		render, ok := dataAttachments[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown email.attachData format %q (use %s)", format, registryNames(dataAttachments))
+		}
+		file, err := render(summary, p.filePrefix())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s attachment: %w", format, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
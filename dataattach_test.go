@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportDataAttachments(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Email:     EmailConfig{AttachData: []string{"csv", "json"}},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	files, err := reportDataAttachments(cfg, p, report)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("reportDataAttachments() = %d files, %v", len(files), err)
+	}
+
+	if files[0].Filename != "02_2026_Reisekosten_Fahrten.csv" {
+		t.Errorf("csv filename = %s", files[0].Filename)
+	}
+	lines := strings.Split(strings.TrimSpace(string(files[0].Data)), "\r\n")
+	if len(lines) != 21 || !strings.HasPrefix(lines[0], "Datum;Kunden-ID;") || !strings.HasPrefix(lines[20], "27.02.2026;1;") {
+		t.Errorf("csv has %d lines:\n%s", len(lines), files[0].Data)
+	}
+
+	var summary runSummary
+	if err := json.Unmarshal(files[1].Data, &summary); err != nil || files[1].Filename != "02_2026_Reisekosten.json" {
+		t.Fatalf("json %s: %v", files[1].Filename, err)
+	}
+	if summary.Workdays != 20 || summary.Total != roundCents(report.Total()) || len(summary.Documents) != 2 {
+		t.Errorf("json summary = %+v", summary)
+	}
+
+	cfg.Email.AttachData = nil
+	if files, err := reportDataAttachments(cfg, p, report); files != nil || err != nil {
+		t.Errorf("reportDataAttachments() without attachData = %v, %v", files, err)
+	}
+}
+
+func TestReportDataAttachmentsMail(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com", AttachData: []string{"csv"}},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := run(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(report.Attachments) != 2 {
+		t.Errorf("report has %d attachments, want the PDFs only", len(report.Attachments))
+	}
+	mails, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml"))
+	if len(mails) != 1 {
+		t.Fatalf("mails = %v", mails)
+	}
+	data, _ := os.ReadFile(mails[0])
+	for _, want := range []string{"02_2026_Reisekosten_Kilometergelderstattung.pdf", "02_2026_Reisekosten_Fahrten.csv"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("mail has no attachment %s", want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ---------------------------------------------------------------------------
+// Delivery Mode
+// ---------------------------------------------------------------------------
+
+// Delivery modes of a generated report
+const (
+	deliveryNone    = "none"    // generate only: archive and write the documents to the output directory
+	deliveryEmail   = "email"   // mail the documents with the configured provider
+	deliveryAPI     = "api"     // post the documents to the accounting system
+	deliveryStorage = "storage" // write the documents to output.dir, e.g. a synced folder
+)
+
+// DeliveryMode returns how reports are delivered. Without an explicit
+// delivery setting reports are mailed if mail is configured, and only
+// generated otherwise.
+func (c *Config) DeliveryMode() string {
+	if c.Delivery != "" {
+		return c.Delivery
+	}
+	if c.SMTP.Host != "" || c.Email.Provider != "" {
+		return deliveryEmail
+	}
+	return deliveryNone
+}
+
+// storeReport delivers a report without mail: the documents are written to
+// output.dir, and with delivery api posted to the accounting system. The
+// report is archived and exported once it is delivered.
+func storeReport(cfg *Config, p Period, report *Report, mode string) error {
+	if err := writeReportFiles(cfg, p, report); err != nil {
+		return fmt.Errorf("failed to write the documents: %w", err)
+	}
+	auditIssued(cfg, p, report, "")
+	var err error
+	action, detail := "store", "delivery: "+mode
+	if mode == deliveryAPI {
+		action = "post"
+		err = postReportVouchers(cfg, p, report)
+		detail = "posted to " + cfg.Accounting.Provider
+		if err != nil {
+			detail = fmt.Sprintf("posting to %s failed: %v", cfg.Accounting.Provider, err)
+		}
+	}
+	audit(cfg, action, p, report, detail)
+	if err != nil {
+		return err
+	}
+	slog.Info("report delivered without mail", "period", p.Label(), "delivery", mode)
+	if serr := recordStored(cfg, p, report); serr != nil {
+		slog.Warn("failed to record the delivery", "error", serr)
+	}
+	exportReport(cfg, p, report)
+
+	summary := newRunSummary(cfg, p, report, nil)
+	if aerr := archiveReport(cfg, summary, report.Attachments); aerr != nil {
+		slog.Warn("failed to archive report", "error", aerr)
+	}
+	if cfg.Webhook.URL != "" {
+		if werr := postWebhook(cfg, summary); werr != nil {
+			slog.Warn("webhook failed", "url", cfg.Webhook.URL, "error", werr)
+		}
+	}
+	return nil
+}
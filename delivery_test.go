@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliveryMode(t *testing.T) {
+	for _, tt := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, deliveryNone},
+		{Config{SMTP: SMTPConfig{Host: "smtp.example.com"}}, deliveryEmail},
+		{Config{Email: EmailConfig{Provider: "eml"}}, deliveryEmail},
+		{Config{Delivery: deliveryStorage, SMTP: SMTPConfig{Host: "smtp.example.com"}}, deliveryStorage},
+	} {
+		if got := tt.cfg.DeliveryMode(); got != tt.want {
+			t.Errorf("DeliveryMode(%+v) = %s, want %s", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestRunWithoutMail(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Outbox:    filepath.Join(dir, "outbox"),
+		Output:    OutputConfig{Dir: filepath.Join(dir, "out")},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, time.February)
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "02_2026_Reisekosten_Kilometergelderstattung.pdf")); err != nil {
+		t.Errorf("document not written: %v", err)
+	}
+	archived, err := loadArchivedPeriod(cfg, p.Key())
+	if err != nil || archived == nil {
+		t.Fatalf("report not archived: %v", err)
+	}
+	if archived.Delivery.Status != "stored" || archived.Delivery.Provider != deliveryNone {
+		t.Errorf("Delivery = %+v", archived.Delivery)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outbox")); !os.IsNotExist(err) {
+		t.Error("report was queued for mail")
+	}
+}
+
+func TestStoreReportWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Delivery:  deliveryStorage,
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Output:    OutputConfig{Dir: filepath.Join(dir, "out")},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, time.February)
+	if _, err := run(cfg, p); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	archived, _ := loadArchivedPeriod(cfg, p.Key())
+
+	// The documents exist: the second delivery fails before it is audited
+	// or archived
+	if _, err := run(cfg, p); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("second run() error = %v, want already exists", err)
+	}
+	if again, _ := loadArchivedPeriod(cfg, p.Key()); again == nil || archived == nil || again.documentID("Kilometergelderstattung") != archived.documentID("Kilometergelderstattung") {
+		t.Error("archive replaced by the failed delivery")
+	}
+	entries, err := readAudit(filepath.Join(dir, defaultAuditFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := 0
+	for _, e := range entries {
+		if e.Action == "store" {
+			stores++
+		}
+	}
+	if stores != 1 {
+		t.Errorf("%d store entries, want 1", stores)
+	}
+}
+
+func TestParseConfigDelivery(t *testing.T) {
+	customers := `customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BY
+`
+	// Without mail settings reports are only generated
+	cfg, err := parseConfig("config.yaml", []byte(customers), "")
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.DeliveryMode() != deliveryNone {
+		t.Errorf("DeliveryMode() = %s, want none", cfg.DeliveryMode())
+	}
+
+	for data, want := range map[string]string{
+		"delivery: email\n" + customers:   "email.from: required",
+		"delivery: storage\n" + customers: "output.dir: required",
+		"delivery: api\n" + customers:     "accounting.provider: required",
+		"delivery: ftp\n" + customers:     `delivery: invalid value "ftp" (use none, email, api, storage)`,
+	} {
+		_, err := parseConfig("config.yaml", []byte(data), "")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseConfig(%.20q) error = %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestDeliverReportExportsAfterDelivery(t *testing.T) {
+	var exported []string
+	registerExporter("test", ExporterFunc(func(cfg *Config, p Period, report *Report) error {
+		exported = append(exported, p.Key())
+		return nil
+	}))
+	t.Cleanup(func() { delete(exporters, "test") })
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "fax", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// A failed delivery exports nothing, a retry exports once
+	if err := deliverReport(cfg, p, report); err == nil || len(exported) != 0 {
+		t.Errorf("failed deliverReport() = %v, exported %v", err, exported)
+	}
+	cfg.Email.Provider = "eml"
+	if err := deliverReport(cfg, p, report); err != nil || len(exported) != 1 {
+		t.Errorf("deliverReport() = %v, exported %v", err, exported)
+	}
+
+	// Without mail the exporters run after the documents are stored
+	exported = nil
+	cfg.Delivery, cfg.Output.Dir = deliveryStorage, filepath.Join(dir, "out")
+	if err := deliverReport(cfg, p, report); err != nil || len(exported) != 1 {
+		t.Errorf("stored deliverReport() = %v, exported %v", err, exported)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import "time"
+
+// ---------------------------------------------------------------------------
+// Departure Location and Distance
+// ---------------------------------------------------------------------------
+
+// Meaning of the customers' distance
+const (
+	distanceRoundTrip = "roundTrip" // the km of the trip there and back, reimbursed once (default)
+	distanceOneWay    = "oneWay"    // the km of the way there, doubled for the way back
+)
+
+// distanceLegs returns how often a customer's distance is driven per trip:
+// twice if it is the one-way distance, otherwise once.
+func (c *Config) distanceLegs() int {
+	if c.DistanceMode == distanceOneWay {
+		return 2
+	}
+	return 1
+}
+
+// DepartureConfig sets the days on which trips start at the office instead
+// of at home (the customers' from), e.g. the day after a team meeting.
+type DepartureConfig struct {
+	Office         string   `yaml:"office,omitempty"`         // office address printed as the start of these trips
+	OfficeWeekdays []string `yaml:"officeWeekdays,omitempty"` // weekdays on which trips start at the office (mon, tue, ...)
+}
+
+// fromOffice reports whether the trip on date starts at the office: on the
+// configured office weekdays and on the days listed in fromOffice of the
+// month's override file.
+func (d DepartureConfig) fromOffice(ov *MonthOverride, date time.Time) bool {
+	return onWeekday(d.OfficeWeekdays, date) || contains(ov.FromOffice, date.Format(isoDate))
+}
+
+// start returns the start of a trip from the office for the entry detail.
+func (d DepartureConfig) start() string {
+	if d.Office != "" {
+		return "Abfahrt: Buero, " + umlautReplacer.Replace(d.Office)
+	}
+	return "Abfahrt: Buero"
+}
+
+// tripDistance returns the configured distance of a trip to the customer,
+// from the office or from home.
+func (c Customer) tripDistance(fromOffice bool) int {
+	if fromOffice && c.OfficeDistance > 0 {
+		return c.OfficeDistance
+	}
+	return c.Distance
+}
+
+// distanceGroup holds the dates of a customer's trips with the same distance
+// and km rate.
+type distanceGroup struct {
+	distance int
+	rate     float64
+	dates    []string // DD.MM.YYYY
+}
+
+// distanceGroups groups the customer's trips by distance and km rate, in the
+// order of the first trip of each group. Without trips from the office or by
+// bike it returns a single group, for a customer reached by public transport
+// none.
+func (c CustomerReport) distanceGroups() []distanceGroup {
+	if c.Customer.Ticket.active() {
+		return nil
+	}
+	var groups []distanceGroup
+	type key struct {
+		km   int
+		rate float64
+	}
+	index := make(map[key]int)
+	for n, d := range c.Dates {
+		k := key{c.distance(n), c.rate(n)}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, distanceGroup{distance: k.km, rate: k.rate})
+		}
+		groups[i].dates = append(groups[i].dates, d)
+	}
+	return groups
+}
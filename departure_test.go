@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDepartureFromOffice(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("fromOffice: [2026-02-04]\n"), 0644)
+	cfg := &Config{
+		Overrides: dir,
+		Departure: DepartureConfig{Office: "Hauptstr. 1, Stuttgart", OfficeWeekdays: []string{"mon"}},
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, OfficeDistance: 60, Province: "BW"},
+		},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Mondays (2, 9, 16, 23) and Wednesday 4 start at the office
+	c := report.Customers[0]
+	if c.Dates[0] != "02.02.2026" || c.distance(0) != 60 || c.distance(1) != 100 || c.distance(2) != 60 {
+		t.Errorf("distances = %v", c.Distances)
+	}
+	if c.km() != 5*60+15*100 || report.KmTotal != 540 {
+		t.Errorf("km = %d, KmTotal = %v, want 1800 km and 540 EUR", c.km(), report.KmTotal)
+	}
+	if groups := c.distanceGroups(); len(groups) != 2 || groups[0].distance != 60 || len(groups[0].dates) != 5 {
+		t.Errorf("distanceGroups() = %v", groups)
+	}
+	if s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil); s.Customers[0].Km != 1800 || s.Customers[0].distance(0) != 60 {
+		t.Errorf("summary km = %d, distances = %v", s.Customers[0].Km, s.Customers[0].Distances)
+	}
+	text := pdfText(t, report.Attachments[0].Data)
+	if !strings.Contains(text, "Fahrkosten \\(60 km x 0,30 EUR\\)") || !strings.Contains(text, "Abfahrt: Buero, Hauptstr. 1, Stuttgart") {
+		t.Errorf("Kilometergelderstattung misses the trips from the office")
+	}
+
+	// Without an office distance trips from the office keep the distance
+	if got := (Customer{Distance: 100}).tripDistance(true); got != 100 {
+		t.Errorf("tripDistance() = %d, want 100", got)
+	}
+}
+
+func TestDistanceMode(t *testing.T) {
+	p := monthPeriod(2026, 2)
+	roundTrip := &Config{Overrides: t.TempDir(), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+	oneWay := &Config{Overrides: t.TempDir(), DistanceMode: distanceOneWay, Customers: []Customer{{ID: "1", Name: "Acme", Distance: 50, Province: "BW"}}}
+
+	a, err := generateReport(roundTrip, p)
+	if err != nil {
+		t.Fatalf("generateReport(roundTrip) error = %v", err)
+	}
+	b, err := generateReport(oneWay, p)
+	if err != nil {
+		t.Fatalf("generateReport(oneWay) error = %v", err)
+	}
+	if a.KmTotal != 600 || b.KmTotal != 600 || b.Customers[0].km() != 2000 {
+		t.Errorf("KmTotal = %v (round trip), %v (one-way, %d km), want 600", a.KmTotal, b.KmTotal, b.Customers[0].km())
+	}
+	for text, want := range map[string]string{
+		pdfText(t, a.Attachments[0].Data): "Fahrkosten \\(100 km x 0,30 EUR\\)",
+		pdfText(t, b.Attachments[0].Data): "Fahrkosten \\(2 x 50 km x 0,30 EUR\\)",
+	} {
+		if !strings.Contains(text, want) || !strings.Contains(text, "02.02.2026  \\(Hin- und Rueckfahrt\\)") {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+}
+
+func TestDetour(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte(`detours:
+  - date: 2026-02-03
+    km: 30
+    note: Sperrung A8
+  - date: 2026-02-07
+    km: 10
+    note: Samstag, kein Reisetag
+`), 0644)
+	cfg := &Config{Overrides: dir, Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if c := report.Customers[0]; c.distance(1) != 130 || c.km() != 2030 || report.KmTotal != 609 {
+		t.Errorf("distance = %d, km = %d, KmTotal = %v, want 130, 2030 and 609", c.distance(1), c.km(), report.KmTotal)
+	}
+	text := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Umweg \\(+30 km x 0,30 EUR\\)            9,00 EUR", "Begruendung: Sperrung A8", "609,00 EUR"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Kilometergelderstattung missing %q", want)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("detours:\n  - date: 2026-02-03\n    km: 30\n"), 0644)
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), "detours[0].note: required") {
+		t.Errorf("generateReport() without note error = %v", err)
+	}
+}
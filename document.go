@@ -17,9 +17,15 @@ const (
 	lineDouble = "==========================================================================="
 )
 
+// Order of the entries in the documents
+const (
+	orderCustomer      = "customer"      // grouped by customer, each group after its customer header (default)
+	orderChronological = "chronological" // all customer headers first, then the entries by date
+)
+
 // documentID generates a structured document reference number.
-// Format: RK-YYYY-MM-XXXX (e.g., RK-2026-02-A7K2)
-func documentID(year int, month time.Month) string {
+// Format: RK-<period key>-XXXX (e.g., RK-2026-02-A7K2, RK-2026-Q1-4BX9, RK-2026-W09-Z3M1)
+func documentID(p Period) string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
 	b := make([]byte, 4)
@@ -28,7 +34,7 @@ func documentID(year int, month time.Month) string {
 		b[i] = charset[int(b[i])%len(charset)]
 	}
 
-	return fmt.Sprintf("RK-%d-%02d-%s", year, month, string(b))
+	return fmt.Sprintf("RK-%s-%s", p.Key(), string(b))
 }
 
 // formatDate formats a date as DD.MM.YYYY (German format).
@@ -36,9 +42,29 @@ func formatDate(year int, month time.Month, day int) string {
 	return fmt.Sprintf("%02d.%02d.%d", day, month, year)
 }
 
-// formatAmount formats a Euro amount with German decimal separator.
+// withWeekday prefixes a DD.MM.YYYY date with its weekday in the given
+// language, e.g. "Mo, 02.02.2026". Unparsable dates are returned unchanged.
+func withWeekday(dateString, lang string) string {
+	date, err := time.Parse("02.01.2006", dateString)
+	if err != nil {
+		return dateString
+	}
+	return localWeekday(date, lang) + ", " + date.Format(printLocale.date)
+}
+
+// formatISODate converts a YYYY-MM-DD date to DD.MM.YYYY.
+func formatISODate(s string) string {
+	d, err := time.Parse(isoDate, s)
+	if err != nil {
+		return s
+	}
+	return formatDate(d.Year(), d.Month(), d.Day())
+}
+
+// formatAmount formats a Euro amount with the decimal separator of the
+// locale (German decimal comma by default).
 func formatAmount(amount float64) string {
-	return strings.Replace(fmt.Sprintf("%.2f", amount), ".", ",", 1)
+	return localDecimal(fmt.Sprintf("%.2f", amount))
 }
 
 // rightAlign returns a string padded to align right within given width.
@@ -53,22 +79,52 @@ func rightAlign(s string, width int) string {
 // Document Content Builders
 // ---------------------------------------------------------------------------
 
-// buildDocumentHeader creates a professional header section for sevDesk compatibility.
-func buildDocumentHeader(year int, month time.Month, dateString, periodStart, periodEnd, title string) string {
+// SevDeskConfig holds additional header fields mapped by the sevDesk OCR
+// import, so vouchers need no manual post-editing.
+type SevDeskConfig struct {
+	CostCenter    string            `yaml:"costCenter,omitempty"`    // Kostenstelle
+	Category      string            `yaml:"category,omitempty"`      // Kategorie of all documents
+	Categories    map[string]string `yaml:"categories,omitempty"`    // Kategorie by document title, e.g. Verpflegungsmehraufwand
+	PaymentMethod string            `yaml:"paymentMethod,omitempty"` // Zahlungsart, e.g. Ueberweisung
+}
+
+// category returns the Kategorie of the document with the given title.
+func (s SevDeskConfig) category(title string) string {
+	if c, ok := s.Categories[title]; ok {
+		return c
+	}
+	return s.Category
+}
+
+// buildDocumentHeader creates a professional header section for sevDesk
+// compatibility. A non-empty purpose is printed as Zweck.
+func buildDocumentHeader(docID, period, dateString, periodStart, periodEnd, title, purpose string, sevDesk SevDeskConfig) string {
 	var b strings.Builder
 
 	// Title block
-	header := fmt.Sprintf("%s %02d/%d", strings.ToUpper(title), month, year)
+	header := fmt.Sprintf("%s %s", strings.ToUpper(title), period)
 	padding := (lineWidth - len(header)) / 2
 	b.WriteString(lineDouble + "\n")
 	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", padding), header))
 	b.WriteString(lineDouble + "\n\n")
 
 	// Document metadata (sevDesk-friendly labels)
-	b.WriteString(fmt.Sprintf("Beleg-Nr.:            %s\n", documentID(year, month)))
-	b.WriteString(fmt.Sprintf("Datum:                %s\n", dateString))
+	b.WriteString(fmt.Sprintf("Beleg-Nr.:            %s\n", docID))
+	b.WriteString(fmt.Sprintf("Datum:                %s\n", localDate(dateString)))
 	b.WriteString(fmt.Sprintf("Rechnungsart:         Reisekosten - %s\n", title))
-	b.WriteString(fmt.Sprintf("Abrechnungszeitraum:  %s - %s\n", periodStart, periodEnd))
+	b.WriteString(fmt.Sprintf("Abrechnungszeitraum:  %s - %s\n", localDate(periodStart), localDate(periodEnd)))
+	if purpose != "" {
+		b.WriteString(fmt.Sprintf("Zweck:                %s\n", purpose))
+	}
+	if c := sevDesk.category(title); c != "" {
+		b.WriteString(fmt.Sprintf("Kategorie:            %s\n", c))
+	}
+	if sevDesk.CostCenter != "" {
+		b.WriteString(fmt.Sprintf("Kostenstelle:         %s\n", sevDesk.CostCenter))
+	}
+	if sevDesk.PaymentMethod != "" {
+		b.WriteString(fmt.Sprintf("Zahlungsart:          %s\n", sevDesk.PaymentMethod))
+	}
 	b.WriteString("\n")
 
 	return b.String()
@@ -84,34 +140,104 @@ func buildCustomerHeader(c Customer) string {
 
 	b.WriteString(fmt.Sprintf("Von:    %s\n", c.From))
 	b.WriteString(fmt.Sprintf("Nach:   %s\n", c.To))
-	b.WriteString(fmt.Sprintf("Grund:  %s\n\n", c.Reason))
+	if len(c.Reasons) == 0 {
+		b.WriteString(fmt.Sprintf("Grund:  %s\n", c.Reason))
+	}
+	if c.Note != "" {
+		b.WriteString(fmt.Sprintf("Notiz:  %s\n", c.Note))
+	}
+	if c.PurchaseOrder != "" {
+		b.WriteString(fmt.Sprintf("Bestell-Nr.:  %s\n", c.PurchaseOrder))
+	}
+	if c.Contract != "" {
+		b.WriteString(fmt.Sprintf("Vertrag:      %s\n", c.Contract))
+	}
+	b.WriteString("\n")
 
 	return b.String()
 }
 
-// buildKilometerEntry creates a single mileage reimbursement entry for a given date.
-func buildKilometerEntry(dateString string, distanceKm int) string {
+// buildTripListHeader separates the customer headers from the entries in
+// chronological order.
+func buildTripListHeader() string {
+	return lineSingle + "\nReisetage\n" + lineSingle + "\n\n"
+}
+
+// buildKilometerEntry creates a single mileage reimbursement entry for a given
+// date: the round trip drives distanceKm legs times (2 for a one-way
+// distance). Non-empty details (booking line, notes) are printed below the
+// amount.
+func buildKilometerEntry(dateString string, legs, distanceKm int, rate float64, details ...string) string {
 	var b strings.Builder
 
-	amount := float64(distanceKm) * kmRatePerKm
+	amount := roundCents(float64(legs*distanceKm) * rate)
 	amountStr := formatAmount(amount) + " EUR"
+	label := fmt.Sprintf("Fahrkosten (%d km x %s EUR)", distanceKm, formatAmount(rate))
+	if legs > 1 {
+		label = fmt.Sprintf("Fahrkosten (%d x %d km x %s EUR)", legs, distanceKm, formatAmount(rate))
+	}
 
-	b.WriteString(fmt.Sprintf("  %s\n", dateString))
-	b.WriteString(fmt.Sprintf("    Fahrkosten (%d km x 0,30 EUR)%s\n\n",
-		distanceKm, rightAlign(amountStr, 45-len(fmt.Sprintf("Fahrkosten (%d km x 0,30 EUR)", distanceKm)))))
+	b.WriteString(fmt.Sprintf("  %s  (Hin- und Rueckfahrt)\n", dateString))
+	b.WriteString(fmt.Sprintf("    %s%s\n", label, rightAlign(amountStr, 45-len(label))))
+	writeEntryDetails(&b, details)
 
 	return b.String()
 }
 
-// buildMealAllowanceEntry creates a single meal allowance entry for a given date.
-func buildMealAllowanceEntry(dateString string) string {
+// buildMealAllowanceEntry creates a single meal allowance entry for a given
+// date with the departure and return times. Non-empty details (booking line,
+// notes) are printed below the amount.
+func buildMealAllowanceEntry(dateString string, times TripTimes, rate float64, details ...string) string {
 	var b strings.Builder
 
-	amountStr := "14,00 EUR"
+	amountStr := formatAmount(rate) + " EUR"
 
-	b.WriteString(fmt.Sprintf("  %s  (07:00 - 17:00)\n", dateString))
-	b.WriteString(fmt.Sprintf("    Verpflegungsmehraufwand (8h - 24h)%s\n\n",
+	b.WriteString(fmt.Sprintf("  %s  (%s)\n", dateString, times))
+	b.WriteString(fmt.Sprintf("    Verpflegungsmehraufwand (8h - 24h)%s\n",
 		rightAlign(amountStr, 45-len("Verpflegungsmehraufwand (8h - 24h)"))))
+	writeEntryDetails(&b, details)
+
+	return b.String()
+}
+
+// detourDetails returns the lines of a detour printed below the standard
+// line of a kilometer entry: its amount and its justification. It returns
+// nil without a detour.
+func detourDetails(d Detour, rate float64) []string {
+	if d.Km == 0 {
+		return nil
+	}
+	amountStr := formatAmount(roundCents(float64(d.Km)*rate)) + " EUR"
+	label := fmt.Sprintf("Umweg (+%d km x %s EUR)", d.Km, formatAmount(rate))
+	return []string{label + rightAlign(amountStr, 45-len(label)), "Begruendung: " + umlautReplacer.Replace(d.Note)}
+}
+
+// writeEntryDetails ends an entry with its non-empty detail lines and a blank line.
+func writeEntryDetails(b *strings.Builder, details []string) {
+	for _, d := range details {
+		if d != "" {
+			b.WriteString(fmt.Sprintf("    %s\n", d))
+		}
+	}
+	b.WriteString("\n")
+}
+
+// buildExpenseEntry creates a single additional expense entry. customerName
+// may be empty.
+func buildExpenseEntry(e Expense, customerName string) string {
+	var b strings.Builder
+
+	amountStr := formatAmount(e.Amount) + " EUR"
+	if customerName != "" {
+		b.WriteString(fmt.Sprintf("  %s  (%s)\n", localDate(formatISODate(e.Date)), customerName))
+	} else {
+		b.WriteString(fmt.Sprintf("  %s\n", localDate(formatISODate(e.Date))))
+	}
+	b.WriteString(fmt.Sprintf("    %s%s\n", e.Description, rightAlign(amountStr, 45-len(e.Description))))
+	if e.VAT > 0 {
+		b.WriteString(fmt.Sprintf("    darin MwSt. %s EUR\n", formatAmount(e.VAT)))
+	}
+	b.WriteString("\n")
 
 	return b.String()
 }
@@ -128,3 +254,74 @@ func buildDocumentFooter(totalAmount float64) string {
 
 	return b.String()
 }
+
+// skippedDayReasons are the German labels of the reasons a day has no trip.
+var skippedDayReasons = map[string]string{
+	reasonWeekend:     "Wochenende",
+	reasonHoliday:     "Feiertag",
+	reasonAbsence:     "Abwesenheit",
+	reasonChristmas:   "Weihnachtspause (24.12., 27.-31.12.)",
+	reasonNoCustomer:  "kein Kunde geplant",
+	reasonExcluded:    "ausgeschlossener Tag",
+	reasonSkipped:     "ausgelassen",
+	reasonNotSelected: "nicht ausgewaehlt",
+	reasonNotEmployed: "ausserhalb der Beschaeftigung",
+	reasonOfficeDay:   "Homeoffice/Buero",
+	reasonCapped:      "Hoechstbetrag erreicht",
+}
+
+// umlautReplacer spells out characters the PDF core fonts cannot print.
+var umlautReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss")
+
+// skippedDayReason returns the German reason of a day without a trip. An
+// absence is named by its own reason (e.g. Urlaub), a holiday by its name
+// and region.
+func skippedDayReason(d dayDecision) string {
+	if d.Reason == reasonAbsence && d.Detail != "" {
+		return umlautReplacer.Replace(d.Detail)
+	}
+	reason := skippedDayReasons[d.Reason]
+	if d.Detail != "" {
+		reason += ": " + umlautReplacer.Replace(d.Detail)
+	}
+	return reason
+}
+
+// buildSkippedDaysAppendix lists the days of the period without a trip with
+// their reason, followed by the number of days per reason.
+func buildSkippedDaysAppendix(period string, days []dayDecision) string {
+	var b strings.Builder
+
+	header := "ANHANG: TAGE OHNE REISE " + period
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", (lineWidth-len(header))/2), header))
+	b.WriteString(lineDouble + "\n\n")
+
+	var order []string
+	counts := make(map[string]int)
+	for _, d := range days {
+		if d.Reason == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s %s  %s\n", weekdayAbbrev(d.Date), formatDate(d.Date.Year(), d.Date.Month(), d.Date.Day()), skippedDayReason(d)))
+
+		label := skippedDayReasons[d.Reason]
+		if d.Reason == reasonAbsence {
+			label = skippedDayReason(d)
+		}
+		if counts[label] == 0 {
+			order = append(order, label)
+		}
+		counts[label]++
+	}
+
+	b.WriteString("\n" + lineSingle + "\n")
+	for _, label := range order {
+		unit := "Tage"
+		if counts[label] == 1 {
+			unit = "Tag"
+		}
+		b.WriteString(fmt.Sprintf("  %-40s %3d %s\n", label, counts[label], unit))
+	}
+	return b.String()
+}
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -31,6 +36,24 @@ func TestFormatDate(t *testing.T) {
 	}
 }
 
+func TestWithWeekday(t *testing.T) {
+	tests := []struct {
+		date, lang, expected string
+	}{
+		{"02.02.2026", "", "Mo, 02.02.2026"},
+		{"03.02.2026", "de", "Di, 03.02.2026"},
+		{"03.02.2026", "en", "Tu, 03.02.2026"},
+		{"08.02.2026", "en", "Su, 08.02.2026"},
+		{"2026-02-02", "de", "2026-02-02"},
+	}
+
+	for _, tt := range tests {
+		if got := withWeekday(tt.date, tt.lang); got != tt.expected {
+			t.Errorf("withWeekday(%q, %q) = %q, want %q", tt.date, tt.lang, got, tt.expected)
+		}
+	}
+}
+
 func TestFormatAmount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -79,11 +102,11 @@ func TestRightAlign(t *testing.T) {
 }
 
 func TestDocumentID(t *testing.T) {
-	id := documentID(2026, 2)
+	id := documentID(monthPeriod(2026, 2))
 
 	// Check prefix
 	if !strings.HasPrefix(id, "RK-2026-02-") {
-		t.Errorf("documentID(2026, 2) = %q, want prefix RK-2026-02-", id)
+		t.Errorf("documentID(monthPeriod(2026, 2)) = %q, want prefix RK-2026-02-", id)
 	}
 
 	// Check total length: "RK-2026-02-XXXX" = 15
@@ -100,7 +123,7 @@ func TestDocumentID(t *testing.T) {
 	}
 
 	// Check uniqueness (two calls should differ)
-	id2 := documentID(2026, 2)
+	id2 := documentID(monthPeriod(2026, 2))
 	if id == id2 {
 		t.Logf("Warning: two documentID calls returned same value %q (possible but unlikely)", id)
 	}
@@ -130,10 +153,19 @@ func TestBuildCustomerHeader(t *testing.T) {
 			t.Errorf("buildCustomerHeader missing %q in:\n%s", want, got)
 		}
 	}
+	if strings.Contains(got, "Bestell-Nr.") || strings.Contains(got, "Vertrag") {
+		t.Errorf("buildCustomerHeader shows empty references:\n%s", got)
+	}
+
+	c.PurchaseOrder, c.Contract = "4500012345", "RV-2026"
+	got = buildCustomerHeader(c)
+	if !strings.Contains(got, "Bestell-Nr.:  4500012345\nVertrag:      RV-2026\n\n") {
+		t.Errorf("buildCustomerHeader missing references in:\n%s", got)
+	}
 }
 
 func TestBuildKilometerEntry(t *testing.T) {
-	got := buildKilometerEntry("13.02.2026", 100)
+	got := buildKilometerEntry("13.02.2026", 1, 100, kmRatePerKm, "")
 
 	checks := []string{
 		"13.02.2026",
@@ -148,6 +180,27 @@ func TestBuildKilometerEntry(t *testing.T) {
 	}
 }
 
+func TestBuildEntryBooking(t *testing.T) {
+	c := Customer{Project: "P-4711", CostCenter: "4100"}
+	if got := c.booking(); got != "Projekt: P-4711, Kostenstelle: 4100" {
+		t.Errorf("booking() = %q", got)
+	}
+	if got := (Customer{CostCenter: "4100"}).booking(); got != "Kostenstelle: 4100" {
+		t.Errorf("booking() = %q", got)
+	}
+
+	km := buildKilometerEntry("13.02.2026", 1, 100, kmRatePerKm, c.booking())
+	verp := buildMealAllowanceEntry("13.02.2026", TripTimes{}, verpflegungRate, c.booking())
+	for _, entry := range []string{km, verp} {
+		if !strings.HasSuffix(entry, "\n    Projekt: P-4711, Kostenstelle: 4100\n\n") {
+			t.Errorf("entry without booking line:\n%s", entry)
+		}
+	}
+	if entry := buildKilometerEntry("13.02.2026", 1, 100, kmRatePerKm, ""); strings.Contains(entry, "Projekt") || !strings.HasSuffix(entry, "EUR\n\n") {
+		t.Errorf("entry without booking = %q", entry)
+	}
+}
+
 func TestBuildKilometerEntryCalculation(t *testing.T) {
 	tests := []struct {
 		distance int
@@ -160,7 +213,7 @@ func TestBuildKilometerEntryCalculation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.amount, func(t *testing.T) {
-			got := buildKilometerEntry("01.01.2026", tt.distance)
+			got := buildKilometerEntry("01.01.2026", 1, tt.distance, kmRatePerKm, "")
 			if !strings.Contains(got, tt.amount) {
 				t.Errorf("buildKilometerEntry with distance %d missing amount %q", tt.distance, tt.amount)
 			}
@@ -169,7 +222,7 @@ func TestBuildKilometerEntryCalculation(t *testing.T) {
 }
 
 func TestBuildMealAllowanceEntry(t *testing.T) {
-	got := buildMealAllowanceEntry("13.02.2026")
+	got := buildMealAllowanceEntry("13.02.2026", TripTimes{}, verpflegungRate, "")
 
 	checks := []string{
 		"13.02.2026",
@@ -208,3 +261,86 @@ func TestBuildDocumentFooterZero(t *testing.T) {
 		t.Errorf("buildDocumentFooter(0) missing 0,00 EUR in:\n%s", got)
 	}
 }
+
+func TestBuildDocumentHeaderSevDesk(t *testing.T) {
+	got := buildDocumentHeader("RK-1", "02/2026", "28.02.2026", "01.02.2026", "28.02.2026", "Kilometergelderstattung", "", SevDeskConfig{})
+	if strings.Contains(got, "Kategorie") || strings.Contains(got, "Kostenstelle") || strings.Contains(got, "Zahlungsart") {
+		t.Errorf("buildDocumentHeader shows empty sevDesk fields:\n%s", got)
+	}
+
+	sevDesk := SevDeskConfig{
+		CostCenter:    "4100",
+		Category:      "Reisekosten Arbeitnehmer",
+		Categories:    map[string]string{"Verpflegungsmehraufwand": "Verpflegungsmehraufwand Arbeitnehmer"},
+		PaymentMethod: "Ueberweisung",
+	}
+	got = buildDocumentHeader("RK-1", "02/2026", "28.02.2026", "01.02.2026", "28.02.2026", "Kilometergelderstattung", "", sevDesk)
+	if !strings.Contains(got, "Abrechnungszeitraum:  01.02.2026 - 28.02.2026\nKategorie:            Reisekosten Arbeitnehmer\nKostenstelle:         4100\nZahlungsart:          Ueberweisung\n\n") {
+		t.Errorf("buildDocumentHeader missing sevDesk fields in:\n%s", got)
+	}
+	got = buildDocumentHeader("RK-2", "02/2026", "28.02.2026", "01.02.2026", "28.02.2026", "Verpflegungsmehraufwand", "", sevDesk)
+	if !strings.Contains(got, "Kategorie:            Verpflegungsmehraufwand Arbeitnehmer\n") {
+		t.Errorf("buildDocumentHeader ignores the document category in:\n%s", got)
+	}
+}
+
+// pdfText returns the inflated content streams of a PDF.
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+	var b strings.Builder
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue
+		}
+		text, _ := io.ReadAll(r)
+		b.Write(text)
+	}
+	return b.String()
+}
+
+// pdfPageCount returns the number of pages of a generated PDF.
+func pdfPageCount(t *testing.T, data []byte) int {
+	t.Helper()
+	m := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("PDF has no page tree")
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+func TestChronologicalOrder(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+			{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	grouped := pdfText(t, report.Attachments[0].Data)
+	if strings.Contains(grouped, "Kunde:") || strings.Index(grouped, "03.02.2026") < strings.Index(grouped, "04.02.2026") {
+		t.Errorf("entries are not grouped by customer")
+	}
+
+	cfg.Order = orderChronological
+	report, err = generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport(chronological) error = %v", err)
+	}
+	for _, doc := range report.Attachments[:2] {
+		text := pdfText(t, doc.Data)
+		first, second, list := strings.Index(text, "Mo, 02.02.2026"), strings.Index(text, "Di, 03.02.2026"), strings.Index(text, "Reisetage")
+		if list < 0 || first < list || second < first || strings.Index(text, "2\\) Globex") > list {
+			t.Errorf("%s: entries are not in chronological order after the customer headers", doc.Filename)
+		}
+		if !strings.Contains(text, "Kunde: 2\\) Globex") {
+			t.Errorf("%s: entry does not name its customer", doc.Filename)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"text/template"
 
 	"github.com/go-gomail/gomail"
 )
@@ -10,21 +15,216 @@ import (
 // Email
 // ---------------------------------------------------------------------------
 
-// Attachment represents an in-memory email attachment.
+// emailBody is the HTML body of the expense report email.
+const emailBody = "Dokumente anbei.<br>"
+
+// defaultSubject is the subject template of the report mail.
+const defaultSubject = "Deine Reisekostenabrechnung {{.Period}}"
+
+// subjectData is available in the email.subject template.
+type subjectData struct {
+	Period         string     // MM/YYYY, Qn/YYYY or KW nn/YYYY
+	Key            string     // YYYY-MM, YYYY-Qn or YYYY-Wnn
+	Total          string     // e.g. 730,00
+	Customers      []Customer // customers with at least one day
+	PurchaseOrders string     // distinct PO numbers of these customers, comma-separated
+	Contracts      string     // distinct contract references of these customers, comma-separated
+}
+
+// reportSubject renders the subject of the report mail from email.subject.
+func reportSubject(cfg *Config, p Period, report *Report) (string, error) {
+	text := cfg.Email.Subject
+	switch {
+	case text != "":
+	case cfg.taxDeduction():
+		text = taxDeductionSubject
+	default:
+		text = defaultSubject
+	}
+	tmpl, err := template.New("subject").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid email.subject: %w", err)
+	}
+
+	data := subjectData{Period: p.Label(), Key: p.Key(), Total: formatAmount(report.Total())}
+	var orders, contracts []string
+	for _, c := range report.Customers {
+		data.Customers = append(data.Customers, c.Customer)
+		if c.Customer.PurchaseOrder != "" && !contains(orders, c.Customer.PurchaseOrder) {
+			orders = append(orders, c.Customer.PurchaseOrder)
+		}
+		if c.Customer.Contract != "" && !contains(contracts, c.Customer.Contract) {
+			contracts = append(contracts, c.Customer.Contract)
+		}
+	}
+	data.PurchaseOrders = strings.Join(orders, ", ")
+	data.Contracts = strings.Join(contracts, ", ")
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid email.subject: %w", err)
+	}
+	// Headers must not contain line breaks
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// Mail is a composed message ready for delivery.
+type Mail struct {
+	To          string            `json:"to,omitempty"` // recipient, defaults to email.to
+	Subject     string            `json:"subject"`
+	Headers     map[string]string `json:"headers,omitempty"` // additional headers (e.g. Message-ID, In-Reply-To)
+	Body        string            `json:"body,omitempty"`    // HTML body, defaults to emailBody
+	Attachments []Attachment      `json:"attachments"`
+}
+
+// body returns the HTML body of the mail.
+func (m Mail) body() string {
+	if m.Body != "" {
+		return m.Body
+	}
+	return emailBody
+}
+
+// recipient returns the address the mail is sent to.
+func (m Mail) recipient(cfg *Config) string {
+	if m.To != "" {
+		return m.To
+	}
+	return cfg.Email.To
+}
+
+// Attachment represents an in-memory email attachment. The documents of a
+// report also carry their type, Beleg-Nr. and amount for the run summary.
 type Attachment struct {
 	Filename string
 	Data     []byte
+	Type     string
+	DocID    string
+	Amount   float64
+}
+
+// ContentType returns the MIME type of the attachment derived from its filename.
+func (a Attachment) ContentType() string {
+	if ct := mime.TypeByExtension(filepath.Ext(a.Filename)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// encodedSize estimates the size of an attachment in the MIME message
+// (base64 with 76-character lines).
+func (a Attachment) encodedSize() int64 {
+	b64 := int64(len(a.Data)+2) / 3 * 4
+	return b64 + b64/76*2
+}
+
+// splitAttachments groups attachments into parts whose encoded size stays within
+// limit bytes, preserving their order. A limit <= 0 disables splitting.
+func splitAttachments(attachments []Attachment, limit int64) ([][]Attachment, error) {
+	if limit <= 0 || len(attachments) == 0 {
+		return [][]Attachment{attachments}, nil
+	}
+
+	var parts [][]Attachment
+	var current []Attachment
+	var size int64
+	for _, a := range attachments {
+		n := a.encodedSize()
+		if n > limit {
+			return nil, fmt.Errorf("attachment %s (%d KB encoded) exceeds the mail size limit of %d KB",
+				a.Filename, n/1024, limit/1024)
+		}
+		if size+n > limit && len(current) > 0 {
+			parts = append(parts, current)
+			current, size = nil, 0
+		}
+		current = append(current, a)
+		size += n
+	}
+	return append(parts, current), nil
+}
+
+// mailParts splits a mail into several if its attachments exceed the
+// configured size limit. Follow-up parts get their own Message-ID and reply
+// to the first part.
+func mailParts(cfg *Config, m Mail) ([]Mail, error) {
+	parts, err := splitAttachments(m.Attachments, int64(cfg.Email.MaxSizeMB)*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+
+	mails := make([]Mail, len(parts))
+	for i, part := range parts {
+		pm := Mail{To: m.To, Subject: m.Subject, Headers: m.Headers, Body: m.Body, Attachments: part}
+		if len(parts) > 1 {
+			pm.Subject = fmt.Sprintf("%s (Teil %d/%d)", m.Subject, i+1, len(parts))
+		}
+		if id, ok := m.Headers["Message-ID"]; ok && i > 0 {
+			pm.Headers = make(map[string]string, len(m.Headers)+2)
+			for k, v := range m.Headers {
+				pm.Headers[k] = v
+			}
+			pm.Headers["Message-ID"] = strings.Replace(id, "@", fmt.Sprintf(".part%d@", i+1), 1)
+			pm.Headers["In-Reply-To"] = id
+			pm.Headers["References"] = strings.TrimSpace(m.Headers["References"] + " " + id)
+		}
+		mails[i] = pm
+	}
+	return mails, nil
+}
+
+// deliver sends the attachments, split into several mails if they exceed the
+// configured size limit. Each part is sent with retries.
+func deliver(cfg *Config, m Mail) error {
+	parts, err := mailParts(cfg, m)
+	if err != nil {
+		return err
+	}
+	for _, pm := range parts {
+		if err := sendWithRetry(cfg, pm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendEmail delivers the generated documents using the configured email provider.
+func sendEmail(cfg *Config, m Mail) error {
+	d, err := deliverer(cfg)
+	if err != nil {
+		return err
+	}
+	return d.Deliver(cfg, m)
+}
+
+// mailHeaders merges the configured custom headers and Reply-To with the
+// message-specific headers. Message headers take precedence.
+func mailHeaders(cfg *Config, m Mail) map[string]string {
+	headers := make(map[string]string, len(cfg.Email.Headers)+len(m.Headers)+1)
+	for k, v := range cfg.Email.Headers {
+		headers[k] = v
+	}
+	if cfg.Email.ReplyTo != "" {
+		headers["Reply-To"] = cfg.Email.ReplyTo
+	}
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	return headers
 }
 
-// sendEmail sends the generated PDFs via SMTP using in-memory attachments.
-func sendEmail(cfg *Config, subject string, attachments ...Attachment) error {
+// buildMessage composes the MIME message with in-memory attachments.
+func buildMessage(cfg *Config, m Mail) *gomail.Message {
 	msg := gomail.NewMessage()
 	msg.SetHeader("From", cfg.Email.From)
-	msg.SetHeader("To", cfg.Email.To)
-	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/html", "Dokumente anbei.<br>")
+	msg.SetHeader("To", m.recipient(cfg))
+	msg.SetHeader("Subject", m.Subject)
+	for k, v := range mailHeaders(cfg, m) {
+		msg.SetHeader(k, v)
+	}
+	msg.SetBody("text/html", m.body())
 
-	for _, a := range attachments {
+	for _, a := range m.Attachments {
 		data := a.Data // capture for closure
 		msg.Attach(a.Filename, gomail.SetCopyFunc(func(w io.Writer) error {
 			_, err := w.Write(data)
@@ -32,6 +232,19 @@ func sendEmail(cfg *Config, subject string, attachments ...Attachment) error {
 		}))
 	}
 
-	dialer := gomail.NewDialer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.User, cfg.SMTP.Pass)
-	return dialer.DialAndSend(msg)
+	return msg
+}
+
+// sendSMTP sends the generated PDFs via SMTP using in-memory attachments.
+func sendSMTP(cfg *Config, m Mail) error {
+	msg := buildMessage(cfg, m)
+
+	sender, err := dialSMTP(cfg.SMTP)
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+	sender.notify = cfg.Email.DSN
+
+	return gomail.Send(sender, msg)
 }
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentContentType(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		{"report.pdf", "application/pdf"},
+		{"unknown.xyz123", "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got := Attachment{Filename: tt.filename}.ContentType()
+			if got != tt.expected {
+				t.Errorf("ContentType() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSendEmailUnknownProvider(t *testing.T) {
+	cfg := &Config{Email: EmailConfig{Provider: "carrier-pigeon"}}
+	if err := sendEmail(cfg, Mail{Subject: "Test"}); err == nil {
+		t.Error("sendEmail() expected error for unknown provider")
+	}
+}
+
+func TestSendGraph(t *testing.T) {
+	var got graphSendMailRequest
+	var gotPath, gotAuth string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant-1/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"tok"}`))
+	})
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	oldToken, oldBase := graphTokenURL, graphBaseURL
+	graphTokenURL, graphBaseURL = srv.URL+"/%s/token", srv.URL
+	defer func() { graphTokenURL, graphBaseURL = oldToken, oldBase }()
+
+	cfg := &Config{
+		Email: EmailConfig{Provider: "graph", From: "me@example.com", To: "boss@example.com"},
+		Graph: GraphConfig{TenantID: "tenant-1", ClientID: "app", ClientSecret: "s3cret"},
+	}
+	err := sendEmail(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}})
+	if err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	if gotPath != "/users/me@example.com/sendMail" {
+		t.Errorf("request path = %q", gotPath)
+	}
+	if gotAuth != "Bearer tok" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer tok")
+	}
+	if got.Message.Subject != "Betreff" || got.Message.ToRecipients[0].EmailAddress.Address != "boss@example.com" {
+		t.Errorf("unexpected message: %+v", got.Message)
+	}
+	if len(got.Message.Attachments) != 1 || got.Message.Attachments[0].ContentBytes != base64.StdEncoding.EncodeToString([]byte("%PDF")) {
+		t.Errorf("unexpected attachments: %+v", got.Message.Attachments)
+	}
+
+	t.Run("token error", func(t *testing.T) {
+		bad := *cfg
+		bad.Graph.ClientSecret = "wrong"
+		err := sendEmail(&bad, Mail{Subject: "Betreff"})
+		if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+			t.Errorf("sendEmail() error = %v, want invalid_client", err)
+		}
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		bad := *cfg
+		bad.Graph = GraphConfig{}
+		if err := sendEmail(&bad, Mail{Subject: "Betreff"}); err == nil {
+			t.Error("sendEmail() expected error for missing credentials")
+		}
+	})
+}
+
+func TestSendGmail(t *testing.T) {
+	var raw []byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"tok"}`))
+	})
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Raw string `json:"raw"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		raw, _ = base64.URLEncoding.DecodeString(body.Raw)
+		w.Write([]byte(`{"id":"123"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	oldToken, oldSend := gmailTokenURL, gmailSendURL
+	gmailTokenURL, gmailSendURL = srv.URL+"/token", srv.URL+"/send"
+	defer func() { gmailTokenURL, gmailSendURL = oldToken, oldSend }()
+
+	cfg := &Config{
+		Email: EmailConfig{Provider: "gmail", From: "me@example.com", To: "boss@example.com"},
+		Gmail: GmailConfig{ClientID: "app", ClientSecret: "s3cret", RefreshToken: "refresh"},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	for _, want := range []string{"To: boss@example.com", "Subject: Betreff", `filename="a.pdf"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("raw message missing %q", want)
+		}
+	}
+
+	t.Run("revoked refresh token", func(t *testing.T) {
+		bad := *cfg
+		bad.Gmail.RefreshToken = "expired"
+		err := sendEmail(&bad, Mail{Subject: "Betreff"})
+		if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+			t.Errorf("sendEmail() error = %v, want invalid_grant", err)
+		}
+	})
+}
+
+func TestSendSendGrid(t *testing.T) {
+	var got sendgridRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer SG.key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid, expired, or revoked","field":null}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.From.Email == "unverified@example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":[{"message":"does not match a verified Sender Identity","field":"from"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	old := sendgridSendURL
+	sendgridSendURL = srv.URL
+	defer func() { sendgridSendURL = old }()
+
+	cfg := &Config{
+		Email:    EmailConfig{Provider: "sendgrid", From: "me@example.com", To: "boss@example.com"},
+		SendGrid: SendGridConfig{APIKey: "SG.key"},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+	if got.Personalizations[0].To[0].Email != "boss@example.com" || got.Subject != "Betreff" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0].Type != "application/pdf" || got.Attachments[0].Disposition != "attachment" {
+		t.Errorf("unexpected attachments: %+v", got.Attachments)
+	}
+
+	t.Run("provider error", func(t *testing.T) {
+		bad := *cfg
+		bad.Email.From = "unverified@example.com"
+		err := sendEmail(&bad, Mail{Subject: "Betreff"})
+		if err == nil || !strings.Contains(err.Error(), "from: does not match a verified Sender Identity") {
+			t.Errorf("sendEmail() error = %v", err)
+		}
+	})
+}
+
+func TestSendMailgun(t *testing.T) {
+	var gotPath, gotTo, gotFilename string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, _ := r.BasicAuth(); pass != "key-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+		gotPath = r.URL.Path
+		r.ParseMultipartForm(1 << 20)
+		gotTo = r.FormValue("to")
+		if fh := r.MultipartForm.File["attachment"]; len(fh) == 1 {
+			gotFilename = fh[0].Filename
+		}
+		w.Write([]byte(`{"id":"<1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer srv.Close()
+
+	oldUS, oldEU := mailgunBaseURL, mailgunEUBaseURL
+	mailgunBaseURL, mailgunEUBaseURL = srv.URL+"/us", srv.URL+"/eu"
+	defer func() { mailgunBaseURL, mailgunEUBaseURL = oldUS, oldEU }()
+
+	cfg := &Config{
+		Email:   EmailConfig{Provider: "mailgun", From: "me@example.com", To: "boss@example.com"},
+		Mailgun: MailgunConfig{Domain: "mg.example.com", APIKey: "key-123", Region: "eu"},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+	if gotPath != "/eu/mg.example.com/messages" {
+		t.Errorf("request path = %q", gotPath)
+	}
+	if gotTo != "boss@example.com" || gotFilename != "a.pdf" {
+		t.Errorf("to = %q, attachment = %q", gotTo, gotFilename)
+	}
+
+	t.Run("invalid key", func(t *testing.T) {
+		bad := *cfg
+		bad.Mailgun.APIKey = "wrong"
+		err := sendEmail(&bad, Mail{Subject: "Betreff"})
+		if err == nil || !strings.Contains(err.Error(), "invalid API key") {
+			t.Errorf("sendEmail() error = %v", err)
+		}
+	})
+}
+
+func TestWriteEML(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email: EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:   EMLConfig{Dir: dir},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Deine Reisekostenabrechnung 02/2026", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Deine_Reisekostenabrechnung_02_2026.eml"))
+	if err != nil {
+		t.Fatalf("eml file not written: %v", err)
+	}
+	for _, want := range []string{"To: boss@example.com", `filename="a.pdf"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("eml missing %q", want)
+		}
+	}
+}
+
+func TestWriteMaildir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "Maildir")
+	cfg := &Config{
+		Email:   EmailConfig{Provider: "maildir", From: "me@example.com", To: "boss@example.com"},
+		Maildir: MaildirConfig{Path: root},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff"}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	newFiles, _ := filepath.Glob(filepath.Join(root, "new", "*"))
+	tmpFiles, _ := filepath.Glob(filepath.Join(root, "tmp", "*"))
+	if len(newFiles) != 1 || len(tmpFiles) != 0 {
+		t.Errorf("new/ has %d files, tmp/ has %d files, want 1 and 0", len(newFiles), len(tmpFiles))
+	}
+
+	t.Run("missing path", func(t *testing.T) {
+		bad := *cfg
+		bad.Maildir.Path = ""
+		if err := sendEmail(&bad, Mail{Subject: "Betreff"}); err == nil {
+			t.Error("sendEmail() expected error for missing maildir path")
+		}
+	})
+}
+
+func TestSplitAttachments(t *testing.T) {
+	a := Attachment{Filename: "a.pdf", Data: make([]byte, 3000)} // 4000 bytes base64 + line breaks
+	b := Attachment{Filename: "b.pdf", Data: make([]byte, 3000)}
+	c := Attachment{Filename: "c.pdf", Data: make([]byte, 300)}
+
+	tests := []struct {
+		name     string
+		limit    int64
+		expected []int // number of attachments per part
+		wantErr  bool
+	}{
+		{"unlimited", 0, []int{3}, false},
+		{"fits in one", 10000, []int{3}, false},
+		{"split in two", 5000, []int{1, 2}, false},
+		{"one each", 4200, []int{1, 1, 1}, false},
+		{"attachment too large", 1000, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := splitAttachments([]Attachment{a, b, c}, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitAttachments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(parts) != len(tt.expected) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(tt.expected))
+			}
+			for i, p := range parts {
+				if len(p) != tt.expected[i] {
+					t.Errorf("part %d has %d attachments, want %d", i, len(p), tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverSplitsSubject(t *testing.T) {
+	var subjects []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req sendgridRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		subjects = append(subjects, req.Subject)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	old := sendgridSendURL
+	sendgridSendURL = srv.URL
+	defer func() { sendgridSendURL = old }()
+
+	cfg := &Config{
+		Email:    EmailConfig{Provider: "sendgrid", From: "me@example.com", To: "boss@example.com", MaxSizeMB: 1},
+		SendGrid: SendGridConfig{APIKey: "key"},
+	}
+	big := make([]byte, 600*1024)
+	err := deliver(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: big}, {Filename: "b.pdf", Data: big}}})
+	if err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if len(subjects) != 2 || subjects[0] != "Betreff (Teil 1/2)" || subjects[1] != "Betreff (Teil 2/2)" {
+		t.Errorf("subjects = %q", subjects)
+	}
+}
+
+func TestComposeMessageHeaders(t *testing.T) {
+	cfg := &Config{Email: EmailConfig{From: "me@example.com", To: "boss@example.com"}}
+	data, err := composeMessage(cfg, Mail{
+		Subject: "Betreff",
+		Headers: map[string]string{"In-Reply-To": "<jan@example.com>", filterHeader: "2026-02"},
+	})
+	if err != nil {
+		t.Fatalf("composeMessage() error = %v", err)
+	}
+	for _, want := range []string{"In-Reply-To: <jan@example.com>", "X-Reisekosten: 2026-02"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("message missing header %q", want)
+		}
+	}
+}
+
+func TestMailHeaders(t *testing.T) {
+	cfg := &Config{Email: EmailConfig{
+		ReplyTo: "office@example.com",
+		Headers: map[string]string{"X-Kostenstelle": "4711", filterHeader: "configured"},
+	}}
+	got := mailHeaders(cfg, Mail{Headers: map[string]string{filterHeader: "2026-02"}})
+
+	if got["Reply-To"] != "office@example.com" || got["X-Kostenstelle"] != "4711" {
+		t.Errorf("mailHeaders() = %v", got)
+	}
+	if got[filterHeader] != "2026-02" {
+		t.Errorf("message header should take precedence, got %q", got[filterHeader])
+	}
+}
+
+func TestReportSubject(t *testing.T) {
+	report := &Report{KmTotal: 450, VerpTotal: 280, Customers: []CustomerReport{
+		{Customer: Customer{ID: "1", Name: "Acme", PurchaseOrder: "4500012345", Contract: "RV-2026"}},
+		{Customer: Customer{ID: "2", Name: "Globex", PurchaseOrder: "4500012345"}},
+		{Customer: Customer{ID: "3", Name: "Initech", PurchaseOrder: "PO-77"}},
+	}}
+	p := monthPeriod(2026, 2)
+
+	cfg := &Config{}
+	if got, err := reportSubject(cfg, p, report); err != nil || got != "Deine Reisekostenabrechnung 02/2026" {
+		t.Errorf("default subject = %q, %v", got, err)
+	}
+
+	cfg.Email.Subject = "Reisekosten {{.Period}} PO {{.PurchaseOrders}} ({{.Contracts}}) {{.Total}} EUR"
+	if got, err := reportSubject(cfg, p, report); err != nil || got != "Reisekosten 02/2026 PO 4500012345, PO-77 (RV-2026) 730,00 EUR" {
+		t.Errorf("subject = %q, %v", got, err)
+	}
+
+	cfg.Email.Subject = "{{range .Customers}}{{.ID}}:{{.PurchaseOrder}}\n{{end}}"
+	if got, err := reportSubject(cfg, p, report); err != nil || got != "1:4500012345 2:4500012345 3:PO-77" {
+		t.Errorf("subject = %q, %v", got, err)
+	}
+
+	cfg.Email.Subject = "{{.Bestellung}}"
+	if _, err := reportSubject(cfg, p, report); err == nil || !strings.Contains(err.Error(), "email.subject") {
+		t.Errorf("reportSubject() error = %v, want template error", err)
+	}
+}
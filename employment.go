@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Employment Period
+// ---------------------------------------------------------------------------
+
+// employed reports whether date lies within employmentStart and
+// employmentEnd. Unset bounds are open.
+func (c *Config) employed(date time.Time) bool {
+	day := date.Format(isoDate)
+	return (c.EmploymentStart == "" || day >= c.EmploymentStart) &&
+		(c.EmploymentEnd == "" || day <= c.EmploymentEnd)
+}
+
+// checkEmployment returns an error if the period lies completely outside the
+// employment. Partially covered periods are truncated by employed.
+func (c *Config) checkEmployment(p Period) error {
+	if c.EmploymentStart != "" && p.End().Format(isoDate) < c.EmploymentStart {
+		return fmt.Errorf("%s is before the employment start on %s", p.Label(), formatISODate(c.EmploymentStart))
+	}
+	if c.EmploymentEnd != "" && p.Start().Format(isoDate) > c.EmploymentEnd {
+		return fmt.Errorf("%s is after the employment end on %s", p.Label(), formatISODate(c.EmploymentEnd))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateReportEmploymentPeriod(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	// Started on Monday, February 16, 2026
+	cfg.EmploymentStart = "2026-02-16"
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 10 || report.Customers[0].Dates[0] != "16.02.2026" {
+		t.Errorf("Workdays = %d, dates = %v", report.Workdays, report.Customers[0].Dates)
+	}
+
+	// Left on Tuesday, February 10, 2026
+	cfg.EmploymentStart, cfg.EmploymentEnd = "", "2026-02-10"
+	report, err = generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 7 || report.Customers[0].Dates[6] != "10.02.2026" {
+		t.Errorf("Workdays = %d, dates = %v", report.Workdays, report.Customers[0].Dates)
+	}
+
+	if _, err := generateReport(cfg, monthPeriod(2026, 3)); err == nil || !strings.Contains(err.Error(), "after the employment end on 10.02.2026") {
+		t.Errorf("generateReport() error = %v, want employment error", err)
+	}
+	cfg.EmploymentStart, cfg.EmploymentEnd = "2026-02-16", ""
+	if _, err := generateReport(cfg, monthPeriod(2026, 1)); err == nil || !strings.Contains(err.Error(), "before the employment start") {
+		t.Errorf("generateReport() error = %v, want employment error", err)
+	}
+}
+
+func TestScheduledRunAfterEmploymentEnd(t *testing.T) {
+	cfg := &Config{EmploymentEnd: "2026-01-31", State: t.TempDir() + "/state.json"}
+	m := &metrics{}
+	scheduledRun(cfg, m, time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC))
+	if m.runs != 0 || !m.isDone(2026, time.February) {
+		t.Errorf("metrics = %+v, want February skipped", m)
+	}
+}
+
+func TestParseConfigEmploymentDates(t *testing.T) {
+	data := `email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+employmentStart: 2026-03-01
+employmentEnd: 01.02.2026
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BY
+`
+	_, err := parseConfig("config.yaml", []byte(data), "")
+	if err == nil {
+		t.Fatal("parseConfig() expected error")
+	}
+	for _, want := range []string{
+		`line 6: employmentEnd: invalid date "01.02.2026" (use YYYY-MM-DD)`,
+		"line 6: employmentEnd: must not be before employmentStart",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() missing %q:\n%v", want, err)
+		}
+	}
+}
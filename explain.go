@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rickar/cal/v2"
+)
+
+// ---------------------------------------------------------------------------
+// Explain Mode (--explain)
+// ---------------------------------------------------------------------------
+
+// dayDecision records whether a day of the period got a trip and why not.
+type dayDecision struct {
+	Date     time.Time
+	Customer string // customer the day was assigned to, empty if none
+	Reason   string // why the day has no trip, empty for a trip
+	Detail   string // holiday name and region, or reason of an absence
+}
+
+// reasonText returns the reason with its detail, e.g. "holiday: Karfreitag (BW)".
+func (d dayDecision) reasonText() string {
+	if d.Detail != "" {
+		return d.Reason + ": " + d.Detail
+	}
+	return d.Reason
+}
+
+// Reasons of days without a trip
+const (
+	reasonWeekend     = "weekend"
+	reasonHoliday     = "holiday"
+	reasonAbsence     = "absence"
+	reasonChristmas   = "Christmas week off (Dec 24, 27-31)"
+	reasonNoCustomer  = "no customer scheduled"
+	reasonExcluded    = "excluded date"
+	reasonSkipped     = "skipped with --skip-days"
+	reasonNotSelected = "not in --only-days"
+	reasonNotEmployed = "outside the employment"
+	reasonOfficeDay   = "office day (officeShare)"
+	reasonCapped      = "dropped to stay within the cap"
+)
+
+// nonWorkdayReason returns why date is no workday in the customer's calendar
+// (weekend, holiday with its name and region as detail, Christmas week), or
+// "" for a workday.
+func nonWorkdayReason(c *cal.BusinessCalendar, province string, date time.Time, christmasWeekOff bool) (reason, detail string) {
+	if _, observed, h := c.IsHoliday(date); observed && h != nil {
+		if _, ok := provinceHolidays[province]; !ok {
+			province = "BW"
+		}
+		return reasonHoliday, fmt.Sprintf("%s (%s)", h.Name, province)
+	}
+	if !c.IsWorkday(date) {
+		return reasonWeekend, ""
+	}
+	if christmasWeekOff && date.Month() == 12 {
+		if day := date.Day(); day == 24 || (day >= 27 && day <= 31) {
+			return reasonChristmas, ""
+		}
+	}
+	return "", ""
+}
+
+// writeExplanation prints every day of the report with its decision,
+// followed by how each amount was calculated.
+func writeExplanation(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Days of %s:\n", report.Period.Label())
+	for _, d := range report.Days {
+		decision := "trip"
+		if d.Reason != "" {
+			decision = "no trip"
+		}
+		detail := d.Customer
+		if d.Reason != "" {
+			detail = d.reasonText()
+		}
+		fmt.Fprintf(tw, "  %s %s\t%s\t%s\n", weekdayAbbrev(d.Date), d.Date.Format("02.01.2006"), decision, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(tw, "\nAmounts:")
+	for _, r := range report.ActualRates {
+		fmt.Fprintf(tw, "  Kilometersatz %s\tactual costs %s\t\n", r.vehicle, r)
+	}
+	for _, c := range report.Customers {
+		if t := c.Customer.Ticket; t.active() {
+			fmt.Fprintf(tw, "  %s Fahrkarte\t%d days x %s EUR (%s)\t= %s EUR\n",
+				c.Customer.Name, len(c.Dates), formatAmount(t.Price), t.Type, formatAmount(c.KmAmount))
+		}
+		for _, g := range c.distanceGroups() {
+			n, perTrip := len(g.dates), roundCents(float64(g.distance)*g.rate)
+			fmt.Fprintf(tw, "  %s Kilometergeld\t%d days x %d km x %s EUR/km = %d x %s EUR\t= %s EUR\n",
+				c.Customer.Name, n, g.distance, formatRate(g.rate), n, formatAmount(perTrip), formatAmount(float64(n)*perTrip))
+		}
+		fmt.Fprintf(tw, "  %s Verpflegungsmehraufwand\t%d days x %s EUR\t= %s EUR\n",
+			c.Customer.Name, c.fullDays(), formatAmount(c.VerpRate), formatAmount(c.VerpAmount))
+		if n := len(c.HalfDays); n > 0 {
+			fmt.Fprintf(tw, "  %s half days\t%d days under 8 hours without meal allowance\t\n", c.Customer.Name, n)
+		}
+		if n := len(c.MealsProvided); n > 0 {
+			fmt.Fprintf(tw, "  %s meals provided\t%d days with provided meals without meal allowance\t\n", c.Customer.Name, n)
+		}
+	}
+	if report.ExpenseTotal > 0 {
+		fmt.Fprintf(tw, "  Reisenebenkosten\texpenses of the override file\t= %s EUR\n", formatAmount(report.ExpenseTotal))
+	}
+	ids := make([]string, 0, len(report.CarryOver))
+	for id := range report.CarryOver {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(tw, "  Customer %s Uebertrag\tover the customer's cap, claimed next month\t= %s EUR\n", id, formatAmount(report.CarryOver[id]))
+	}
+	for _, c := range report.Commutes {
+		fmt.Fprintf(tw, "  %s Entfernungspauschale\t%d days x %d km one-way, not part of the total\t= %s EUR\n",
+			c.Customer.Name, len(c.Dates), c.Distance, formatAmount(c.Amount))
+	}
+	fmt.Fprintf(tw, "  Total\t%d trips\t= %s EUR\n", report.Workdays, formatAmount(report.Total()))
+	return tw.Flush()
+}
+
+// weekdayAbbrevs are the two-letter weekday abbreviations by language,
+// starting with Sunday.
+var weekdayAbbrevs = map[string][7]string{
+	"de": {"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	"en": {"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"},
+}
+
+// weekdayAbbrev returns the German two-letter abbreviation of the weekday.
+func weekdayAbbrev(date time.Time) string {
+	return localWeekday(date, "de")
+}
+
+// localWeekday returns the two-letter abbreviation of the weekday in the
+// given language, German if the language is empty or unknown.
+func localWeekday(date time.Time, lang string) string {
+	names, ok := weekdayAbbrevs[lang]
+	if !ok {
+		names = weekdayAbbrevs["de"]
+	}
+	return names[date.Weekday()]
+}
+
+// formatRate formats a km rate with up to three decimals, e.g. 0,30 or 0,385.
+func formatRate(rate float64) string {
+	return strings.TrimSuffix(localDecimal(fmt.Sprintf("%.3f", rate)), "0")
+}
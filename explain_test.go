@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+		Filter:    RunFilter{SkipDays: []string{"2026-04-09"}},
+		Draft:     true,
+	}
+	os.MkdirAll(cfg.Overrides, 0o700)
+	os.WriteFile(filepath.Join(cfg.Overrides, "2026-04.yaml"), []byte("absences:\n  - from: 2026-04-13\n    to: 2026-04-14\n    reason: Urlaub\n"), 0o600)
+
+	report, err := generateReport(cfg, monthPeriod(2026, time.April))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, d := range report.Days {
+		reasons[d.Date.Format(isoDate)] = d.reasonText()
+	}
+	for day, want := range map[string]string{
+		"2026-04-01": "",
+		"2026-04-03": "holiday: Karfreitag (BW)",
+		"2026-04-04": reasonWeekend,
+		"2026-04-09": reasonSkipped,
+		"2026-04-13": "absence: Urlaub",
+	} {
+		if reasons[day] != want {
+			t.Errorf("reason of %s = %q, want %q", day, reasons[day], want)
+		}
+	}
+	if len(report.Days) != 30 {
+		t.Errorf("len(Days) = %d, want 30", len(report.Days))
+	}
+
+	var out bytes.Buffer
+	if err := writeExplanation(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Fr 03.04.2026", "Karfreitag", "17 days x 100 km x 0,30 EUR/km = 17 x 30,00 EUR", "= 510,00 EUR", "17 trips"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("explanation missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSkippedDaysAppendix(t *testing.T) {
+	days := []dayDecision{
+		{Date: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), Customer: "1"},
+		{Date: time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), Reason: reasonHoliday, Detail: "Karfreitag (BW)"},
+		{Date: time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC), Reason: reasonHoliday, Detail: "Mariä Himmelfahrt (BY)"},
+		{Date: time.Date(2026, 4, 13, 0, 0, 0, 0, time.UTC), Reason: reasonAbsence, Detail: "Urlaub"},
+		{Date: time.Date(2026, 4, 21, 0, 0, 0, 0, time.UTC), Reason: reasonOfficeDay},
+	}
+	got := buildSkippedDaysAppendix("04/2026", days)
+	for _, want := range []string{
+		"ANHANG: TAGE OHNE REISE 04/2026",
+		"Fr 03.04.2026  Feiertag: Karfreitag (BW)",
+		"Feiertag: Mariae Himmelfahrt (BY)",
+		"Mo 13.04.2026  Urlaub\n",
+		"Di 21.04.2026  Homeoffice/Buero",
+		"Feiertag                                   2 Tage",
+		"Urlaub                                     1 Tag\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("appendix missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "02.04.2026") {
+		t.Errorf("appendix lists a day with a trip:\n%s", got)
+	}
+
+	without, err := createPDF("header", nil, "footer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	with, err := createPDF("header", nil, "footer", got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := pdfPageCount(t, with); n != 2 || len(with) <= len(without) {
+		t.Errorf("PDF with appendix has %d pages", n)
+	}
+}
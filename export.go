@@ -0,0 +1,215 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Tax Advisor Export
+// ---------------------------------------------------------------------------
+
+// TaxAdvisorConfig names the recipient of the yearly export bundle.
+type TaxAdvisorConfig struct {
+	Name  string `yaml:"name,omitempty"`  // used in the cover letter
+	Email string `yaml:"email,omitempty"` // mail the bundle to this address
+}
+
+// exportBundle zips the archived documents of a year together with an index
+// CSV and a cover letter into the output directory. If taxAdvisor.email is configured the
+// bundle is mailed as well. It returns the path of the written ZIP.
+func exportBundle(cfg *Config, year int) (string, error) {
+	months, err := loadArchive(cfg, year)
+	if err != nil {
+		return "", err
+	}
+	if len(months) == 0 {
+		return "", fmt.Errorf("no archived reports for %d in %s", year, cfg.ArchiveDir())
+	}
+
+	a := newAnnualSummary(year, months)
+	if len(a.Missing) > 0 {
+		slog.Warn("months missing from the archive", "months", strings.Join(a.Missing, ", "))
+	}
+
+	documents, missing, err := loadArchivedDocuments(cfg, months)
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		slog.Warn("documents missing from the archive, set archiveDocuments to keep them",
+			"documents", strings.Join(missing, ", "))
+	}
+
+	index, err := bundleIndexCSV(months, missing)
+	if err != nil {
+		return "", err
+	}
+	letter, err := createPDF(buildCoverLetterHeader(cfg, year, cfg.now()),
+		buildCoverLetterBlocks(a, len(documents)), buildDocumentFooter(a.Total()))
+	if err != nil {
+		return "", err
+	}
+	files := append([]Attachment{
+		{Filename: "Anschreiben.pdf", Data: letter},
+		{Filename: "index.csv", Data: index},
+	}, documents...)
+
+	// Reuse the report ZIP password if one is configured
+	filename := fmt.Sprintf("%d_Reisekosten_Steuerberater.zip", year)
+	var bundle Attachment
+	if cfg.Zip.Password != "" {
+		bundle, err = bundleZip(cfg.Zip.Password, filename, files)
+	} else {
+		bundle, err = plainZip(filename, files)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	path, err := cfg.Output.path(newOutputFile(year, Period{}, filename, ""))
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.Output.write(path, bundle.Data); err != nil {
+		return "", fmt.Errorf("failed to write export bundle: %w", err)
+	}
+	slog.Info("export bundle written", "year", year, "months", len(months),
+		"documents", len(documents), "total", formatAmount(a.Total()), "path", path)
+
+	if cfg.TaxAdvisor.Email == "" {
+		return path, nil
+	}
+	attachments, err := encryptAttachments(cfg, []string{cfg.TaxAdvisor.Email}, []Attachment{bundle})
+	if err != nil {
+		return path, err
+	}
+	subject := fmt.Sprintf("Reisekostenabrechnungen %d", year)
+	if err := deliver(cfg, Mail{To: cfg.TaxAdvisor.Email, Subject: subject, Attachments: attachments}); err != nil {
+		return path, err
+	}
+	slog.Info("export bundle sent", "to", cfg.TaxAdvisor.Email)
+	return path, nil
+}
+
+// loadArchivedDocuments reads the archived PDFs of the given months. Documents
+// listed in a summary but not found on disk are returned by filename.
+func loadArchivedDocuments(cfg *Config, months []runSummary) ([]Attachment, []string, error) {
+	var documents []Attachment
+	var missing []string
+	for _, m := range months {
+		for _, d := range m.Documents {
+			data, err := os.ReadFile(filepath.Join(cfg.ArchiveDir(), m.Period, d.Filename))
+			if errors.Is(err, os.ErrNotExist) {
+				missing = append(missing, d.Filename)
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read archived document: %w", err)
+			}
+			documents = append(documents, Attachment{Filename: d.Filename, Data: data})
+		}
+	}
+	return documents, missing, nil
+}
+
+// bundleIndexCSV lists every document of the year with its Beleg-Nr. and
+// amount. Documents not included in the bundle are marked as such.
+func bundleIndexCSV(months []runSummary, missing []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	var total float64
+	w.Write([]string{"Monat", "Beleg-Nr.", "Dokument", "Datei", "Betrag", "Enthalten"})
+	for _, m := range months {
+		for _, d := range m.Documents {
+			included := "ja"
+			if contains(missing, d.Filename) {
+				included = "nein"
+			}
+			w.Write([]string{periodLabel(m.Period), d.ID, d.Type, d.Filename, formatAmount(d.Amount), included})
+			total += d.Amount
+		}
+	}
+	w.Write([]string{"Summe", "", "", "", formatAmount(total), ""})
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// buildCoverLetterHeader renders the address block of the cover letter.
+func buildCoverLetterHeader(cfg *Config, year int, now time.Time) string {
+	var b strings.Builder
+
+	recipient := cfg.TaxAdvisor.Name
+	if recipient == "" {
+		recipient = cfg.TaxAdvisor.Email
+	}
+
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("ANSCHREIBEN REISEKOSTEN %d\n", year))
+	b.WriteString(lineDouble + "\n\n")
+	if recipient != "" {
+		b.WriteString(fmt.Sprintf("An:       %s\n", recipient))
+	}
+	b.WriteString(fmt.Sprintf("Von:      %s\n", cfg.Email.From))
+	b.WriteString(fmt.Sprintf("Datum:    %s\n", now.Format(printLocale.date)))
+
+	return b.String()
+}
+
+// buildCoverLetterBlocks renders the letter text and the monthly overview.
+func buildCoverLetterBlocks(a *annualSummary, documents int) []string {
+	var text strings.Builder
+	text.WriteString("Sehr geehrte Damen und Herren,\n\n")
+	text.WriteString(fmt.Sprintf("anbei erhalten Sie meine Reisekostenabrechnungen fuer das Jahr %d\n", a.Year))
+	text.WriteString(fmt.Sprintf("(%d Monate, %d Belege). Die Datei index.csv listet alle Belege\n", len(a.Months), documents))
+	text.WriteString("mit Beleg-Nr. und Betrag.\n")
+	if len(a.Missing) > 0 {
+		text.WriteString(fmt.Sprintf("\nKeine Abrechnung liegt vor fuer: %s\n", strings.Join(a.Missing, ", ")))
+	}
+
+	var overview strings.Builder
+	overview.WriteString(lineSingle + "\n")
+	overview.WriteString(fmt.Sprintf("%-10s%s\n", "Monat", rightAlign("Betrag", 64)))
+	overview.WriteString(lineSingle + "\n")
+	for _, m := range a.Months {
+		overview.WriteString(fmt.Sprintf("%-10s%s\n", periodLabel(m.Period), rightAlign(formatAmount(m.Total)+" EUR", 64)))
+	}
+	overview.WriteString("\n")
+	overview.WriteString(fmt.Sprintf("%-30s%s\n", "Kilometergelderstattung", rightAlign(formatAmount(a.KmTotal)+" EUR", 44)))
+	overview.WriteString(fmt.Sprintf("%-30s%s\n", "Verpflegungsmehraufwand", rightAlign(formatAmount(a.VerpTotal)+" EUR", 44)))
+	if a.Expenses > 0 {
+		overview.WriteString(fmt.Sprintf("%-30s%s\n", "Reisenebenkosten", rightAlign(formatAmount(a.Expenses)+" EUR", 44)))
+	}
+
+	return []string{text.String(), overview.String(), "Mit freundlichen Gruessen\n"}
+}
+
+// plainZip packs the files into an unencrypted ZIP archive.
+func plainZip(filename string, files []Attachment) (Attachment, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Filename, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return Attachment{}, err
+		}
+		if _, err := w.Write(f.Data); err != nil {
+			return Attachment{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return Attachment{}, err
+	}
+	return Attachment{Filename: filename, Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// archiveMonths generates and archives the reports of the given months.
+func archiveMonths(t *testing.T, cfg *Config, year int, months ...time.Month) {
+	t.Helper()
+	for _, month := range months {
+		report, err := generateReport(cfg, monthPeriod(year, month))
+		if err != nil {
+			t.Fatalf("generateReport() error = %v", err)
+		}
+		if err := archiveReport(cfg, newRunSummary(cfg, monthPeriod(year, month), report, nil), report.Attachments); err != nil {
+			t.Fatalf("archiveReport() error = %v", err)
+		}
+	}
+}
+
+func TestExportBundle(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:            EmailConfig{Provider: "eml", From: "max@example.com", To: "hr@example.com"},
+		EML:              EMLConfig{Dir: filepath.Join(dir, "mail")},
+		TaxAdvisor:       TaxAdvisorConfig{Name: "Kanzlei Muster", Email: "kanzlei@example.com"},
+		Archive:          filepath.Join(dir, "archive"),
+		ArchiveDocuments: true,
+		Output:           OutputConfig{Dir: dir},
+		Overrides:        filepath.Join(dir, "overrides"),
+		Customers:        []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+
+	if _, err := exportBundle(cfg, 2025); err == nil {
+		t.Error("exportBundle() expected error without archive")
+	}
+	archiveMonths(t, cfg, 2025, time.January, time.February)
+
+	path, err := exportBundle(cfg, 2025)
+	if err != nil {
+		t.Fatalf("exportBundle() error = %v", err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer zr.Close()
+
+	var names []string
+	var index string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "index.csv" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			index = string(data)
+		}
+	}
+	want := []string{"Anschreiben.pdf", "index.csv",
+		"01_2025_Reisekosten_Kilometergelderstattung.pdf", "01_2025_Reisekosten_Verpflegungsmehraufwand.pdf",
+		"02_2025_Reisekosten_Kilometergelderstattung.pdf", "02_2025_Reisekosten_Verpflegungsmehraufwand.pdf"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("bundle files = %v, want %v", names, want)
+	}
+
+	// January 2025 has 21 workdays in BW, February 20
+	for _, w := range []string{
+		"Monat;Beleg-Nr.;Dokument;Datei;Betrag;Enthalten\n",
+		";Kilometergelderstattung;01_2025_Reisekosten_Kilometergelderstattung.pdf;630,00;ja\n",
+		";Verpflegungsmehraufwand;02_2025_Reisekosten_Verpflegungsmehraufwand.pdf;280,00;ja\n",
+		"Summe;;;;1804,00;\n",
+	} {
+		if !strings.Contains(index, w) {
+			t.Errorf("index missing %q:\n%s", w, index)
+		}
+	}
+
+	eml, err := os.ReadFile(filepath.Join(cfg.EML.Dir, outboxSlug("Reisekostenabrechnungen 2025")+".eml"))
+	if err != nil {
+		t.Fatalf("bundle was not mailed: %v", err)
+	}
+	if !strings.Contains(string(eml), "To: kanzlei@example.com") {
+		t.Error("bundle not addressed to the tax advisor")
+	}
+}
+
+func TestBundleIndexMarksMissingDocuments(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	archiveMonths(t, cfg, 2025, time.March)
+
+	months := mustLoadArchive(t, cfg, 2025)
+	documents, missing, err := loadArchivedDocuments(cfg, months)
+	if err != nil {
+		t.Fatalf("loadArchivedDocuments() error = %v", err)
+	}
+	if len(documents) != 0 || len(missing) != 2 {
+		t.Fatalf("documents = %d, missing = %v", len(documents), missing)
+	}
+	index, err := bundleIndexCSV(months, missing)
+	if err != nil {
+		t.Fatalf("bundleIndexCSV() error = %v", err)
+	}
+	if !strings.Contains(string(index), "03_2025_Reisekosten_Kilometergelderstattung.pdf;") ||
+		strings.Count(string(index), ";nein\n") != 2 {
+		t.Errorf("index = %s", index)
+	}
+}
+
+func TestMailRecipient(t *testing.T) {
+	cfg := &Config{Email: EmailConfig{To: "hr@example.com"}}
+	if got := (Mail{}).recipient(cfg); got != "hr@example.com" {
+		t.Errorf("recipient() = %q", got)
+	}
+	if got := (Mail{To: "kanzlei@example.com"}).recipient(cfg); got != "kanzlei@example.com" {
+		t.Errorf("recipient() = %q", got)
+	}
+}
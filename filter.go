@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Run Filter (command line)
+// ---------------------------------------------------------------------------
+
+// RunFilter restricts a single run to a subset of days and customers, set
+// from the command line.
+type RunFilter struct {
+	SkipDays  []string `json:"skipDays,omitempty" yaml:"skipDays,omitempty"`   // days without trips (YYYY-MM-DD)
+	OnlyDays  []string `json:"onlyDays,omitempty" yaml:"onlyDays,omitempty"`   // if set, trips only on these days (YYYY-MM-DD)
+	Customers []string `json:"customers,omitempty" yaml:"customers,omitempty"` // if set, only these customer IDs get days
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// check validates that all days are valid dates within the report period.
+func (f RunFilter) check(p Period) error {
+	var errs []error
+	for _, list := range []struct {
+		flag string
+		days []string
+	}{{"--skip-days", f.SkipDays}, {"--only-days", f.OnlyDays}} {
+		flag := list.flag
+		for _, s := range list.days {
+			d, err := time.Parse(isoDate, s)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: invalid date %q (use YYYY-MM-DD)", flag, s))
+			case !p.includes(d):
+				errs = append(errs, fmt.Errorf("%s: date %s is not in %s", flag, s, p.Label()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// excluded reports whether the filter removes date from the run.
+func (f RunFilter) excluded(date time.Time) bool {
+	return f.exclusionReason(date) != ""
+}
+
+// exclusionReason returns why the command line excludes date, or "".
+func (f RunFilter) exclusionReason(date time.Time) string {
+	day := date.Format(isoDate)
+	if len(f.OnlyDays) > 0 && !contains(f.OnlyDays, day) {
+		return reasonNotSelected
+	}
+	if contains(f.SkipDays, day) {
+		return reasonSkipped
+	}
+	return ""
+}
+
+// selectCustomers returns the customers chosen with --customers in config
+// order, or all customers if none were chosen.
+func (f RunFilter) selectCustomers(all []Customer) ([]Customer, error) {
+	if len(f.Customers) == 0 {
+		return all, nil
+	}
+	var selected []Customer
+	for _, c := range all {
+		if contains(f.Customers, c.ID) {
+			selected = append(selected, c)
+		}
+	}
+	for _, id := range f.Customers {
+		found := false
+		for _, c := range selected {
+			found = found || c.ID == id
+		}
+		if !found {
+			return nil, fmt.Errorf("--customers: unknown customer id %q", id)
+		}
+	}
+	return selected, nil
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
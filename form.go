@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Employer Expense Form
+// ---------------------------------------------------------------------------
+
+// FormConfig replaces the text documents by the employer's expense claim
+// form, filled in at the positions of a template descriptor.
+type FormConfig struct {
+	Template string `yaml:"template,omitempty"` // template descriptor (YAML), see formTemplate
+}
+
+// Page size of the form and height of a field in mm
+const (
+	formPageWidth   = 210
+	formPageHeight  = 297
+	formFieldHeight = 5
+)
+
+// defaultFormTitle is the document title of a form without title.
+const defaultFormTitle = "Reisekostenabrechnung"
+
+// formTemplate describes the layout of an expense claim form: where each
+// value is printed, in mm from the top left corner of an A4 page.
+type formTemplate struct {
+	Title      string      `yaml:"title,omitempty"`      // document title (default: Reisekostenabrechnung)
+	Background string      `yaml:"background,omitempty"` // scan of the blank form (PNG or JPEG), relative to the descriptor
+	FontSize   float64     `yaml:"fontSize,omitempty"`   // pt (default 10)
+	Fields     []formField `yaml:"fields,omitempty"`     // values printed on every page
+	Rows       formRows    `yaml:"rows,omitempty"`       // one row per trip
+
+	PDF        string            `yaml:"pdf,omitempty"`        // fillable PDF (AcroForm) instead of positioned text, relative to the descriptor
+	AcroFields map[string]string `yaml:"acroFields,omitempty"` // field name -> value; {n} in a name stands for the n-th trip
+}
+
+// formField is a value or a fixed text at a position of the form.
+type formField struct {
+	Value string  `yaml:"value,omitempty"` // name of the value, see formFieldValues and formRowValues
+	Text  string  `yaml:"text,omitempty"`  // fixed text instead of a value, e.g. X for a checkbox
+	X     float64 `yaml:"x"`               // left edge
+	Y     float64 `yaml:"y"`               // top edge; of a column: offset within the row
+	Width float64 `yaml:"width,omitempty"` // width of the box the text is aligned in
+	Align string  `yaml:"align,omitempty"` // left (default), right or center
+}
+
+// formRows is the table of trips, one row per trip in date order.
+type formRows struct {
+	Y       float64     `yaml:"y"`       // top edge of the first row
+	Height  float64     `yaml:"height"`  // distance between two rows
+	PerPage int         `yaml:"perPage"` // rows per page; more trips continue on another page
+	Columns []formField `yaml:"columns"` // values of a trip
+}
+
+// formFieldValues are the values of the fields printed on every page.
+var formFieldValues = map[string]string{
+	"docId":        "Beleg-Nr.",
+	"period":       "period label, e.g. 02/2026",
+	"date":         "document date (last trip)",
+	"periodStart":  "date of the first trip",
+	"periodEnd":    "date of the last trip",
+	"name":         "signature.name",
+	"place":        "signature.place",
+	"workdays":     "number of trips",
+	"km":           "total km",
+	"kmTotal":      "Kilometergeld",
+	"verpTotal":    "meal allowance",
+	"expenseTotal": "additional expenses",
+	"total":        "sum of all reimbursements",
+	"page":         "page number",
+	"pages":        "number of pages",
+}
+
+// formRowValues are the values of the columns of a trip.
+var formRowValues = map[string]string{
+	"date":     "date of the trip",
+	"weekday":  "weekday (language)",
+	"customer": "customer name",
+	"from":     "start address",
+	"to":       "destination",
+	"reason":   "reason of the visit",
+	"km":       "km driven",
+	"kmRate":   "km rate",
+	"kmAmount": "Kilometergeld of the trip",
+	"perDiem":  "meal allowance of the day",
+	"amount":   "Kilometergeld and meal allowance",
+}
+
+// loadFormTemplate reads and checks a template descriptor. A relative
+// background is resolved against the descriptor's directory.
+func loadFormTemplate(path string) (*formTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form template: %w", err)
+	}
+	var tpl formTemplate
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&tpl); err != nil {
+		return nil, fmt.Errorf("failed to parse form template %s: %w", path, err)
+	}
+	if err := tpl.check(); err != nil {
+		return nil, fmt.Errorf("invalid form template %s: %w", path, err)
+	}
+	for _, file := range []*string{&tpl.Background, &tpl.PDF} {
+		if *file != "" && !filepath.IsAbs(*file) {
+			*file = filepath.Join(filepath.Dir(path), *file)
+		}
+	}
+	return &tpl, nil
+}
+
+// check reports every problem of the descriptor.
+func (tpl *formTemplate) check() error {
+	var errs []error
+	checkField := func(path string, f formField, values map[string]string, column bool) {
+		switch {
+		case f.Value == "" && f.Text == "":
+			errs = append(errs, fmt.Errorf("%s: value or text required", path))
+		case f.Value != "" && f.Text != "":
+			errs = append(errs, fmt.Errorf("%s: value and text are exclusive", path))
+		case f.Value != "":
+			if _, ok := values[f.Value]; !ok {
+				errs = append(errs, fmt.Errorf("%s.value: unknown value %q (use %s)", path, f.Value, registryNames(values)))
+			}
+		}
+		if f.X < 0 || f.X > formPageWidth || f.Y < 0 || (!column && f.Y > formPageHeight) {
+			errs = append(errs, fmt.Errorf("%s: position %v,%v is outside the page", path, f.X, f.Y))
+		}
+		switch f.Align {
+		case "", "left":
+		case "right", "center":
+			if f.Width <= 0 {
+				errs = append(errs, fmt.Errorf("%s.width: required for align %s", path, f.Align))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s.align: invalid value %q (use left, right or center)", path, f.Align))
+		}
+	}
+	for i, f := range tpl.Fields {
+		checkField(fmt.Sprintf("fields[%d]", i), f, formFieldValues, false)
+	}
+	if r := tpl.Rows; len(r.Columns) > 0 {
+		if r.Height <= 0 || r.PerPage <= 0 {
+			errs = append(errs, errors.New("rows: height and perPage are required"))
+		} else if bottom := r.Y + float64(r.PerPage)*r.Height; bottom > formPageHeight {
+			errs = append(errs, fmt.Errorf("rows: %d rows of %v mm from %v end below the page", r.PerPage, r.Height, r.Y))
+		}
+		for i, c := range r.Columns {
+			checkField(fmt.Sprintf("rows.columns[%d]", i), c, formRowValues, true)
+		}
+	}
+	switch strings.ToLower(filepath.Ext(tpl.Background)) {
+	case "", ".png", ".jpg", ".jpeg":
+	default:
+		errs = append(errs, errors.New("background: must be a PNG or JPEG file"))
+	}
+	if tpl.PDF != "" {
+		if !strings.EqualFold(filepath.Ext(tpl.PDF), ".pdf") {
+			errs = append(errs, errors.New("pdf: must be a PDF file"))
+		}
+		if len(tpl.Fields) > 0 || len(tpl.Rows.Columns) > 0 || tpl.Background != "" {
+			errs = append(errs, errors.New("pdf: fields, rows and background are not used with a fillable PDF"))
+		}
+		if len(tpl.AcroFields) == 0 {
+			errs = append(errs, errors.New("acroFields: required with pdf"))
+		}
+	} else if len(tpl.AcroFields) > 0 {
+		errs = append(errs, errors.New("acroFields: requires pdf"))
+	}
+	names := make([]string, 0, len(tpl.AcroFields))
+	for name := range tpl.AcroFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := tpl.AcroFields[name]
+		values := formFieldValues
+		if strings.Contains(name, "{n}") {
+			values = formRowValues
+		}
+		if _, ok := values[value]; !ok {
+			errs = append(errs, fmt.Errorf("acroFields.%s: unknown value %q (use %s)", name, value, registryNames(values)))
+		}
+	}
+	if tpl.FontSize < 0 {
+		errs = append(errs, errors.New("fontSize: must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// formRowsOf returns the values of the trips of a report in date order.
+func formRowsOf(cfg *Config, report *Report) []map[string]string {
+	var rows []map[string]string
+	for _, c := range report.Customers {
+		for n, date := range c.Dates {
+			perDiem := c.VerpRate
+			if contains(c.HalfDays, date) || contains(c.MealsProvided, date) {
+				perDiem = 0
+			}
+			weekday := ""
+			if d, err := time.Parse("02.01.2006", date); err == nil {
+				weekday = localWeekday(d, cfg.Language)
+			}
+			rows = append(rows, map[string]string{
+				"date":     localDate(date),
+				"weekday":  weekday,
+				"customer": c.Customer.Name,
+				"from":     c.Customer.From,
+				"to":       c.Customer.To,
+				"reason":   c.Customer.visitReason(n),
+				"km":       strconv.Itoa(c.distance(n)),
+				"kmRate":   formatAmount(c.rate(n)),
+				"kmAmount": formatAmount(c.tripAmount(n)),
+				"perDiem":  formatAmount(perDiem),
+				"amount":   formatAmount(c.tripAmount(n) + perDiem),
+				"order":    dayOrder(date),
+			})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i]["order"] < rows[j]["order"] })
+	return rows
+}
+
+// formFields returns the values printed on every page of the form, without
+// the page numbers.
+func formFields(cfg *Config, p Period, report *Report, rows []map[string]string) map[string]string {
+	km := 0
+	for _, c := range report.Customers {
+		km += c.km()
+	}
+	values := map[string]string{
+		"docId":        report.KmDocID,
+		"period":       p.Label(),
+		"name":         cfg.Signature.Name,
+		"place":        cfg.Signature.Place,
+		"workdays":     strconv.Itoa(report.Workdays),
+		"km":           strconv.Itoa(km),
+		"kmTotal":      formatAmount(report.KmTotal),
+		"verpTotal":    formatAmount(report.VerpTotal),
+		"expenseTotal": formatAmount(report.ExpenseTotal),
+		"total":        formatAmount(report.Total()),
+	}
+	if len(rows) > 0 {
+		values["periodStart"] = rows[0]["date"]
+		values["periodEnd"] = rows[len(rows)-1]["date"]
+		values["date"] = values["periodEnd"]
+	}
+	return values
+}
+
+// text returns the text of a field with the given values.
+func (f formField) text(values map[string]string) string {
+	if f.Text != "" {
+		return umlautReplacer.Replace(f.Text)
+	}
+	return umlautReplacer.Replace(values[f.Value])
+}
+
+// createForm fills the employer's expense claim form with the trips and
+// totals of a report. Trips beyond the rows of a page continue on further
+// pages with the same layout and fields.
+func createForm(cfg *Config, p Period, report *Report) (Attachment, error) {
+	tpl, err := loadFormTemplate(cfg.Form.Template)
+	if err != nil {
+		return Attachment{}, err
+	}
+	rows := formRowsOf(cfg, report)
+	values := formFields(cfg, p, report, rows)
+	if tpl.PDF != "" {
+		return createAcroForm(cfg, p, tpl, values, rows)
+	}
+	perPage := tpl.Rows.PerPage
+	if len(tpl.Rows.Columns) == 0 || perPage <= 0 {
+		perPage = len(rows)
+	}
+	pages := 1
+	if perPage > 0 && len(rows) > perPage {
+		pages = (len(rows) + perPage - 1) / perPage
+	}
+
+	pdf := newTaggedPDF()
+	fontSize := tpl.FontSize
+	if fontSize == 0 {
+		fontSize = 10
+	}
+	pdf.SetFont("Helvetica", "", fontSize)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.title = defaultFormTitle
+	if tpl.Title != "" {
+		pdf.title = umlautReplacer.Replace(tpl.Title)
+	}
+	values["pages"] = strconv.Itoa(pages)
+	for page := 1; page <= pages; page++ {
+		if page > 1 {
+			pdf.AddPage()
+		}
+		if tpl.Background != "" {
+			pdf.background(tpl.Background)
+		}
+		values["page"] = strconv.Itoa(page)
+		for _, f := range tpl.Fields {
+			pdf.field(f, f.text(values))
+		}
+		if len(tpl.Rows.Columns) == 0 {
+			continue
+		}
+		for i, row := range rows[(page-1)*perPage : min(page*perPage, len(rows))] {
+			for _, c := range tpl.Rows.Columns {
+				c.Y += tpl.Rows.Y + float64(i)*tpl.Rows.Height
+				pdf.field(c, c.text(row))
+			}
+		}
+	}
+	data, err := pdf.output()
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to create form: %w", err)
+	}
+	slog.Info("form generated", "template", cfg.Form.Template, "trips", len(rows), "pages", pages)
+	return Attachment{Filename: p.filePrefix() + "_Reisekosten_Formular.pdf", Data: data}, nil
+}
+
+// background draws the scan of the blank form over the whole page, marked
+// as an artifact.
+func (t *taggedPDF) background(file string) {
+	w, h := t.GetPageSize()
+	t.RawWriteStr("/Artifact BMC")
+	t.ImageOptions(file, 0, 0, w, h, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+	t.RawWriteStr("EMC")
+}
+
+// field writes the text of a form field at its position as a paragraph of
+// its own. Empty fields are left out.
+func (t *taggedPDF) field(f formField, text string) {
+	if text == "" {
+		return
+	}
+	align := map[string]string{"right": "R", "center": "C"}[f.Align]
+	if align == "" {
+		align = "L"
+	}
+	m := t.mark()
+	t.RawWriteStr(fmt.Sprintf("/P <</MCID %d>> BDC", m.mcid))
+	t.SetXY(f.X, f.Y)
+	t.CellFormat(f.Width, formFieldHeight, text, "", 0, align, false, 0, "")
+	t.RawWriteStr("EMC")
+	t.elems = append(t.elems, structElem{tag: "P", lines: []markedContent{m}})
+}
+
+// createAcroForm fills the fields of the employer's fillable PDF. A field
+// name with {n} is filled for every trip, so the form needs such a field for
+// each trip of the period.
+func createAcroForm(cfg *Config, p Period, tpl *formTemplate, values map[string]string, rows []map[string]string) (Attachment, error) {
+	data, err := os.ReadFile(tpl.PDF)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read form: %w", err)
+	}
+	values["page"], values["pages"] = "1", "1"
+	fill := map[string]string{}
+	for name, value := range tpl.AcroFields {
+		if !strings.Contains(name, "{n}") {
+			fill[name] = values[value]
+			continue
+		}
+		for i, row := range rows {
+			fill[strings.ReplaceAll(name, "{n}", strconv.Itoa(i+1))] = row[value]
+		}
+	}
+	if data, err = fillAcroForm(data, fill); err != nil {
+		return Attachment{}, fmt.Errorf("failed to fill %s: %w", tpl.PDF, err)
+	}
+	slog.Info("form filled", "template", cfg.Form.Template, "trips", len(rows), "fields", len(fill))
+	return Attachment{Filename: p.filePrefix() + "_Reisekosten_Formular.pdf", Data: data}, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFormTemplate = `title: Reisekostenabrechnung Mitarbeiter
+background: formular.png
+fields:
+  - {value: name, x: 30, y: 20}
+  - {value: period, x: 150, y: 20, width: 40, align: right}
+  - {text: X, x: 20, y: 40}
+  - {value: total, x: 150, y: 270, width: 40, align: right}
+  - {value: page, x: 180, y: 285}
+rows:
+  y: 60
+  height: 6
+  perPage: 8
+  columns:
+    - {value: date, x: 20}
+    - {value: customer, x: 45}
+    - {value: km, x: 120, width: 15, align: right}
+    - {value: amount, x: 150, width: 40, align: right}
+`
+
+// writeFormTemplate writes a template descriptor with a blank background.
+func writeFormTemplate(t *testing.T, data string) string {
+	t.Helper()
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 210, 297)))
+	os.WriteFile(filepath.Join(dir, "formular.png"), buf.Bytes(), 0644)
+	path := filepath.Join(dir, "formular.yaml")
+	os.WriteFile(path, []byte(data), 0644)
+	return path
+}
+
+func TestCreateForm(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Form:      FormConfig{Template: writeFormTemplate(t, testFormTemplate)},
+		Signature: SignatureConfig{Name: "Max Mustermann"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if len(report.Attachments) != 1 || report.Attachments[0].Filename != "02_2026_Reisekosten_Formular.pdf" {
+		t.Fatalf("%d attachments, want only the form", len(report.Attachments))
+	}
+	data := report.Attachments[0].Data
+
+	// 20 trips in rows of 8 continue on three pages with the same fields
+	if n := pdfPageCount(t, data); n != 3 {
+		t.Errorf("pages = %d, want 3", n)
+	}
+	text := pdfText(t, data)
+	for want, count := range map[string]int{
+		"(Max Mustermann)Tj": 3,
+		"(X)Tj":              3,
+		"(Acme)Tj":           20,
+		"(02.02.2026)Tj":     1,
+		"(27.02.2026)Tj":     1,
+		"(3)Tj":              1,
+	} {
+		if n := strings.Count(text, want); n != count {
+			t.Errorf("%s appears %d times, want %d", want, n, count)
+		}
+	}
+	total := "(" + formatAmount(report.Total()) + ")Tj"
+	if !strings.Contains(text, total) {
+		t.Errorf("form has no total %s", total)
+	}
+	if strings.Count(text, "/Artifact BMC") != 3 {
+		t.Errorf("background is not an artifact on every page")
+	}
+	for _, want := range []string{"/Subtype /Image", "Reisekostenabrechnung Mitarbeiter"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("form missing %q", want)
+		}
+	}
+}
+
+func TestLoadFormTemplateInvalid(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{"fields:\n  - {value: iban, x: 10, y: 10}\n", `unknown value "iban"`},
+		{"fields:\n  - {value: total, x: 10, y: 10, align: right}\n", "fields[0].width"},
+		{"fields:\n  - {x: 10, y: 10}\n", "value or text required"},
+		{"fields:\n  - {text: X, x: 10, y: 400}\n", "outside the page"},
+		{"rows:\n  columns:\n    - {value: km, x: 10}\n", "height and perPage"},
+		{"rows:\n  y: 200\n  height: 10\n  perPage: 20\n  columns:\n    - {value: total, x: 10}\n", "rows.columns[0].value"},
+		{"background: formular.pdf\n", "PNG or JPEG"},
+		{"font: Arial\n", "field font not found"},
+	}
+	for _, tt := range tests {
+		_, err := loadFormTemplate(writeFormTemplate(t, tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("loadFormTemplate(%q) error = %v, want %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateFormSummary(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Form:      FormConfig{Template: writeFormTemplate(t, testFormTemplate)},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Timesheet: true}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// The form and the hours sheet keep their own types, not the ones of
+	// the text documents they replace
+	docs := newRunSummary(cfg, p, report, nil).Documents
+	if len(docs) != 2 || docs[0].Type != "Reisekostenformular" || docs[0].Amount != 880 || docs[0].ID != report.KmDocID ||
+		docs[1].Type != "Stundennachweis" || docs[1].Amount != 0 {
+		t.Errorf("documents = %+v", docs)
+	}
+	vouchers := reportVouchers(cfg, p, report)
+	if len(vouchers) != 1 || vouchers[0].Title != "Reisekostenformular" || vouchers[0].Amount != 880 {
+		t.Errorf("vouchers = %+v", vouchers)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+)
+
+// ---------------------------------------------------------------------------
+// GDPdU Export (Datenueberlassung)
+// ---------------------------------------------------------------------------
+
+// gdpduDTD is the document type of the index.xml, as published by the BMF
+// with the "Beschreibungsstandard fuer die Datenueberlassung".
+const gdpduDTD = "gdpdu-01-09-2004.dtd"
+
+// gdpduColumn describes a column of an exported table.
+type gdpduColumn struct {
+	name, description string
+	kind              string // AlphaNumeric, Numeric or Date
+	accuracy          int    // decimal places of numeric columns
+	key               bool   // part of the primary key
+}
+
+// gdpduTable is a CSV file of the export with its column descriptions.
+type gdpduTable struct {
+	file, name, description string
+	columns                 []gdpduColumn
+	rows                    [][]string
+}
+
+// gdpduTrips lists every trip of the archived months with its amounts.
+func gdpduTrips(months []runSummary) gdpduTable {
+	t := gdpduTable{
+		file:        "Fahrten.csv",
+		name:        "Fahrten",
+		description: "Dienstreisen mit Kilometergeld und Verpflegungsmehraufwand",
+		columns: []gdpduColumn{
+			{name: "Datum", kind: "Date", key: true},
+			{name: "Kunden-ID", kind: "AlphaNumeric", key: true},
+			{name: "Monat", kind: "AlphaNumeric"},
+			{name: "Beleg-Nr. Kilometergeld", kind: "AlphaNumeric"},
+			{name: "Beleg-Nr. Verpflegung", kind: "AlphaNumeric"},
+			{name: "Kunde", kind: "AlphaNumeric"},
+			{name: "Projekt", kind: "AlphaNumeric"},
+			{name: "Kostenstelle", kind: "AlphaNumeric"},
+			{name: "Entfernung", description: "gefahrene km je Reisetag (Hin- und Rueckfahrt)", kind: "Numeric"},
+			{name: "Kilometersatz", description: "EUR je km", kind: "Numeric", accuracy: 3},
+			{name: "Kilometergeld", description: "EUR", kind: "Numeric", accuracy: 2},
+			{name: "Verpflegungsmehraufwand", description: "EUR", kind: "Numeric", accuracy: 2},
+		},
+	}
+	for _, m := range months {
+		kmDocID, verpDocID := m.documentID("Kilometergelderstattung"), m.documentID("Verpflegungsmehraufwand")
+		if form := m.documentID("Reisekostenformular"); form != "" {
+			kmDocID, verpDocID = form, form
+		}
+		for _, c := range m.Customers {
+			for n, d := range c.Dates {
+				distance, rate, verp := c.distance(n), c.rate(n), c.VerpflegungRate
+				if c.Ticket != "" {
+					rate = 0
+				}
+				if contains(c.HalfDays, d) || contains(c.MealsProvided, d) {
+					verp = 0
+				}
+				t.rows = append(t.rows, []string{
+					d, c.ID, periodLabel(m.Period), kmDocID, verpDocID, c.Name, c.Project, c.CostCenter,
+					fmt.Sprint(distance), localDecimal(fmt.Sprintf("%.3f", rate)),
+					formatAmount(c.tripAmount(n)), formatAmount(verp),
+				})
+			}
+		}
+	}
+	return t
+}
+
+// gdpduDocuments lists every archived document with its amount and checksum.
+func gdpduDocuments(months []runSummary) gdpduTable {
+	t := gdpduTable{
+		file:        "Belege.csv",
+		name:        "Belege",
+		description: "Reisekostenbelege",
+		columns: []gdpduColumn{
+			{name: "Beleg-Nr.", kind: "AlphaNumeric", key: true},
+			{name: "Monat", kind: "AlphaNumeric"},
+			{name: "Dokument", kind: "AlphaNumeric"},
+			{name: "Datei", kind: "AlphaNumeric"},
+			{name: "Betrag", description: "EUR", kind: "Numeric", accuracy: 2},
+			{name: "SHA256", description: "Pruefsumme der Datei", kind: "AlphaNumeric"},
+		},
+	}
+	for _, m := range months {
+		for _, d := range m.Documents {
+			t.rows = append(t.rows, []string{d.ID, periodLabel(m.Period), d.Type, d.Filename, formatAmount(d.Amount), d.SHA256})
+		}
+	}
+	return t
+}
+
+// csv renders the table with a header row, separated by semicolons. Records
+// end with CR LF, the default of the description standard.
+func (t gdpduTable) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	w.UseCRLF = true
+
+	header := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = c.name
+	}
+	w.Write(header)
+	for _, row := range t.rows {
+		w.Write(row)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// gdpduIndex builds the index.xml describing the exported tables.
+func gdpduIndex(cfg *Config, year int, tables []gdpduTable) ([]byte, error) {
+	supplier := cfg.XRechnung.Name
+	if supplier == "" {
+		supplier = cfg.Email.From
+	}
+	index := gdpduDataSet{
+		Version: "1.0",
+		Supplier: gdpduSupplier{
+			Name:     supplier,
+			Location: cfg.XRechnung.Address.City,
+			Comment:  fmt.Sprintf("Reisekosten %d", year),
+		},
+		Media: gdpduMedia{Name: fmt.Sprintf("Reisekosten %d", year)},
+	}
+	for _, t := range tables {
+		table := gdpduXMLTable{
+			URL:         t.file,
+			Name:        t.name,
+			Description: t.description,
+			Validity: gdpduValidity{
+				From:   fmt.Sprintf("01.01.%d", year),
+				To:     fmt.Sprintf("31.12.%d", year),
+				Format: "DD.MM.YYYY",
+			},
+			UTF8:           &struct{}{},
+			DecimalSymbol:  printLocale.decimal,
+			GroupingSymbol: printLocale.grouping,
+			Range:          gdpduRange{From: 2}, // skip the header row
+			Layout: gdpduVariableLength{
+				ColumnDelimiter:  ";",
+				TextEncapsulator: `"`,
+			},
+		}
+		for _, c := range t.columns {
+			col := gdpduXMLColumn{Name: c.name, Description: c.description}
+			switch c.kind {
+			case "Numeric":
+				col.Numeric = &gdpduNumeric{Accuracy: c.accuracy}
+			case "Date":
+				col.Date = &gdpduDate{Format: "DD.MM.YYYY"}
+			default:
+				col.AlphaNumeric = &struct{}{}
+			}
+			if c.key {
+				table.Layout.PrimaryKeys = append(table.Layout.PrimaryKeys, col)
+			} else {
+				table.Layout.Columns = append(table.Layout.Columns, col)
+			}
+		}
+		index.Media.Tables = append(index.Media.Tables, table)
+	}
+
+	data, err := xml.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	head := xml.Header + fmt.Sprintf("<!DOCTYPE DataSet SYSTEM %q>\n", gdpduDTD)
+	return append([]byte(head), data...), nil
+}
+
+// exportGDPdU writes the trips and documents of the archived months of a
+// year as CSV files with an index.xml (GDPdU/IDEA description standard) into
+// a ZIP in the output directory. It returns the path of the written ZIP.
+func exportGDPdU(cfg *Config, year int) (string, error) {
+	months, err := loadArchive(cfg, year)
+	if err != nil {
+		return "", err
+	}
+	if len(months) == 0 {
+		return "", fmt.Errorf("no archived reports for %d in %s", year, cfg.ArchiveDir())
+	}
+
+	tables := []gdpduTable{gdpduDocuments(months), gdpduTrips(months)}
+	index, err := gdpduIndex(cfg, year, tables)
+	if err != nil {
+		return "", err
+	}
+	files := []Attachment{{Filename: "index.xml", Data: index}}
+	for _, t := range tables {
+		data, err := t.csv()
+		if err != nil {
+			return "", err
+		}
+		files = append(files, Attachment{Filename: t.file, Data: data})
+	}
+
+	filename := fmt.Sprintf("%d_Reisekosten_GDPdU.zip", year)
+	bundle, err := plainZip(filename, files)
+	if err != nil {
+		return "", err
+	}
+	path, err := cfg.Output.path(newOutputFile(year, Period{}, filename, ""))
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.Output.write(path, bundle.Data); err != nil {
+		return "", fmt.Errorf("failed to write GDPdU export: %w", err)
+	}
+	slog.Info("GDPdU export written", "year", year, "months", len(months),
+		"trips", len(tables[1].rows), "documents", len(tables[0].rows), "path", path)
+	return path, nil
+}
+
+// index.xml elements, in the order required by the DTD.
+type gdpduDataSet struct {
+	XMLName  xml.Name      `xml:"DataSet"`
+	Version  string        `xml:"Version"`
+	Supplier gdpduSupplier `xml:"DataSupplier"`
+	Media    gdpduMedia    `xml:"Media"`
+}
+
+type gdpduSupplier struct {
+	Name     string `xml:"Name"`
+	Location string `xml:"Location"`
+	Comment  string `xml:"Comment"`
+}
+
+type gdpduMedia struct {
+	Name   string          `xml:"Name"`
+	Tables []gdpduXMLTable `xml:"Table"`
+}
+
+type gdpduXMLTable struct {
+	URL            string              `xml:"URL"`
+	Name           string              `xml:"Name"`
+	Description    string              `xml:"Description"`
+	Validity       gdpduValidity       `xml:"Validity"`
+	UTF8           *struct{}           `xml:"UTF8"`
+	DecimalSymbol  string              `xml:"DecimalSymbol"`
+	GroupingSymbol string              `xml:"DigitGroupingSymbol"`
+	Range          gdpduRange          `xml:"Range"`
+	Layout         gdpduVariableLength `xml:"VariableLength"`
+}
+
+type gdpduValidity struct {
+	From   string `xml:"Range>From"`
+	To     string `xml:"Range>To"`
+	Format string `xml:"Format"`
+}
+
+type gdpduRange struct {
+	From int `xml:"From"`
+}
+
+type gdpduVariableLength struct {
+	ColumnDelimiter  string           `xml:"ColumnDelimiter"`
+	TextEncapsulator string           `xml:"TextEncapsulator"`
+	PrimaryKeys      []gdpduXMLColumn `xml:"VariablePrimaryKey"`
+	Columns          []gdpduXMLColumn `xml:"VariableColumn"`
+}
+
+type gdpduXMLColumn struct {
+	Name         string        `xml:"Name"`
+	Description  string        `xml:"Description,omitempty"`
+	Numeric      *gdpduNumeric `xml:"Numeric"`
+	AlphaNumeric *struct{}     `xml:"AlphaNumeric"`
+	Date         *gdpduDate    `xml:"Date"`
+}
+
+type gdpduNumeric struct {
+	Accuracy int `xml:"Accuracy,omitempty"`
+}
+
+type gdpduDate struct {
+	Format string `xml:"Format"`
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportGDPdU(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{From: "max@example.com"},
+		Archive:   filepath.Join(dir, "archive"),
+		Output:    OutputConfig{Dir: dir},
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Project: "P-4711"}},
+	}
+	if _, err := exportGDPdU(cfg, 2025); err == nil {
+		t.Error("exportGDPdU() expected error without archive")
+	}
+	archiveMonths(t, cfg, 2025, time.January, time.February)
+
+	path, err := exportGDPdU(cfg, 2025)
+	if err != nil {
+		t.Fatalf("exportGDPdU() error = %v", err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]string)
+	var names []string
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "index.xml,Belege.csv,Fahrten.csv" {
+		t.Fatalf("files = %s", got)
+	}
+
+	// January 2025 has 21 workdays in BW, February 20
+	trips := strings.Split(strings.TrimSuffix(files["Fahrten.csv"], "\r\n"), "\r\n")
+	if len(trips) != 1+41 || !strings.HasPrefix(trips[0], "Datum;Kunden-ID;Monat;") {
+		t.Fatalf("Fahrten.csv has %d lines:\n%s", len(trips), files["Fahrten.csv"])
+	}
+	if !strings.HasPrefix(trips[1], "02.01.2025;1;01/2025;RK-2025-01-") || !strings.HasSuffix(trips[1], ";Acme;P-4711;;100;0,300;30,00;14,00") {
+		t.Errorf("first trip = %q", trips[1])
+	}
+	if !strings.Contains(files["Belege.csv"], ";02/2025;Verpflegungsmehraufwand;02_2025_Reisekosten_Verpflegungsmehraufwand.pdf;280,00;") {
+		t.Errorf("Belege.csv =\n%s", files["Belege.csv"])
+	}
+
+	var index struct {
+		Supplier string `xml:"DataSupplier>Name"`
+		Tables   []struct {
+			URL     string   `xml:"URL"`
+			Keys    []string `xml:"VariableLength>VariablePrimaryKey>Name"`
+			Columns []string `xml:"VariableLength>VariableColumn>Name"`
+		} `xml:"Media>Table"`
+	}
+	if err := xml.Unmarshal([]byte(files["index.xml"]), &index); err != nil {
+		t.Fatalf("invalid index.xml: %v", err)
+	}
+	if index.Supplier != "max@example.com" || len(index.Tables) != 2 || index.Tables[1].URL != "Fahrten.csv" ||
+		strings.Join(index.Tables[1].Keys, ",") != "Datum,Kunden-ID" || len(index.Tables[1].Columns) != 10 {
+		t.Errorf("index = %+v", index)
+	}
+	if !strings.Contains(files["index.xml"], `<!DOCTYPE DataSet SYSTEM "gdpdu-01-09-2004.dtd">`) {
+		t.Error("index.xml without DOCTYPE")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Gmail API Delivery
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("gmail", DelivererFunc(sendGmail))
+}
+
+// Gmail endpoints (variables to allow overriding in tests).
+var (
+	gmailTokenURL = "https://oauth2.googleapis.com/token"
+	gmailSendURL  = "https://gmail.googleapis.com/gmail/v1/users/me/messages/send"
+)
+
+// sendGmail sends the generated PDFs via the Gmail API. An access token is
+// obtained from the configured OAuth refresh token on every run.
+func sendGmail(cfg *Config, m Mail) error {
+	g := cfg.Gmail
+	if g.ClientID == "" || g.ClientSecret == "" || g.RefreshToken == "" {
+		return permanent(fmt.Errorf("gmail provider requires clientId, clientSecret and refreshToken"))
+	}
+
+	token, err := fetchAccessToken(gmailTokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {g.ClientID},
+		"client_secret": {g.ClientSecret},
+		"refresh_token": {g.RefreshToken},
+	})
+	if err != nil {
+		return fmt.Errorf("gmail: %w", err)
+	}
+
+	// Gmail expects the complete RFC 5322 message, base64url encoded
+	raw, err := composeMessage(cfg, m)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]string{
+		"raw": base64.URLEncoding.EncodeToString(raw),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, gmailSendURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gmail: send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return httpStatusError(resp.StatusCode, fmt.Errorf("gmail: send failed (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(body))))
+	}
+	return nil
+}
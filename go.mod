@@ -3,13 +3,23 @@ module reisekosten
 go 1.21.3
 
 require (
+	filippo.io/age v1.1.1
+	github.com/ProtonMail/go-crypto v1.1.6
 	github.com/go-gomail/gomail v0.0.0-20160411212932-81ebce5c23df
 	github.com/go-pdf/fpdf v0.9.0
 	github.com/rickar/cal/v2 v2.1.18
+	golang.org/x/crypto v0.23.0
+	golang.org/x/sys v0.20.0
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.2
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/cloudflare/circl v1.3.7 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
 	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df // indirect
 )
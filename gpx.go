@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// GPS Tracks (GPX)
+// ---------------------------------------------------------------------------
+
+// defaultGPXRadius is the distance in meters within which a track point
+// counts as reaching a customer.
+const defaultGPXRadius = 500
+
+// GPXConfig enables the import of recorded tracks, e.g. from a dashcam or a
+// phone app. A track that reaches a customer's location assigns its day to
+// that customer and replaces the configured distance by the driven one.
+type GPXConfig struct {
+	Dir    string `yaml:"dir,omitempty"`    // directory of the .gpx files
+	Radius int    `yaml:"radius,omitempty"` // meters around the customer's location (default 500)
+}
+
+// radius returns the matching radius in meters.
+func (g GPXConfig) radius() float64 {
+	if g.Radius > 0 {
+		return float64(g.Radius)
+	}
+	return defaultGPXRadius
+}
+
+// gpxFile is the part of a GPX document needed to measure the tracks.
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64   `xml:"lat,attr"`
+	Lon  float64   `xml:"lon,attr"`
+	Time time.Time `xml:"time"`
+}
+
+// track is a recorded drive with its date and length.
+type track struct {
+	file   string
+	date   string // YYYY-MM-DD of the first point in local time
+	km     float64
+	points []gpxPoint
+}
+
+// trackLog holds the tracks by date.
+type trackLog map[string][]track
+
+// parseLocation parses a location given as "lat,lon".
+func parseLocation(s string) (lat, lon float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid location %q (use lat,lon)", s)
+	}
+	lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err == nil {
+		lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	}
+	if err != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("invalid location %q (use lat,lon)", s)
+	}
+	return lat, lon, nil
+}
+
+// haversine returns the great-circle distance between two points in meters.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000
+	rad := math.Pi / 180
+	dLat, dLon := (lat2-lat1)*rad, (lon2-lon1)*rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// loadTracks reads the .gpx files of dir whose first point lies within the
+// period, dated in the timezone loc. Files without timestamps are skipped.
+func loadTracks(dir string, p Period, loc *time.Location) (trackLog, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.gpx"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	byDate := make(trackLog)
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read track: %w", err)
+		}
+		var g gpxFile
+		if err := xml.Unmarshal(data, &g); err != nil {
+			return nil, fmt.Errorf("failed to parse track %s: %w", path, err)
+		}
+		t := track{file: filepath.Base(path)}
+		for _, trk := range g.Tracks {
+			for _, seg := range trk.Segments {
+				for i, pt := range seg.Points {
+					if i > 0 {
+						prev := seg.Points[i-1]
+						t.km += haversine(prev.Lat, prev.Lon, pt.Lat, pt.Lon) / 1000
+					}
+					t.points = append(t.points, pt)
+				}
+			}
+		}
+		if len(t.points) == 0 || t.points[0].Time.IsZero() {
+			continue
+		}
+		first := t.points[0].Time.In(loc)
+		day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+		if !p.includes(day) {
+			continue
+		}
+		t.date = day.Format(isoDate)
+		byDate[t.date] = append(byDate[t.date], t)
+	}
+	return byDate, nil
+}
+
+// reaches reports whether a point of the track lies within radius meters of
+// the location.
+func (t track) reaches(lat, lon, radius float64) bool {
+	for _, pt := range t.points {
+		if haversine(pt.Lat, pt.Lon, lat, lon) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// tracked is the driven distance of a day to a customer.
+type tracked struct {
+	customer int
+	km       int
+	files    []string
+}
+
+// match returns the customer reached by the tracks of date, the first in
+// configured order, with the length of all the day's tracks in full km.
+// ok is false if no track of the day reaches a customer with a location.
+func (l trackLog) match(date time.Time, customers []Customer, radius float64) (tracked, bool) {
+	tracks := l[date.Format(isoDate)]
+	if len(tracks) == 0 {
+		return tracked{}, false
+	}
+	for i, c := range customers {
+		if c.Location == "" || c.Ticket.active() {
+			continue
+		}
+		lat, lon, err := parseLocation(c.Location)
+		if err != nil {
+			continue
+		}
+		for _, t := range tracks {
+			if t.reaches(lat, lon, radius) {
+				var km float64
+				m := tracked{customer: i}
+				for _, t := range tracks {
+					km += t.km
+					m.files = append(m.files, t.file)
+				}
+				m.km = int(math.Round(km))
+				return m, true
+			}
+		}
+	}
+	return tracked{}, false
+}
+
+// legs returns how often the entry's km are driven: once for a tracked trip,
+// whose km already cover the way back, otherwise the configured legs.
+func (t tripDay) legs(configured int) int {
+	if t.tracks != nil {
+		return 1
+	}
+	return configured
+}
+
+// trackDetail returns the entry line naming the tracks of a trip, or "" if
+// the distance is the configured one.
+func trackDetail(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	return "GPS-Track: " + strings.Join(files, ", ")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><trkseg>
+    <trkpt lat="48.0" lon="9.0"><time>%sT06:00:00Z</time></trkpt>
+    <trkpt lat="48.3" lon="9.0"><time>%sT07:00:00Z</time></trkpt>
+    <trkpt lat="48.0" lon="9.0"><time>%sT16:00:00Z</time></trkpt>
+  </trkseg></trk>
+</gpx>`
+
+func TestGPXTracks(t *testing.T) {
+	dir := t.TempDir()
+	for name, date := range map[string]string{"wed.gpx": "2026-02-04", "old.gpx": "2025-02-04"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(strings.ReplaceAll(testGPX, "%s", date)), 0644)
+	}
+	cfg := &Config{
+		GPX: GPXConfig{Dir: dir},
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Location: "49.0,8.4"},
+			{ID: "2", Name: "Beta", Distance: 50, Province: "BW", Location: "48.3005, 9.0"},
+		},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// 2 x 33.4 km on the track to Beta, which gets the Wednesday
+	beta := report.Customers[1]
+	n := -1
+	for i, d := range beta.Dates {
+		if d == "04.02.2026" {
+			n = i
+		}
+	}
+	if n < 0 || beta.distance(n) != 67 {
+		t.Fatalf("Beta dates = %v, distances = %v", beta.Dates, beta.Distances)
+	}
+	if km := beta.km(); km != 67+50*(len(beta.Dates)-1) {
+		t.Errorf("Beta km = %d", km)
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Fahrkosten \\(67 km x 0,30 EUR\\)", "GPS-Track: wed.gpx"} {
+		if !strings.Contains(km, want) {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+
+	// Outside the radius the configured distance is kept
+	cfg.GPX.Radius = 10
+	if report, err = generateReport(cfg, monthPeriod(2026, 2)); err != nil {
+		t.Fatalf("generateReport(radius 10) error = %v", err)
+	}
+	for _, c := range report.Customers {
+		for n := range c.Dates {
+			if c.distance(n) != c.Customer.Distance {
+				t.Errorf("%s distance on %s = %d", c.Customer.Name, c.Dates[n], c.distance(n))
+			}
+		}
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	if lat, lon, err := parseLocation("48.137, 11.575"); err != nil || lat != 48.137 || lon != 11.575 {
+		t.Errorf("parseLocation() = %v, %v, %v", lat, lon, err)
+	}
+	for _, s := range []string{"48.137", "north,east", "91,0", "0,181"} {
+		if _, _, err := parseLocation(s); err == nil {
+			t.Errorf("parseLocation(%q) error = nil", s)
+		}
+	}
+}
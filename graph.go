@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Microsoft Graph Delivery
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("graph", DelivererFunc(sendGraph))
+}
+
+// Microsoft Graph endpoints (variables to allow overriding in tests).
+var (
+	graphTokenURL = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+	graphBaseURL  = "https://graph.microsoft.com/v1.0"
+)
+
+type graphEmailAddress struct {
+	Address string `json:"address"`
+}
+
+type graphRecipient struct {
+	EmailAddress graphEmailAddress `json:"emailAddress"`
+}
+
+type graphBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type graphAttachment struct {
+	ODataType    string `json:"@odata.type"`
+	Name         string `json:"name"`
+	ContentType  string `json:"contentType"`
+	ContentBytes string `json:"contentBytes"`
+}
+
+type graphHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type graphMessage struct {
+	Subject                string            `json:"subject"`
+	Body                   graphBody         `json:"body"`
+	ToRecipients           []graphRecipient  `json:"toRecipients"`
+	ReplyTo                []graphRecipient  `json:"replyTo,omitempty"`
+	Attachments            []graphAttachment `json:"attachments,omitempty"`
+	InternetMessageHeaders []graphHeader     `json:"internetMessageHeaders,omitempty"`
+}
+
+type graphSendMailRequest struct {
+	Message         graphMessage `json:"message"`
+	SaveToSentItems bool         `json:"saveToSentItems"`
+}
+
+// buildGraphRequest creates the sendMail request payload.
+func buildGraphRequest(cfg *Config, m Mail) graphSendMailRequest {
+	msg := graphMessage{
+		Subject:      m.Subject,
+		Body:         graphBody{ContentType: "HTML", Content: m.body()},
+		ToRecipients: []graphRecipient{{EmailAddress: graphEmailAddress{Address: m.recipient(cfg)}}},
+	}
+	// Graph only accepts custom X- headers and assigns its own Message-ID, so
+	// the mails are not threaded: In-Reply-To and References are dropped and
+	// the Message-ID stored in the state file only records the delivery
+	if cfg.Email.ReplyTo != "" {
+		msg.ReplyTo = []graphRecipient{{EmailAddress: graphEmailAddress{Address: cfg.Email.ReplyTo}}}
+	}
+	for k, v := range mailHeaders(cfg, m) {
+		if strings.HasPrefix(strings.ToUpper(k), "X-") {
+			msg.InternetMessageHeaders = append(msg.InternetMessageHeaders, graphHeader{Name: k, Value: v})
+		}
+	}
+	sort.Slice(msg.InternetMessageHeaders, func(i, j int) bool {
+		return msg.InternetMessageHeaders[i].Name < msg.InternetMessageHeaders[j].Name
+	})
+	for _, a := range m.Attachments {
+		msg.Attachments = append(msg.Attachments, graphAttachment{
+			ODataType:    "#microsoft.graph.fileAttachment",
+			Name:         a.Filename,
+			ContentType:  a.ContentType(),
+			ContentBytes: base64.StdEncoding.EncodeToString(a.Data),
+		})
+	}
+	return graphSendMailRequest{Message: msg, SaveToSentItems: true}
+}
+
+// sendGraph sends the generated PDFs via the Microsoft Graph sendMail API
+// using the OAuth 2.0 client credentials flow.
+func sendGraph(cfg *Config, m Mail) error {
+	g := cfg.Graph
+	if g.TenantID == "" || g.ClientID == "" || g.ClientSecret == "" {
+		return permanent(fmt.Errorf("graph provider requires tenantId, clientId and clientSecret"))
+	}
+
+	token, err := fetchAccessToken(fmt.Sprintf(graphTokenURL, url.PathEscape(g.TenantID)), url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {g.ClientID},
+		"client_secret": {g.ClientSecret},
+		"scope":         {"https://graph.microsoft.com/.default"},
+	})
+	if err != nil {
+		return fmt.Errorf("graph: %w", err)
+	}
+
+	payload, err := json.Marshal(buildGraphRequest(cfg, m))
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/users/%s/sendMail", graphBaseURL, url.PathEscape(cfg.Email.From))
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("graph: sendMail request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return httpStatusError(resp.StatusCode, fmt.Errorf("graph: sendMail failed (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(body))))
+	}
+	return nil
+}
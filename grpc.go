@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"reisekosten/api"
+)
+
+// ---------------------------------------------------------------------------
+// gRPC Service
+// ---------------------------------------------------------------------------
+
+// GRPCConfig enables the gRPC service (api/reisekosten.proto) in serve mode.
+type GRPCConfig struct {
+	Listen       string `yaml:"listen,omitempty"`       // address of the gRPC service, e.g. :9111 (localhost unless a host is given)
+	Token        string `yaml:"token,omitempty"`        // bearer token required for GenerateReport with deliver
+	CertFile     string `yaml:"certFile,omitempty"`     // server certificate, enables TLS
+	KeyFile      string `yaml:"keyFile,omitempty"`      // private key of certFile
+	ClientCAFile string `yaml:"clientCAFile,omitempty"` // CA of the client certificates (mTLS)
+}
+
+// listenAddr returns the address to listen on. An address without host
+// binds to localhost only.
+func (g GRPCConfig) listenAddr() string {
+	host, port, err := net.SplitHostPort(g.Listen)
+	if err != nil || host != "" {
+		return g.Listen
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// authorizeDelivery checks that a client may deliver reports: it must send
+// the configured token, or the server verified its certificate (mTLS).
+// Without either delivery via gRPC is refused.
+func (g GRPCConfig) authorizeDelivery(ctx context.Context) error {
+	if g.Token != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if subtle.ConstantTimeCompare([]byte(v), []byte("Bearer "+g.Token)) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	if g.ClientCAFile != "" {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "delivery via gRPC needs grpc.token or grpc.clientCAFile")
+}
+
+// credentials returns the TLS server options, requiring client certificates
+// signed by clientCAFile if set.
+func (g GRPCConfig) credentials() ([]grpc.ServerOption, error) {
+	if g.CertFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(g.CertFile, g.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load grpc certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if g.ClientCAFile != "" {
+		pem, err := os.ReadFile(g.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read grpc clientCAFile: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in grpc clientCAFile %q", g.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsCfg))}, nil
+}
+
+// grpcService implements the Reisekosten service on top of the same
+// generation, delivery and archive functions as the command line.
+type grpcService struct {
+	api.UnimplementedReisekostenServer
+	cfg *Config
+	mu  sync.Mutex // serializes deliveries, which update the state file
+}
+
+// GenerateReport generates the report of a period. With deliver it is sent,
+// archived and recorded like a command line run (or sent for approval).
+func (s *grpcService) GenerateReport(ctx context.Context, req *api.GenerateReportRequest) (*api.Report, error) {
+	p, err := parseRequestPeriod(req.Period)
+	if err != nil {
+		return nil, err
+	}
+	cfg := *s.cfg
+	cfg.Filter = RunFilter{SkipDays: req.SkipDays, OnlyDays: req.OnlyDays, Customers: req.Customers}
+	if err := cfg.Filter.check(p); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !req.Deliver {
+		report, err := generateReport(&cfg, p)
+		if err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		msg := reportMessage(newRunSummary(&cfg, p, report, nil), report.Attachments)
+		msg.Delivery = nil
+		return msg, nil
+	}
+
+	if err := s.cfg.GRPC.authorizeDelivery(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := acquireLock(cfg.LockFile())
+	if err != nil {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	report, err := run(&cfg, p)
+	lock.release()
+	if report == nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	notifyRun(&cfg, p, report, err)
+	slog.Info("report generated via gRPC", "period", p.Label(), "error", err)
+	return reportMessage(newRunSummary(&cfg, p, report, err), report.Attachments), nil
+}
+
+// ListReports returns the archived monthly reports of a year.
+func (s *grpcService) ListReports(ctx context.Context, req *api.ListReportsRequest) (*api.ListReportsResponse, error) {
+	months, err := loadArchive(s.cfg, int(req.Year))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &api.ListReportsResponse{}
+	for _, m := range months {
+		resp.Reports = append(resp.Reports, reportMessage(m, nil))
+	}
+	return resp, nil
+}
+
+// GetPDF returns a document of an archived report. Only documents listed in
+// the archived summary can be requested.
+func (s *grpcService) GetPDF(ctx context.Context, req *api.GetPDFRequest) (*api.Document, error) {
+	p, err := parseRequestPeriod(req.Period)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := loadArchivedPeriod(s.cfg, p.Key())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if summary == nil {
+		return nil, status.Errorf(codes.NotFound, "no archived report for %s", p.Label())
+	}
+	for _, d := range summary.Documents {
+		if d.Filename != req.Filename {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.cfg.ArchiveDir(), summary.Period, d.Filename))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s is not archived, set archiveDocuments to keep the PDFs", d.Filename)
+		}
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		msg := documentMessage(d)
+		msg.Data = data
+		return msg, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "no document %q in the report of %s", req.Filename, p.Label())
+}
+
+// parseRequestPeriod parses the period key of a request, rejecting months,
+// quarters and weeks out of range.
+func parseRequestPeriod(key string) (Period, error) {
+	p, ok := parsePeriodKey(key)
+	if ok {
+		switch p.Kind {
+		case periodQuarter:
+			ok = p.Num >= 1 && p.Num <= 4
+		case periodWeek:
+			ok = p.Num >= 1 && p.Num <= weeksInYear(p.Year)
+		default:
+			ok = p.Num >= 1 && p.Num <= 12
+		}
+	}
+	if !ok {
+		return Period{}, status.Errorf(codes.InvalidArgument, "invalid period %q (use YYYY-MM, YYYY-Qn or YYYY-Wnn)", key)
+	}
+	return p, nil
+}
+
+// reportMessage converts a run summary. Documents get their PDF data from
+// the attachments, which are in the same order.
+func reportMessage(s runSummary, attachments []Attachment) *api.Report {
+	msg := &api.Report{
+		Period:           s.Period,
+		Workdays:         int32(s.Workdays),
+		OfficeDays:       int32(s.OfficeDays),
+		Warnings:         s.Warnings,
+		KmTotal:          s.KmTotal,
+		VerpflegungTotal: s.VerpflegungTotal,
+		ExpensesTotal:    s.ExpensesTotal,
+		Total:            s.Total,
+		Delivery: &api.Delivery{
+			Status:   s.Delivery.Status,
+			Provider: s.Delivery.Provider,
+			Outbox:   s.Delivery.Outbox,
+			Error:    s.Delivery.Error,
+		},
+	}
+	for _, c := range s.Customers {
+		msg.Customers = append(msg.Customers, &api.Customer{
+			Id:                c.ID,
+			Name:              c.Name,
+			Days:              int32(c.Days),
+			Dates:             c.Dates,
+			DistanceKm:        int32(c.Distance),
+			Km:                int32(c.Km),
+			KmRate:            c.KmRate,
+			KmAmount:          c.KmAmount,
+			VerpflegungRate:   c.VerpflegungRate,
+			VerpflegungAmount: c.VerpflegungAmount,
+			Project:           c.Project,
+			CostCenter:        c.CostCenter,
+		})
+	}
+	for i, d := range s.Documents {
+		doc := documentMessage(d)
+		if i < len(attachments) {
+			doc.Data = attachments[i].Data
+		}
+		msg.Documents = append(msg.Documents, doc)
+	}
+	return msg
+}
+
+// documentMessage converts a document summary without its data.
+func documentMessage(d documentSummary) *api.Document {
+	return &api.Document{Type: d.Type, Id: d.ID, Filename: d.Filename, Bytes: int64(d.Bytes), Amount: d.Amount}
+}
+
+// startGRPC serves the gRPC service on the configured address until the
+// returned server is stopped.
+func startGRPC(cfg *Config) (*grpc.Server, error) {
+	opts, err := cfg.GRPC.credentials()
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", cfg.GRPC.listenAddr())
+	if err != nil {
+		return nil, err
+	}
+	srv := grpc.NewServer(opts...)
+	api.RegisterReisekostenServer(srv, &grpcService{cfg: cfg})
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			slog.Error("gRPC server failed", "error", err)
+		}
+	}()
+	slog.Info("serving gRPC", "addr", ln.Addr().String())
+	return srv, nil
+}
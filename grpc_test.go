@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"reisekosten/api"
+)
+
+func TestGRPCService(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:            EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:              EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:            filepath.Join(dir, "state.json"),
+		Archive:          filepath.Join(dir, "archive"),
+		ArchiveDocuments: true,
+		Overrides:        filepath.Join(dir, "overrides"),
+		Customers:        []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+		GRPC:             GRPCConfig{Token: "s3cret"},
+	}
+
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	api.RegisterReisekostenServer(srv, &grpcService{cfg: cfg})
+	go srv.Serve(ln)
+	defer srv.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := api.NewReisekostenClient(conn)
+	ctx := context.Background()
+
+	preview, err := client.GenerateReport(ctx, &api.GenerateReportRequest{Period: "2026-02", SkipDays: []string{"2026-02-13"}})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if preview.Workdays != 19 || len(preview.Documents) != 2 || len(preview.Documents[0].Data) == 0 || preview.Delivery != nil {
+		t.Errorf("preview = workdays %d, %d documents, delivery %v", preview.Workdays, len(preview.Documents), preview.Delivery)
+	}
+	if list, _ := client.ListReports(ctx, &api.ListReportsRequest{Year: 2026}); len(list.Reports) != 0 {
+		t.Errorf("preview was archived: %v", list.Reports)
+	}
+
+	for _, md := range []metadata.MD{nil, metadata.Pairs("authorization", "Bearer wrong")} {
+		req := &api.GenerateReportRequest{Period: "2026-02", Deliver: true}
+		if _, err := client.GenerateReport(metadata.NewOutgoingContext(ctx, md), req); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("GenerateReport(deliver) with %v error = %v", md, err)
+		}
+	}
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+	delivered, err := client.GenerateReport(authCtx, &api.GenerateReportRequest{Period: "2026-02", Deliver: true})
+	if err != nil || delivered.Delivery.GetStatus() != "sent" {
+		t.Fatalf("GenerateReport(deliver) = %v, %v", delivered.GetDelivery(), err)
+	}
+	list, err := client.ListReports(ctx, &api.ListReportsRequest{Year: 2026})
+	if err != nil || len(list.Reports) != 1 || list.Reports[0].Documents[0].Id != delivered.Documents[0].Id {
+		t.Fatalf("ListReports() = %v, %v", list, err)
+	}
+
+	doc, err := client.GetPDF(ctx, &api.GetPDFRequest{Period: "2026-02", Filename: delivered.Documents[1].Filename})
+	if err != nil || doc.Type != "Verpflegungsmehraufwand" || !bytes.Equal(doc.Data, delivered.Documents[1].Data) {
+		t.Errorf("GetPDF() = %v, %v", doc.GetFilename(), err)
+	}
+
+	for _, tt := range []struct {
+		req  *api.GetPDFRequest
+		code codes.Code
+	}{
+		{&api.GetPDFRequest{Period: "2026-13"}, codes.InvalidArgument},
+		{&api.GetPDFRequest{Period: "2026-03", Filename: doc.Filename}, codes.NotFound},
+		{&api.GetPDFRequest{Period: "2026-02", Filename: "../state.json"}, codes.NotFound},
+	} {
+		if _, err := client.GetPDF(ctx, tt.req); status.Code(err) != tt.code {
+			t.Errorf("GetPDF(%v) error = %v, want %v", tt.req, err, tt.code)
+		}
+	}
+}
+
+func TestGRPCDeliveryAuth(t *testing.T) {
+	ctx := context.Background()
+	if err := (GRPCConfig{}).authorizeDelivery(ctx); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("authorizeDelivery() without token or mTLS error = %v", err)
+	}
+	if err := (GRPCConfig{CertFile: "cert.pem", KeyFile: "key.pem", ClientCAFile: "ca.pem"}).authorizeDelivery(ctx); err != nil {
+		t.Errorf("authorizeDelivery() with mTLS error = %v", err)
+	}
+
+	for _, tt := range []struct{ listen, want string }{
+		{":9111", "127.0.0.1:9111"},
+		{"0.0.0.0:9111", "0.0.0.0:9111"},
+		{"grpc.internal:9111", "grpc.internal:9111"},
+	} {
+		if got := (GRPCConfig{Listen: tt.listen}).listenAddr(); got != tt.want {
+			t.Errorf("listenAddr(%q) = %q, want %q", tt.listen, got, tt.want)
+		}
+	}
+}
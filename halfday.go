@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Half-Day Trips
+// ---------------------------------------------------------------------------
+
+// halfDay reports whether the trip on date is a half-day visit: an absence
+// under 8 hours that claims the kilometers but no meal allowance. Half days
+// are set by weekday (halfDayWeekdays) or by date (halfDays of the month's
+// override file).
+func (c *Config) halfDay(ov *MonthOverride, date time.Time) bool {
+	return onWeekday(c.HalfDayWeekdays, date) || contains(ov.HalfDays, date.Format(isoDate))
+}
+
+// halfDayDetail marks the kilometer entry of a half-day trip.
+const halfDayDetail = "Halber Tag (Abwesenheit unter 8 Stunden)"
+
+// buildHalfDayEntry creates the meal allowance entry of a half-day trip,
+// which lists the day without an amount. Non-empty details are printed
+// below.
+func buildHalfDayEntry(dateString string, details ...string) string {
+	var b strings.Builder
+
+	label := "Kein Verpflegungsmehraufwand (unter 8h)"
+	b.WriteString(fmt.Sprintf("  %s  (07:00 - 12:00)\n", dateString))
+	b.WriteString(fmt.Sprintf("    %s%s\n", label, rightAlign(formatAmount(0)+" EUR", 45-len(label))))
+	writeEntryDetails(&b, details)
+
+	return b.String()
+}
+
+// fullDays returns the number of trips to the customer with meal allowance.
+func (c CustomerReport) fullDays() int {
+	return len(c.Dates) - len(c.HalfDays) - len(c.MealsProvided)
+}
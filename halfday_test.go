@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHalfDays(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("halfDays: [2026-02-04]\n"), 0644)
+	cfg := &Config{
+		Overrides:       dir,
+		HalfDayWeekdays: []string{"fri"},
+		Customers:       []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Fridays 6, 13, 20, 27 and Wednesday 4 claim kilometers only
+	c := report.Customers[0]
+	if len(c.HalfDays) != 5 || c.HalfDays[0] != "04.02.2026" || c.fullDays() != 15 {
+		t.Errorf("half days = %v", c.HalfDays)
+	}
+	if report.KmTotal != 600 || report.VerpTotal != 15*verpflegungRate {
+		t.Errorf("KmTotal = %v, VerpTotal = %v, want 600 and 210", report.KmTotal, report.VerpTotal)
+	}
+	if !strings.Contains(pdfText(t, report.Attachments[0].Data), "Halber Tag \\(Abwesenheit unter 8 Stunden\\)") {
+		t.Errorf("Kilometergelderstattung does not mark the half days")
+	}
+	if verp := pdfText(t, report.Attachments[1].Data); !strings.Contains(verp, "Fr, 06.02.2026  \\(07:00 - 12:00\\)") || !strings.Contains(verp, "Kein Verpflegungsmehraufwand \\(unter 8h\\)") {
+		t.Errorf("Verpflegungsmehraufwand does not list the half days")
+	}
+
+	var out strings.Builder
+	if err := writeExplanation(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "5 days under 8 hours without meal allowance") {
+		t.Errorf("explanation misses the half days:\n%s", out.String())
+	}
+	if s := newRunSummary(cfg, p, report, nil); len(s.Customers[0].HalfDays) != 5 || s.Customers[0].VerpflegungAmount != 210 {
+		t.Errorf("summary half days = %v, amount = %v", s.Customers[0].HalfDays, s.Customers[0].VerpflegungAmount)
+	}
+}
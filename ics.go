@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ---------------------------------------------------------------------------
+// Calendar Export (ICS / CalDAV)
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerExporter("ics", ExporterFunc(exportCalendar))
+}
+
+// ICSConfig enables the calendar export of the trips of a report.
+type ICSConfig struct {
+	Dir    string       `yaml:"dir,omitempty"`    // write <period>_Reisekosten.ics into this directory
+	CalDAV CalDAVConfig `yaml:"caldav,omitempty"` // also upload every trip to a CalDAV calendar
+}
+
+// CalDAVConfig is the calendar collection the trips are uploaded to.
+type CalDAVConfig struct {
+	URL      string `yaml:"url,omitempty"` // collection URL, e.g. https://cloud.example.com/remote.php/dav/calendars/max/reisen/
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// icsEvent is one all-day trip event.
+type icsEvent struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Location    string
+	Description string
+}
+
+// tripEvents returns one event per trip of the report in date order. UIDs
+// are derived from date and customer, so a regenerated report replaces the
+// events of the earlier run.
+func tripEvents(report *Report) []icsEvent {
+	var events []icsEvent
+	for _, c := range report.Customers {
+		for n, d := range c.Dates {
+			date, _ := time.Parse("02.01.2006", d)
+			events = append(events, icsEvent{
+				UID:         fmt.Sprintf("reisekosten-%s-%s@reisekosten", date.Format(isoDate), c.Customer.ID),
+				Date:        date,
+				Summary:     fmt.Sprintf("%s, %d km", c.Customer.Name, c.distance(n)),
+				Location:    c.Customer.To,
+				Description: c.Customer.visitReason(n),
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return events
+}
+
+// buildICS renders events as an iCalendar (RFC 5545) document.
+func buildICS(events []icsEvent, stamp time.Time) []byte {
+	var b bytes.Buffer
+	line := func(s string) {
+		// Lines longer than 75 octets are folded with a leading space,
+		// without splitting UTF-8 sequences
+		for len(s) > 75 {
+			n := 75
+			for !utf8.RuneStart(s[n]) {
+				n--
+			}
+			b.WriteString(s[:n] + "\r\n")
+			s = " " + s[n:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//reisekosten//reisekosten " + version + "//DE")
+	line("CALSCALE:GREGORIAN")
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsEscape(e.Summary))
+		if e.Location != "" {
+			line("LOCATION:" + icsEscape(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION:" + icsEscape(e.Description))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.Bytes()
+}
+
+// icsEscape escapes text values as required by RFC 5545.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// exportCalendar writes and uploads the trips of a report as configured.
+func exportCalendar(cfg *Config, p Period, report *Report) error {
+	if cfg.ICS.Dir == "" && cfg.ICS.CalDAV.URL == "" {
+		return nil
+	}
+	events := tripEvents(report)
+	now := time.Now()
+
+	if cfg.ICS.Dir != "" {
+		if err := os.MkdirAll(cfg.ICS.Dir, 0o700); err != nil {
+			return err
+		}
+		path := filepath.Join(cfg.ICS.Dir, p.filePrefix()+"_Reisekosten.ics")
+		if err := os.WriteFile(path, buildICS(events, now), 0o600); err != nil {
+			return fmt.Errorf("failed to write calendar: %w", err)
+		}
+		slog.Info("calendar written", "path", path, "events", len(events))
+	}
+
+	if cfg.ICS.CalDAV.URL != "" {
+		for _, e := range events {
+			if err := putCalDAVEvent(cfg.ICS.CalDAV, e, buildICS([]icsEvent{e}, now)); err != nil {
+				return err
+			}
+		}
+		slog.Info("calendar uploaded", "url", cfg.ICS.CalDAV.URL, "events", len(events))
+	}
+	return nil
+}
+
+// putCalDAVEvent creates or replaces a single event resource in the collection.
+func putCalDAVEvent(c CalDAVConfig, e icsEvent, data []byte) error {
+	url := strings.TrimSuffix(c.URL, "/") + "/" + strings.TrimSuffix(e.UID, "@reisekosten") + ".ics"
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("caldav upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("caldav upload of %s failed: %s", e.Date.Format(isoDate), resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuildICS(t *testing.T) {
+	report := &Report{Customers: []CustomerReport{
+		{Customer: Customer{ID: "2", Name: "Globex", Distance: 50, To: "München, Bahnhofstr. 1"}, Dates: []string{"03.02.2026"}},
+		{Customer: Customer{ID: "1", Name: "Acme", Distance: 120, Reason: "Workshop; Planung"}, Dates: []string{"02.02.2026"}},
+	}}
+	events := tripEvents(report)
+	if len(events) != 2 || events[0].Summary != "Acme, 120 km" {
+		t.Fatalf("events = %+v", events)
+	}
+
+	ics := string(buildICS(events, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:reisekosten-2026-02-02-1@reisekosten\r\n",
+		"DTSTAMP:20260301T080000Z\r\n",
+		"DTSTART;VALUE=DATE:20260202\r\nDTEND;VALUE=DATE:20260203\r\n",
+		"DESCRIPTION:Workshop\\; Planung\r\n",
+		"LOCATION:München\\, Bahnhofstr. 1\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ICS missing %q:\n%s", want, ics)
+		}
+	}
+
+	long := string(buildICS([]icsEvent{{UID: "x", Summary: strings.Repeat("ä", 60)}}, time.Now()))
+	for _, line := range strings.Split(long, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line not folded: %q", line)
+		}
+	}
+}
+
+func TestExportCalendar(t *testing.T) {
+	var mu sync.Mutex
+	puts := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.Method != http.MethodPut || user != "max" || pass != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		puts[r.URL.Path] = string(body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &Config{ICS: ICSConfig{Dir: dir, CalDAV: CalDAVConfig{URL: srv.URL + "/cal/", Username: "max", Password: "secret"}}}
+	report := &Report{Customers: []CustomerReport{
+		{Customer: Customer{ID: "1", Name: "Acme", Distance: 100}, Dates: []string{"02.02.2026", "04.02.2026"}},
+	}}
+	if err := exportCalendar(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("exportCalendar() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "02_2026_Reisekosten.ics"))
+	if err != nil || strings.Count(string(data), "BEGIN:VEVENT") != 2 {
+		t.Errorf("ics file = %q, %v", data, err)
+	}
+	if len(puts) != 2 || !strings.Contains(puts["/cal/reisekosten-2026-02-04-1.ics"], "SUMMARY:Acme\\, 100 km") {
+		t.Errorf("caldav puts = %v", puts)
+	}
+
+	cfg.ICS.CalDAV.Password = "wrong"
+	if err := exportCalendar(cfg, monthPeriod(2026, 2), report); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("exportCalendar() error = %v, want 403", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// IMAP Sent Folder
+// ---------------------------------------------------------------------------
+
+const (
+	defaultIMAPPort   = 993
+	defaultIMAPFolder = "Sent"
+)
+
+// IMAPConfig configures appending sent mails to an IMAP folder.
+type IMAPConfig struct {
+	Host   string `yaml:"host"`
+	Port   int    `yaml:"port,omitempty"`   // default: 993
+	User   string `yaml:"user,omitempty"`   // default: smtp.user
+	Pass   string `yaml:"pass,omitempty"`   // default: smtp.pass
+	Folder string `yaml:"folder,omitempty"` // default: Sent
+	TLS    string `yaml:"tls,omitempty"`    // implicit (default) or none (e.g. local mail bridge)
+}
+
+// imapConn is a minimal IMAP4rev1 client supporting LOGIN, APPEND and LOGOUT.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapQuote quotes a string for use in an IMAP command.
+func imapQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// readResponse reads lines until the tagged response (or a continuation
+// request if continuation is true) and returns the final line.
+func (c *imapConn) readResponse(tag string, continuation bool) (string, error) {
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if continuation && strings.HasPrefix(line, "+") {
+			return line, nil
+		}
+		if strings.HasPrefix(line, tag+" ") {
+			status := strings.TrimPrefix(line, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				return "", fmt.Errorf("imap: %s", status)
+			}
+			return line, nil
+		}
+	}
+}
+
+// command sends a tagged command and waits for its completion.
+func (c *imapConn) command(format string, args ...any) error {
+	c.tag++
+	tag := fmt.Sprintf("A%d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return err
+	}
+	_, err := c.readResponse(tag, false)
+	return err
+}
+
+// appendMessage uploads a message to the given folder, flagged as seen.
+func (c *imapConn) appendMessage(folder string, msg []byte) error {
+	c.tag++
+	tag := fmt.Sprintf("A%d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s APPEND %s (\\Seen) {%d}\r\n", tag, imapQuote(folder), len(msg)); err != nil {
+		return err
+	}
+	if _, err := c.readResponse(tag, true); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(msg, '\r', '\n')); err != nil {
+		return err
+	}
+	_, err := c.readResponse(tag, false)
+	return err
+}
+
+// archiveSentMail stores a copy of a delivered message in the IMAP Sent folder.
+// The message has already been delivered, so failures are only reported.
+func archiveSentMail(cfg *Config, m Mail) {
+	if err := saveToSentFolder(cfg, m); err != nil {
+		slog.Warn("failed to save message to IMAP folder", "folder", cfg.IMAP.Folder, "error", err)
+		return
+	}
+	if cfg.IMAP.Host != "" {
+		slog.Debug("message saved to IMAP folder", "host", cfg.IMAP.Host)
+	}
+}
+
+// saveToSentFolder appends the sent message to the configured IMAP folder.
+// It is a no-op if no IMAP host is configured.
+func saveToSentFolder(cfg *Config, m Mail) error {
+	im := cfg.IMAP
+	if im.Host == "" {
+		return nil
+	}
+	port, user, pass, folder := im.Port, im.User, im.Pass, im.Folder
+	if port == 0 {
+		port = defaultIMAPPort
+	}
+	if user == "" {
+		user, pass = cfg.SMTP.User, cfg.SMTP.Pass
+	}
+	if folder == "" {
+		folder = defaultIMAPFolder
+	}
+
+	msg, err := composeMessage(cfg, m)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(im.Host, fmt.Sprint(port))
+	var conn net.Conn
+	switch im.TLS {
+	case "", smtpTLSImplicit:
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, &tls.Config{ServerName: im.Host})
+	case smtpTLSNone:
+		conn, err = net.DialTimeout("tcp", addr, 10*time.Second)
+	default:
+		return fmt.Errorf("invalid imap tls mode %q (use implicit or none)", im.TLS)
+	}
+	if err != nil {
+		return fmt.Errorf("imap: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(60 * time.Second))
+
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	if _, err := c.readResponse("*", false); err != nil {
+		return fmt.Errorf("imap greeting: %w", err)
+	}
+	if err := c.command("LOGIN %s %s", imapQuote(user), imapQuote(pass)); err != nil {
+		return err
+	}
+	if err := c.appendMessage(folder, msg); err != nil {
+		return err
+	}
+	return c.command("LOGOUT")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeIMAPServer accepts one connection and records the commands and appended message.
+func fakeIMAPServer(t *testing.T) (port int, done <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var log []string
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "* OK IMAP4rev1 ready\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				ch <- log
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			log = append(log, line)
+			tag, cmd, _ := strings.Cut(line, " ")
+
+			var size int
+			if i := strings.LastIndex(cmd, "{"); i >= 0 && strings.HasPrefix(cmd, "APPEND") {
+				fmt.Sscanf(cmd[i:], "{%d}", &size)
+				fmt.Fprint(conn, "+ Ready for literal data\r\n")
+				msg := make([]byte, size+2)
+				io.ReadFull(r, msg)
+				log = append(log, string(msg[:size]))
+			}
+			if strings.Contains(cmd, "wrong") {
+				fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] Invalid credentials\r\n", tag)
+				continue
+			}
+			fmt.Fprintf(conn, "%s OK done\r\n", tag)
+			if cmd == "LOGOUT" {
+				ch <- log
+				return
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSaveToSentFolder(t *testing.T) {
+	port, done := fakeIMAPServer(t)
+
+	cfg := &Config{
+		SMTP:  SMTPConfig{User: "me", Pass: `p"w`},
+		Email: EmailConfig{From: "me@example.com", To: "boss@example.com"},
+		IMAP:  IMAPConfig{Host: "127.0.0.1", Port: port, TLS: "none"},
+	}
+	if err := saveToSentFolder(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("saveToSentFolder() error = %v", err)
+	}
+
+	log := <-done
+	if len(log) != 4 {
+		t.Fatalf("unexpected session: %q", log)
+	}
+	if log[0] != `A1 LOGIN "me" "p\"w"` {
+		t.Errorf("LOGIN = %q", log[0])
+	}
+	if !strings.HasPrefix(log[1], `A2 APPEND "Sent" (\Seen) {`) {
+		t.Errorf("APPEND = %q", log[1])
+	}
+	if !strings.Contains(log[2], "Subject: Betreff") {
+		t.Error("appended message missing subject")
+	}
+}
+
+func TestSaveToSentFolderLoginFailure(t *testing.T) {
+	port, _ := fakeIMAPServer(t)
+
+	cfg := &Config{IMAP: IMAPConfig{Host: "127.0.0.1", Port: port, TLS: "none", User: "wrong", Pass: "x"}}
+	err := saveToSentFolder(cfg, Mail{Subject: "Betreff"})
+	if err == nil || !strings.Contains(err.Error(), "AUTHENTICATIONFAILED") {
+		t.Errorf("saveToSentFolder() error = %v, want AUTHENTICATIONFAILED", err)
+	}
+}
+
+func TestSaveToSentFolderDisabled(t *testing.T) {
+	if err := saveToSentFolder(&Config{}, Mail{Subject: "Betreff"}); err != nil {
+		t.Errorf("saveToSentFolder() without IMAP config error = %v", err)
+	}
+}
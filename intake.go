@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Receipt Intake
+// ---------------------------------------------------------------------------
+
+// defaultReceiptsDir is the directory scanned by intake without an argument.
+const defaultReceiptsDir = "receipts"
+
+// OCRConfig selects the OCR backend that reads receipt images.
+type OCRConfig struct {
+	Provider string `yaml:"provider,omitempty"` // tesseract (default) or api
+	Command  string `yaml:"command,omitempty"`  // tesseract binary (default: tesseract in PATH)
+	Language string `yaml:"language,omitempty"` // tesseract language (default: deu)
+	URL      string `yaml:"url,omitempty"`      // api: endpoint the image is posted to
+	APIKey   string `yaml:"apiKey,omitempty"`   // api: bearer token
+	Dir      string `yaml:"dir,omitempty"`      // receipts directory (default: receipts)
+}
+
+// OCRBackend returns the text recognized on a receipt image, selected by
+// ocr.provider.
+type OCRBackend interface {
+	Recognize(cfg *Config, path string) (string, error)
+}
+
+// OCRFunc adapts a function to the OCRBackend interface.
+type OCRFunc func(cfg *Config, path string) (string, error)
+
+func (f OCRFunc) Recognize(cfg *Config, path string) (string, error) { return f(cfg, path) }
+
+var ocrBackends = map[string]OCRBackend{}
+
+// registerOCR makes an OCR backend available as ocr.provider. Backends
+// register themselves from an init function in their own file.
+func registerOCR(name string, b OCRBackend) {
+	if _, ok := ocrBackends[name]; ok {
+		panic("ocr backend registered twice: " + name)
+	}
+	ocrBackends[name] = b
+}
+
+// receiptExtensions are the image types passed to the OCR backend.
+var receiptExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".tif": true, ".tiff": true}
+
+var (
+	// receiptDateRegex matches DD.MM.YYYY, DD.MM.YY and YYYY-MM-DD
+	receiptDateRegex = regexp.MustCompile(`\b(\d{1,2})\.(\d{1,2})\.(\d{4}|\d{2})\b|\b(\d{4})-(\d{2})-(\d{2})\b`)
+	// receiptAmountRegex matches amounts with two decimals, e.g. 12,50 or 1.234,56
+	receiptAmountRegex = regexp.MustCompile(`\d{1,3}(?:[.' ]\d{3})*[.,]\d{2}\b|\d+[.,]\d{2}\b`)
+	// receiptPercentRegex matches VAT rates, which are not amounts
+	receiptPercentRegex = regexp.MustCompile(`\d+(?:[.,]\d+)?\s*%`)
+	// receiptTotalRegex and receiptVATRegex find the lines of the total and the VAT
+	receiptTotalRegex = regexp.MustCompile(`(?i)summe|gesamt|total|zu zahlen|betrag`)
+	receiptVATRegex   = regexp.MustCompile(`(?i)mwst|ust\b|umsatzsteuer|vat|steuer`)
+)
+
+// receipt is what could be read from a receipt image.
+type receipt struct {
+	File   string
+	Date   string  // YYYY-MM-DD, empty if not found
+	Vendor string  // first line with letters
+	Amount float64 // gross total, 0 if not found
+	VAT    float64 // included VAT, 0 if not found
+}
+
+// parseAmount converts an amount with German or English separators to EUR.
+func parseAmount(s string) float64 {
+	s = strings.NewReplacer("'", "", " ", "").Replace(s)
+	i := strings.LastIndexAny(s, ".,")
+	whole := strings.NewReplacer(".", "", ",", "").Replace(s[:i])
+	v, _ := strconv.ParseFloat(whole+"."+s[i+1:], 64)
+	return v
+}
+
+// lineAmount returns the last amount of a line, ignoring percentages.
+func lineAmount(line string) (float64, bool) {
+	matches := receiptAmountRegex.FindAllString(receiptPercentRegex.ReplaceAllString(line, ""), -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	return parseAmount(matches[len(matches)-1]), true
+}
+
+// parseReceipt extracts date, vendor, total and VAT from the recognized
+// text. The total is taken from a line naming it (Summe, Gesamt, ...) and
+// otherwise is the largest amount; the VAT from a line naming it.
+func parseReceipt(text string) receipt {
+	var r receipt
+	var largest float64
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if r.Vendor == "" && strings.IndexFunc(line, unicode.IsLetter) >= 0 && !receiptDateRegex.MatchString(line) {
+			r.Vendor = line
+		}
+		if r.Date == "" {
+			r.Date = receiptDate(line)
+		}
+		amount, ok := lineAmount(line)
+		if !ok {
+			continue
+		}
+		largest = max(largest, amount)
+		switch {
+		case receiptVATRegex.MatchString(line):
+			if r.VAT == 0 {
+				r.VAT = amount
+			}
+		case receiptTotalRegex.MatchString(line):
+			if r.Amount == 0 {
+				r.Amount = amount
+			}
+		}
+	}
+	if r.Amount == 0 {
+		r.Amount = largest
+	}
+	if r.VAT >= r.Amount {
+		r.VAT = 0
+	}
+	return r
+}
+
+// receiptDate returns the first valid date of a line as YYYY-MM-DD, or "".
+func receiptDate(line string) string {
+	for _, m := range receiptDateRegex.FindAllStringSubmatch(line, -1) {
+		s := fmt.Sprintf("%s-%s-%s", m[4], m[5], m[6])
+		if m[1] != "" {
+			day, _ := strconv.Atoi(m[1])
+			month, _ := strconv.Atoi(m[2])
+			year, _ := strconv.Atoi(m[3])
+			if year < 100 {
+				year += 2000
+			}
+			s = fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+		}
+		if _, err := time.Parse(isoDate, s); err == nil {
+			return s
+		}
+	}
+	return ""
+}
+
+// expense returns the expense item proposed for the receipt.
+func (r receipt) expense() Expense {
+	description := r.Vendor
+	if len(description) > 40 {
+		description = strings.TrimSpace(description[:40])
+	}
+	if description == "" {
+		description = r.File
+	}
+	return Expense{Date: r.Date, Description: description, Amount: roundCents(r.Amount), VAT: roundCents(r.VAT)}
+}
+
+// intakeReceipts runs OCR over the receipt images in dir and writes the
+// proposed expense items as override file snippets, one per month, to w.
+// Receipts without a date or amount are listed as comments to be completed
+// by hand. Nothing is written to the override files.
+func intakeReceipts(cfg *Config, dir string, w io.Writer) error {
+	provider := cfg.OCR.Provider
+	if provider == "" {
+		provider = "tesseract"
+	}
+	backend, ok := ocrBackends[provider]
+	if !ok {
+		return fmt.Errorf("unknown ocr provider %q (use %s)", provider, registryNames(ocrBackends))
+	}
+	if dir == "" {
+		dir = cfg.OCR.Dir
+	}
+	if dir == "" {
+		dir = defaultReceiptsDir
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read receipts: %w", err)
+	}
+	months := make(map[string][]Expense)
+	var unread []string
+	for _, e := range entries {
+		if e.IsDir() || !receiptExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+			continue
+		}
+		text, err := backend.Recognize(cfg, filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", provider, e.Name(), err)
+		}
+		r := parseReceipt(text)
+		r.File = e.Name()
+		if r.Date == "" || r.Amount == 0 {
+			slog.Warn("receipt not recognized", "file", r.File, "date", r.Date, "amount", formatAmount(r.Amount))
+			unread = append(unread, r.File)
+			continue
+		}
+		months[r.Date[:7]] = append(months[r.Date[:7]], r.expense())
+		slog.Info("receipt read", "file", r.File, "date", r.Date, "amount", formatAmount(r.Amount), "vat", formatAmount(r.VAT))
+	}
+
+	keys := make([]string, 0, len(months))
+	for k := range months {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		expenses := months[k]
+		sort.SliceStable(expenses, func(i, j int) bool { return expenses[i].Date < expenses[j].Date })
+		data, err := yaml.Marshal(MonthOverride{Expenses: expenses})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "# %s\n%s\n", filepath.Join(cfg.OverridesDir(), k+".yaml"), data)
+	}
+	for _, f := range unread {
+		fmt.Fprintf(w, "# %s: date or amount not recognized, add it by hand\n", f)
+	}
+	return nil
+}
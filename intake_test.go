@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleReceipt = `APCOA Parking
+Flughafen Stuttgart P14
+Datum: 17.02.26 14:32
+
+Parkgebuehr           12,50
+Summe EUR             12,50
+MwSt 19,00%            2,00
+Netto                 10,50
+Gegeben Bar           20,00
+`
+
+func TestParseReceipt(t *testing.T) {
+	r := parseReceipt(sampleReceipt)
+	if r.Date != "2026-02-17" || r.Vendor != "APCOA Parking" || r.Amount != 12.50 || r.VAT != 2.00 {
+		t.Errorf("parseReceipt() = %+v", r)
+	}
+
+	// Without a total line the largest amount is taken
+	r = parseReceipt("Hotel Post\n2026-03-02\nUebernachtung 1.234,56\nFruehstueck 15,00\n")
+	if r.Date != "2026-03-02" || r.Amount != 1234.56 || r.VAT != 0 {
+		t.Errorf("parseReceipt(no total) = %+v", r)
+	}
+}
+
+func TestIntakeReceipts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if string(data) == "unreadable" {
+			io.WriteString(w, "???")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"text": "APCOA Parking\nDatum: 17.02.26\nSumme EUR 12,50\nMwSt 19% 2,00\n"}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "parking.jpg"), []byte("image"), 0o600)
+	os.WriteFile(filepath.Join(dir, "blurred.png"), []byte("unreadable"), 0o600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skipped"), 0o600)
+	cfg := &Config{Overrides: "overrides", OCR: OCRConfig{Provider: "api", URL: srv.URL}}
+
+	var out strings.Builder
+	if err := intakeReceipts(cfg, dir, &out); err != nil {
+		t.Fatalf("intakeReceipts() error = %v", err)
+	}
+	want := "# " + filepath.Join("overrides", "2026-02.yaml") + "\nexpenses:\n    - date: \"2026-02-17\"\n      description: APCOA Parking\n      amount: 12.5\n      vat: 2\n"
+	if !strings.HasPrefix(out.String(), want) || !strings.Contains(out.String(), "# blurred.png: date or amount not recognized") {
+		t.Errorf("intakeReceipts() output:\n%s", out.String())
+	}
+
+	if entry := buildExpenseEntry(Expense{Date: "2026-02-17", Description: "APCOA Parking", Amount: 12.5, VAT: 2}, ""); !strings.Contains(entry, "    darin MwSt. 2,00 EUR\n") {
+		t.Errorf("buildExpenseEntry() misses the VAT:\n%s", entry)
+	}
+
+	cfg.OCR.Provider = "unknown"
+	if err := intakeReceipts(cfg, dir, &out); err == nil {
+		t.Error("intakeReceipts(unknown provider) error = nil")
+	}
+}
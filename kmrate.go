@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Annual Km Rate Recalculation
+// ---------------------------------------------------------------------------
+
+// vehicleLabels are the German names of the vehicles in the km rate report.
+var vehicleLabels = map[string]string{
+	vehicleCar:   "Pkw",
+	vehicleBike:  "Fahrrad",
+	vehicleEBike: "E-Bike",
+}
+
+// kmRateVehicle compares the flat rate and the actual costs of a vehicle
+// over the business trips of a year.
+type kmRateVehicle struct {
+	vehicle  string
+	km       int         // business km of the archived reports
+	claimed  float64     // km amount claimed in the archived reports
+	flatRate float64     // statutory rate, bikeRate by bike
+	actual   *actualRate // nil without actualCosts for the vehicle
+}
+
+// flat returns the business km at the flat rate.
+func (v kmRateVehicle) flat() float64 {
+	return roundCents(float64(v.km) * v.flatRate)
+}
+
+// actualAmount returns the business km at the individual rate of the year.
+func (v kmRateVehicle) actualAmount() float64 {
+	return roundCents(float64(v.km) * v.actual.rate)
+}
+
+// kmRateVehicles sums the business km and claimed amounts of the archived
+// months by vehicle, in the order car, bike, e-bike. Trips by public
+// transport are left out.
+func kmRateVehicles(cfg *Config, year int, months []runSummary) ([]kmRateVehicle, error) {
+	rates, err := cfg.ratesFor(year)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := cfg.actualRates(year)
+	if err != nil {
+		return nil, err
+	}
+
+	km := make(map[string]int)
+	claimed := make(map[string]float64)
+	for _, m := range months {
+		for _, c := range m.Customers {
+			if c.Ticket != "" {
+				continue
+			}
+			for n := range c.Dates {
+				vehicle := vehicleCar
+				if n < len(c.Vehicles) {
+					vehicle = c.Vehicles[n]
+				}
+				km[vehicle] += c.distance(n)
+				claimed[vehicle] += roundCents(float64(c.distance(n)) * c.rate(n))
+			}
+		}
+	}
+
+	var vehicles []kmRateVehicle
+	for _, vehicle := range []string{vehicleCar, vehicleBike, vehicleEBike} {
+		v := kmRateVehicle{vehicle: vehicle, km: km[vehicle], claimed: roundCents(claimed[vehicle]), flatRate: rates.KmRate}
+		if byBike(vehicle) {
+			v.flatRate = rates.BikeRate
+		}
+		for i := range actual {
+			if actual[i].vehicle == vehicle {
+				v.actual = &actual[i]
+			}
+		}
+		if v.km > 0 || v.actual != nil {
+			vehicles = append(vehicles, v)
+		}
+	}
+	return vehicles, nil
+}
+
+// buildKmRateHeader creates the title block of the km rate report.
+func buildKmRateHeader(year int, months []runSummary) string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("KILOMETERSATZ JAHRESABRECHNUNG %d", year)
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", (lineWidth-len(header))/2), header))
+	b.WriteString(lineDouble + "\n\n")
+
+	b.WriteString(fmt.Sprintf("Zeitraum:             01/%d - 12/%d\n", year, year))
+	archived := archivedMonths(months)
+	covered := 0
+	for month := time.January; month <= time.December; month++ {
+		if archived[periodKey(year, month)] {
+			covered++
+		}
+	}
+	b.WriteString(fmt.Sprintf("Erfasste Monate:      %d von 12\n", covered))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// buildKmRateBlock creates the calculation of one vehicle: the individual
+// rate from its costs and the business km under each method.
+func buildKmRateBlock(v kmRateVehicle) string {
+	var b strings.Builder
+
+	line := func(label, value string) {
+		b.WriteString(fmt.Sprintf("%s%s\n", label, rightAlign(value, lineWidth-len(label))))
+	}
+	b.WriteString(buildSectionHeader("Fahrzeug: " + vehicleLabels[v.vehicle]))
+	if a := v.actual; a != nil {
+		line("Fixkosten (AfA, Versicherung, Steuer, Wartung)", formatAmount(a.costs)+" EUR")
+		line("Kraftstoff/Strom laut Belegen", formatAmount(a.fuel)+" EUR")
+		line("Jahresfahrleistung", fmt.Sprintf("%d km", a.km))
+		line("Individueller Kilometersatz", formatAmount(a.rate)+" EUR/km")
+		b.WriteString("\n")
+	}
+	line("Dienstliche Kilometer", fmt.Sprintf("%d km", v.km))
+	line("Abgerechnet", formatAmount(v.claimed)+" EUR")
+	line(fmt.Sprintf("Pauschale (%s EUR/km)", formatRate(v.flatRate)), formatAmount(v.flat())+" EUR")
+	if v.actual != nil {
+		line(fmt.Sprintf("Tatsaechliche Kosten (%s EUR/km)", formatAmount(v.actual.rate)), formatAmount(v.actualAmount())+" EUR")
+		line("Differenz tatsaechliche Kosten zur Abrechnung", signedAmount(v.actualAmount()-v.claimed)+" EUR")
+	} else {
+		b.WriteString("Keine tatsaechlichen Kosten erfasst (actualCosts).\n")
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// generateKmRateReport derives the individual km rates of the year from the
+// recorded vehicle costs and compares the archived business km under the
+// flat rate and the actual costs. It writes a PDF to the output directory and
+// returns its path.
+func generateKmRateReport(cfg *Config, year int) (string, error) {
+	months, err := loadArchive(cfg, year)
+	if err != nil {
+		return "", err
+	}
+	if len(months) == 0 {
+		return "", fmt.Errorf("no archived reports for %d in %s", year, cfg.ArchiveDir())
+	}
+	vehicles, err := kmRateVehicles(cfg, year, months)
+	if err != nil {
+		return "", err
+	}
+	if len(vehicles) == 0 {
+		return "", fmt.Errorf("no km driven in %d", year)
+	}
+
+	var claimed float64
+	blocks := make([]string, 0, len(vehicles))
+	for _, v := range vehicles {
+		blocks = append(blocks, buildKmRateBlock(v))
+		claimed += v.claimed
+	}
+	data, err := createPDF(buildKmRateHeader(year, months), blocks, buildDocumentFooter(claimed))
+	if err != nil {
+		return "", err
+	}
+
+	filename := fmt.Sprintf("%d_Reisekosten_Kilometersatz.pdf", year)
+	path, err := cfg.Output.path(newOutputFile(year, Period{}, filename, ""))
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.Output.write(path, data); err != nil {
+		return "", fmt.Errorf("failed to write km rate report: %w", err)
+	}
+	slog.Info("km rate report written", "year", year, "months", len(months), "claimed", formatAmount(claimed), "pdf", path)
+	return path, nil
+}
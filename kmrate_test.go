@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKmRateReport(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: filepath.Join(dir, "archive"), Output: OutputConfig{Dir: dir}, Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+	if _, err := generateKmRateReport(cfg, 2026); err == nil {
+		t.Error("generateKmRateReport() expected error without archive")
+	}
+
+	// February is claimed at the flat rate: 20 days x 100 km x 0.30
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, p, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+
+	// The year's costs give (4000 + 1400) / 15000 = 0.36 per km
+	os.MkdirAll(cfg.Overrides, 0o755)
+	os.WriteFile(filepath.Join(cfg.Overrides, "2026-03.yaml"), []byte("fuel:\n  - date: 2026-03-02\n    amount: 1400\n"), 0o644)
+	cfg.ActualCosts = map[string]ActualCosts{vehicleCar: {Km: 15000, Costs: 4000}}
+	months, _ := loadArchive(cfg, 2026)
+	vehicles, err := kmRateVehicles(cfg, 2026, months)
+	if err != nil {
+		t.Fatalf("kmRateVehicles() error = %v", err)
+	}
+	if len(vehicles) != 1 || vehicles[0].km != 2000 || vehicles[0].claimed != 600 || vehicles[0].flat() != 600 || vehicles[0].actual.rate != 0.36 || vehicles[0].actualAmount() != 720 {
+		t.Fatalf("vehicles = %+v", vehicles)
+	}
+
+	path, err := generateKmRateReport(cfg, 2026)
+	if err != nil {
+		t.Fatalf("generateKmRateReport() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	text := pdfText(t, data)
+	for _, want := range []string{"Fahrzeug: Pkw", "Individueller Kilometersatz", "0,36 EUR/km", "Pauschale \\(0,30 EUR/km\\)", "+120,00 EUR"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("km rate report misses %q", want)
+		}
+	}
+}
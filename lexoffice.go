@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// lexoffice Accounting
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerAccounting("lexoffice", AccountingFunc(postLexoffice))
+}
+
+// lexofficeAPI is the base URL of the lexoffice API (variable for tests).
+var lexofficeAPI = "https://api.lexoffice.io/v1"
+
+type lexofficeVoucherItem struct {
+	Amount         float64 `json:"amount"`
+	TaxAmount      float64 `json:"taxAmount"`
+	TaxRatePercent float64 `json:"taxRatePercent"`
+	CategoryID     string  `json:"categoryId"`
+}
+
+type lexofficeVoucher struct {
+	Type             string                 `json:"type"` // purchaseinvoice: an expense
+	VoucherNumber    string                 `json:"voucherNumber"`
+	VoucherDate      string                 `json:"voucherDate"` // ISO 8601
+	TotalGrossAmount float64                `json:"totalGrossAmount"`
+	TotalTaxAmount   float64                `json:"totalTaxAmount"`
+	TaxType          string                 `json:"taxType"`
+	UseCollective    bool                   `json:"useCollectiveContact"`
+	Remark           string                 `json:"remark"`
+	VoucherItems     []lexofficeVoucherItem `json:"voucherItems"`
+}
+
+// lexofficeRequest calls the lexoffice API and decodes the response into out.
+func lexofficeRequest(cfg *Config, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest(http.MethodPost, lexofficeAPI+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.Accounting.APIKey)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s failed (HTTP %d): %s", path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// postLexoffice creates a purchase voucher booked on the configured category
+// and attaches the document to it.
+func postLexoffice(cfg *Config, v Voucher) error {
+	voucher := lexofficeVoucher{
+		Type:             "purchaseinvoice",
+		VoucherNumber:    v.DocID,
+		VoucherDate:      v.Date.Format("2006-01-02T15:04:05.000-07:00"),
+		TotalGrossAmount: v.Amount,
+		TaxType:          "gross",
+		UseCollective:    true,
+		Remark:           "Reisekosten - " + v.Title,
+		VoucherItems:     []lexofficeVoucherItem{{Amount: v.Amount, CategoryID: v.Category}},
+	}
+	payload, err := json.Marshal(voucher)
+	if err != nil {
+		return err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := lexofficeRequest(cfg, "/vouchers", "application/json", bytes.NewReader(payload), &created); err != nil {
+		return err
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("file", v.File.Filename)
+	if err != nil {
+		return err
+	}
+	fw.Write(v.File.Data)
+	mw.Close()
+	return lexofficeRequest(cfg, "/vouchers/"+created.ID+"/files", mw.FormDataContentType(), &form, nil)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone names on systems without a zoneinfo database
+)
+
+// ---------------------------------------------------------------------------
+// Timezone and Locale
+// ---------------------------------------------------------------------------
+
+// locale sets how amounts and dates are printed.
+type locale struct {
+	decimal  string // decimal separator of amounts
+	grouping string // digit grouping symbol, declared in the GDPdU index
+	date     string // layout of printed dates
+}
+
+// defaultLocale is the locale of the documents without configuration.
+const defaultLocale = "de-DE"
+
+// locales are the supported values of locale.
+var locales = map[string]locale{
+	"de-DE": {decimal: ",", grouping: ".", date: "02.01.2006"},
+	"de-AT": {decimal: ",", grouping: ".", date: "02.01.2006"},
+	"de-CH": {decimal: ".", grouping: "'", date: "02.01.2006"},
+	"en-GB": {decimal: ".", grouping: ",", date: "02/01/2006"},
+	"en-US": {decimal: ".", grouping: ",", date: "01/02/2006"},
+	"fr-FR": {decimal: ",", grouping: " ", date: "02/01/2006"},
+	"nl-NL": {decimal: ",", grouping: ".", date: "02-01-2006"},
+}
+
+// printLocale formats the amounts and dates of the documents, mails and
+// reports. main sets it from the configuration; dates kept in the state,
+// archive and run summary stay DD.MM.YYYY.
+var printLocale = locales[defaultLocale]
+
+// setLocale selects the locale by name, the default for an unknown or empty
+// name.
+func setLocale(name string) {
+	l, ok := locales[name]
+	if !ok {
+		l = locales[defaultLocale]
+	}
+	printLocale = l
+}
+
+// localDate converts a DD.MM.YYYY date to the layout of the locale.
+// Unparsable dates are returned unchanged.
+func localDate(dateString string) string {
+	date, err := time.Parse("02.01.2006", dateString)
+	if err != nil {
+		return dateString
+	}
+	return date.Format(printLocale.date)
+}
+
+// localDecimal replaces the decimal point of a formatted number with the
+// separator of the locale.
+func localDecimal(s string) string {
+	return strings.Replace(s, ".", printLocale.decimal, 1)
+}
+
+// location returns the configured timezone, or the system's local time.
+// Invalid names are rejected by the validation.
+func (c *Config) location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// now returns the current time in the configured timezone, which decides the
+// current month and the dates of cover letters, closings and backups.
+func (c *Config) now() time.Time {
+	return time.Now().In(c.location())
+}
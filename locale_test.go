@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	setLocale("en-US")
+	t.Cleanup(func() { setLocale("") })
+
+	if got := formatAmount(1234.5); got != "1234.50" {
+		t.Errorf("formatAmount() = %q, want 1234.50", got)
+	}
+	if got := withWeekday("02.02.2026", "en"); got != "Mo, 02/02/2026" {
+		t.Errorf("withWeekday() = %q", got)
+	}
+
+	cfg := &Config{Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Abrechnungszeitraum:  02/02/2026 - 02/27/2026", "Fahrkosten \\(100 km x 0.30 EUR\\)", "GESAMTBETRAG:"} {
+		if !strings.Contains(km, want) {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+
+	// The summary keeps its dates for the archive
+	if s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil); s.Customers[0].Dates[0] != "02.02.2026" {
+		t.Errorf("summary date = %q, want 02.02.2026", s.Customers[0].Dates[0])
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	// 25 hours apart: the two zones never share a date
+	east, west := &Config{Timezone: "Pacific/Kiritimati"}, &Config{Timezone: "Pacific/Pago_Pago"}
+	if e, w := east.now(), west.now(); e.Format(isoDate) == w.Format(isoDate) || e.Location().String() != "Pacific/Kiritimati" {
+		t.Errorf("now() = %v and %v", e, w)
+	}
+	if (&Config{}).location() != time.Local {
+		t.Error("location() without timezone is not the local time")
+	}
+
+	_, err := parseConfig("config.yaml", []byte("timezone: Europe/Nowhere\nlocale: xx-XX\ncustomers: []\n"), "")
+	for _, want := range []string{`timezone: unknown timezone "Europe/Nowhere"`, `locale: unknown locale "xx-XX"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseConfig() error = %v, want %q", err, want)
+		}
+	}
+}
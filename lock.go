@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Run Lock
+// ---------------------------------------------------------------------------
+
+// errLocked is returned while another process holds the run lock.
+var errLocked = errors.New("another run is in progress")
+
+// LockFile returns the path of the lock file guarding the state file.
+func (c *Config) LockFile() string {
+	return c.StateFile() + ".lock"
+}
+
+// runLock is an advisory lock on the lock file. The operating system drops
+// it when the process exits, so a crashed run never leaves a stale lock.
+type runLock struct {
+	f *os.File
+}
+
+// acquireLock takes the run lock without waiting. If another process holds
+// it, the error names that process.
+func acquireLock(path string) (*runLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLocked) {
+			holder, _ := os.ReadFile(path)
+			if pid := strings.TrimSpace(string(holder)); pid != "" {
+				return nil, fmt.Errorf("%w (pid %s, lock file %s)", errLocked, pid, path)
+			}
+			return nil, fmt.Errorf("%w (lock file %s)", errLocked, path)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	f.Truncate(0)
+	f.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0)
+	return &runLock{f: f}, nil
+}
+
+// release drops the run lock. The file is kept, removing it would let a
+// waiting process lock a file that is no longer visible to others.
+func (l *runLock) release() {
+	l.f.Truncate(0)
+	unlockFile(l.f)
+	l.f.Close()
+}
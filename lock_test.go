@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLock(t *testing.T) {
+	cfg := &Config{State: filepath.Join(t.TempDir(), "state.json")}
+
+	lock, err := acquireLock(cfg.LockFile())
+	if err != nil {
+		t.Fatalf("acquireLock() error = %v", err)
+	}
+	_, err = acquireLock(cfg.LockFile())
+	if !errors.Is(err, errLocked) || !strings.Contains(err.Error(), "pid ") {
+		t.Errorf("second acquireLock() error = %v, want errLocked with pid", err)
+	}
+
+	lock.release()
+	lock, err = acquireLock(cfg.LockFile())
+	if err != nil {
+		t.Fatalf("acquireLock() after release error = %v", err)
+	}
+	lock.release()
+}
+
+func TestScheduledRunLocked(t *testing.T) {
+	cfg := &Config{State: filepath.Join(t.TempDir(), "state.json")}
+	lock, err := acquireLock(cfg.LockFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.release()
+
+	m := &metrics{}
+	err = scheduledRun(cfg, m, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC))
+	if m.runs != 0 || m.isDone(2026, 2) {
+		t.Errorf("scheduledRun() ran despite lock: runs = %d", m.runs)
+	}
+	if exitCode(err) != exitTempFail {
+		t.Errorf("scheduledRun() error = %v, want the lock error", err)
+	}
+}
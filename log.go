@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// ---------------------------------------------------------------------------
+// Logging
+// ---------------------------------------------------------------------------
+
+// setupLogger installs the default slog logger writing to w.
+// Format is "text" (default) or "json".
+func setupLogger(w io.Writer, format string, level slog.Level) error {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (use text or json)", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// logLevel returns the log level for the --verbose/--quiet flags.
+func logLevel(verbose, quiet bool) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Exit codes following sysexits.h, so that systemd units and timers can
+// tell failures apart (e.g. RestartPreventExitStatus=64 78)
+const (
+	exitFailure  = 1  // the run failed
+	exitUsage    = 64 // invalid command line (EX_USAGE)
+	exitTempFail = 75 // another run holds the lock, or the report waits in the outbox (EX_TEMPFAIL)
+	exitConfig   = 78 // the configuration is missing or invalid (EX_CONFIG)
+)
+
+// exitCode returns the exit status of a failed run.
+func exitCode(err error) int {
+	var queued *QueuedError
+	var cfgErr *ConfigErrors
+	switch {
+	case errors.Is(err, errLocked), errors.As(err, &queued):
+		return exitTempFail
+	case errors.As(err, &cfgErr):
+		return exitConfig
+	default:
+		return exitFailure
+	}
+}
+
+// quietLog holds the log of a run with --quiet-on-success until it is known
+// whether the run fails; nil otherwise.
+var quietLog *deferredLog
+
+// deferredLog buffers log output that is written to w only on failure.
+type deferredLog struct {
+	bytes.Buffer
+	w io.Writer
+}
+
+// logOutput returns the writer of the log: w, or with quietOnSuccess a buffer
+// that reaches w only if the run fails (see fatal), so that journald
+// contains nothing but failures.
+func logOutput(w io.Writer, quietOnSuccess bool) io.Writer {
+	if !quietOnSuccess {
+		return w
+	}
+	quietLog = &deferredLog{w: w}
+	return quietLog
+}
+
+// fatal logs the error and exits with the exit code of err.
+func fatal(msg string, err error) {
+	fatalCode(exitCode(err), msg, err)
+}
+
+// fatalCode logs the error, writes a log held back by --quiet-on-success and
+// exits with code.
+func fatalCode(code int, msg string, err error) {
+	slog.Error(msg, "error", err)
+	if quietLog != nil {
+		quietLog.w.Write(quietLog.Bytes())
+	}
+	os.Exit(code)
+}
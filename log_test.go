@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSetupLogger(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	var buf bytes.Buffer
+	if err := setupLogger(&buf, "json", slog.LevelInfo); err != nil {
+		t.Fatalf("setupLogger() error = %v", err)
+	}
+	slog.Debug("hidden")
+	slog.Info("workdays computed", "workdays", 20)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON log line, got %q", buf.String())
+	}
+	if entry["msg"] != "workdays computed" || entry["workdays"] != float64(20) {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	buf.Reset()
+	setupLogger(&buf, "text", slog.LevelWarn)
+	slog.Info("hidden")
+	slog.Warn("visible")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "msg=visible") {
+		t.Errorf("unexpected text output: %q", buf.String())
+	}
+
+	if err := setupLogger(&buf, "xml", slog.LevelInfo); err == nil {
+		t.Error("setupLogger() expected error for invalid format")
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	if logLevel(true, false) != slog.LevelDebug || logLevel(false, true) != slog.LevelWarn || logLevel(false, false) != slog.LevelInfo {
+		t.Error("logLevel() returned unexpected levels")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("boom"), exitFailure},
+		{fmt.Errorf("%w (pid 42)", errLocked), exitTempFail},
+		{&QueuedError{Path: "outbox/x.eml", Attempts: 3, Err: errors.New("timeout")}, exitTempFail},
+		{&ConfigErrors{Path: "config.yaml"}, exitConfig},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestQuietOnSuccess(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer func() { quietLog = nil }()
+
+	var out bytes.Buffer
+	if w := logOutput(&out, false); w != &out || quietLog != nil {
+		t.Error("logOutput() without --quiet-on-success is not the writer")
+	}
+	setupLogger(logOutput(&out, true), "text", slog.LevelInfo)
+	slog.Info("workdays computed")
+	if out.Len() != 0 || !strings.Contains(quietLog.String(), "workdays computed") {
+		t.Errorf("log written before the run failed: %q", out.String())
+	}
+}
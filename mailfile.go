@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// EML / Maildir Output
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("eml", DelivererFunc(writeEML))
+	registerDeliverer("maildir", DelivererFunc(writeMaildir))
+}
+
+// EMLConfig configures writing the composed message to an .eml file.
+type EMLConfig struct {
+	Dir string `yaml:"dir,omitempty"` // output directory (default: current directory)
+}
+
+// MaildirConfig configures delivering the composed message into a local Maildir.
+type MaildirConfig struct {
+	Path string `yaml:"path"` // Maildir root containing cur/, new/ and tmp/
+}
+
+// composeMessage renders the complete RFC 5322 message including attachments.
+func composeMessage(cfg *Config, m Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buildMessage(cfg, m).WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeEML writes the composed message to <dir>/<subject>.eml.
+func writeEML(cfg *Config, m Mail) error {
+	dir := cfg.EML.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := composeMessage(cfg, m)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, outboxSlug(m.Subject)+".eml")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	slog.Info("message written", "path", path)
+	return nil
+}
+
+// writeMaildir delivers the composed message into the new/ folder of a Maildir,
+// writing to tmp/ first and renaming as required by the Maildir specification.
+func writeMaildir(cfg *Config, m Mail) error {
+	root := cfg.Maildir.Path
+	if root == "" {
+		return permanent(fmt.Errorf("maildir provider requires maildir.path"))
+	}
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(root, sub), 0700); err != nil {
+			return err
+		}
+	}
+
+	data, err := composeMessage(cfg, m)
+	if err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	name := fmt.Sprintf("%d.%d.%s", time.Now().UnixNano(), os.Getpid(), host)
+
+	tmpPath := filepath.Join(root, "tmp", name)
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	newPath := filepath.Join(root, "new", name)
+	if err := os.Rename(tmpPath, newPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	slog.Info("message delivered to maildir", "path", newPath)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Mailgun Delivery
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("mailgun", DelivererFunc(sendMailgun))
+}
+
+// Mailgun API base URLs per region (variables to allow overriding in tests).
+var (
+	mailgunBaseURL   = "https://api.mailgun.net/v3"
+	mailgunEUBaseURL = "https://api.eu.mailgun.net/v3"
+)
+
+// mailgunError formats the error message returned by the Mailgun API.
+func mailgunError(status int, body []byte) error {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Message != "" {
+		msg = resp.Message
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		msg = "invalid API key or domain (" + msg + ")"
+	case http.StatusRequestEntityTooLarge:
+		msg = "message too large (" + msg + ")"
+	}
+	return httpStatusError(status, fmt.Errorf("mailgun: send failed (HTTP %d): %s", status, msg))
+}
+
+// sendMailgun sends the generated PDFs via the Mailgun messages API
+// as a multipart upload.
+func sendMailgun(cfg *Config, m Mail) error {
+	mg := cfg.Mailgun
+	if mg.Domain == "" || mg.APIKey == "" {
+		return permanent(fmt.Errorf("mailgun provider requires domain and apiKey"))
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fields := [][2]string{
+		{"from", cfg.Email.From},
+		{"to", m.recipient(cfg)},
+		{"subject", m.Subject},
+		{"html", m.body()},
+	}
+	for k, v := range mailHeaders(cfg, m) {
+		fields = append(fields, [2]string{"h:" + k, v})
+	}
+	for _, f := range fields {
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+	for _, a := range m.Attachments {
+		part, err := w.CreateFormFile("attachment", a.Filename)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(a.Data); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	baseURL := mailgunBaseURL
+	if strings.EqualFold(mg.Region, "eu") {
+		baseURL = mailgunEUBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/%s/messages", baseURL, url.PathEscape(mg.Domain))
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", mg.APIKey)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailgun: send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return mailgunError(resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Mail Preview
+// ---------------------------------------------------------------------------
+
+// previewMail generates the report of a period and renders the mail that a
+// run would send, without sending, archiving or recording anything: headers,
+// body and the attachments with their sizes, one block per part of a split
+// mail. With output the composed messages are written as .eml files instead;
+// a split mail gets the part number before the extension.
+func previewMail(cfg *Config, p Period, output string, w io.Writer) error {
+	preview := *cfg
+	preview.Simulation = true
+	report, err := generateReport(&preview, p)
+	if err != nil {
+		return err
+	}
+	m, _, err := reportMail(&preview, p, report)
+	if err != nil {
+		return err
+	}
+	parts, err := mailParts(&preview, m)
+	if err != nil {
+		return err
+	}
+
+	for i, pm := range parts {
+		if output == "" {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeMailPreview(&preview, pm, w)
+			continue
+		}
+		data, err := composeMessage(&preview, pm)
+		if err != nil {
+			return err
+		}
+		path := output
+		if i > 0 {
+			ext := filepath.Ext(output)
+			path = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(output, ext), i+1, ext)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return err
+		}
+		slog.Info("mail preview written", "path", path, "attachments", len(pm.Attachments))
+		fmt.Fprintln(w, path)
+	}
+	return nil
+}
+
+// writeMailPreview prints the headers, the body and the attachment list of a
+// mail.
+func writeMailPreview(cfg *Config, m Mail, w io.Writer) {
+	fmt.Fprintf(w, "From: %s\nTo: %s\nSubject: %s\n", cfg.Email.From, m.recipient(cfg), m.Subject)
+	headers := mailHeaders(cfg, m)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s\n", name, headers[name])
+	}
+	fmt.Fprintf(w, "\n%s\n\nAttachments:\n", m.body())
+
+	width := 0
+	for _, a := range m.Attachments {
+		width = max(width, len(a.Filename))
+	}
+	var total int64
+	for _, a := range m.Attachments {
+		fmt.Fprintf(w, "  %-*s  %8s  %s\n", width, a.Filename, formatSize(int64(len(a.Data))), a.ContentType())
+		total += a.encodedSize()
+	}
+	fmt.Fprintf(w, "%d attachments, %s encoded\n", len(m.Attachments), formatSize(total))
+}
+
+// formatSize renders a byte count in KB or MB, e.g. 12.3 KB.
+func formatSize(n int64) string {
+	if n < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/1024/1024)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewMail(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email: EmailConfig{
+			Provider: "eml", From: "me@example.com", To: "boss@example.com",
+			Subject: "Reisekosten {{.Period}} {{.Total}}", Headers: map[string]string{"X-Kostenstelle": "4711"},
+		},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, 2)
+
+	var out bytes.Buffer
+	if err := previewMail(cfg, p, "", &out); err != nil {
+		t.Fatalf("previewMail() error = %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"From: me@example.com\nTo: boss@example.com\nSubject: Reisekosten 02/2026 880,00\n",
+		"Message-ID: <",
+		"X-Kostenstelle: 4711\n",
+		"SHA-256:",
+		"\nAttachments:\n  02_2026_Reisekosten_Kilometergelderstattung.pdf   ",
+		" KB  application/pdf\n",
+		"2 attachments, ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("preview missing %q:\n%s", want, got)
+		}
+	}
+
+	// Nothing is sent, archived or recorded
+	for _, path := range []string{cfg.EML.Dir, cfg.State, cfg.Archive, filepath.Join(dir, defaultAuditFile)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s written by the preview", path)
+		}
+	}
+
+	path := filepath.Join(dir, "preview.eml")
+	out.Reset()
+	if err := previewMail(cfg, p, path, &out); err != nil {
+		t.Fatalf("previewMail() to a file error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.Contains(data, []byte("Subject: Reisekosten 02/2026 880,00")) || !bytes.Contains(data, []byte(`filename="02_2026_Reisekosten_Verpflegungsmehraufwand.pdf"`)) {
+		t.Errorf("preview.eml = %s, %v", data, err)
+	}
+	if strings.TrimSpace(out.String()) != path {
+		t.Errorf("output = %q, want the path", out.String())
+	}
+}
@@ -4,23 +4,53 @@
 //   - Verpflegungsmehraufwand (meal allowance)
 //
 // Workdays are distributed equally among configured customers.
-// The documents are automatically emailed and then deleted locally.
+// The documents are emailed, kept locally, stored in a folder or posted to an
+// accounting system, depending on the delivery setting.
 //
-// Usage: reisekosten [--config path] [M/YYYY]
+// Usage:
+//
+//	reisekosten [--config path] [--profile name] [--verbose|--quiet] [--quiet-on-success] [--log-format text|json] [--json]
+//	            [--skip-days YYYY-MM-DD,...] [--only-days YYYY-MM-DD,...] [--customers ID,...] [--appendix]
+//	            [--order customer|chronological] [--include-unclaimed] [M/YYYY]
+//	reisekosten [options] [--jobs n] M/YYYY-M/YYYY
+//	reisekosten --period quarter|week [options] [Qn/YYYY|KWnn/YYYY]
+//	reisekosten flush|serve|validate [--config path] [--profile name]
+//	reisekosten oneshot [--config path] [--profile name] [--quiet-on-success]
+//	reisekosten install-service|remove-service [--config path] [--profile name]
+//	reisekosten update-rates [--config path] [--profile name]
+//	reisekosten test-mail [--no-send]
+//	reisekosten intake [DIR]
+//	reisekosten annual|kmrate|export-bundle|gdpdu [--output dir] [--overwrite] [YYYY]
+//	reisekosten [options] --output - [--document type] [M/YYYY]
+//	reisekosten [options] --explain [M/YYYY]
+//	reisekosten [options] preview-mail [--output file.eml] [M/YYYY]
+//	reisekosten simulate [--add-customer NAME:KM[:PROVINCE]] [--weights ID=N,...] [--office-share N] [M/YYYY]
+//	reisekosten approve|reject TOKEN
+//	reisekosten prune [--yes]
+//	reisekosten close|reopen M/YYYY
+//	reisekosten diff M/YYYY M/YYYY
+//	reisekosten audit [--json] [M/YYYY|YYYY]
+//	reisekosten backup
+//	reisekosten restore [--output dir] [--overwrite] [NAME]
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/rickar/cal/v2"
 	"github.com/rickar/cal/v2/de"
-	"gopkg.in/yaml.v3"
 )
 
 // ---------------------------------------------------------------------------
@@ -31,7 +61,7 @@ const (
 	// Version
 	version = "1.10.0"
 
-	// Reimbursement rates
+	// Current reimbursement rates, see statutoryRates for earlier years
 	kmRatePerKm     = 0.30 // EUR per kilometer
 	verpflegungRate = 14.0 // 8h < 24h meal allowance
 
@@ -43,6 +73,9 @@ const (
 // monthArgRegex validates command line argument format: M/YYYY or MM/YYYY
 var monthArgRegex = regexp.MustCompile(`^(0?[1-9]|1[0-2])/(20[0-9]{2})$`)
 
+// yearArgRegex validates the year argument of the annual command: YYYY
+var yearArgRegex = regexp.MustCompile(`^20[0-9]{2}$`)
+
 // ---------------------------------------------------------------------------
 // Configuration
 // ---------------------------------------------------------------------------
@@ -52,11 +85,54 @@ type SMTPConfig struct {
 	Port int    `yaml:"port"`
 	User string `yaml:"user"`
 	Pass string `yaml:"pass"`
+
+	// TLS settings
+	TLS                string `yaml:"tls,omitempty"`                // auto (default), starttls, implicit or none
+	CAFile             string `yaml:"caFile,omitempty"`             // PEM bundle of trusted CAs
+	MinTLSVersion      string `yaml:"minTLSVersion,omitempty"`      // 1.0, 1.1, 1.2 (default) or 1.3
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"` // DISCOURAGED: skip certificate verification
 }
 
 type EmailConfig struct {
-	From string `yaml:"from"`
-	To   string `yaml:"to"`
+	Provider string `yaml:"provider,omitempty"` // smtp (default), graph, gmail, sendgrid, mailgun, eml or maildir
+	From     string `yaml:"from"`
+	To       string `yaml:"to"`
+
+	MaxSizeMB int `yaml:"maxSizeMB,omitempty"` // split into several mails above this attachment size (0 = unlimited)
+
+	Subject string `yaml:"subject,omitempty"` // text/template for the report subject, see reportSubject
+
+	ReplyTo string            `yaml:"replyTo,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"` // custom headers, e.g. X-Kostenstelle
+	DSN     []string          `yaml:"dsn,omitempty"`     // SMTP delivery status notifications: success, failure, delay or never
+
+	AttachData []string `yaml:"attachData,omitempty"` // csv and/or json: machine-readable data next to the PDFs
+}
+
+// GraphConfig holds the Azure AD app registration used for Microsoft Graph delivery.
+type GraphConfig struct {
+	TenantID     string `yaml:"tenantId"`
+	ClientID     string `yaml:"clientId"`
+	ClientSecret string `yaml:"clientSecret"`
+}
+
+// GmailConfig holds the OAuth client and refresh token used for Gmail API delivery.
+type GmailConfig struct {
+	ClientID     string `yaml:"clientId"`
+	ClientSecret string `yaml:"clientSecret"`
+	RefreshToken string `yaml:"refreshToken"`
+}
+
+// SendGridConfig holds the API key used for SendGrid delivery.
+type SendGridConfig struct {
+	APIKey string `yaml:"apiKey"`
+}
+
+// MailgunConfig holds the sending domain and API key used for Mailgun delivery.
+type MailgunConfig struct {
+	Domain string `yaml:"domain"`
+	APIKey string `yaml:"apiKey"`
+	Region string `yaml:"region,omitempty"` // "us" (default) or "eu"
 }
 
 // Customer represents a client with trip details.
@@ -66,15 +142,157 @@ type Customer struct {
 	From     string `yaml:"from"`
 	To       string `yaml:"to"`
 	Reason   string `yaml:"reason"`
-	Distance int    `yaml:"distance"` // one-way distance in km
+	Distance int    `yaml:"distance"` // km per trip, round trip or one-way (see distanceMode)
 	Province string `yaml:"province"` // German state abbreviation (e.g., "BW", "BY")
+
+	OfficeDistance int    `yaml:"officeDistance,omitempty"` // distance on trips starting at the office (default: distance)
+	Location       string `yaml:"location,omitempty"`       // "lat,lon" of the destination, matched against GPX tracks
+
+	KmRate      float64     `yaml:"kmRate,omitempty"`      // EUR per km, overrides the default rate
+	PerDiemRate float64     `yaml:"perDiemRate,omitempty"` // EUR per day, overrides the default meal allowance
+	Vehicle     string      `yaml:"vehicle,omitempty"`     // car (default), bike or ebike
+	Ticket      Ticket      `yaml:"ticket,omitempty"`      // public transport: fare per visit day instead of the km
+	Cap         CustomerCap `yaml:"cap,omitempty"`         // contractual limit of the re-billable costs per report
+
+	Schedule Schedule  `yaml:"schedule,omitempty"` // weekdays and weeks of the month the customer is visited
+	Times    TripTimes `yaml:"times,omitempty"`    // departure and return of trips to this customer
+
+	Project    string `yaml:"project,omitempty"`    // project code printed in every entry and exported
+	CostCenter string `yaml:"costCenter,omitempty"` // cost center (Kostenstelle) printed in every entry and exported
+
+	Reasons       []string `yaml:"reasons,omitempty"`       // rotated per visit instead of reason, e.g. kickoff, workshop
+	Note          string   `yaml:"note,omitempty"`          // free text printed in the customer header
+	PurchaseOrder string   `yaml:"purchaseOrder,omitempty"` // PO number (Bestellnummer) printed in the customer header
+	Contract      string   `yaml:"contract,omitempty"`      // contract reference printed in the customer header
+
+	Timesheet bool `yaml:"timesheet,omitempty"` // also generate a Stundennachweis for this customer
+
+	FirstPlaceOfWork bool `yaml:"firstPlaceOfWork,omitempty"` // erste Taetigkeitsstaette: commuting (Entfernungspauschale), not Reisekosten
+
+	LeitwegID string  `yaml:"leitwegId,omitempty"` // public authority: re-bill the trips as XRechnung
+	Address   Address `yaml:"address,omitempty"`   // postal address of the invoice recipient
+}
+
+// visitReason returns the reason of the customer's n-th visit (0-based) in a
+// report: the reasons in turn if configured, otherwise the fixed reason.
+func (c Customer) visitReason(n int) string {
+	if len(c.Reasons) > 0 {
+		return c.Reasons[n%len(c.Reasons)]
+	}
+	return c.Reason
+}
+
+// booking returns the project and cost center line of the customer's
+// entries, or "" if neither is set.
+func (c Customer) booking() string {
+	var parts []string
+	if c.Project != "" {
+		parts = append(parts, "Projekt: "+c.Project)
+	}
+	if c.CostCenter != "" {
+		parts = append(parts, "Kostenstelle: "+c.CostCenter)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// kmRate returns the customer's km rate, or the statutory rate if none is set.
+func (c Customer) kmRate(rates Rates) float64 {
+	if c.KmRate > 0 {
+		return c.KmRate
+	}
+	return rates.KmRate
+}
+
+// perDiemRate returns the customer's meal allowance per day, or the statutory rate.
+func (c Customer) perDiemRate(rates Rates) float64 {
+	if c.PerDiemRate > 0 {
+		return c.PerDiemRate
+	}
+	return rates.PerDiemPartial
 }
 
 type Config struct {
-	SMTP             SMTPConfig  `yaml:"smtp"`
-	Email            EmailConfig `yaml:"email"`
-	Customers        []Customer  `yaml:"customers"`
-	ChristmasWeekOff *bool       `yaml:"christmasWeekOff,omitempty"` // exclude Dec 24, 27-31 (default: true)
+	Delivery         string                 `yaml:"delivery,omitempty"` // none, email, api or storage (default: email if mail is configured, otherwise none)
+	SMTP             SMTPConfig             `yaml:"smtp"`
+	Email            EmailConfig            `yaml:"email"`
+	Graph            GraphConfig            `yaml:"graph,omitempty"`
+	Gmail            GmailConfig            `yaml:"gmail,omitempty"`
+	SendGrid         SendGridConfig         `yaml:"sendgrid,omitempty"`
+	Mailgun          MailgunConfig          `yaml:"mailgun,omitempty"`
+	EML              EMLConfig              `yaml:"eml,omitempty"`
+	Maildir          MaildirConfig          `yaml:"maildir,omitempty"`
+	IMAP             IMAPConfig             `yaml:"imap,omitempty"`
+	PGP              PGPConfig              `yaml:"pgp,omitempty"`
+	Notify           []NotifyConfig         `yaml:"notify,omitempty"`
+	Webhook          WebhookConfig          `yaml:"webhook,omitempty"`
+	Serve            ServeConfig            `yaml:"serve,omitempty"`
+	GRPC             GRPCConfig             `yaml:"grpc,omitempty"`
+	Preflight        PreflightConfig        `yaml:"preflight,omitempty"`
+	Budget           BudgetConfig           `yaml:"budget,omitempty"`
+	Cap              CapConfig              `yaml:"cap,omitempty"`
+	ICS              ICSConfig              `yaml:"ics,omitempty"`
+	Zip              ZipConfig              `yaml:"zip,omitempty"`
+	Approval         ApprovalConfig         `yaml:"approval,omitempty"`
+	Output           OutputConfig           `yaml:"output,omitempty"`
+	Retry            RetryConfig            `yaml:"retry,omitempty"`
+	Outbox           string                 `yaml:"outbox,omitempty"`           // directory for undeliverable messages (default: outbox)
+	Rates            []Rates                `yaml:"rates,omitempty"`            // additional or corrected rates by year
+	RateData         RateDataConfig         `yaml:"rateData,omitempty"`         // signed rate tables downloaded by update-rates
+	Archive          string                 `yaml:"archive,omitempty"`          // directory of report summaries for the annual report (default: archive)
+	ArchiveDocuments bool                   `yaml:"archiveDocuments,omitempty"` // also keep the generated PDFs in the archive
+	Retention        RetentionConfig        `yaml:"retention,omitempty"`
+	Backup           BackupConfig           `yaml:"backup,omitempty"`
+	TaxAdvisor       TaxAdvisorConfig       `yaml:"taxAdvisor,omitempty"`
+	OCR              OCRConfig              `yaml:"ocr,omitempty"`
+	GPX              GPXConfig              `yaml:"gpx,omitempty"`
+	SevDesk          SevDeskConfig          `yaml:"sevDesk,omitempty"`
+	Accounting       AccountingConfig       `yaml:"accounting,omitempty"`
+	XRechnung        XRechnungConfig        `yaml:"xrechnung,omitempty"`
+	Overrides        string                 `yaml:"overrides,omitempty"` // directory of per-month override files (default: overrides)
+	State            string                 `yaml:"state,omitempty"`     // state file (default: reisekosten-state.json)
+	Audit            string                 `yaml:"audit,omitempty"`     // append-only audit log (default: reisekosten-audit.jsonl next to the state file)
+	Customers        []Customer             `yaml:"customers"`
+	ChristmasWeekOff *bool                  `yaml:"christmasWeekOff,omitempty"` // exclude Dec 24, 27-31 (default: true)
+	OfficeShare      int                    `yaml:"officeShare,omitempty"`      // percent of workdays spent in the office without a trip
+	Appendix         bool                   `yaml:"appendix,omitempty"`         // add a page listing the days without a trip and why
+	Overview         bool                   `yaml:"overview,omitempty"`         // add a calendar page of the period before the entries
+	Language         string                 `yaml:"language,omitempty"`         // de (default) or en: weekday names of the entries
+	Locale           string                 `yaml:"locale,omitempty"`           // de-DE (default), en-US, ...: decimal separator and date layout of the documents
+	Timezone         string                 `yaml:"timezone,omitempty"`         // IANA name, e.g. Europe/Berlin, of the current month (default: system time)
+	Order            string                 `yaml:"order,omitempty"`            // customer (default) or chronological: order of the entries
+	Departure        DepartureConfig        `yaml:"departure,omitempty"`        // days on which trips start at the office
+	MapLinks         string                 `yaml:"mapLinks,omitempty"`         // google or osm: link each trip's route to its directions in the PDF
+	Signature        SignatureConfig        `yaml:"signature,omitempty"`        // "Ort, Datum, Unterschrift" block at the end of the documents
+	Form             FormConfig             `yaml:"form,omitempty"`             // employer's expense claim form instead of the text documents
+	DistanceMode     string                 `yaml:"distanceMode,omitempty"`     // roundTrip (default) or oneWay: meaning of the customers' distance
+	HalfDayWeekdays  []string               `yaml:"halfDayWeekdays,omitempty"`  // weekdays of half-day trips: kilometers but no meal allowance
+	Purpose          string                 `yaml:"purpose,omitempty"`          // reimbursement (default) or taxDeduction (Werbungskosten)
+	BikeRate         float64                `yaml:"bikeRate,omitempty"`         // EUR per km of trips by bike or e-bike (default 0, no statutory rate)
+	ActualCosts      map[string]ActualCosts `yaml:"actualCosts,omitempty"`      // vehicle -> annual costs for an individual km rate
+	Times            TripTimes              `yaml:"times,omitempty"`            // departure and return of the trips (default 07:00 - 17:00)
+	EmploymentStart  string                 `yaml:"employmentStart,omitempty"`  // first day of employment (YYYY-MM-DD)
+	EmploymentEnd    string                 `yaml:"employmentEnd,omitempty"`    // last day of employment (YYYY-MM-DD)
+
+	Profile string    `yaml:"-"` // name of the selected profile, empty for the top level
+	Filter  RunFilter `yaml:"-"` // days and customers selected on the command line
+	Draft   bool      `yaml:"-"` // generate previews without an official Beleg-Nr. (approval)
+	Sources []string  `yaml:"-"` // config file and overlays the config was read from (backup)
+
+	Simulation bool `yaml:"-"` // what-if run of simulate: no audit entry
+
+	IncludeUnclaimed bool           `yaml:"includeUnclaimed,omitempty"` // claim days dropped by caps in earlier reports as Nachtraege
+	Weights          map[string]int `yaml:"-"`                          // customer ID -> weight replacing those of the override files (simulate)
+	Downloaded       *rateTable     `yaml:"-"`                          // rate data of update-rates, nil if none is stored
+}
+
+// customerName returns the name of the customer with the given ID, or "".
+func (c *Config) customerName(id string) string {
+	for _, cust := range c.Customers {
+		if cust.ID == id {
+			return cust.Name
+		}
+	}
+	return ""
 }
 
 // ChristmasWeekOffEnabled returns whether the Christmas/New Year week off is enabled.
@@ -83,31 +301,41 @@ func (c *Config) ChristmasWeekOffEnabled() bool {
 	return c.ChristmasWeekOff == nil || *c.ChristmasWeekOff
 }
 
-// findConfigFile searches for the config file in the current directory first,
-// then in the directory of the running executable.
-func findConfigFile(filename string) (string, error) {
-	// Try current directory first
-	if _, err := os.Stat(filename); err == nil {
-		return filename, nil
+// configSearchPaths returns the locations searched for the config file, in order:
+// current directory, executable directory, the user's config directory
+// ($XDG_CONFIG_HOME/reisekosten, default ~/.config/reisekosten; %APPDATA%\reisekosten
+// on Windows) and the system-wide one (/etc/reisekosten; %ProgramData%\reisekosten).
+func configSearchPaths(filename string) []string {
+	paths := []string{filename}
+
+	if exePath, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exePath), filename))
 	}
 
-	// Try executable directory
-	exePath, err := os.Executable()
-	if err == nil {
-		exeDir := filepath.Dir(exePath)
-		exeConfigPath := filepath.Join(exeDir, filename)
-		if _, err := os.Stat(exeConfigPath); err == nil {
-			return exeConfigPath, nil
-		}
+	if configHome := userConfigDir(); configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "reisekosten", filename))
 	}
 
-	return "", fmt.Errorf("config file %q not found in current directory or executable directory", filename)
+	return append(paths, filepath.Join(systemConfigDir(), filename))
 }
 
-// loadConfig reads and parses the YAML configuration file.
+// findConfigFile returns the first existing config file from configSearchPaths.
+func findConfigFile(filename string) (string, error) {
+	paths := configSearchPaths(filename)
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("config file %q not found (searched %s)", filename, strings.Join(paths, ", "))
+}
+
+// loadConfig reads, parses and validates the YAML configuration file.
 // If configPath is non-empty, it uses that path directly.
-// Otherwise, it searches for the file in the current directory and executable directory.
-func loadConfig(filename, configPath string) (*Config, error) {
+// Otherwise, it searches the locations returned by configSearchPaths.
+// Overlays from the config.d directory next to the file are merged in
+// lexical order. A non-empty profile is merged over the top-level settings.
+func loadConfig(filename, configPath, profile string) (*Config, error) {
 	var path string
 	var err error
 
@@ -120,21 +348,18 @@ func loadConfig(filename, configPath string) (*Config, error) {
 		}
 	}
 
-	data, err := os.ReadFile(path)
+	sources, err := readConfigSources(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-
-	if len(cfg.Customers) == 0 {
-		return nil, fmt.Errorf("no customers configured")
+	cfg, err := parseConfigSources(sources, profile)
+	if err != nil {
+		return nil, err
 	}
 
-	return &cfg, nil
+	slog.Debug("config loaded", "path", path, "profile", profile, "customers", len(cfg.Customers))
+	return cfg, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -188,53 +413,913 @@ func getCustomerCalendars(customers []Customer) []*cal.BusinessCalendar {
 // isWorkday checks if a date is a valid workday for expense reporting.
 // Excludes weekends, holidays, and optionally Christmas/New Year week off (Dec 24, 27-31).
 func isWorkday(c *cal.BusinessCalendar, date time.Time, christmasWeekOff bool) bool {
-	if !c.IsWorkday(date) {
-		return false
+	reason, _ := nonWorkdayReason(c, "", date, christmasWeekOff)
+	return reason == ""
+}
+
+// ---------------------------------------------------------------------------
+// Report Generation
+// ---------------------------------------------------------------------------
+
+// Report holds the generated documents and totals of a run.
+type Report struct {
+	Period      Period
+	Workdays    int // days with a trip
+	OfficeDays  int // workdays spent in the office (officeShare)
+	Warnings    []string
+	KmTotal     float64
+	VerpTotal   float64
+	Customers   []CustomerReport
+	KmDocID     string
+	VerpDocID   string
+	Attachments []Attachment
+
+	ExpenseTotal float64 // additional expenses from the month override
+	ExpenseDocID string  // empty if there are no additional expenses
+
+	ActualRates []actualRate // individual km rates of the vehicles with actual costs
+
+	Commutes     []CommuteReport // days at a first place of work (firstPlaceOfWork)
+	CommuteTotal float64         // Entfernungspauschale, not part of Total
+	CommuteDocID string          // empty if there are no commuting days
+
+	Timesheets []Timesheet // hours sheets of customers with timesheet: true
+	Invoices   []Invoice   // XRechnungen of customers with a Leitweg-ID
+
+	Days []dayDecision // every day of the period with the reason it has no trip (--explain)
+
+	Budget *budgetStatus // year-to-date usage of the annual targets, nil without budget
+
+	CarryOver map[string]float64 // customer ID -> EUR over the customer's cap carried to the next month
+
+	Unclaimed  []unclaimedDay // trips dropped by the caps, recorded for a later Nachtrag
+	LateClaims []unclaimedDay // unclaimed days of earlier reports claimed as Nachtraege
+}
+
+// Total returns the sum of all reimbursements in the report. Commuting is
+// not travel expense and is left out.
+func (r *Report) Total() float64 {
+	return r.KmTotal + r.VerpTotal + r.ExpenseTotal
+}
+
+// CustomerReport holds the days assigned to a customer and the resulting amounts.
+type CustomerReport struct {
+	Customer      Customer
+	Dates         []string           // DD.MM.YYYY
+	Distances     []int              // km driven per date, from home or from the office
+	HalfDays      []string           // DD.MM.YYYY dates of half-day trips without meal allowance
+	MealsProvided []string           // DD.MM.YYYY dates with provided meals, without meal allowance
+	Vehicles      []string           // vehicle per date, only if a trip was by bike
+	BikeRate      float64            // km rate of the trips by bike
+	ActualRates   map[string]float64 // vehicle -> individual km rate from actual costs
+	KmRate        float64
+	KmAmount      float64
+	VerpRate      float64
+	VerpAmount    float64
+}
+
+// distance returns the km driven on the n-th date.
+func (c CustomerReport) distance(n int) int {
+	if n < len(c.Distances) {
+		return c.Distances[n]
+	}
+	return c.Customer.Distance
+}
+
+// km returns the km driven on all trips to the customer.
+func (c CustomerReport) km() int {
+	km := 0
+	for n := range c.Dates {
+		km += c.distance(n)
+	}
+	return km
+}
+
+// newTrip returns the trip to customers[ci] on date with the settings of the
+// month's override file and the configured distance.
+func (c *Config) newTrip(customers []Customer, ci int, ov *MonthOverride, date time.Time) tripDay {
+	customer := customers[ci]
+	fromOffice := c.Departure.fromOffice(ov, date)
+	detour := ov.detour(date)
+	distance := customer.tripDistance(fromOffice)*c.distanceLegs() + detour.Km
+	if customer.Ticket.active() {
+		// No km are driven by public transport
+		distance, detour = 0, Detour{}
+	}
+	return tripDay{
+		customer:   ci,
+		date:       formatDate(date.Year(), date.Month(), date.Day()),
+		distance:   distance,
+		fromOffice: fromOffice,
+		detour:     detour,
+		halfDay:    c.halfDay(ov, date),
+		meals:      mealsProvided(ov, date),
+		vehicle:    vehicleOf(ov, customer, date),
+		times:      c.tripTimes(ov, customer, date),
+	}
+}
+
+// generateReport distributes the period's workdays among the customers and
+// creates the PDF documents in memory.
+func generateReport(cfg *Config, p Period) (*Report, error) {
+	if err := cfg.Filter.check(p); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkEmployment(p); err != nil {
+		return nil, err
+	}
+	customers, err := cfg.Filter.selectCustomers(cfg.Customers)
+	if err != nil {
+		return nil, err
+	}
+	customers = cfg.claimable(customers)
+	rates, err := cfg.ratesFor(p.Year)
+	if err != nil {
+		return nil, err
+	}
+	if rates.Actual, err = cfg.actualRates(p.Year); err != nil {
+		return nil, err
+	}
+
+	// Month-specific absences, weights and expenses
+	overrides, err := loadPeriodOverrides(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recorded GPX tracks replace the configured distance
+	var tracks trackLog
+	if cfg.GPX.Dir != "" {
+		if tracks, err = loadTracks(cfg.GPX.Dir, p, cfg.location()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize calendars per customer
+	calendars := getCustomerCalendars(customers)
+	cfg.Downloaded.addHolidays(calendars, customers)
+
+	// Distribute workdays among customers (weighted round-robin, respecting each customer's holidays)
+	weights := func(ov *MonthOverride) []int {
+		w := make([]int, len(customers))
+		for i, c := range customers {
+			w[i] = ov.weight(c.ID)
+			if sw, ok := cfg.Weights[c.ID]; ok {
+				w[i] = sw
+			}
+		}
+		return w
+	}
+	override := overrides.month(p.Start())
+	distributor := newDayDistributor(weights(override))
+
+	var trips []tripDay
+	var days []dayDecision
+	visits := make(weekVisits)
+	office := newOfficeDistributor(cfg.OfficeShare)
+	officeDays := 0
+
+	for date := p.Start(); !date.After(p.End()); date = date.AddDate(0, 0, 1) {
+		if ov := overrides.month(date); ov != override {
+			override = ov
+			distributor.setWeights(weights(override))
+		}
+		customerIdx := pickCustomer(distributor, customers, visits, date)
+		drive, driven := tracks.match(date, customers, cfg.GPX.radius())
+		if driven {
+			// The track shows which customer was visited
+			customerIdx = drive.customer
+			slog.Info("track matched", "date", date.Format(isoDate), "customer", customers[customerIdx].ID, "km", drive.km, "files", drive.files)
+		}
+		day := dayDecision{Date: date}
+		switch {
+		case customerIdx < 0:
+			day.Reason = reasonNoCustomer
+		case override.excluded(date):
+			day.Reason, day.Detail = override.exclusionReason(date)
+		case cfg.Filter.excluded(date):
+			day.Reason = cfg.Filter.exclusionReason(date)
+		case !cfg.employed(date):
+			day.Reason = reasonNotEmployed
+		default:
+			// Check if workday for current customer's province
+			day.Customer = customers[customerIdx].Name
+			day.Reason, day.Detail = nonWorkdayReason(calendars[customerIdx], customers[customerIdx].Province, date, cfg.ChristmasWeekOffEnabled())
+			if day.Reason == reasonHoliday {
+				slog.Info("holiday skipped", "date", date.Format(isoDate), "holiday", day.Detail, "customer", customers[customerIdx].ID)
+			}
+		}
+		if day.Reason == "" && office != nil && !driven && !customers[customerIdx].Schedule.appointment() {
+			// Office days get no trip, except on a customer's appointment day or a
+			// day with a recorded track
+			slot := office.next()
+			office.commit(slot)
+			if slot == 1 {
+				officeDays++
+				day.Reason = reasonOfficeDay
+			}
+		}
+		days = append(days, day)
+		if day.Reason != "" {
+			continue
+		}
+		trip := cfg.newTrip(customers, customerIdx, override, date)
+		if driven {
+			trip.distance, trip.tracks = drive.km+trip.detour.Km, drive.files
+		}
+		trips = append(trips, trip)
+		distributor.commit(customerIdx)
+		visits.add(customerIdx, date)
+	}
+
+	// Forgotten trips of earlier months from the override files, then the
+	// days dropped by the caps of earlier reports follow as Nachtraege, so
+	// the caps drop them first
+	late, err := cfg.lateEntries(customers, overrides, p, trips)
+	if err != nil {
+		return nil, err
+	}
+	trips = append(trips, late...)
+	pending, err := pendingUnclaimed(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	var lateClaims []unclaimedDay
+	if cfg.IncludeUnclaimed {
+		var late []tripDay
+		if late, lateClaims, err = cfg.lateClaims(customers, pending); err != nil {
+			return nil, err
+		}
+		trips = append(trips, late...)
+	}
+
+	// Keep the reimbursement within the customers' and the configured caps;
+	// commuting to a first place of work is no travel expense and not capped
+	expenses := overrides.expenses(p)
+	trips, commutes := splitCommutes(trips, customers)
+	uncapped := trips
+	previous := p.previous()
+	caps := applyCustomerCaps(trips, customers, rates, expenses, carriedOver(cfg, previous), p, previous.Label())
+	trips, expenses = caps.trips, append(caps.carried, expenses...)
+	trips, err = cfg.Cap.apply(trips, customers, rates, expenses)
+	if err != nil {
+		return nil, err
+	}
+	unclaimed := append(droppedTrips(uncapped, caps.trips, customers, p, true), droppedTrips(caps.trips, trips, customers, p, false)...)
+	lateClaims = claimedDays(lateClaims, trips)
+	if w := unclaimedWarning(pending); w != "" && !cfg.IncludeUnclaimed {
+		caps.warnings = append(caps.warnings, w)
+	}
+	kept := make(map[string]bool, len(trips)+len(commutes))
+	for _, t := range trips {
+		kept[t.date] = true
+	}
+	for _, t := range commutes {
+		kept[t.date] = true
+	}
+	for i, d := range days {
+		if d.Reason == "" && !kept[formatDate(d.Date.Year(), d.Date.Month(), d.Date.Day())] {
+			days[i].Reason = reasonCapped
+		}
+	}
+	for _, m := range p.months() {
+		for _, d := range overrides[m.Key()].Detours {
+			if !kept[formatISODate(d.Date)] {
+				slog.Warn("detour on a day without a trip ignored", "date", d.Date, "km", d.Km)
+			}
+		}
+	}
+
+	customerTrips := make(map[int][]tripDay, len(customers))
+	for _, t := range trips {
+		customerTrips[t.customer] = append(customerTrips[t.customer], t)
+	}
+	totalWorkdays := len(trips)
+	var firstDateString, lastDateString string
+	for _, t := range trips {
+		// Nachtraege are not part of the period's date range
+		if t.late {
+			continue
+		}
+		if firstDateString == "" {
+			firstDateString = t.date
+		}
+		lastDateString = t.date
+	}
+	slog.Info("workdays computed", "workdays", totalWorkdays, "office_days", officeDays, "first", firstDateString, "last", lastDateString)
+
+	// Build document blocks for each customer
+	kmBlocks := make([]string, 0, totalWorkdays+len(customers))
+	verpBlocks := make([]string, 0, totalWorkdays+len(customers))
+	var totalKmCost, totalVerpCost float64
+	var customerReports []CustomerReport
+
+	// In chronological order the entries are collected by date and added
+	// after all customer headers
+	chronological := cfg.Order == orderChronological
+	kmEntries := make(map[string]string, totalWorkdays)
+	verpEntries := make(map[string]string, totalWorkdays)
+	// Nachtraege of earlier months follow in their own section
+	var lateTrips []tripDay
+
+	for i, customer := range customers {
+		days := customerTrips[i]
+		if len(days) == 0 {
+			continue
+		}
+
+		// Add customer header as a block
+		kmBlocks = append(kmBlocks, buildCustomerHeader(customer))
+		verpBlocks = append(verpBlocks, buildCustomerHeader(customer))
+
+		// Add entries for each assigned day
+		verpRate, booking, legs := customer.perDiemRate(rates), customer.booking(), cfg.distanceLegs()
+		var dates, halfDays, meals, vehicles []string
+		var distances []int
+		var kmAmount float64
+		bike := false
+		for n, t := range days {
+			dateString, kmRate := t.date, t.kmRate(customer, rates)
+			dates, distances, vehicles = append(dates, dateString), append(distances, t.distance), append(vehicles, t.vehicle)
+			bike = bike || byBike(t.vehicle)
+			kmAmount += t.kmAmount(customer, rates)
+			var start string
+			if t.fromOffice {
+				start = cfg.Departure.start()
+			}
+			var reason string
+			if len(customer.Reasons) > 0 {
+				reason = "Grund: " + customer.visitReason(n)
+			}
+			note := overrides.note(dateString)
+			late := t.lateNote
+			entryDate := withWeekday(dateString, cfg.Language)
+			var customerLine string
+			if chronological || t.late {
+				customerLine = fmt.Sprintf("Kunde: %s) %s", customer.ID, customer.Name)
+			}
+			var half string
+			switch {
+			case t.halfDay:
+				half = halfDayDetail
+				halfDays = append(halfDays, dateString)
+			case t.meals:
+				half = mealsProvidedDetail
+				meals = append(meals, dateString)
+			case !t.perDiem():
+				halfDays = append(halfDays, dateString)
+			}
+			details := append(detourDetails(t.detour, kmRate), cfg.routeDetail(t, customer), trackDetail(t.tracks), vehicleDetail(t.vehicle), half, late, customerLine, start, reason, booking, note)
+			km := buildKilometerEntry(entryDate, t.legs(legs), (t.distance-t.detour.Km)/t.legs(legs), kmRate, details...)
+			if customer.Ticket.active() {
+				km = buildTicketEntry(entryDate, customer.Ticket, half, late, customerLine, start, reason, booking, note)
+			}
+			// Days under 8 hours by their times get no meal allowance entry
+			var verp string
+			switch {
+			case t.halfDay:
+				verp = buildHalfDayEntry(entryDate, late, customerLine, reason, booking, note)
+			case t.meals:
+				verp = buildMealsProvidedEntry(entryDate, t.times, late, customerLine, reason, booking, note)
+			case t.perDiem():
+				verp = buildMealAllowanceEntry(entryDate, t.times, verpRate, late, customerLine, reason, booking, note)
+			}
+			if chronological || t.late {
+				kmEntries[dateString], verpEntries[dateString] = km, verp
+				if t.late {
+					lateTrips = append(lateTrips, t)
+				}
+				continue
+			}
+			kmBlocks = append(kmBlocks, km)
+			if verp != "" {
+				verpBlocks = append(verpBlocks, verp)
+			}
+		}
+
+		// Accumulate costs for this customer
+		kmAmount = roundCents(kmAmount)
+		verpAmount := float64(len(days)-len(halfDays)-len(meals)) * verpRate
+		totalKmCost += kmAmount
+		totalVerpCost += verpAmount
+		cr := CustomerReport{
+			Customer:      customer,
+			Dates:         dates,
+			Distances:     distances,
+			HalfDays:      halfDays,
+			MealsProvided: meals,
+			KmRate:        customer.vehicleRate(vehicleCar, rates),
+			ActualRates:   actualRatesFor(customer, rates.Actual),
+			KmAmount:      kmAmount,
+			VerpRate:      verpRate,
+			VerpAmount:    verpAmount,
+		}
+		if bike {
+			cr.Vehicles, cr.BikeRate = vehicles, rates.BikeRate
+		}
+		customerReports = append(customerReports, cr)
+		slog.Debug("days distributed", "customer", customer.ID, "name", customer.Name, "days", len(days))
+	}
+	if chronological && totalWorkdays > 0 {
+		kmBlocks = append(kmBlocks, buildTripListHeader())
+		verpBlocks = append(verpBlocks, buildTripListHeader())
+		for _, t := range trips {
+			if t.late {
+				continue
+			}
+			kmBlocks = append(kmBlocks, kmEntries[t.date])
+			if verp := verpEntries[t.date]; verp != "" {
+				verpBlocks = append(verpBlocks, verp)
+			}
+		}
+	}
+	if len(lateTrips) > 0 {
+		sort.SliceStable(lateTrips, func(i, j int) bool { return dayOrder(lateTrips[i].date) < dayOrder(lateTrips[j].date) })
+		kmBlocks = append(kmBlocks, buildLateEntriesHeader())
+		verpBlocks = append(verpBlocks, buildLateEntriesHeader())
+		for _, t := range lateTrips {
+			kmBlocks = append(kmBlocks, kmEntries[t.date])
+			if verp := verpEntries[t.date]; verp != "" {
+				verpBlocks = append(verpBlocks, verp)
+			}
+		}
+	}
+
+	// Build document headers
+	kmDocID, verpDocID := cfg.documentID(p), cfg.documentID(p)
+	kmHeader := buildDocumentHeader(kmDocID, p.Label(), lastDateString, firstDateString, lastDateString, cfg.kmTitle(), cfg.purposeLine(), cfg.SevDesk)
+	verpHeader := buildDocumentHeader(verpDocID, p.Label(), lastDateString, firstDateString, lastDateString, "Verpflegungsmehraufwand", cfg.purposeLine(), cfg.SevDesk)
+
+	// Build document footers
+	signature := buildSignatureBlock(cfg.Signature, lastDateString)
+	kmFooter := buildDocumentFooter(totalKmCost) + signature
+	verpFooter := buildDocumentFooter(totalVerpCost) + signature
+
+	// Generate PDFs in memory
+	kmFilename := p.filePrefix() + "_Reisekosten_Kilometergelderstattung.pdf"
+	verpFilename := p.filePrefix() + "_Reisekosten_Verpflegungsmehraufwand.pdf"
+
+	// Optional calendar overview as first page
+	if cfg.Overview {
+		overview := buildMonthOverview(p, days, customers) + "\f"
+		kmHeader, verpHeader = overview+kmHeader, overview+verpHeader
+	}
+
+	// Optional appendix listing the days without a trip
+	var appendix []string
+	if cfg.Appendix {
+		appendix = append(appendix, buildSkippedDaysAppendix(p.Label(), days))
+	}
+
+	kmData, err := createPDF(kmHeader, kmBlocks, kmFooter, appendix...)
+	if err != nil {
+		return nil, err
+	}
+	verpData, err := createPDF(verpHeader, verpBlocks, verpFooter, appendix...)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("documents generated",
+		"km_total", formatAmount(totalKmCost), "verpflegung_total", formatAmount(totalVerpCost),
+		"km_bytes", len(kmData), "verpflegung_bytes", len(verpData))
+
+	report := &Report{
+		Period:      p,
+		Workdays:    totalWorkdays,
+		OfficeDays:  officeDays,
+		KmTotal:     totalKmCost,
+		VerpTotal:   totalVerpCost,
+		Customers:   customerReports,
+		KmDocID:     kmDocID,
+		VerpDocID:   verpDocID,
+		Days:        days,
+		ActualRates: rates.Actual,
+		CarryOver:   caps.carry,
+		Unclaimed:   unclaimed,
+		LateClaims:  lateClaims,
+		Attachments: []Attachment{
+			{Filename: kmFilename, Data: kmData, Type: "Kilometergelderstattung", DocID: kmDocID, Amount: totalKmCost},
+			{Filename: verpFilename, Data: verpData, Type: "Verpflegungsmehraufwand", DocID: verpDocID, Amount: totalVerpCost},
+		},
+	}
+
+	// Additional expenses from the month overrides go into a third document
+	if len(expenses) > 0 {
+		expenseBlocks := make([]string, 0, len(expenses))
+		for _, e := range expenses {
+			expenseBlocks = append(expenseBlocks, buildExpenseEntry(e, cfg.customerName(e.Customer)))
+			report.ExpenseTotal += e.Amount
+		}
+		report.ExpenseDocID = cfg.documentID(p)
+		expenseHeader := buildDocumentHeader(report.ExpenseDocID, p.Label(), lastDateString,
+			formatISODate(expenses[0].Date), formatISODate(expenses[len(expenses)-1].Date), "Reisenebenkosten", cfg.purposeLine(), cfg.SevDesk)
+		expenseData, err := createPDF(expenseHeader, expenseBlocks, buildDocumentFooter(report.ExpenseTotal)+signature)
+		if err != nil {
+			return nil, err
+		}
+		report.Attachments = append(report.Attachments, Attachment{
+			Filename: p.filePrefix() + "_Reisekosten_Reisenebenkosten.pdf",
+			Data:     expenseData,
+			Type:     "Reisenebenkosten",
+			DocID:    report.ExpenseDocID,
+			Amount:   report.ExpenseTotal,
+		})
+		slog.Info("expenses document generated", "expenses", len(expenses), "total", formatAmount(report.ExpenseTotal))
 	}
 
-	// Exclude Christmas/New Year week off (Dec 24 + Dec 27-31)
-	if christmasWeekOff && date.Month() == 12 {
-		day := date.Day()
-		if day == 24 || (day >= 27 && day <= 31) {
-			return false
+	// The employer's expense claim form replaces the text documents
+	if cfg.Form.Template != "" {
+		form, err := createForm(cfg, p, report)
+		if err != nil {
+			return nil, err
+		}
+		form.Type, form.DocID, form.Amount = "Reisekostenformular", kmDocID, report.Total()
+		report.Attachments = []Attachment{form}
+	}
+
+	// Commuting to a first place of work goes into its own document
+	if len(commutes) > 0 {
+		if err := addCommuteDocument(cfg, p, report, commutes, customers, rates, overrides); err != nil {
+			return nil, err
+		}
+	}
+
+	// Hours sheets for customers that require one alongside the expense report
+	for _, c := range customerReports {
+		if !c.Customer.Timesheet || cfg.taxDeduction() {
+			continue
+		}
+		ts, attachment, err := createTimesheet(p, cfg.documentID(p), lastDateString, c)
+		if err != nil {
+			return nil, err
+		}
+		report.Timesheets = append(report.Timesheets, ts)
+		report.Attachments = append(report.Attachments, attachment)
+		slog.Info("timesheet generated", "customer", c.Customer.ID, "hours", ts.Hours)
+	}
+
+	// E-invoices re-billing the trips to public authorities
+	for _, c := range customerReports {
+		if c.Customer.LeitwegID == "" || cfg.taxDeduction() {
+			continue
+		}
+		inv, attachment, err := createInvoice(cfg, p, cfg.documentID(p), c, expenses)
+		if err != nil {
+			return nil, err
+		}
+		report.Invoices = append(report.Invoices, inv)
+		report.Attachments = append(report.Attachments, attachment)
+		slog.Info("xrechnung generated", "customer", c.Customer.ID, "total", formatAmount(inv.Total))
+	}
+
+	if cfg.Budget.enabled() {
+		report.Budget = yearToDate(cfg, p, report)
+	}
+	report.Warnings = append(preflight(cfg, p, customers, report), caps.warnings...)
+	return report, nil
+}
+
+// run generates the report for the given period and delivers it. With an
+// approver configured only a preview is sent; see requestApproval.
+func run(cfg *Config, p Period) (*Report, error) {
+	if err := checkOpen(cfg, p); err != nil {
+		return nil, err
+	}
+	if err := checkOverlap(cfg, p); err != nil {
+		return nil, err
+	}
+	if cfg.Approval.To != "" {
+		return requestApproval(cfg, p)
+	}
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	return report, deliverReport(cfg, p, report)
+}
+
+// reportMail composes the mail of a generated report: the documents with
+// the data files, bundled and encrypted as configured, threaded with the
+// previous mails of the year. A period delivered before is composed as a
+// correction listing the changes.
+func reportMail(cfg *Config, p Period, report *Report) (m Mail, correction bool, err error) {
+	// Machine-readable data for the recipient's import
+	data, err := reportDataAttachments(cfg, p, report)
+	if err != nil {
+		return Mail{}, false, err
+	}
+	attachments := append(report.Attachments[:len(report.Attachments):len(report.Attachments)], data...)
+
+	// Bundle into a password-protected ZIP if configured
+	if cfg.Zip.Password != "" {
+		zipFilename := p.filePrefix() + "_Reisekosten.zip"
+		bundle, err := bundleZip(cfg.Zip.Password, zipFilename, attachments)
+		if err != nil {
+			return Mail{}, false, err
 		}
+		attachments = []Attachment{bundle}
+		slog.Debug("attachments bundled", "file", zipFilename)
 	}
 
-	return true
+	// Encrypt attachments if PGP keys are configured
+	attachments, err = encryptAttachments(cfg, []string{cfg.recipient()}, attachments)
+	if err != nil {
+		return Mail{}, false, err
+	}
+
+	// Thread with the previous mails of the same year
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return Mail{}, false, err
+	}
+	headers := state.threadHeaders(cfg.Email.From, p)
+
+	subject, err := reportSubject(cfg, p, report)
+	if err != nil {
+		return Mail{}, false, err
+	}
+	body := reportBody(newRunSummary(cfg, p, report, nil))
+
+	previous, err := loadArchivedPeriod(cfg, p.Key())
+	if err != nil {
+		slog.Warn("failed to compare with the archived report", "error", err)
+	}
+	if previous != nil {
+		subject = correctionPrefix + subject
+		body = correctionBody(*previous, newRunSummary(cfg, p, report, nil))
+	}
+	return Mail{To: cfg.recipient(), Subject: subject, Headers: headers, Body: body, Attachments: attachments}, previous != nil, nil
+}
+
+// deliverReport sends a generated report, archives it and records its
+// Message-ID for threading. The exporters only run once the report is sent,
+// queued or stored.
+func deliverReport(cfg *Config, p Period, report *Report) error {
+	if err := checkOpen(cfg, p); err != nil {
+		return err
+	}
+	if err := checkOverlap(cfg, p); err != nil {
+		return err
+	}
+	if mode := cfg.DeliveryMode(); mode != deliveryEmail {
+		return storeReport(cfg, p, report, mode)
+	}
+
+	m, correction, err := reportMail(cfg, p, report)
+	if err != nil {
+		return err
+	}
+	if correction {
+		slog.Info("sending correction", "period", p.Label())
+	}
+	slog.Debug("delivering report", "provider", cfg.Email.Provider, "to", m.To, "message_id", m.Headers["Message-ID"])
+	err = deliver(cfg, m)
+	var queued *QueuedError
+	issued := err == nil || errors.As(err, &queued)
+	if issued {
+		auditIssued(cfg, p, report, "")
+	}
+	action := "send"
+	if correction {
+		action = "correction"
+	}
+	audit(cfg, action, p, report, deliveryDetail(cfg.recipient(), err))
+
+	// Archive the totals for the annual report once the mail is sent or queued,
+	// run the configured exporters (e.g. calendar) and tell downstream systems
+	if issued {
+		exportReport(cfg, p, report)
+		summary := newRunSummary(cfg, p, report, err)
+		if aerr := archiveReport(cfg, summary, report.Attachments); aerr != nil {
+			slog.Warn("failed to archive report", "error", aerr)
+		}
+		if cfg.Webhook.URL != "" {
+			if werr := postWebhook(cfg, summary); werr != nil {
+				slog.Warn("webhook failed", "url", cfg.Webhook.URL, "error", werr)
+			}
+		}
+	}
+	if err != nil && queued == nil {
+		return err
+	}
+
+	// A queued mail is recorded as well, so later runs do not issue the
+	// period again while it waits in the outbox
+	state, serr := loadState(cfg.StateFile())
+	if serr != nil {
+		return serr
+	}
+	state.recordMessageID(p, m.Headers["Message-ID"])
+	state.settleUnclaimed(p, report)
+	if queued != nil {
+		state.recordQueued(p, time.Now())
+	}
+	if serr := state.save(cfg.StateFile()); serr != nil {
+		return serr
+	}
+	return err
 }
 
 // ---------------------------------------------------------------------------
 // Main
 // ---------------------------------------------------------------------------
 
-// parseArgs parses command line arguments and returns config path, year, and month.
-func parseArgs() (configPath string, year int, month time.Month) {
-	args := os.Args[1:]
+// commands lists the available subcommands. Without a subcommand the monthly
+// report is generated and sent.
+var commands = map[string]bool{
+	"flush":           true, // deliver messages queued in the outbox
+	"validate":        true, // check the configuration and report all problems
+	"test-mail":       true, // check the mail connection and send a test message
+	"intake":          true, // read receipt images by OCR and propose expense items
+	"simulate":        true, // print the totals of a month for hypothetical inputs, without writing anything
+	"preview-mail":    true, // render the report mail of a month without sending it
+	"serve":           true, // run as a daemon with scheduled reports, /metrics and /healthz
+	"oneshot":         true, // send the previous month's report once it is due, for systemd timers
+	"install-service": true, // install serve mode as a Windows service
+	"remove-service":  true, // remove the Windows service
+	"update-rates":    true, // download the signed statutory rates and holidays
+	"annual":          true, // aggregate the archived months of a year into a PDF/CSV
+	"kmrate":          true, // compare a year's km under the flat rate and the actual vehicle costs
+	"export-bundle":   true, // zip a year's archived documents for the tax advisor
+	"gdpdu":           true, // export a year's trips and documents for a tax audit (GDPdU)
+	"diff":            true, // compare two archived months
+	"audit":           true, // print the audit log
+	"prune":           true, // delete archived reports older than the retention period
+	"close":           true, // mark a month as finalized, refusing further reports
+	"reopen":          true, // allow reports of a closed month again
+	"backup":          true, // upload an encrypted backup of archive, config and state
+	"restore":         true, // download and unpack an encrypted backup
+	"approve":         true, // generate and deliver a report waiting for approval
+	"reject":          true, // discard a report waiting for approval
+}
+
+// cliArgs holds the parsed command line.
+type cliArgs struct {
+	Command        string
+	ConfigPath     string
+	Profile        string
+	Year           int
+	Month          time.Month
+	Current        bool // no period given, the current one is used
+	Verbose        bool
+	Quiet          bool
+	QuietOnSuccess bool // log nothing unless the run fails (systemd timers)
+	LogFormat      string
+	JSON           bool
+	Period         string // month (default), quarter or week
+	Num            int    // quarter or week number with --period
+	SkipDays       string // comma-separated YYYY-MM-DD
+	OnlyDays       string // comma-separated YYYY-MM-DD
+	Customers      string // comma-separated customer IDs
+	ToYear         int    // end of a backfill range M/YYYY-M/YYYY, second month of diff
+	ToMonth        time.Month
+	Jobs           int        // concurrent workers for a backfill range
+	Token          string     // approval token of approve and reject
+	Backup         string     // backup to restore (default: latest)
+	Overwrite      bool       // replace existing output files
+	Yes            bool       // prune without asking for confirmation
+	Output         string     // output directory, "-" streams one document to stdout
+	Document       string     // document type streamed with --output -
+	Explain        bool       // print why each day has a trip and how the amounts are calculated, without sending
+	Appendix       bool       // add the page of days without a trip to the PDFs
+	Order          string     // customer or chronological order of the entries
+	NoSend         bool       // test-mail only verifies the SMTP session
+	Unclaimed      bool       // claim days dropped by caps in earlier reports as Nachtraege
+	Receipts       string     // receipts directory of intake (default: ocr.dir)
+	Simulation     simulation // hypothetical inputs of simulate
+}
+
+// parseArgs parses command line arguments and returns subcommand, config path, year, and month.
+func parseArgs(args []string) cliArgs {
+	var a cliArgs
 
-	// Parse --config flag
+	// Parse flags with values
 	for i := 0; i < len(args); i++ {
-		if args[i] == "--config" && i+1 < len(args) {
-			configPath = args[i+1]
-			// Remove --config and its value from args
-			args = append(args[:i], args[i+2:]...)
-			break
+		switch {
+		case args[i] == "--config" && i+1 < len(args):
+			a.ConfigPath = args[i+1]
+		case args[i] == "--profile" && i+1 < len(args):
+			a.Profile = args[i+1]
+		case args[i] == "--log-format" && i+1 < len(args):
+			a.LogFormat = args[i+1]
+		case args[i] == "--skip-days" && i+1 < len(args):
+			a.SkipDays = args[i+1]
+		case args[i] == "--only-days" && i+1 < len(args):
+			a.OnlyDays = args[i+1]
+		case args[i] == "--customers" && i+1 < len(args):
+			a.Customers = args[i+1]
+		case args[i] == "--period" && i+1 < len(args):
+			a.Period = args[i+1]
+		case args[i] == "--jobs" && i+1 < len(args):
+			a.Jobs, _ = strconv.Atoi(args[i+1])
+		case args[i] == "--output" && i+1 < len(args):
+			a.Output = args[i+1]
+		case args[i] == "--document" && i+1 < len(args):
+			a.Document = args[i+1]
+		case args[i] == "--order" && i+1 < len(args):
+			a.Order = args[i+1]
+		case args[i] == "--add-customer" && i+1 < len(args):
+			a.Simulation.Customer = args[i+1]
+		case args[i] == "--weights" && i+1 < len(args):
+			a.Simulation.Weights = args[i+1]
+		case args[i] == "--office-share" && i+1 < len(args):
+			a.Simulation.OfficeShare = args[i+1]
+		default:
+			continue
 		}
+		// Remove the flag and its value from args
+		args = append(args[:i:i], args[i+2:]...)
+		i--
 	}
 
-	// Parse month/year from remaining args
+	// Parse boolean flags, subcommand and month/year from remaining args
 	for _, arg := range args {
-		if monthArgRegex.MatchString(arg) {
+		switch {
+		case arg == "--verbose":
+			a.Verbose = true
+		case arg == "--quiet" || arg == "-q":
+			a.Quiet = true
+		case arg == "--quiet-on-success":
+			a.QuietOnSuccess = true
+		case arg == "--json":
+			a.JSON = true
+		case arg == "--overwrite":
+			a.Overwrite = true
+		case arg == "--explain":
+			a.Explain = true
+		case arg == "--appendix":
+			a.Appendix = true
+		case arg == "--no-send":
+			a.NoSend = true
+		case arg == "--include-unclaimed":
+			a.Unclaimed = true
+		case arg == "--yes" || arg == "-y":
+			a.Yes = true
+		case a.Command == "" && commands[arg]:
+			a.Command = arg
+		case a.Token == "" && (a.Command == "approve" || a.Command == "reject"):
+			a.Token = arg
+		case a.Backup == "" && a.Command == "restore" && !strings.HasPrefix(arg, "-"):
+			a.Backup = arg
+		case a.Receipts == "" && a.Command == "intake" && !strings.HasPrefix(arg, "-"):
+			a.Receipts = arg
+		case a.Year == 0 && (a.Command == "annual" || a.Command == "kmrate" || a.Command == "export-bundle" || a.Command == "gdpdu" || a.Command == "audit") && yearArgRegex.MatchString(arg):
+			a.Year, _ = strconv.Atoi(arg)
+		case a.Year == 0 && a.Period == periodQuarter && quarterArgRegex.MatchString(arg):
+			m := quarterArgRegex.FindStringSubmatch(arg)
+			a.Num, _ = strconv.Atoi(m[1])
+			a.Year, _ = strconv.Atoi(m[2])
+		case a.Year == 0 && a.Period == periodWeek && weekArgRegex.MatchString(arg):
+			m := weekArgRegex.FindStringSubmatch(arg)
+			a.Num, _ = strconv.Atoi(m[1])
+			a.Year, _ = strconv.Atoi(m[2])
+		case a.Year != 0 && a.ToYear == 0 && a.Command == "diff" && monthArgRegex.MatchString(arg):
 			parts := strings.Split(arg, "/")
-			year, _ = strconv.Atoi(parts[1])
+			a.ToYear, _ = strconv.Atoi(parts[1])
 			m, _ := strconv.Atoi(parts[0])
-			month = time.Month(m)
-			return
+			a.ToMonth = time.Month(m)
+		case a.Year == 0 && (a.Period == "" || a.Period == periodMonth) && monthRangeArgRegex.MatchString(arg):
+			a.Year, a.Month, a.ToYear, a.ToMonth = parseMonthRange(arg)
+		case a.Year == 0 && (a.Period == "" || a.Period == periodMonth) && monthArgRegex.MatchString(arg):
+			parts := strings.Split(arg, "/")
+			a.Year, _ = strconv.Atoi(parts[1])
+			m, _ := strconv.Atoi(parts[0])
+			a.Month = time.Month(m)
 		}
 	}
 
-	// Default to current date
-	year, month, _ = time.Now().Date()
-	return
+	// Default to current date, except for commands that change a booked month
+	if a.Year == 0 && a.Command != "close" && a.Command != "reopen" && a.Command != "diff" && a.Command != "audit" {
+		a.Current = true
+		a.setCurrent(time.Now())
+	}
+	return a
+}
+
+// setCurrent selects the period containing now. main selects it again in
+// the configured timezone once the config is loaded.
+func (a *cliArgs) setCurrent(now time.Time) {
+	a.Year, a.Month, _ = now.Date()
+	if a.Period == periodQuarter || a.Period == periodWeek {
+		p := currentPeriod(a.Period, now)
+		a.Year, a.Num = p.Year, p.Num
+	}
+}
+
+// period returns the report period selected on the command line.
+func (a cliArgs) period() (Period, error) {
+	switch a.Period {
+	case "", periodMonth:
+		return monthPeriod(a.Year, a.Month), nil
+	case periodQuarter:
+		return Period{Kind: periodQuarter, Year: a.Year, Num: a.Num}, nil
+	case periodWeek:
+		if a.Num > weeksInYear(a.Year) {
+			return Period{}, fmt.Errorf("%d has no KW %d", a.Year, a.Num)
+		}
+		return Period{Kind: periodWeek, Year: a.Year, Num: a.Num}, nil
+	}
+	return Period{}, fmt.Errorf("invalid --period %q (use month, quarter or week)", a.Period)
 }
 
 // daysInMonth returns the number of days in the given month.
@@ -252,92 +1337,320 @@ func main() {
 	}
 
 	// Parse command line arguments
-	configPath, year, month := parseArgs()
+	args := parseArgs(os.Args[1:])
+
+	// As a Windows service serve mode runs in the directory of its config
+	// file and logs to a file there
+	logFile := os.Stderr
+	service := args.Command == "serve" && runningAsService()
+	if service {
+		path, err := enterConfigDir(args.ConfigPath)
+		if err != nil {
+			fatal("cannot start service", err)
+		}
+		args.ConfigPath = path
+		if logFile, err = os.OpenFile(serviceLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err != nil {
+			fatal("cannot start service", err)
+		}
+		defer logFile.Close()
+	}
+
+	if err := setupLogger(logOutput(logFile, args.QuietOnSuccess), args.LogFormat, logLevel(args.Verbose, args.Quiet)); err != nil {
+		fatalCode(exitUsage, "invalid arguments", err)
+	}
+
+	// Removing the service needs no configuration
+	if args.Command == "remove-service" {
+		if err := removeService(); err != nil {
+			fatal("service removal failed", err)
+		}
+		return
+	}
 
 	// Load configuration
-	cfg, err := loadConfig("config.yaml", configPath)
+	cfg, err := loadConfig("config.yaml", args.ConfigPath, args.Profile)
+	if args.Command == "validate" {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitConfig)
+		}
+		fmt.Printf("configuration is valid (%d customers)\n", len(cfg.Customers))
+		return
+	}
 	if err != nil {
-		panic(err)
+		fatalCode(exitConfig, "failed to load configuration", err)
 	}
+	setLocale(cfg.Locale)
 
-	// Initialize calendars per customer
-	calendars := getCustomerCalendars(cfg.Customers)
+	if args.Command == "update-rates" {
+		if err := updateRates(cfg, os.Stdout); err != nil {
+			fatal("rate update failed", err)
+		}
+		return
+	}
+	if cfg.Downloaded, err = loadRateTable(cfg); err != nil {
+		fatalCode(exitConfig, "failed to load rate data", err)
+	}
+	if args.Current {
+		args.setCurrent(cfg.now())
+	}
+	year := args.Year
+	cfg.Filter = RunFilter{
+		SkipDays:  splitList(args.SkipDays),
+		OnlyDays:  splitList(args.OnlyDays),
+		Customers: splitList(args.Customers),
+	}
+	cfg.Output.Overwrite = args.Overwrite
+	if args.Appendix {
+		cfg.Appendix = true
+	}
+	if args.Unclaimed {
+		cfg.IncludeUnclaimed = true
+	}
+	if args.Order != "" {
+		if args.Order != orderCustomer && args.Order != orderChronological {
+			fatalCode(exitUsage, "invalid arguments", fmt.Errorf("invalid --order %q (use customer or chronological)", args.Order))
+		}
+		cfg.Order = args.Order
+	}
+	if args.Output != "" && args.Output != "-" {
+		cfg.Output.Dir = args.Output
+	}
 
-	// Distribute workdays among customers (round-robin, respecting each customer's holidays)
-	numDays := daysInMonth(year, month)
-	customerDays := make(map[int][]string, len(cfg.Customers))
-	customerIdx := 0
-	var firstDateString, lastDateString string
-	totalWorkdays := 0
+	// Commands that send reports or update the state run one at a time
+	switch args.Command {
+	case "", "flush", "approve", "reject", "prune", "close", "reopen", "backup":
+		if args.Output == "-" || args.Explain {
+			break
+		}
+		lock, err := acquireLock(cfg.LockFile())
+		if err != nil {
+			fatal("cannot start run", err)
+		}
+		defer lock.release()
+	}
 
-	for day := 1; day <= numDays; day++ {
-		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if args.Command == "flush" {
+		if err := flushOutbox(cfg); err != nil {
+			fatal("flush failed", err)
+		}
+		return
+	}
 
-		// Check if workday for current customer's province
-		if isWorkday(calendars[customerIdx], date, cfg.ChristmasWeekOffEnabled()) {
-			dateString := formatDate(year, month, day)
-			customerDays[customerIdx] = append(customerDays[customerIdx], dateString)
-			if firstDateString == "" {
-				firstDateString = dateString
-			}
-			lastDateString = dateString
-			totalWorkdays++
-			customerIdx = (customerIdx + 1) % len(cfg.Customers)
+	if args.Command == "test-mail" {
+		if err := testMail(cfg, !args.NoSend, os.Stdout); err != nil {
+			fatal("mail test failed", err)
 		}
+		return
 	}
 
-	// Build document blocks for each customer
-	kmBlocks := make([]string, 0, totalWorkdays+len(cfg.Customers))
-	verpBlocks := make([]string, 0, totalWorkdays+len(cfg.Customers))
-	var totalKmCost float64
+	if args.Command == "intake" {
+		if err := intakeReceipts(cfg, args.Receipts, os.Stdout); err != nil {
+			fatal("receipt intake failed", err)
+		}
+		return
+	}
 
-	for i, customer := range cfg.Customers {
-		days := customerDays[i]
-		if len(days) == 0 {
-			continue
+	if args.Command == "annual" {
+		if _, err := generateAnnualReport(cfg, year); err != nil {
+			fatal("annual report failed", err)
 		}
+		return
+	}
 
-		// Add customer header as a block
-		kmBlocks = append(kmBlocks, buildCustomerHeader(customer))
-		verpBlocks = append(verpBlocks, buildCustomerHeader(customer))
+	if args.Command == "kmrate" {
+		if _, err := generateKmRateReport(cfg, year); err != nil {
+			fatal("km rate report failed", err)
+		}
+		return
+	}
 
-		// Add entries for each assigned day
-		for _, dateString := range days {
-			kmBlocks = append(kmBlocks, buildKilometerEntry(dateString, customer.Distance))
-			verpBlocks = append(verpBlocks, buildMealAllowanceEntry(dateString))
+	if args.Command == "export-bundle" {
+		if _, err := exportBundle(cfg, year); err != nil {
+			fatal("export bundle failed", err)
 		}
+		return
+	}
 
-		// Accumulate km cost for this customer
-		totalKmCost += float64(len(days)) * float64(customer.Distance) * kmRatePerKm
+	if args.Command == "gdpdu" {
+		if _, err := exportGDPdU(cfg, year); err != nil {
+			fatal("GDPdU export failed", err)
+		}
+		return
 	}
 
-	// Build document headers
-	kmHeader := buildDocumentHeader(year, month, lastDateString, firstDateString, lastDateString, "Kilometergelderstattung")
-	verpHeader := buildDocumentHeader(year, month, lastDateString, firstDateString, lastDateString, "Verpflegungsmehraufwand")
+	if args.Command == "prune" {
+		if err := prune(cfg, cfg.now(), args.Yes, os.Stdin, os.Stdout); err != nil {
+			fatal("prune failed", err)
+		}
+		return
+	}
 
-	// Build document footers
-	kmFooter := buildDocumentFooter(totalKmCost)
-	verpFooter := buildDocumentFooter(verpflegungRate * float64(totalWorkdays))
+	if args.Command == "audit" {
+		prefix := ""
+		switch {
+		case args.Month != 0:
+			prefix = periodKey(args.Year, args.Month)
+		case args.Year != 0:
+			prefix = strconv.Itoa(args.Year)
+		}
+		if err := printAudit(cfg, prefix, args.JSON, os.Stdout); err != nil {
+			fatal("audit failed", err)
+		}
+		return
+	}
 
-	// Generate PDFs in memory
-	kmFilename := fmt.Sprintf("%02d_%d_Reisekosten_Kilometergelderstattung.pdf", month, year)
-	verpFilename := fmt.Sprintf("%02d_%d_Reisekosten_Verpflegungsmehraufwand.pdf", month, year)
+	if args.Command == "diff" {
+		if args.ToYear == 0 {
+			fatalCode(exitUsage, "invalid arguments", errors.New("diff requires two months (M/YYYY M/YYYY)"))
+		}
+		if err := compareMonths(cfg, monthPeriod(args.Year, args.Month), monthPeriod(args.ToYear, args.ToMonth), os.Stdout); err != nil {
+			fatal("diff failed", err)
+		}
+		return
+	}
 
-	kmData, err := createPDF(kmHeader, kmBlocks, kmFooter)
-	if err != nil {
-		panic(err)
+	if args.Command == "close" || args.Command == "reopen" {
+		if args.Year == 0 {
+			fatalCode(exitUsage, "invalid arguments", fmt.Errorf("%s requires a month (M/YYYY)", args.Command))
+		}
+		p := monthPeriod(args.Year, args.Month)
+		if args.Command == "close" {
+			err = closeMonth(cfg, p, cfg.now())
+		} else {
+			err = reopenMonth(cfg, p)
+		}
+		if err != nil {
+			fatal(args.Command+" failed", err)
+		}
+		return
 	}
-	verpData, err := createPDF(verpHeader, verpBlocks, verpFooter)
+
+	if args.Command == "backup" {
+		name, err := backup(cfg, cfg.now())
+		if err != nil {
+			fatal("backup failed", err)
+		}
+		fmt.Println(name)
+		return
+	}
+
+	if args.Command == "restore" {
+		dir := args.Output
+		if dir == "" {
+			dir = "."
+		}
+		n, err := restore(cfg, args.Backup, dir)
+		if err != nil {
+			fatal("restore failed", err)
+		}
+		fmt.Printf("%d files restored to %s\n", n, dir)
+		return
+	}
+
+	if args.Command == "approve" {
+		period, report, err := approve(cfg, args.Token)
+		if period.Year != 0 {
+			notifyRun(cfg, period, report, err)
+		}
+		if err != nil {
+			fatal("approval failed", err)
+		}
+		return
+	}
+
+	if args.Command == "reject" {
+		if _, err := reject(cfg, args.Token); err != nil {
+			fatal("rejection failed", err)
+		}
+		return
+	}
+
+	if args.Command == "oneshot" {
+		// Started by a timer: like serve, the previous month's report is sent
+		// once it is due and not yet delivered
+		if err := scheduledRun(cfg, &metrics{}, cfg.now()); err != nil {
+			fatal("run failed", err)
+		}
+		return
+	}
+
+	if args.Command == "install-service" {
+		if err := installService(args.ConfigPath, args.Profile); err != nil {
+			fatal("service installation failed", err)
+		}
+		return
+	}
+
+	if args.Command == "serve" {
+		if service {
+			if err := runService(cfg); err != nil {
+				fatal("service failed", err)
+			}
+			return
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := serve(ctx, cfg); err != nil {
+			fatal("serve failed", err)
+		}
+		return
+	}
+
+	if args.ToYear != 0 {
+		periods, err := monthRange(args.Year, args.Month, args.ToYear, args.ToMonth)
+		if err != nil {
+			fatalCode(exitUsage, "invalid arguments", err)
+		}
+		if err := backfill(cfg, periods, args.Jobs, args.JSON); err != nil {
+			fatal("backfill failed", err)
+		}
+		return
+	}
+
+	period, err := args.period()
 	if err != nil {
-		panic(err)
+		fatalCode(exitUsage, "invalid arguments", err)
+	}
+	if args.Command == "simulate" {
+		if err := simulate(cfg, period, args.Simulation, os.Stdout); err != nil {
+			fatal("simulate failed", err)
+		}
+		return
+	}
+	if args.Command == "preview-mail" {
+		if err := previewMail(cfg, period, args.Output, os.Stdout); err != nil {
+			fatal("preview failed", err)
+		}
+		return
+	}
+	if args.Output == "-" {
+		if err := streamDocument(cfg, period, args.Document, os.Stdout); err != nil {
+			fatal("streaming failed", err)
+		}
+		return
+	}
+	if args.Explain {
+		cfg.Draft = true
+		report, err := generateReport(cfg, period)
+		if err != nil {
+			fatal("explain failed", err)
+		}
+		if err := writeExplanation(os.Stdout, report); err != nil {
+			fatal("explain failed", err)
+		}
+		return
 	}
 
-	// Send via email
-	subject := fmt.Sprintf("Deine Reisekostenabrechnung %02d/%d", month, year)
-	if err := sendEmail(cfg, subject,
-		Attachment{Filename: kmFilename, Data: kmData},
-		Attachment{Filename: verpFilename, Data: verpData},
-	); err != nil {
-		panic(err)
+	slog.Info("generating report", "period", period.Label(), "customers", len(cfg.Customers))
+	report, err := run(cfg, period)
+	notifyRun(cfg, period, report, err)
+	if args.JSON {
+		if werr := writeRunSummary(os.Stdout, newRunSummary(cfg, period, report, err)); werr != nil {
+			slog.Warn("failed to write run summary", "error", werr)
+		}
+	}
+	if err != nil {
+		fatal("run failed", err)
 	}
 }
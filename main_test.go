@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -160,7 +161,7 @@ customers:
 `
 		os.WriteFile(configFile, []byte(content), 0644)
 
-		cfg, err := loadConfig("config.yaml", configFile)
+		cfg, err := loadConfig("config.yaml", configFile, "")
 		if err != nil {
 			t.Fatalf("loadConfig() error = %v", err)
 		}
@@ -176,7 +177,7 @@ customers:
 	})
 
 	t.Run("missing file", func(t *testing.T) {
-		_, err := loadConfig("config.yaml", "/nonexistent/config.yaml")
+		_, err := loadConfig("config.yaml", "/nonexistent/config.yaml", "")
 		if err == nil {
 			t.Error("loadConfig() expected error for missing file")
 		}
@@ -187,7 +188,7 @@ customers:
 		configFile := filepath.Join(dir, "config.yaml")
 		os.WriteFile(configFile, []byte("{{invalid yaml"), 0644)
 
-		_, err := loadConfig("config.yaml", configFile)
+		_, err := loadConfig("config.yaml", configFile, "")
 		if err == nil {
 			t.Error("loadConfig() expected error for invalid YAML")
 		}
@@ -202,7 +203,7 @@ customers: []
 `
 		os.WriteFile(configFile, []byte(content), 0644)
 
-		_, err := loadConfig("config.yaml", configFile)
+		_, err := loadConfig("config.yaml", configFile, "")
 		if err == nil {
 			t.Error("loadConfig() expected error for no customers")
 		}
@@ -211,7 +212,11 @@ customers: []
 	t.Run("christmasWeekOff defaults to true", func(t *testing.T) {
 		dir := t.TempDir()
 		configFile := filepath.Join(dir, "config.yaml")
-		content := `customers:
+		content := `email:
+  provider: eml
+  from: user@example.com
+  to: boss@example.com
+customers:
   - id: "1"
     name: Test
     from: A
@@ -222,7 +227,7 @@ customers: []
 `
 		os.WriteFile(configFile, []byte(content), 0644)
 
-		cfg, err := loadConfig("config.yaml", configFile)
+		cfg, err := loadConfig("config.yaml", configFile, "")
 		if err != nil {
 			t.Fatalf("loadConfig() error = %v", err)
 		}
@@ -232,6 +237,28 @@ customers: []
 	})
 }
 
+func TestFindConfigFile(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	paths := configSearchPaths("reisekosten-test.yaml")
+	want := filepath.Join(configHome, "reisekosten", "reisekosten-test.yaml")
+	if paths[0] != "reisekosten-test.yaml" || paths[len(paths)-2] != want || paths[len(paths)-1] != "/etc/reisekosten/reisekosten-test.yaml" {
+		t.Errorf("configSearchPaths() = %v", paths)
+	}
+
+	if _, err := findConfigFile("reisekosten-test.yaml"); err == nil {
+		t.Error("findConfigFile() expected error when no file exists")
+	}
+
+	os.MkdirAll(filepath.Dir(want), 0755)
+	os.WriteFile(want, []byte("customers: []\n"), 0644)
+	got, err := findConfigFile("reisekosten-test.yaml")
+	if err != nil || got != want {
+		t.Errorf("findConfigFile() = %q, %v, want %q", got, err, want)
+	}
+}
+
 func TestCreatePDF(t *testing.T) {
 	header := "Test Header\n"
 	blocks := []string{"Block 1\nLine 2\n", "Block 2\n"}
@@ -260,3 +287,118 @@ func TestCreatePDFEmpty(t *testing.T) {
 		t.Error("createPDF() with empty input returned empty data")
 	}
 }
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected cliArgs
+	}{
+		{"month only", []string{"2/2026"}, cliArgs{Year: 2026, Month: 2}},
+		{"config and month", []string{"--config", "/tmp/c.yaml", "12/2025"}, cliArgs{ConfigPath: "/tmp/c.yaml", Year: 2025, Month: 12}},
+		{"subcommand", []string{"--config", "c.yaml", "flush"}, cliArgs{Command: "flush", ConfigPath: "c.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseArgs(tt.args)
+			if tt.expected.Year == 0 {
+				// Defaults to current month
+				tt.expected.Year, tt.expected.Month, _ = time.Now().Date()
+				tt.expected.Current = true
+			}
+			if got != tt.expected {
+				t.Errorf("parseArgs(%v) = %+v, want %+v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateReport(t *testing.T) {
+	cfg := &Config{Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+	}}
+
+	// February 2026 has 20 weekdays and no public holidays in BW
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 20 {
+		t.Errorf("Workdays = %d, want 20", report.Workdays)
+	}
+	if report.KmTotal != 10*100*kmRatePerKm+10*50*kmRatePerKm {
+		t.Errorf("KmTotal = %v, want 450", report.KmTotal)
+	}
+	if report.VerpTotal != 20*verpflegungRate {
+		t.Errorf("VerpTotal = %v, want 280", report.VerpTotal)
+	}
+	if len(report.Attachments) != 2 || report.Attachments[0].Filename != "02_2026_Reisekosten_Kilometergelderstattung.pdf" {
+		t.Errorf("unexpected attachments: %d", len(report.Attachments))
+	}
+}
+
+func TestGenerateReportCustomerRates(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 33, Province: "BW", KmRate: 0.35},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW", PerDiemRate: 28},
+	}}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	// 10 days each: 10 x 11,55 + 10 x 15,00 km; 10 x 14 + 10 x 28 per diem
+	if formatAmount(report.KmTotal) != "265,50" || formatAmount(report.VerpTotal) != "420,00" {
+		t.Errorf("totals = %s/%s, want 265,50/420,00", formatAmount(report.KmTotal), formatAmount(report.VerpTotal))
+	}
+	if report.Customers[0].KmAmount != 115.5 || report.Customers[1].VerpAmount != 280 {
+		t.Errorf("customer amounts = %v/%v", report.Customers[0].KmAmount, report.Customers[1].VerpAmount)
+	}
+	if entry := buildKilometerEntry("02.02.2026", 1, 33, 0.35, ""); !strings.Contains(entry, "Fahrkosten (33 km x 0,35 EUR)") || !strings.Contains(entry, "11,55 EUR") {
+		t.Errorf("entry does not show customer rate:\n%s", entry)
+	}
+}
+
+func TestVisitReason(t *testing.T) {
+	c := Customer{Reason: "Projektarbeit"}
+	if got := c.visitReason(3); got != "Projektarbeit" {
+		t.Errorf("visitReason() = %q, want fixed reason", got)
+	}
+
+	c.Reasons = []string{"Kickoff", "Sprint Review", "Workshop"}
+	var got []string
+	for n := 0; n < 4; n++ {
+		got = append(got, c.visitReason(n))
+	}
+	if strings.Join(got, ",") != "Kickoff,Sprint Review,Workshop,Kickoff" {
+		t.Errorf("visitReason() = %v", got)
+	}
+	if header := buildCustomerHeader(c); strings.Contains(header, "Grund:") {
+		t.Errorf("header shows fixed reason with rotation:\n%s", header)
+	}
+
+	report := &Report{Customers: []CustomerReport{{Customer: c, Dates: []string{"02.02.2026", "03.02.2026"}}}}
+	if events := tripEvents(report); events[1].Description != "Sprint Review" {
+		t.Errorf("event description = %q, want Sprint Review", events[1].Description)
+	}
+}
+
+func TestParseArgsFlags(t *testing.T) {
+	got := parseArgs([]string{"--verbose", "--log-format", "json", "-q", "3/2026", "--config", "c.yaml", "--json", "--profile", "gmbh", "--skip-days", "2026-03-02,2026-03-03", "--customers", "1,3"})
+	want := cliArgs{Customers: "1,3", ConfigPath: "c.yaml", Profile: "gmbh", SkipDays: "2026-03-02,2026-03-03", Year: 2026, Month: 3, Verbose: true, Quiet: true, LogFormat: "json", JSON: true}
+	if got != want {
+		t.Errorf("parseArgs() = %+v, want %+v", got, want)
+	}
+
+	got = parseArgs([]string{"annual", "2025"})
+	if got.Command != "annual" || got.Year != 2025 {
+		t.Errorf("parseArgs(annual 2025) = %+v", got)
+	}
+
+	got = parseArgs([]string{"export-bundle", "2024"})
+	if got.Command != "export-bundle" || got.Year != 2024 {
+		t.Errorf("parseArgs(export-bundle 2024) = %+v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Checksum Manifest
+// ---------------------------------------------------------------------------
+
+// manifestFilename is the name of the manifest in the archive.
+const manifestFilename = "manifest.json"
+
+// reportManifest lists the documents of a run with their SHA-256 hashes, so
+// recipients and auditors can verify that a PDF is unchanged.
+type reportManifest struct {
+	Period           string            `json:"period"`
+	Generated        time.Time         `json:"generated"`
+	KmTotal          float64           `json:"kmTotal"`
+	VerpflegungTotal float64           `json:"verpflegungTotal"`
+	ExpensesTotal    float64           `json:"expensesTotal"`
+	Total            float64           `json:"total"`
+	Documents        []documentSummary `json:"documents"`
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifestJSON renders the manifest of a run summary.
+func manifestJSON(s runSummary, generated time.Time) ([]byte, error) {
+	m := reportManifest{
+		Period:           s.Period,
+		Generated:        generated,
+		KmTotal:          s.KmTotal,
+		VerpflegungTotal: s.VerpflegungTotal,
+		ExpensesTotal:    s.ExpensesTotal,
+		Total:            s.Total,
+		Documents:        s.Documents,
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// reportBody returns the HTML body of the report mail with the hashes of
+// the attached documents.
+func reportBody(s runSummary) string {
+	var b strings.Builder
+	if s.Purpose == purposeTaxDeduction {
+		b.WriteString(taxDeductionBody)
+	} else {
+		b.WriteString(emailBody)
+	}
+	if s.Budget != nil {
+		fmt.Fprintf(&b, "<br>Jahresbudget %d: %s<br>", s.Budget.Year, s.Budget)
+	}
+	b.WriteString("<br>SHA-256:<br>")
+	for _, d := range s.Documents {
+		fmt.Fprintf(&b, "<code>%s</code> %s<br>", d.SHA256, html.EscapeString(d.Filename))
+	}
+	return b.String()
+}
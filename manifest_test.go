@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Archive:          filepath.Join(dir, "archive"),
+		ArchiveDocuments: true,
+		Overrides:        filepath.Join(dir, "overrides"),
+		Customers:        []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil)
+	if s.Documents[0].SHA256 != sha256Hex(report.Attachments[0].Data) || len(s.Documents[0].SHA256) != 64 {
+		t.Errorf("SHA256 = %q", s.Documents[0].SHA256)
+	}
+	if body := reportBody(s); !strings.Contains(body, "<code>"+s.Documents[1].SHA256+"</code> "+s.Documents[1].Filename) {
+		t.Errorf("reportBody() = %q", body)
+	}
+
+	if err := archiveReport(cfg, s, report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "archive", "2026-02", manifestFilename))
+	if err != nil {
+		t.Fatalf("manifest not archived: %v", err)
+	}
+	var m reportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Period != "2026-02" || m.Total != s.Total || len(m.Documents) != 2 || m.Documents[0].ID != report.KmDocID ||
+		m.Documents[0].SHA256 != s.Documents[0].SHA256 || time.Since(m.Generated) > time.Minute {
+		t.Errorf("manifest = %+v", m)
+	}
+}
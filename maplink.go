@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Map Links
+// ---------------------------------------------------------------------------
+
+// pdfLinkSep separates the text of a line from the URL it links to, see
+// withLink.
+const pdfLinkSep = "\x1f"
+
+// withLink returns a line that createPDF prints as a link to target.
+func withLink(text, target string) string {
+	return text + pdfLinkSep + target
+}
+
+// mapProviders build the directions URL of a trip, selected by mapLinks.
+var mapProviders = map[string]func(from, to string, bike bool) string{
+	"google": googleMapsURL,
+	"osm":    osmDirectionsURL,
+}
+
+// googleMapsURL returns the Google Maps directions from one address to
+// another.
+func googleMapsURL(from, to string, bike bool) string {
+	mode := "driving"
+	if bike {
+		mode = "bicycling"
+	}
+	q := url.Values{"api": {"1"}, "origin": {from}, "destination": {to}, "travelmode": {mode}}
+	return "https://www.google.com/maps/dir/?" + q.Encode()
+}
+
+// osmDirectionsURL returns the OpenStreetMap directions from one address to
+// another.
+func osmDirectionsURL(from, to string, bike bool) string {
+	engine := "fossgis_osrm_car"
+	if bike {
+		engine = "fossgis_osrm_bike"
+	}
+	q := url.Values{"engine": {engine}, "from": {from}, "to": {to}}
+	return "https://www.openstreetmap.org/directions?" + q.Encode()
+}
+
+// routeDetail returns the entry line of the trip's route, linked to its
+// directions on the configured map so the distance can be checked. It
+// returns "" without mapLinks and for trips from an office without address.
+func (c *Config) routeDetail(t tripDay, customer Customer) string {
+	directions, ok := mapProviders[c.MapLinks]
+	if !ok {
+		return ""
+	}
+	from := customer.From
+	if t.fromOffice {
+		from = c.Departure.Office
+	}
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(customer.To) == "" {
+		return ""
+	}
+	text := "Route: " + umlautReplacer.Replace(from) + " -> " + umlautReplacer.Replace(customer.To)
+	return withLink(text, directions(from, customer.To, byBike(t.vehicle)))
+}
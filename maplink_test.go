@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMapProviders(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{googleMapsURL("Hauptstr. 1, Stuttgart", "Karlsruhe", false), "https://www.google.com/maps/dir/?api=1&destination=Karlsruhe&origin=Hauptstr.+1%2C+Stuttgart&travelmode=driving"},
+		{googleMapsURL("Stuttgart", "Karlsruhe", true), "https://www.google.com/maps/dir/?api=1&destination=Karlsruhe&origin=Stuttgart&travelmode=bicycling"},
+		{osmDirectionsURL("Stuttgart", "Karlsruhe", false), "https://www.openstreetmap.org/directions?engine=fossgis_osrm_car&from=Stuttgart&to=Karlsruhe"},
+		{osmDirectionsURL("Stuttgart", "Karlsruhe", true), "https://www.openstreetmap.org/directions?engine=fossgis_osrm_bike&from=Stuttgart&to=Karlsruhe"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("URL = %s, want %s", tt.got, tt.want)
+		}
+	}
+}
+
+func TestRouteDetail(t *testing.T) {
+	customer := Customer{From: "Stuttgart", To: "Muenchen"}
+	cfg := &Config{}
+	if got := cfg.routeDetail(tripDay{}, customer); got != "" {
+		t.Errorf("routeDetail() without mapLinks = %q", got)
+	}
+
+	cfg.MapLinks = "osm"
+	if got := cfg.routeDetail(tripDay{}, customer); got != withLink("Route: Stuttgart -> Muenchen", osmDirectionsURL("Stuttgart", "Muenchen", false)) {
+		t.Errorf("routeDetail() = %q", got)
+	}
+
+	// Trips from the office start at its address, if known
+	if got := cfg.routeDetail(tripDay{fromOffice: true}, customer); got != "" {
+		t.Errorf("routeDetail() from an office without address = %q", got)
+	}
+	cfg.Departure.Office = "Koenigstr. 10, Stuttgart"
+	if got := cfg.routeDetail(tripDay{fromOffice: true}, customer); !strings.HasPrefix(got, "Route: Koenigstr. 10, Stuttgart -> Muenchen"+pdfLinkSep) {
+		t.Errorf("routeDetail() from the office = %q", got)
+	}
+}
+
+func TestMapLinksInPDF(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		MapLinks:  "google",
+		Customers: []Customer{{ID: "1", Name: "Acme", From: "Stuttgart", To: "Karlsruhe", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	km := report.Attachments[0].Data
+	if n := strings.Count(pdfText(t, km), "(    Route: Stuttgart -> Karlsruhe)Tj"); n != 20 {
+		t.Errorf("PDF has %d route lines, want one per trip", n)
+	}
+	// Counted before the tagging update, which writes the pages again
+	if n := bytes.Count(km[:bytes.Index(km, []byte("%%EOF"))], []byte("/URI (https://www.google.com/maps/dir/?api=1&destination=Karlsruhe&origin=Stuttgart&travelmode=driving)")); n != 20 {
+		t.Errorf("PDF has %d map links, want one per trip", n)
+	}
+	if bytes.Contains(report.Attachments[1].Data, []byte("/URI")) {
+		t.Errorf("Verpflegungsmehraufwand has map links")
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Provided Meals
+// ---------------------------------------------------------------------------
+
+// mealsProvided reports whether the meals of the trip on date were provided,
+// e.g. full catering at a conference: the trip claims the kilometers but no
+// meal allowance. These days are listed in mealsProvided of the month's
+// override file.
+func mealsProvided(ov *MonthOverride, date time.Time) bool {
+	return contains(ov.MealsProvided, date.Format(isoDate))
+}
+
+// mealsProvidedDetail marks the kilometer entry of a trip with provided meals.
+const mealsProvidedDetail = "Verpflegung gestellt (kein Verpflegungsmehraufwand)"
+
+// buildMealsProvidedEntry creates the meal allowance entry of a trip with
+// provided meals, which lists the day without an amount. Non-empty details
+// are printed below.
+func buildMealsProvidedEntry(dateString string, times TripTimes, details ...string) string {
+	var b strings.Builder
+
+	label := "Verpflegung gestellt"
+	b.WriteString(fmt.Sprintf("  %s  (%s)\n", dateString, times))
+	b.WriteString(fmt.Sprintf("    %s%s\n", label, rightAlign(formatAmount(0)+" EUR", 45-len(label))))
+	writeEntryDetails(&b, details)
+
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMealsProvided(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("mealsProvided: [2026-02-12, 2026-02-13]\n"), 0644)
+	cfg := &Config{
+		Overrides: dir,
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	c := report.Customers[0]
+	if len(c.MealsProvided) != 2 || c.fullDays() != 18 || len(c.HalfDays) != 0 {
+		t.Errorf("meals provided = %v, half days = %v", c.MealsProvided, c.HalfDays)
+	}
+	if report.KmTotal != 600 || report.VerpTotal != 18*verpflegungRate {
+		t.Errorf("KmTotal = %v, VerpTotal = %v, want 600 and 252", report.KmTotal, report.VerpTotal)
+	}
+	if !strings.Contains(pdfText(t, report.Attachments[0].Data), "Verpflegung gestellt \\(kein Verpflegungsmehraufwand\\)") {
+		t.Errorf("Kilometergelderstattung does not mark the days with provided meals")
+	}
+	if verp := pdfText(t, report.Attachments[1].Data); !strings.Contains(verp, "Do, 12.02.2026  \\(07:00 - 17:00\\)") || !strings.Contains(verp, "Verpflegung gestellt ") {
+		t.Errorf("Verpflegungsmehraufwand does not list the days with provided meals")
+	}
+	if s := newRunSummary(cfg, p, report, nil); len(s.Customers[0].MealsProvided) != 2 || s.Customers[0].VerpflegungAmount != 252 {
+		t.Errorf("summary meals provided = %v, amount = %v", s.Customers[0].MealsProvided, s.Customers[0].VerpflegungAmount)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Late Entries (Nachtraege)
+// ---------------------------------------------------------------------------
+
+// LateEntry adds a forgotten trip of an earlier month to the report, printed
+// with its original date in the Nachtraege section instead of correcting the
+// earlier report.
+type LateEntry struct {
+	Date     string `yaml:"date"`           // YYYY-MM-DD, before the month of the override file
+	Customer string `yaml:"customer"`       // customer ID
+	Note     string `yaml:"note,omitempty"` // e.g. Beleg nachgereicht
+}
+
+// lateEntryDetail returns the entry line of a late entry.
+func lateEntryDetail(e LateEntry) string {
+	if e.Note == "" {
+		return "Nachtrag"
+	}
+	return "Nachtrag: " + umlautReplacer.Replace(e.Note)
+}
+
+// lateTrip returns the trip to customers[ci] on a date of an earlier month
+// with the settings of that month's override file, read once into loaded.
+func (c *Config) lateTrip(customers []Customer, ci int, date time.Time, loaded map[string]*MonthOverride) (tripDay, error) {
+	key := periodKey(date.Year(), date.Month())
+	ov, ok := loaded[key]
+	if !ok {
+		var err error
+		if ov, err = loadMonthOverride(c, date.Year(), date.Month()); err != nil {
+			return tripDay{}, err
+		}
+		loaded[key] = ov
+	}
+	t := c.newTrip(customers, ci, ov, date)
+	t.late = true
+	return t, nil
+}
+
+// lateEntries returns the late entries of the period's override files as
+// trips. Entries of customers not in the run are left out, as are entries
+// dated within the period or on a day that already has a trip. An entry on a
+// day the archived report of its month already claims is an error.
+func (c *Config) lateEntries(customers []Customer, overrides periodOverrides, p Period, trips []tripDay) ([]tripDay, error) {
+	index := make(map[string]int, len(customers))
+	for i, cust := range customers {
+		index[cust.ID] = i
+	}
+	taken := make(map[string]bool, len(trips))
+	for _, t := range trips {
+		taken[t.date] = true
+	}
+	loaded := make(map[string]*MonthOverride)
+	var late []tripDay
+	for _, m := range p.months() {
+		for _, e := range overrides[m.Key()].LateEntries {
+			ci, ok := index[e.Customer]
+			if !ok {
+				continue
+			}
+			date, _ := time.Parse(isoDate, e.Date)
+			day := formatDate(date.Year(), date.Month(), date.Day())
+			if !date.Before(p.Start()) || taken[day] {
+				slog.Warn("late entry on a day of the report ignored", "date", e.Date, "customer", e.Customer)
+				continue
+			}
+			archived, err := loadArchivedMonth(c, date.Year(), date.Month())
+			if err != nil {
+				return nil, err
+			}
+			if archived != nil {
+				if _, ok := summaryDays(*archived)[day]; ok {
+					return nil, fmt.Errorf("late entry %s: the report %s already claims a trip on that day", e.Date, monthPeriod(date.Year(), date.Month()).Label())
+				}
+			}
+			t, err := c.lateTrip(customers, ci, date, loaded)
+			if err != nil {
+				return nil, err
+			}
+			t.lateNote = lateEntryDetail(e)
+			late = append(late, t)
+			taken[day] = true
+		}
+	}
+	sort.SliceStable(late, func(i, j int) bool { return dayOrder(late[i].date) < dayOrder(late[j].date) })
+	return late, nil
+}
+
+// buildLateEntriesHeader separates the Nachtraege of earlier months from the
+// entries of the period.
+func buildLateEntriesHeader() string {
+	return lineSingle + "\nNachtraege aus Vormonaten\n" + lineSingle + "\n\n"
+}
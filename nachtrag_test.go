@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLateEntries(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-03.yaml"), []byte(`lateEntries:
+  - date: 2026-02-27
+    customer: "1"
+    note: Beleg nachgereicht
+`), 0644)
+	cfg := &Config{Overrides: dir, Archive: filepath.Join(dir, "archive"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	// The Nachtrag adds a day and keeps the period's date range
+	report, err := generateReport(cfg, monthPeriod(2026, 3))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 23 || report.KmTotal != 23*30 || report.Customers[0].Dates[22] != "27.02.2026" {
+		t.Errorf("workdays = %d, km = %v", report.Workdays, report.KmTotal)
+	}
+	for _, a := range report.Attachments[:2] {
+		text := pdfText(t, a.Data)
+		section := strings.Index(text, "Nachtraege aus Vormonaten")
+		if section < 0 || strings.Index(text, "31.03.2026") > section || !strings.Contains(text[section:], "Fr, 27.02.2026") ||
+			!strings.Contains(text[section:], "Kunde: 1\\) Acme") || !strings.Contains(text[section:], "Nachtrag: Beleg nachgereicht") {
+			t.Errorf("%s misses the Nachtraege section", a.Filename)
+		}
+		if !strings.Contains(text, "Abrechnungszeitraum:  02.03.2026 - 31.03.2026") {
+			t.Errorf("%s: period changed by the Nachtrag", a.Filename)
+		}
+	}
+
+	// A day the archived report already claims cannot be added again
+	feb := monthPeriod(2026, 2)
+	if report, err = generateReport(cfg, feb); err != nil {
+		t.Fatalf("generateReport(February) error = %v", err)
+	}
+	if err := archiveReport(cfg, newRunSummary(cfg, feb, report, nil), report.Attachments); err != nil {
+		t.Fatalf("archiveReport() error = %v", err)
+	}
+	if _, err := generateReport(cfg, monthPeriod(2026, 3)); err == nil || !strings.Contains(err.Error(), "already claims") {
+		t.Errorf("generateReport(claimed day) error = %v", err)
+	}
+
+	// Late entries must be dated before the month of the override file
+	os.WriteFile(filepath.Join(dir, "2026-03.yaml"), []byte("lateEntries:\n  - date: 2026-03-02\n    customer: \"9\"\n"), 0644)
+	_, err = loadMonthOverride(cfg, 2026, 3)
+	if err == nil || !strings.Contains(err.Error(), "not before 03/2026") || !strings.Contains(err.Error(), "unknown customer") {
+		t.Errorf("loadMonthOverride() error = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Run Notifications
+// ---------------------------------------------------------------------------
+
+// pushoverURL is the Pushover message endpoint (variable for tests).
+var pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// NotifyConfig describes one notification target for run results.
+type NotifyConfig struct {
+	Type  string `yaml:"type"`            // ntfy, pushover, slack or teams
+	URL   string `yaml:"url,omitempty"`   // ntfy topic URL or Slack/Teams webhook URL
+	Token string `yaml:"token,omitempty"` // ntfy access token or Pushover application token
+	User  string `yaml:"user,omitempty"`  // Pushover user key
+	On    string `yaml:"on,omitempty"`    // always (default) or failure
+}
+
+// runStatus is the short notification text for a run.
+type runStatus struct {
+	Title   string
+	Message string
+	Failed  bool
+}
+
+// deliveredTitles end the notification title of a successful run, by
+// delivery mode.
+var deliveredTitles = map[string]string{
+	deliveryNone:    "erstellt",
+	deliveryEmail:   "versendet",
+	deliveryAPI:     "uebermittelt",
+	deliveryStorage: "gespeichert",
+}
+
+// newRunStatus summarizes the result of a run. The title says what happened
+// to the report: sent for approval or delivered in the configured mode.
+func newRunStatus(cfg *Config, p Period, report *Report, runErr error) runStatus {
+	period := p.Label()
+	if runErr != nil {
+		return runStatus{
+			Title:   "Reisekosten " + period + " fehlgeschlagen",
+			Message: runErr.Error(),
+			Failed:  true,
+		}
+	}
+	msg := fmt.Sprintf("%d Tage, Kilometergeld %s EUR, Verpflegung %s EUR", report.Workdays,
+		formatAmount(report.KmTotal), formatAmount(report.VerpTotal))
+	if report.ExpenseTotal > 0 {
+		msg += fmt.Sprintf(", Nebenkosten %s EUR", formatAmount(report.ExpenseTotal))
+	}
+	done := deliveredTitles[cfg.DeliveryMode()]
+	if report.KmDocID == draftDocumentID {
+		done = "zur Freigabe versendet"
+	}
+	return runStatus{
+		Title:   "Reisekosten " + period + " " + done,
+		Message: msg + fmt.Sprintf(", gesamt %s EUR", formatAmount(report.Total())),
+	}
+}
+
+// notifyRun posts the run result to all configured notification targets.
+// Notification failures are only reported as warnings.
+func notifyRun(cfg *Config, p Period, report *Report, runErr error) {
+	status := newRunStatus(cfg, p, report, runErr)
+	for _, n := range cfg.Notify {
+		if n.On == "failure" && !status.Failed {
+			continue
+		}
+		if err := sendNotification(n, status); err != nil {
+			slog.Warn("notification failed", "type", n.Type, "error", err)
+			continue
+		}
+		slog.Debug("notification sent", "type", n.Type)
+	}
+}
+
+// sendNotification delivers a status message to a single target.
+func sendNotification(n NotifyConfig, s runStatus) error {
+	var req *http.Request
+	var err error
+
+	switch n.Type {
+	case "ntfy":
+		req, err = http.NewRequest(http.MethodPost, n.URL, strings.NewReader(s.Message))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Title", s.Title)
+		req.Header.Set("Tags", "white_check_mark")
+		if s.Failed {
+			req.Header.Set("Priority", "high")
+			req.Header.Set("Tags", "warning")
+		}
+		if n.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+n.Token)
+		}
+
+	case "pushover":
+		form := url.Values{
+			"token":   {n.Token},
+			"user":    {n.User},
+			"title":   {s.Title},
+			"message": {s.Message},
+		}
+		if s.Failed {
+			form.Set("priority", "1")
+		}
+		req, err = http.NewRequest(http.MethodPost, pushoverURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	case "slack", "teams":
+		payload := map[string]string{"text": fmt.Sprintf("*%s*\n%s", s.Title, s.Message)}
+		if n.Type == "teams" {
+			payload = map[string]string{"title": s.Title, "text": s.Message}
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		req, err = http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+	default:
+		return fmt.Errorf("unknown notification type %q", n.Type)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
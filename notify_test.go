@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewRunStatus(t *testing.T) {
+	report := &Report{Workdays: 20, KmTotal: 600, VerpTotal: 280}
+
+	cfg := &Config{Email: EmailConfig{Provider: "eml"}}
+	s := newRunStatus(cfg, monthPeriod(2026, 2), report, nil)
+	if s.Failed || s.Title != "Reisekosten 02/2026 versendet" {
+		t.Errorf("unexpected status: %+v", s)
+	}
+	if !strings.Contains(s.Message, "20 Tage") || !strings.Contains(s.Message, "gesamt 880,00 EUR") {
+		t.Errorf("Message = %q", s.Message)
+	}
+
+	// The title follows the delivery mode and a pending approval
+	for mode, want := range map[string]string{deliveryNone: "erstellt", deliveryStorage: "gespeichert", deliveryAPI: "uebermittelt"} {
+		cfg := &Config{Delivery: mode}
+		if s := newRunStatus(cfg, monthPeriod(2026, 2), report, nil); s.Title != "Reisekosten 02/2026 "+want {
+			t.Errorf("%s: Title = %q", mode, s.Title)
+		}
+	}
+	preview := &Report{Workdays: 20, KmDocID: draftDocumentID}
+	if s := newRunStatus(cfg, monthPeriod(2026, 2), preview, nil); s.Title != "Reisekosten 02/2026 zur Freigabe versendet" {
+		t.Errorf("preview: Title = %q", s.Title)
+	}
+
+	s = newRunStatus(cfg, monthPeriod(2026, 2), nil, errors.New("smtp: connection refused"))
+	if !s.Failed || s.Message != "smtp: connection refused" {
+		t.Errorf("unexpected failure status: %+v", s)
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	old := pushoverURL
+	pushoverURL = srv.URL
+	defer func() { pushoverURL = old }()
+
+	failed := runStatus{Title: "Reisekosten 02/2026 fehlgeschlagen", Message: "timeout", Failed: true}
+
+	t.Run("ntfy", func(t *testing.T) {
+		if err := sendNotification(NotifyConfig{Type: "ntfy", URL: srv.URL}, failed); err != nil {
+			t.Fatal(err)
+		}
+		if gotHeader.Get("Title") != failed.Title || gotHeader.Get("Priority") != "high" || gotBody != "timeout" {
+			t.Errorf("unexpected request: %v %q", gotHeader, gotBody)
+		}
+	})
+
+	t.Run("pushover", func(t *testing.T) {
+		if err := sendNotification(NotifyConfig{Type: "pushover", Token: "app", User: "usr"}, failed); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(gotBody, "token=app") || !strings.Contains(gotBody, "priority=1") {
+			t.Errorf("unexpected body: %q", gotBody)
+		}
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		if err := sendNotification(NotifyConfig{Type: "slack", URL: srv.URL}, failed); err != nil {
+			t.Fatal(err)
+		}
+		var payload map[string]string
+		json.Unmarshal([]byte(gotBody), &payload)
+		if payload["text"] != "*Reisekosten 02/2026 fehlgeschlagen*\ntimeout" {
+			t.Errorf("unexpected payload: %v", payload)
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		if err := sendNotification(NotifyConfig{Type: "fax"}, failed); err == nil {
+			t.Error("expected error for unknown type")
+		}
+	})
+}
+
+func TestNotifyRunOnFailureOnly(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+	defer srv.Close()
+
+	cfg := &Config{Notify: []NotifyConfig{{Type: "ntfy", URL: srv.URL, On: "failure"}}}
+
+	notifyRun(cfg, monthPeriod(2026, 2), &Report{}, nil)
+	if calls != 0 {
+		t.Errorf("notification sent on success, calls = %d", calls)
+	}
+	notifyRun(cfg, monthPeriod(2026, 2), nil, errors.New("boom"))
+	if calls != 1 {
+		t.Errorf("notification not sent on failure, calls = %d", calls)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// OAuth / HTTP API Helpers
+// ---------------------------------------------------------------------------
+
+// httpClient is shared by all HTTP-based delivery backends.
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+// fetchAccessToken requests an OAuth 2.0 access token from the given token endpoint.
+func fetchAccessToken(tokenURL string, form url.Values) (string, error) {
+	resp, err := httpClient.PostForm(tokenURL, form)
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		msg := strings.TrimSpace(token.Error + " " + token.ErrorDescription)
+		return "", httpStatusError(resp.StatusCode, fmt.Errorf("token request failed (HTTP %d): %s", resp.StatusCode, msg))
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// OCR API
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerOCR("api", OCRFunc(apiOCR))
+}
+
+// apiOCR posts a receipt image to the OCR service at ocr.url and returns the
+// recognized text: the text field of a JSON response or a plain text body.
+func apiOCR(cfg *Config, path string) (string, error) {
+	if cfg.OCR.URL == "" {
+		return "", errors.New("ocr.url is not configured")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, cfg.OCR.URL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	if cfg.OCR.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.OCR.APIKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var out struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(body, &out); err != nil {
+			return "", fmt.Errorf("invalid response: %w", err)
+		}
+		return out.Text, nil
+	}
+	return string(body), nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Retry and Outbox
+// ---------------------------------------------------------------------------
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryDelay    = 5 * time.Second
+	defaultOutboxDir     = "outbox"
+)
+
+// sleep is replaced in tests to avoid real backoff delays.
+var sleep = time.Sleep
+
+// RetryConfig controls how often delivery is retried before the message is queued.
+type RetryConfig struct {
+	Attempts int           `yaml:"attempts,omitempty"` // total delivery attempts (default: 3)
+	Delay    time.Duration `yaml:"delay,omitempty"`    // initial backoff delay, doubled per attempt (default: 5s)
+}
+
+// outboxMessage is a fully built message persisted for later delivery.
+type outboxMessage struct {
+	Mail
+	Created   time.Time `json:"created"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// QueuedError reports that delivery failed and the message was saved to the outbox.
+type QueuedError struct {
+	Path     string
+	Attempts int
+	Err      error
+}
+
+func (e *QueuedError) Error() string {
+	return fmt.Sprintf("delivery failed after %d attempts: %v (message saved to %s, run 'reisekosten flush' to retry)", e.Attempts, e.Err, e.Path)
+}
+
+func (e *QueuedError) Unwrap() error {
+	return e.Err
+}
+
+// PermanentError reports a delivery failure that retrying cannot fix, e.g.
+// an unknown provider, missing credentials or a rejected login. Such mails
+// are neither retried nor queued.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// permanent marks a delivery error as permanent.
+func permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// httpStatusError marks the error of a failed API request as permanent if
+// the status is a client error other than a timeout or rate limit.
+func httpStatusError(status int, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return permanent(err)
+	}
+	return err
+}
+
+// OutboxDir returns the directory for undeliverable messages. Profiles get
+// their own subdirectory by default.
+func (c *Config) OutboxDir() string {
+	if c.Outbox != "" {
+		return c.Outbox
+	}
+	if c.Profile != "" {
+		return filepath.Join(defaultOutboxDir, c.Profile)
+	}
+	return defaultOutboxDir
+}
+
+// sendWithRetry sends the email, retrying with exponential backoff. If all attempts
+// fail, the message is written to the outbox so that a later `flush` can deliver it.
+// A PermanentError is returned right away.
+func sendWithRetry(cfg *Config, m Mail) error {
+	attempts := cfg.Retry.Attempts
+	if attempts <= 0 {
+		attempts = defaultRetryAttempts
+	}
+	delay := cfg.Retry.Delay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	var err error
+	for i := 1; i <= attempts; i++ {
+		if err = sendEmail(cfg, m); err == nil {
+			slog.Info("mail delivered", "provider", cfg.Email.Provider, "subject", m.Subject, "attachments", len(m.Attachments))
+			archiveSentMail(cfg, m)
+			return nil
+		}
+		var perm *PermanentError
+		if errors.As(err, &perm) {
+			return err
+		}
+		if i < attempts {
+			slog.Warn("delivery failed, retrying", "attempt", i, "of", attempts, "delay", delay, "error", err)
+			sleep(delay)
+			delay *= 2
+		}
+	}
+
+	path, qerr := queueMessage(cfg.OutboxDir(), outboxMessage{
+		Mail:      m,
+		Created:   time.Now(),
+		LastError: err.Error(),
+	})
+	if qerr != nil {
+		return fmt.Errorf("delivery failed after %d attempts: %w (queueing also failed: %v)", attempts, err, qerr)
+	}
+	return &QueuedError{Path: path, Attempts: attempts, Err: err}
+}
+
+// queueMessage writes a message to the outbox directory and returns its path.
+func queueMessage(dir string, msg outboxMessage) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s_%s.json", msg.Created.Format("20060102-150405"), outboxSlug(msg.Subject))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// outboxSlug turns a subject into a filesystem-safe filename fragment.
+func outboxSlug(subject string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, subject)
+}
+
+// flushOutbox tries to deliver all queued messages. Delivered messages are removed;
+// failed ones stay in the outbox with the last error recorded. A period whose
+// messages are all delivered is no longer marked as queued in the state file.
+func flushOutbox(cfg *Config) error {
+	dir := cfg.OutboxDir()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		slog.Info("outbox is empty", "dir", dir)
+		return nil
+	}
+
+	failed := 0
+	sent, pending := map[string]bool{}, map[string]bool{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var msg outboxMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("failed to parse outbox message %s: %w", path, err)
+		}
+
+		key := msg.Mail.Headers[filterHeader]
+		if err := sendEmail(cfg, msg.Mail); err != nil {
+			failed++
+			pending[key] = true
+			slog.Error("queued message not delivered", "file", filepath.Base(path), "error", err)
+			msg.LastError = err.Error()
+			if data, err := json.MarshalIndent(msg, "", "  "); err == nil {
+				os.WriteFile(path, data, 0600)
+			}
+			continue
+		}
+
+		slog.Info("queued message delivered", "file", filepath.Base(path))
+		sent[key] = true
+		p, _ := parsePeriodKey(key)
+		audit(cfg, "resend", p, nil, "sent to "+msg.Mail.recipient(cfg))
+		archiveSentMail(cfg, msg.Mail)
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	if err := settleQueued(cfg, sent, pending); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d queued messages could not be delivered", failed, len(paths))
+	}
+	return nil
+}
+
+// settleQueued removes the queued marker of the periods whose messages were
+// sent by a flush and none is left in the outbox.
+func settleQueued(cfg *Config, sent, pending map[string]bool) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	changed := false
+	for key := range sent {
+		if _, ok := state.Queued[key]; ok && !pending[key] {
+			delete(state.Queued, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return state.save(cfg.StateFile())
+}
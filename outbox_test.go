@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeSendGrid starts a SendGrid API stub that fails the first failCount requests.
+func fakeSendGrid(t *testing.T, failCount int) *int {
+	t.Helper()
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failCount {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	old := sendgridSendURL
+	sendgridSendURL = srv.URL
+	t.Cleanup(func() { sendgridSendURL = old })
+	return &calls
+}
+
+func noSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	old := sleep
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = old })
+	return &delays
+}
+
+func retryTestConfig(dir string) *Config {
+	return &Config{
+		Email:    EmailConfig{Provider: "sendgrid", From: "me@example.com", To: "boss@example.com"},
+		SendGrid: SendGridConfig{APIKey: "key"},
+		Retry:    RetryConfig{Attempts: 3, Delay: time.Second},
+		Outbox:   dir,
+	}
+}
+
+func TestSendWithRetrySucceeds(t *testing.T) {
+	calls := fakeSendGrid(t, 2)
+	delays := noSleep(t)
+	dir := t.TempDir()
+
+	if err := sendWithRetry(retryTestConfig(dir), Mail{Subject: "Betreff"}); err != nil {
+		t.Fatalf("sendWithRetry() error = %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want 3", *calls)
+	}
+	if len(*delays) != 2 || (*delays)[0] != time.Second || (*delays)[1] != 2*time.Second {
+		t.Errorf("backoff delays = %v, want [1s 2s]", *delays)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("outbox contains %d files, want 0", len(files))
+	}
+}
+
+func TestSendWithRetryQueuesAndFlush(t *testing.T) {
+	calls := fakeSendGrid(t, 3)
+	noSleep(t)
+	dir := t.TempDir()
+	cfg := retryTestConfig(dir)
+
+	err := sendWithRetry(cfg, Mail{Subject: "Deine Reisekostenabrechnung 02/2026", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}})
+	if err == nil {
+		t.Fatal("sendWithRetry() expected error after all attempts failed")
+	}
+	var queued *QueuedError
+	if !errors.As(err, &queued) {
+		t.Errorf("sendWithRetry() error = %T, want *QueuedError", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("outbox contains %d files, want 1", len(files))
+	}
+
+	// Server recovers, flush delivers and removes the queued message
+	if err := flushOutbox(cfg); err != nil {
+		t.Fatalf("flushOutbox() error = %v", err)
+	}
+	if *calls != 4 {
+		t.Errorf("calls = %d, want 4", *calls)
+	}
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Error("flushOutbox() did not remove delivered message")
+	}
+}
+
+func TestSendWithRetryPermanent(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+	old := sendgridSendURL
+	sendgridSendURL = srv.URL
+	t.Cleanup(func() { sendgridSendURL = old })
+	delays := noSleep(t)
+	dir := t.TempDir()
+
+	// Rejected credentials, a missing API key and an unknown provider fail
+	// at the first attempt and are not queued
+	cfg := retryTestConfig(dir)
+	noKey := retryTestConfig(dir)
+	noKey.SendGrid.APIKey = ""
+	unknown := retryTestConfig(dir)
+	unknown.Email.Provider = "fax"
+	for _, c := range []*Config{cfg, noKey, unknown} {
+		err := sendWithRetry(c, Mail{Subject: "Betreff"})
+		var perm *PermanentError
+		if !errors.As(err, &perm) {
+			t.Errorf("sendWithRetry(%s) error = %v, want *PermanentError", c.Email.Provider, err)
+		}
+	}
+	if calls != 1 || len(*delays) != 0 {
+		t.Errorf("calls = %d, delays = %v, want a single attempt", calls, *delays)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("outbox contains %d files, want 0", len(files))
+	}
+}
+
+func TestFlushOutboxEmpty(t *testing.T) {
+	cfg := &Config{Outbox: filepath.Join(t.TempDir(), "missing")}
+	if err := flushOutbox(cfg); err != nil {
+		t.Errorf("flushOutbox() on empty outbox error = %v", err)
+	}
+}
+
+func TestOutboxSlug(t *testing.T) {
+	got := outboxSlug("Deine Reisekostenabrechnung 02/2026")
+	if got != "Deine_Reisekostenabrechnung_02_2026" {
+		t.Errorf("outboxSlug() = %q", got)
+	}
+}
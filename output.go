@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Output Files
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerExporter("output", ExporterFunc(exportReportFiles))
+}
+
+// OutputConfig places generated files on disk.
+type OutputConfig struct {
+	Dir      string `yaml:"dir,omitempty"`      // directory of written files (default: current directory), also keeps the monthly PDFs
+	Filename string `yaml:"filename,omitempty"` // path template below dir, e.g. {{.Year}}/{{.Month}}_{{.DocType}}.pdf
+
+	Overwrite bool `yaml:"-"` // --overwrite: replace existing files
+}
+
+// outputFile describes a generated file for the filename template.
+type outputFile struct {
+	Year    int    // e.g. 2026
+	Month   string // 02, Q1 or KW09; empty for yearly files
+	Period  string // prefix of the default name: 02_2026, Q1_2026, KW09_2026 or 2026
+	DocType string // e.g. Kilometergelderstattung, Stundennachweis_1, Jahresuebersicht
+	DocID   string // Beleg-Nr., empty for yearly files
+	Name    string // default file name, e.g. 02_2026_Reisekosten_Kilometergelderstattung.pdf
+}
+
+// newOutputFile describes a file of a period, or of a whole year if p is the
+// zero Period.
+func newOutputFile(year int, p Period, name, docID string) outputFile {
+	f := outputFile{Year: year, Period: strconv.Itoa(year), DocID: docID, Name: name}
+	if p.Year != 0 {
+		f.Period = p.filePrefix()
+		f.Month = strings.TrimSuffix(f.Period, "_"+strconv.Itoa(p.Year))
+	}
+	docType := strings.TrimSuffix(name, filepath.Ext(name))
+	docType = strings.TrimPrefix(docType, f.Period+"_")
+	f.DocType = strings.TrimPrefix(docType, "Reisekosten_")
+	return f
+}
+
+// path returns where a file is written: the rendered filename template (or
+// the default name) below the output directory. The extension of the
+// template is replaced by the file's own, so one template fits PDF, CSV and
+// ZIP files.
+func (o OutputConfig) path(f outputFile) (string, error) {
+	name := f.Name
+	if o.Filename != "" {
+		tmpl, err := template.New("filename").Parse(o.Filename)
+		if err != nil {
+			return "", fmt.Errorf("invalid output.filename: %w", err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, f); err != nil {
+			return "", fmt.Errorf("invalid output.filename: %w", err)
+		}
+		name = b.String()
+		switch filepath.Ext(name) {
+		case ".pdf", ".csv", ".zip":
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		name = filepath.Clean(name + filepath.Ext(f.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("invalid output.filename: %w", fmt.Errorf("%s is outside the output directory", name))
+		}
+	}
+	dir := o.Dir
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// write stores a generated file. An existing file is only replaced with
+// --overwrite.
+func (o OutputConfig) write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !o.Overwrite {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flag, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists (use --overwrite to replace it)", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportReportFiles keeps the documents of a mailed report in output.dir.
+// Reports delivered without mail are written by storeReport, where a failed
+// write fails the delivery.
+func exportReportFiles(cfg *Config, p Period, report *Report) error {
+	if cfg.DeliveryMode() != deliveryEmail {
+		return nil
+	}
+	return writeReportFiles(cfg, p, report)
+}
+
+// writeReportFiles keeps the unencrypted documents of a delivered report in
+// output.dir. Without output.dir the documents are only mailed.
+func writeReportFiles(cfg *Config, p Period, report *Report) error {
+	if cfg.Output.Dir == "" && cfg.DeliveryMode() != deliveryNone {
+		return nil
+	}
+	summary := newRunSummary(cfg, p, report, nil)
+	documents := summary.Documents
+	for i, d := range documents {
+		path, err := cfg.Output.path(newOutputFile(p.Year, p, d.Filename, d.ID))
+		if err != nil {
+			return err
+		}
+		if err := cfg.Output.write(path, report.Attachments[i].Data); err != nil {
+			return err
+		}
+		slog.Debug("document written", "path", path)
+	}
+
+	manifest, err := manifestJSON(summary, time.Now())
+	if err != nil {
+		return err
+	}
+	path, err := cfg.Output.path(newOutputFile(p.Year, p, p.filePrefix()+"_"+manifestFilename, ""))
+	if err != nil {
+		return err
+	}
+	if err := cfg.Output.write(path, manifest); err != nil {
+		return err
+	}
+	slog.Info("documents written", "dir", cfg.Output.Dir, "files", len(documents))
+	return nil
+}
+
+// streamDocument generates the report of a period and writes one of its
+// documents (default: the first) to w. The report is neither delivered nor
+// archived.
+func streamDocument(cfg *Config, p Period, docType string, w io.Writer) error {
+	if err := checkOpen(cfg, p); err != nil {
+		return err
+	}
+	if err := checkOverlap(cfg, p); err != nil {
+		return err
+	}
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		return err
+	}
+	var types []string
+	for i, d := range newRunSummary(cfg, p, report, nil).Documents {
+		t := newOutputFile(p.Year, p, d.Filename, d.ID).DocType
+		if docType == "" || strings.EqualFold(t, docType) {
+			if _, err := w.Write(report.Attachments[i].Data); err != nil {
+				return err
+			}
+			slog.Info("document streamed", "document", d.Filename, "id", d.ID)
+			auditIssued(cfg, p, report, "streamed "+d.Filename)
+			return nil
+		}
+		types = append(types, t)
+	}
+	return fmt.Errorf("no document %q in the report of %s (use %s)", docType, p.Label(), strings.Join(types, ", "))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		file     outputFile
+		want     string
+	}{
+		{"default name", "", newOutputFile(2026, monthPeriod(2026, 2), "02_2026_Reisekosten_Kilometergelderstattung.pdf", "RK-2026-02-AB12"), "out/02_2026_Reisekosten_Kilometergelderstattung.pdf"},
+		{"template", "{{.Year}}/{{.Month}}_{{.DocType}}.pdf", newOutputFile(2026, monthPeriod(2026, 2), "02_2026_Reisekosten_Kilometergelderstattung.pdf", "RK-2026-02-AB12"), "out/2026/02_Kilometergelderstattung.pdf"},
+		{"timesheet", "{{.Year}}/{{.Month}}_{{.DocType}}.pdf", newOutputFile(2026, monthPeriod(2026, 2), "02_2026_Stundennachweis_1.pdf", ""), "out/2026/02_Stundennachweis_1.pdf"},
+		{"quarter", "{{.Year}}/{{.Month}}/{{.DocID}}", newOutputFile(2026, Period{Kind: periodQuarter, Year: 2026, Num: 1}, "Q1_2026_Reisekosten_Verpflegungsmehraufwand.pdf", "RK-2026-Q1-AB12"), "out/2026/Q1/RK-2026-Q1-AB12.pdf"},
+		{"csv keeps extension", "{{.Year}}/{{.DocType}}.pdf", newOutputFile(2025, Period{}, "2025_Reisekosten_Jahresuebersicht.csv", ""), "out/2025/Jahresuebersicht.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OutputConfig{Dir: "out", Filename: tt.filename}.path(tt.file)
+			if err != nil || got != filepath.FromSlash(tt.want) {
+				t.Errorf("path() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	for _, filename := range []string{"{{.Year", "{{.Customer}}", "../{{.Name}}"} {
+		if _, err := (OutputConfig{Filename: filename}).path(tests[0].file); err == nil || !strings.HasPrefix(err.Error(), "invalid output.filename") {
+			t.Errorf("path(%q) error = %v", filename, err)
+		}
+	}
+}
+
+func TestOutputWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2026", "02_Kilometergelderstattung.pdf")
+	o := OutputConfig{}
+	if err := o.write(path, []byte("first")); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if err := o.write(path, []byte("second")); err == nil || !strings.Contains(err.Error(), "--overwrite") {
+		t.Errorf("write() error = %v, want overwrite protection", err)
+	}
+	o.Overwrite = true
+	if err := o.write(path, []byte("second")); err != nil {
+		t.Errorf("write() with overwrite error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestWriteReportFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Overrides: filepath.Join(dir, "overrides"),
+		Output:    OutputConfig{Dir: dir, Filename: "{{.Year}}/{{.Month}}_{{.DocType}}.pdf"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeReportFiles(cfg, monthPeriod(2026, 2), report); err != nil {
+		t.Fatalf("writeReportFiles() error = %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "2026", "*.pdf"))
+	if len(files) != 2 || filepath.Base(files[0]) != "02_Kilometergelderstattung.pdf" {
+		t.Errorf("files = %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2026", "02_manifest.json")); err != nil {
+		t.Errorf("manifest not written: %v", err)
+	}
+}
+
+func TestStreamDocument(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+	if got := parseArgs([]string{"--output", "-", "--document", "verpflegungsmehraufwand", "2/2026"}); got.Output != "-" || got.Document != "verpflegungsmehraufwand" || got.Month != 2 {
+		t.Fatalf("parseArgs() = %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := streamDocument(cfg, monthPeriod(2026, 2), "verpflegungsmehraufwand", &buf); err != nil {
+		t.Fatalf("streamDocument() error = %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Error("streamed document is not a PDF")
+	}
+
+	err := streamDocument(cfg, monthPeriod(2026, 2), "Stundennachweis_1", io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "use Kilometergelderstattung, Verpflegungsmehraufwand") {
+		t.Errorf("streamDocument() error = %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Per-Month Overrides
+// ---------------------------------------------------------------------------
+
+const defaultOverridesDir = "overrides"
+
+// isoDate is the date format used in override files.
+const isoDate = "2006-01-02"
+
+// MonthOverride holds month-specific data read from <overrides>/YYYY-MM.yaml.
+type MonthOverride struct {
+	Absences      []Absence            `yaml:"absences,omitempty"`      // vacation, sick leave, ...
+	ExcludedDates []string             `yaml:"excludedDates,omitempty"` // single days without trips (YYYY-MM-DD)
+	Weights       map[string]int       `yaml:"weights,omitempty"`       // customer ID -> relative share of days (default 1)
+	Expenses      []Expense            `yaml:"expenses,omitempty"`      // additional costs (parking, tolls, tickets)
+	Notes         map[string]string    `yaml:"notes,omitempty"`         // YYYY-MM-DD -> note printed under the day's entries
+	FromOffice    []string             `yaml:"fromOffice,omitempty"`    // days on which the trip starts at the office (YYYY-MM-DD)
+	Detours       []Detour             `yaml:"detours,omitempty"`       // extra kilometers of single trips with their justification
+	HalfDays      []string             `yaml:"halfDays,omitempty"`      // half-day trips without meal allowance (YYYY-MM-DD)
+	Times         map[string]TripTimes `yaml:"times,omitempty"`         // YYYY-MM-DD -> departure and return of that day's trip
+	MealsProvided []string             `yaml:"mealsProvided,omitempty"` // days with provided meals, no meal allowance (YYYY-MM-DD)
+	Vehicles      map[string]string    `yaml:"vehicles,omitempty"`      // YYYY-MM-DD -> car, bike or ebike of that day's trip
+	Fuel          []FuelReceipt        `yaml:"fuel,omitempty"`          // fuel and charging receipts of vehicles with actual costs
+	LateEntries   []LateEntry          `yaml:"lateEntries,omitempty"`   // forgotten trips of earlier months, claimed as Nachtraege
+}
+
+// Absence is an inclusive date range without trips.
+type Absence struct {
+	From   string `yaml:"from"` // YYYY-MM-DD
+	To     string `yaml:"to"`   // YYYY-MM-DD, defaults to From
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Detour adds kilometers to the trip of a day, e.g. a road closure. The
+// note justifies the unusual distance in the entry.
+type Detour struct {
+	Date string `yaml:"date"` // YYYY-MM-DD
+	Km   int    `yaml:"km"`   // extra km driven in total
+	Note string `yaml:"note"` // e.g. Sperrung A8
+}
+
+// Expense is an additional travel cost reported in the Reisenebenkosten document.
+type Expense struct {
+	Date        string  `yaml:"date"` // YYYY-MM-DD
+	Description string  `yaml:"description"`
+	Amount      float64 `yaml:"amount"`             // EUR
+	VAT         float64 `yaml:"vat,omitempty"`      // included VAT in EUR, printed in the entry
+	Customer    string  `yaml:"customer,omitempty"` // optional customer ID
+}
+
+// OverridesDir returns the directory containing the per-month override files.
+func (c *Config) OverridesDir() string {
+	if c.Overrides != "" {
+		return c.Overrides
+	}
+	return defaultOverridesDir
+}
+
+// loadMonthOverride reads the override file for the given month. A missing
+// file yields an empty override.
+func loadMonthOverride(cfg *Config, year int, month time.Month) (*MonthOverride, error) {
+	path := filepath.Join(cfg.OverridesDir(), periodKey(year, month)+".yaml")
+	ov := &MonthOverride{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ov, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read override file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(ov); err != nil {
+		return nil, fmt.Errorf("failed to parse override file %s: %w", path, err)
+	}
+	if err := ov.check(cfg, year, month); err != nil {
+		return nil, fmt.Errorf("invalid override file %s: %w", path, err)
+	}
+	sort.SliceStable(ov.Expenses, func(i, j int) bool { return ov.Expenses[i].Date < ov.Expenses[j].Date })
+	slog.Info("month override loaded", "path", path,
+		"absences", len(ov.Absences), "excluded", len(ov.ExcludedDates), "expenses", len(ov.Expenses), "notes", len(ov.Notes), "detours", len(ov.Detours))
+	return ov, nil
+}
+
+// periodOverrides holds the override files of all months a period overlaps,
+// keyed by YYYY-MM.
+type periodOverrides map[string]*MonthOverride
+
+// loadPeriodOverrides reads the override files of every month of the period.
+func loadPeriodOverrides(cfg *Config, p Period) (periodOverrides, error) {
+	overrides := make(periodOverrides)
+	for _, m := range p.months() {
+		ov, err := loadMonthOverride(cfg, m.Year, time.Month(m.Num))
+		if err != nil {
+			return nil, err
+		}
+		overrides[m.Key()] = ov
+	}
+	return overrides, nil
+}
+
+// month returns the override of the month containing date.
+func (po periodOverrides) month(date time.Time) *MonthOverride {
+	if ov, ok := po[periodKey(date.Year(), date.Month())]; ok {
+		return ov
+	}
+	return &MonthOverride{}
+}
+
+// expenses returns the expenses dated within the period in date order.
+func (po periodOverrides) expenses(p Period) []Expense {
+	var expenses []Expense
+	for _, m := range p.months() {
+		for _, e := range po[m.Key()].Expenses {
+			if d, _ := time.Parse(isoDate, e.Date); p.includes(d) {
+				expenses = append(expenses, e)
+			}
+		}
+	}
+	return expenses
+}
+
+// check validates dates and customer references of the override.
+func (ov *MonthOverride) check(cfg *Config, year int, month time.Month) error {
+	inMonth := func(field, s string) error {
+		d, err := time.Parse(isoDate, s)
+		if err != nil {
+			return fmt.Errorf("%s: invalid date %q (use YYYY-MM-DD)", field, s)
+		}
+		if d.Year() != year || d.Month() != month {
+			return fmt.Errorf("%s: date %s is not in %02d/%d", field, s, month, year)
+		}
+		return nil
+	}
+	customer := func(field, id string) error {
+		for _, c := range cfg.Customers {
+			if c.ID == id {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: unknown customer id %q", field, id)
+	}
+
+	var errs []error
+	for i, a := range ov.Absences {
+		errs = append(errs, inMonth(fmt.Sprintf("absences[%d].from", i), a.From))
+		if a.To != "" {
+			errs = append(errs, inMonth(fmt.Sprintf("absences[%d].to", i), a.To))
+		}
+	}
+	for i, d := range ov.ExcludedDates {
+		errs = append(errs, inMonth(fmt.Sprintf("excludedDates[%d]", i), d))
+	}
+	for d := range ov.Notes {
+		errs = append(errs, inMonth("notes", d))
+	}
+	for i, d := range ov.FromOffice {
+		errs = append(errs, inMonth(fmt.Sprintf("fromOffice[%d]", i), d))
+	}
+	for d, times := range ov.Times {
+		errs = append(errs, inMonth("times", d))
+		if err := times.check(); err != nil {
+			errs = append(errs, fmt.Errorf("times.%s: %w", d, err))
+		}
+	}
+	for i, d := range ov.HalfDays {
+		errs = append(errs, inMonth(fmt.Sprintf("halfDays[%d]", i), d))
+	}
+	for i, d := range ov.MealsProvided {
+		errs = append(errs, inMonth(fmt.Sprintf("mealsProvided[%d]", i), d))
+	}
+	for d, v := range ov.Vehicles {
+		errs = append(errs, inMonth("vehicles", d))
+		if v != vehicleCar && !byBike(v) {
+			errs = append(errs, fmt.Errorf("vehicles.%s: invalid vehicle %q (use car, bike or ebike)", d, v))
+		}
+	}
+	for i, f := range ov.Fuel {
+		errs = append(errs, inMonth(fmt.Sprintf("fuel[%d].date", i), f.Date))
+		if f.Amount <= 0 {
+			errs = append(errs, fmt.Errorf("fuel[%d].amount: must be positive", i))
+		}
+		if v := f.vehicle(); v != vehicleCar && !byBike(v) {
+			errs = append(errs, fmt.Errorf("fuel[%d].vehicle: invalid vehicle %q (use car, bike or ebike)", i, v))
+		}
+	}
+	for i, d := range ov.Detours {
+		errs = append(errs, inMonth(fmt.Sprintf("detours[%d].date", i), d.Date))
+		if d.Km <= 0 {
+			errs = append(errs, fmt.Errorf("detours[%d].km: must be positive", i))
+		}
+		if d.Note == "" {
+			errs = append(errs, fmt.Errorf("detours[%d].note: required", i))
+		}
+	}
+	for i, e := range ov.LateEntries {
+		field := fmt.Sprintf("lateEntries[%d]", i)
+		if d, err := time.Parse(isoDate, e.Date); err != nil {
+			errs = append(errs, fmt.Errorf("%s.date: invalid date %q (use YYYY-MM-DD)", field, e.Date))
+		} else if !d.Before(monthPeriod(year, month).Start()) {
+			errs = append(errs, fmt.Errorf("%s.date: date %s is not before %02d/%d", field, e.Date, month, year))
+		}
+		errs = append(errs, customer(field+".customer", e.Customer))
+	}
+	for id, w := range ov.Weights {
+		errs = append(errs, customer("weights", id))
+		if w < 0 {
+			errs = append(errs, fmt.Errorf("weights: weight of customer %q must not be negative", id))
+		}
+	}
+	for i, e := range ov.Expenses {
+		errs = append(errs, inMonth(fmt.Sprintf("expenses[%d].date", i), e.Date))
+		if e.VAT < 0 || (e.VAT > 0 && e.VAT >= e.Amount) {
+			errs = append(errs, fmt.Errorf("expenses[%d].vat: must be below the amount", i))
+		}
+		if e.Description == "" {
+			errs = append(errs, fmt.Errorf("expenses[%d].description: required", i))
+		}
+		if e.Amount <= 0 {
+			errs = append(errs, fmt.Errorf("expenses[%d].amount: must be positive", i))
+		}
+		if e.Customer != "" {
+			errs = append(errs, customer(fmt.Sprintf("expenses[%d].customer", i), e.Customer))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// note returns the note for a DD.MM.YYYY date of the period, or "".
+func (po periodOverrides) note(dateString string) string {
+	date, err := time.Parse("02.01.2006", dateString)
+	if err != nil {
+		return ""
+	}
+	return po.month(date).Notes[date.Format(isoDate)]
+}
+
+// detour returns the detour of the trip on date, or a zero Detour.
+func (ov *MonthOverride) detour(date time.Time) Detour {
+	day := date.Format(isoDate)
+	for _, d := range ov.Detours {
+		if d.Date == day {
+			return d
+		}
+	}
+	return Detour{}
+}
+
+// excluded reports whether no trip may be recorded on date.
+func (ov *MonthOverride) excluded(date time.Time) bool {
+	reason, _ := ov.exclusionReason(date)
+	return reason != ""
+}
+
+// exclusionReason returns why the override excludes date (an absence with
+// its reason as detail, or an excluded date), or "" if it does not.
+func (ov *MonthOverride) exclusionReason(date time.Time) (reason, detail string) {
+	day := date.Format(isoDate)
+	for _, d := range ov.ExcludedDates {
+		if d == day {
+			return reasonExcluded, ""
+		}
+	}
+	for _, a := range ov.Absences {
+		to := a.To
+		if to == "" {
+			to = a.From
+		}
+		if day >= a.From && day <= to {
+			return reasonAbsence, a.Reason
+		}
+	}
+	return "", ""
+}
+
+// weight returns the relative share of days for a customer.
+func (ov *MonthOverride) weight(customerID string) int {
+	if w, ok := ov.Weights[customerID]; ok {
+		return w
+	}
+	return 1
+}
+
+// dayDistributor assigns days to customers by smooth weighted round-robin.
+// With equal weights it yields plain round-robin order.
+type dayDistributor struct {
+	weights []int
+	current []int
+	total   int
+}
+
+func newDayDistributor(weights []int) *dayDistributor {
+	d := &dayDistributor{current: make([]int, len(weights))}
+	d.setWeights(weights)
+	return d
+}
+
+// next returns the customer index due for the next day, or -1 if all weights are zero.
+func (d *dayDistributor) next() int {
+	return d.nextEligible(func(int) bool { return true })
+}
+
+// nextEligible is like next but only considers customers for which ok
+// returns true. It returns -1 if no customer is eligible.
+func (d *dayDistributor) nextEligible(ok func(i int) bool) int {
+	best := -1
+	for i, w := range d.weights {
+		if w > 0 && ok(i) && (best < 0 || d.current[i]+w > d.current[best]+d.weights[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// setWeights replaces the weights, e.g. when a period crosses into a month
+// with a different override, keeping the accumulated balance.
+func (d *dayDistributor) setWeights(weights []int) {
+	d.weights, d.total = weights, 0
+	for _, w := range weights {
+		d.total += w
+	}
+}
+
+// commit records that customer i received a day.
+func (d *dayDistributor) commit(i int) {
+	for j, w := range d.weights {
+		d.current[j] += w
+	}
+	d.current[i] -= d.total
+}
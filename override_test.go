@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDayDistributor(t *testing.T) {
+	tests := []struct {
+		weights []int
+		want    string
+	}{
+		{[]int{1, 1, 1}, "012012"},
+		{[]int{2, 1}, "010010"},
+		{[]int{0, 1}, "111111"},
+		{[]int{0, 0}, ""},
+	}
+	for _, tt := range tests {
+		d := newDayDistributor(tt.weights)
+		var got strings.Builder
+		for i := 0; i < 6; i++ {
+			next := d.next()
+			if next < 0 {
+				break
+			}
+			got.WriteByte(byte('0' + next))
+			d.commit(next)
+		}
+		if got.String() != tt.want {
+			t.Errorf("weights %v: order = %q, want %q", tt.weights, got.String(), tt.want)
+		}
+	}
+}
+
+func TestGenerateReportWithOverride(t *testing.T) {
+	dir := t.TempDir()
+	override := `absences:
+  - from: 2026-02-09
+    to: 2026-02-13
+    reason: Urlaub
+excludedDates:
+  - 2026-02-20
+weights:
+  "1": 2
+expenses:
+  - date: 2026-02-17
+    description: Parkgebuehren
+    amount: 12.50
+    customer: "2"
+  - date: 2026-02-03
+    description: Maut
+    amount: 7.5
+`
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte(override), 0644)
+
+	cfg := &Config{Overrides: dir, Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+	}}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// 20 weekdays minus 5 days vacation and one excluded day, split 2:1
+	if report.Workdays != 14 {
+		t.Errorf("Workdays = %d, want 14", report.Workdays)
+	}
+	if len(report.Customers[0].Dates) != 9 || len(report.Customers[1].Dates) != 5 {
+		t.Errorf("days = %d/%d, want 9/5", len(report.Customers[0].Dates), len(report.Customers[1].Dates))
+	}
+	for _, c := range report.Customers {
+		for _, d := range c.Dates {
+			if d == "10.02.2026" || d == "20.02.2026" {
+				t.Errorf("excluded date %s assigned to %s", d, c.Customer.Name)
+			}
+		}
+	}
+
+	if report.ExpenseTotal != 20 || report.ExpenseDocID == "" || report.Total() != report.KmTotal+report.VerpTotal+20 {
+		t.Errorf("ExpenseTotal = %v, DocID = %q", report.ExpenseTotal, report.ExpenseDocID)
+	}
+	if len(report.Attachments) != 3 || report.Attachments[2].Filename != "02_2026_Reisekosten_Reisenebenkosten.pdf" {
+		t.Errorf("unexpected attachments: %d", len(report.Attachments))
+	}
+}
+
+func TestOverrideNotes(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("notes:\n  2026-02-03: Workshop Anlagenplanung\n"), 0644)
+	overrides, err := loadPeriodOverrides(&Config{Overrides: dir}, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("loadPeriodOverrides() error = %v", err)
+	}
+	if got := overrides.note("03.02.2026"); got != "Workshop Anlagenplanung" {
+		t.Errorf("note(03.02.2026) = %q", got)
+	}
+	if got := overrides.note("04.02.2026"); got != "" {
+		t.Errorf("note(04.02.2026) = %q, want empty", got)
+	}
+
+	entry := buildMealAllowanceEntry("03.02.2026", TripTimes{}, verpflegungRate, "", overrides.note("03.02.2026"))
+	if !strings.HasSuffix(entry, "EUR\n    Workshop Anlagenplanung\n\n") {
+		t.Errorf("entry without note:\n%s", entry)
+	}
+	if header := buildCustomerHeader(Customer{ID: "1", Note: "Rahmenvertrag 2026"}); !strings.Contains(header, "Notiz:  Rahmenvertrag 2026\n") {
+		t.Errorf("customer header without note:\n%s", header)
+	}
+}
+
+func TestLoadMonthOverrideErrors(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Overrides: dir, Customers: []Customer{{ID: "1"}}}
+
+	// Missing file is not an error
+	if ov, err := loadMonthOverride(cfg, 2026, 3); err != nil || ov.excluded(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("loadMonthOverride() without file = %+v, %v", ov, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte(`excludedDates: [2026-03-01]
+weights: {"9": 1}
+notes: {"2026-02-30": x}
+expenses:
+  - date: 2026-02-03
+    amount: -1
+`), 0644)
+	_, err := loadMonthOverride(cfg, 2026, 2)
+	if err == nil {
+		t.Fatal("loadMonthOverride() expected error")
+	}
+	for _, want := range []string{
+		"excludedDates[0]: date 2026-03-01 is not in 02/2026",
+		`weights: unknown customer id "9"`,
+		`notes: invalid date "2026-02-30"`,
+		"expenses[0].description: required",
+		"expenses[0].amount: must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("absense: []\n"), 0644)
+	if _, err := loadMonthOverride(cfg, 2026, 2); err == nil {
+		t.Error("loadMonthOverride() expected error for unknown key")
+	}
+}
+
+func TestGenerateReportRunFilter(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 10, Province: "BW"}}}
+
+	cfg.Filter = RunFilter{SkipDays: splitList("2026-02-02, 2026-02-03,")}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 18 || report.Customers[0].Dates[0] != "04.02.2026" {
+		t.Errorf("Workdays = %d, first = %s, want 18 starting 04.02.2026", report.Workdays, report.Customers[0].Dates[0])
+	}
+
+	// Only-days still respects weekends and holidays
+	cfg.Filter = RunFilter{OnlyDays: []string{"2026-02-06", "2026-02-07", "2026-02-10"}}
+	report, err = generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if got := strings.Join(report.Customers[0].Dates, ","); got != "06.02.2026,10.02.2026" {
+		t.Errorf("Dates = %s, want 06.02.2026,10.02.2026", got)
+	}
+
+	cfg.Filter = RunFilter{SkipDays: []string{"2026-03-01", "13.02.2026"}}
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), "not in 02/2026") || !strings.Contains(err.Error(), "invalid date") {
+		t.Errorf("generateReport() error = %v, want date errors", err)
+	}
+}
+
+func TestGenerateReportCustomerFilter(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+		{ID: "3", Name: "Initech", Distance: 20, Province: "BW"},
+	}}
+
+	cfg.Filter = RunFilter{Customers: []string{"3", "1"}}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if len(report.Customers) != 2 || report.Customers[0].Customer.ID != "1" || report.Customers[1].Customer.ID != "3" {
+		t.Fatalf("Customers = %+v, want 1 and 3", report.Customers)
+	}
+	if len(report.Customers[0].Dates) != 10 || len(report.Customers[1].Dates) != 10 {
+		t.Errorf("days = %d/%d, want 10/10", len(report.Customers[0].Dates), len(report.Customers[1].Dates))
+	}
+
+	cfg.Filter = RunFilter{Customers: []string{"4"}}
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil || !strings.Contains(err.Error(), `unknown customer id "4"`) {
+		t.Errorf("generateReport() error = %v, want unknown customer", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Month Overview
+// ---------------------------------------------------------------------------
+
+// Symbols of days without a trip in the month overview
+const (
+	overviewWeekend = "."
+	overviewHoliday = "*"
+	overviewNoTrip  = "-"
+)
+
+// customerCodes assigns the letters A, B, ... to the customers in their
+// configured order, keyed by customer name as in dayDecision.
+func customerCodes(customers []Customer) map[string]string {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	codes := make(map[string]string, len(customers))
+	for i, c := range customers {
+		code := "?"
+		if i < len(letters) {
+			code = letters[i : i+1]
+		}
+		if _, ok := codes[c.Name]; !ok {
+			codes[c.Name] = code
+		}
+	}
+	return codes
+}
+
+// overviewSymbol returns the code of a day in the month overview: the
+// customer's letter for a trip, otherwise the symbol of the reason.
+func overviewSymbol(d dayDecision, codes map[string]string) string {
+	switch d.Reason {
+	case "":
+		return codes[d.Customer]
+	case reasonWeekend:
+		return overviewWeekend
+	case reasonHoliday:
+		return overviewHoliday
+	default:
+		return overviewNoTrip
+	}
+}
+
+// buildMonthOverview renders the period as a calendar grid, one month per
+// grid with calendar weeks (KW) as rows, followed by the legend of the
+// customer letters and symbols. Days outside the period have no symbol.
+func buildMonthOverview(p Period, days []dayDecision, customers []Customer) string {
+	var b strings.Builder
+
+	header := "MONATSUEBERSICHT " + p.Label()
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", (lineWidth-len(header))/2), header))
+	b.WriteString(lineDouble + "\n")
+
+	codes := customerCodes(customers)
+	byDate := make(map[string]dayDecision, len(days))
+	trips := make(map[string]int)
+	for _, d := range days {
+		byDate[d.Date.Format(isoDate)] = d
+		if d.Reason == "" {
+			trips[d.Customer]++
+		}
+	}
+
+	for _, m := range p.months() {
+		b.WriteString("\n")
+		if m.Key() != p.Key() {
+			b.WriteString(m.Label() + "\n\n")
+		}
+		b.WriteString("  KW    Mo    Di    Mi    Do    Fr    Sa    So\n")
+
+		var row strings.Builder
+		start, end := m.Start(), m.End()
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			col := (int(date.Weekday()) + 6) % 7 // Monday first
+			if col == 0 || date.Equal(start) {
+				_, week := date.ISOWeek()
+				row.WriteString(fmt.Sprintf("  %2d%s", week, strings.Repeat("      ", col)))
+			}
+			symbol := ""
+			if d, ok := byDate[date.Format(isoDate)]; ok {
+				symbol = overviewSymbol(d, codes)
+			}
+			row.WriteString(fmt.Sprintf("%4d %-1s", date.Day(), symbol))
+			if col == 6 || date.Equal(end) {
+				b.WriteString(strings.TrimRight(row.String(), " ") + "\n")
+				row.Reset()
+			}
+		}
+	}
+
+	b.WriteString("\n" + lineSingle + "\n")
+	for _, c := range customers {
+		if trips[c.Name] == 0 {
+			continue
+		}
+		unit := "Reisetage"
+		if trips[c.Name] == 1 {
+			unit = "Reisetag"
+		}
+		b.WriteString(fmt.Sprintf("  %s  %-36s %3d %s\n", codes[c.Name], umlautReplacer.Replace(c.Name), trips[c.Name], unit))
+	}
+	b.WriteString(fmt.Sprintf("  %s  Wochenende   %s  Feiertag   %s  ohne Reise (Urlaub, Buero, ...)\n",
+		overviewWeekend, overviewHoliday, overviewNoTrip))
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMonthOverview(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+			{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	got := buildMonthOverview(p, report.Days, cfg.Customers)
+	for _, want := range []string{
+		"MONATSUEBERSICHT 02/2026",
+		"  KW    Mo    Di    Mi    Do    Fr    Sa    So\n",
+		"   5                                       1 .\n",
+		"   6   2 A   3 B   4 A   5 B   6 A   7 .   8 .\n",
+		"   9  23 B  24 A  25 B  26 A  27 B  28 .\n",
+		"  A  Acme                                  10 Reisetage\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("overview missing %q:\n%s", want, got)
+		}
+	}
+
+	// The overview is the first page of both PDFs
+	without := report.Attachments[0].Data
+	cfg.Overview = true
+	report, err = generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport(overview) error = %v", err)
+	}
+	if a, b := pdfPageCount(t, without), pdfPageCount(t, report.Attachments[0].Data); b != a+1 {
+		t.Errorf("PDF with overview has %d pages, without %d", b, a)
+	}
+}
+
+func TestBuildMonthOverviewQuarter(t *testing.T) {
+	p := Period{Kind: periodQuarter, Year: 2026, Num: 2}
+	got := buildMonthOverview(p, nil, nil)
+	for _, want := range []string{"04/2026\n", "05/2026\n", "06/2026\n", "  14               1     2     3     4     5\n", "  27  29    30\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("quarter overview missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Backfill (Parallel Generation)
+// ---------------------------------------------------------------------------
+
+// monthRangeArgRegex validates a backfill range: M/YYYY-M/YYYY
+var monthRangeArgRegex = regexp.MustCompile(`^(0?[1-9]|1[0-2])/(20[0-9]{2})-(0?[1-9]|1[0-2])/(20[0-9]{2})$`)
+
+// maxBackfillMonths limits a backfill range to catch typos in the year.
+const maxBackfillMonths = 36
+
+// monthRange returns the months from (year, month) to (toYear, toMonth) inclusive.
+func monthRange(year int, month time.Month, toYear int, toMonth time.Month) ([]Period, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(toYear, toMonth, 1, 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return nil, fmt.Errorf("range end %02d/%d is before its start %02d/%d", toMonth, toYear, month, year)
+	}
+
+	var periods []Period
+	for d := start; !d.After(end); d = d.AddDate(0, 1, 0) {
+		periods = append(periods, monthPeriod(d.Year(), d.Month()))
+	}
+	if len(periods) > maxBackfillMonths {
+		return nil, fmt.Errorf("range covers %d months (at most %d)", len(periods), maxBackfillMonths)
+	}
+	return periods, nil
+}
+
+// parseMonthRange parses an M/YYYY-M/YYYY argument.
+func parseMonthRange(arg string) (year int, month time.Month, toYear int, toMonth time.Month) {
+	m := monthRangeArgRegex.FindStringSubmatch(arg)
+	n := make([]int, 4)
+	for i := range n {
+		n[i], _ = strconv.Atoi(m[i+1])
+	}
+	return n[1], time.Month(n[0]), n[3], time.Month(n[2])
+}
+
+// generateReports generates the reports of all periods with up to jobs
+// concurrent workers (default: number of CPUs). Reports and errors are
+// returned in the order of periods; a failed period has a nil report.
+func generateReports(cfg *Config, periods []Period, jobs int) ([]*Report, []error) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(periods) {
+		jobs = len(periods)
+	}
+
+	reports := make([]*Report, len(periods))
+	errs := make([]error, len(periods))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				reports[i], errs[i] = generateReport(cfg, periods[i])
+				if errs[i] != nil {
+					errs[i] = fmt.Errorf("%s: %w", periods[i].Label(), errs[i])
+				}
+			}
+		}()
+	}
+	for i := range periods {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	slog.Info("reports generated", "periods", len(periods), "workers", jobs)
+	return reports, errs
+}
+
+// chainedPeriods reports whether a report depends on the delivery of the one
+// before: a customer cap carries its overflow into the next month,
+// includeUnclaimed claims the days dropped by earlier reports, or an annual
+// budget sums the year to date.
+func chainedPeriods(cfg *Config) bool {
+	if cfg.IncludeUnclaimed || cfg.Budget.enabled() {
+		return true
+	}
+	for _, c := range cfg.Customers {
+		if c.Cap.OnExceed == capCarry {
+			return true
+		}
+	}
+	return false
+}
+
+// backfill generates the reports of several periods concurrently and then
+// delivers them one after another in period order, so mails are threaded and
+// archived as if the months had been run individually. Chained periods are
+// generated one by one, each after the previous one is delivered. All
+// failures are returned together; successful periods are delivered
+// regardless. With an approver nothing is delivered, every month needs its
+// own preview.
+func backfill(cfg *Config, periods []Period, jobs int, printJSON bool) error {
+	if cfg.Approval.To != "" {
+		return fmt.Errorf("backfill is not available with approval.to, run the months one by one for approval")
+	}
+	chained := chainedPeriods(cfg)
+	reports, errs := make([]*Report, len(periods)), make([]error, len(periods))
+	if !chained {
+		reports, errs = generateReports(cfg, periods, jobs)
+	}
+
+	summaries := make([]runSummary, len(periods))
+	for i, p := range periods {
+		if chained {
+			if reports[i], errs[i] = generateReport(cfg, p); errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Label(), errs[i])
+			}
+		}
+		if errs[i] == nil {
+			if err := deliverReport(cfg, p, reports[i]); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Label(), err)
+			}
+		}
+		notifyRun(cfg, p, reports[i], errors.Unwrap(errs[i]))
+		summaries[i] = newRunSummary(cfg, p, reports[i], errors.Unwrap(errs[i]))
+		if errs[i] != nil {
+			slog.Error("period failed", "period", p.Label(), "error", errs[i])
+		}
+	}
+
+	if printJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summaries); err != nil {
+			slog.Warn("failed to write run summary", "error", err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMonthRange(t *testing.T) {
+	got := parseArgs([]string{"--jobs", "4", "11/2025-2/2026"})
+	if got.Year != 2025 || got.Month != 11 || got.ToYear != 2026 || got.ToMonth != 2 || got.Jobs != 4 {
+		t.Fatalf("parseArgs() = %+v", got)
+	}
+
+	periods, err := monthRange(got.Year, got.Month, got.ToYear, got.ToMonth)
+	if err != nil {
+		t.Fatalf("monthRange() error = %v", err)
+	}
+	var keys []string
+	for _, p := range periods {
+		keys = append(keys, p.Key())
+	}
+	if strings.Join(keys, ",") != "2025-11,2025-12,2026-01,2026-02" {
+		t.Errorf("monthRange() = %v", keys)
+	}
+
+	if _, err := monthRange(2026, 3, 2026, 1); err == nil {
+		t.Error("monthRange() expected error for reversed range")
+	}
+	if _, err := monthRange(2020, 1, 2026, 1); err == nil {
+		t.Error("monthRange() expected error for too many months")
+	}
+}
+
+func TestGenerateReports(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), EmploymentStart: "2026-02-01", Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+	periods, _ := monthRange(2026, 1, 2026, 4)
+
+	reports, errs := generateReports(cfg, periods, 2)
+	if errs[0] == nil || !strings.Contains(errs[0].Error(), "01/2026: ") || reports[0] != nil {
+		t.Errorf("January: error = %v, want employment error", errs[0])
+	}
+	// February 20, March 22, April 20 workdays in BW (Easter)
+	for i, want := range []int{20, 22, 20} {
+		r, err := reports[i+1], errs[i+1]
+		if err != nil || r.Period != periods[i+1] || r.Workdays != want {
+			t.Errorf("%s: error = %v, want %d workdays", periods[i+1].Label(), err, want)
+		}
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	periods, _ := monthRange(2026, 1, 2026, 3)
+
+	if err := backfill(cfg, periods, 0, false); err != nil {
+		t.Fatalf("backfill() error = %v", err)
+	}
+	months, err := loadArchive(cfg, 2026)
+	if err != nil || len(months) != 3 || months[2].Period != "2026-03" {
+		t.Errorf("archive = %+v, %v", months, err)
+	}
+	mails, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml"))
+	if len(mails) != 3 {
+		t.Errorf("mails = %v, want 3", mails)
+	}
+}
+
+func TestBackfillCarry(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Cap: CustomerCap{Total: 500, OnExceed: capCarry}}},
+	}
+	periods, _ := monthRange(2026, 2, 2026, 3)
+
+	// February's overflow of 9 days is claimed in March, as in single runs
+	if err := backfill(cfg, periods, 2, false); err != nil {
+		t.Fatalf("backfill() error = %v", err)
+	}
+	months, err := loadArchive(cfg, 2026)
+	if err != nil || len(months) != 2 {
+		t.Fatalf("archive = %+v, %v", months, err)
+	}
+	if months[0].CarryOver["1"] != 9*44 || months[1].Workdays != 2 || months[1].Total != 484 {
+		t.Errorf("carry = %v, March: workdays = %d, total = %v", months[0].CarryOver, months[1].Workdays, months[1].Total)
+	}
+}
+
+func TestBackfillApproval(t *testing.T) {
+	cfg, dir := approvalTestConfig(t)
+	periods, _ := monthRange(2026, 1, 2026, 2)
+	if err := backfill(cfg, periods, 0, false); err == nil || !strings.Contains(err.Error(), "approval.to") {
+		t.Errorf("backfill() with approval error = %v", err)
+	}
+	if mails, _ := filepath.Glob(filepath.Join(dir, "mails", "*.eml")); len(mails) != 0 {
+		t.Errorf("mails = %v, want none", mails)
+	}
+}
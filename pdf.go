@@ -1,11 +1,6 @@
 package main
 
-import (
-	"bytes"
-	"strings"
-
-	"github.com/go-pdf/fpdf"
-)
+import "strings"
 
 // ---------------------------------------------------------------------------
 // PDF Generation
@@ -13,42 +8,38 @@ import (
 
 // createPDF generates a PDF document with smart page breaks and returns it as bytes.
 // Blocks are never split across pages - if a block doesn't fit, a new page is added.
-func createPDF(header string, blocks []string, footer string) ([]byte, error) {
-	pdf := fpdf.New("P", "mm", "A4", "")
-	pdf.SetFont("Courier", "", pdfFontSize)
-	pdf.AddPage()
-
-	// Calculate available page height
-	_, pageHeight := pdf.GetPageSize()
-	_, _, _, marginBottom := pdf.GetMargins()
-	maxY := pageHeight - marginBottom
-
-	// Use large width to prevent line wrapping (text uses spaces for alignment)
-	const cellWidth = 300
+// A form feed (\f) in the header starts a new page, e.g. after an overview
+// page. Each appendix starts on a new page after the footer. The document is
+// tagged for screen readers, see taggedPDF.
+func createPDF(header string, blocks []string, footer string, appendices ...string) ([]byte, error) {
+	pdf := newTaggedPDF()
 
 	// Write header (always fits on first page)
-	pdf.MultiCell(cellWidth, pdfLineHeight, header, "", "", false)
+	for i, page := range strings.Split(header, "\f") {
+		if i > 0 {
+			pdf.AddPage()
+		}
+		pdf.write(page)
+	}
 
 	// Write each block, adding page break if block won't fit
 	for _, block := range blocks {
-		blockHeight := float64(strings.Count(block, "\n")+1) * pdfLineHeight
-
-		if pdf.GetY()+blockHeight > maxY {
+		if !pdf.fits(block) {
 			pdf.AddPage()
 		}
-		pdf.MultiCell(cellWidth, pdfLineHeight, block, "", "", false)
+		pdf.write(block)
 	}
 
 	// Write footer (total amount)
-	footerHeight := float64(strings.Count(footer, "\n")+1) * pdfLineHeight
-	if pdf.GetY()+footerHeight > maxY {
+	if !pdf.fits(footer) {
 		pdf.AddPage()
 	}
-	pdf.MultiCell(cellWidth, pdfLineHeight, footer, "", "", false)
+	pdf.write(footer)
 
-	var buf bytes.Buffer
-	if err := pdf.Output(&buf); err != nil {
-		return nil, err
+	for _, appendix := range appendices {
+		pdf.AddPage()
+		pdf.write(appendix)
 	}
-	return buf.Bytes(), nil
+
+	return pdf.output()
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Report Periods
+// ---------------------------------------------------------------------------
+
+// Report period kinds selected with --period.
+const (
+	periodMonth   = "month"
+	periodQuarter = "quarter"
+	periodWeek    = "week"
+)
+
+// quarterArgRegex validates the period argument in quarterly mode: Q1/YYYY
+var quarterArgRegex = regexp.MustCompile(`^Q([1-4])/(20[0-9]{2})$`)
+
+// weekArgRegex validates the period argument in weekly mode: KW9/YYYY or KW09/YYYY
+var weekArgRegex = regexp.MustCompile(`^KW(0?[1-9]|[1-4][0-9]|5[0-3])/(20[0-9]{2})$`)
+
+// periodKeyRegex matches the keys returned by Period.Key.
+var periodKeyRegex = regexp.MustCompile(`^(20[0-9]{2})-(Q|W)?([0-9]{1,2})$`)
+
+// Period is the time span covered by one report: a calendar month, a quarter
+// or an ISO week (KW).
+type Period struct {
+	Kind string // month, quarter or week
+	Year int    // calendar year, ISO week-numbering year for weeks
+	Num  int    // month 1-12, quarter 1-4 or week 1-53
+}
+
+// monthPeriod returns the period of a calendar month.
+func monthPeriod(year int, month time.Month) Period {
+	return Period{Kind: periodMonth, Year: year, Num: int(month)}
+}
+
+// Start returns the first day of the period.
+func (p Period) Start() time.Time {
+	switch p.Kind {
+	case periodQuarter:
+		return time.Date(p.Year, time.Month(3*(p.Num-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	case periodWeek:
+		// ISO week 1 is the week containing January 4th
+		jan4 := time.Date(p.Year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, 7*(p.Num-1))
+	default:
+		return time.Date(p.Year, time.Month(p.Num), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// End returns the last day of the period (inclusive).
+func (p Period) End() time.Time {
+	switch p.Kind {
+	case periodQuarter:
+		return p.Start().AddDate(0, 3, -1)
+	case periodWeek:
+		return p.Start().AddDate(0, 0, 6)
+	default:
+		return p.Start().AddDate(0, 1, -1)
+	}
+}
+
+// Key returns the identifier used for state, archive and document IDs:
+// YYYY-MM, YYYY-Qn or YYYY-Wnn.
+func (p Period) Key() string {
+	switch p.Kind {
+	case periodQuarter:
+		return fmt.Sprintf("%d-Q%d", p.Year, p.Num)
+	case periodWeek:
+		return fmt.Sprintf("%d-W%02d", p.Year, p.Num)
+	default:
+		return periodKey(p.Year, time.Month(p.Num))
+	}
+}
+
+// Label returns the period as shown in documents and mails: MM/YYYY,
+// Qn/YYYY or KW nn/YYYY.
+func (p Period) Label() string {
+	switch p.Kind {
+	case periodQuarter:
+		return fmt.Sprintf("Q%d/%d", p.Num, p.Year)
+	case periodWeek:
+		return fmt.Sprintf("KW %02d/%d", p.Num, p.Year)
+	default:
+		return fmt.Sprintf("%02d/%d", p.Num, p.Year)
+	}
+}
+
+// filePrefix returns the prefix of generated file names: MM_YYYY, Qn_YYYY
+// or KWnn_YYYY.
+func (p Period) filePrefix() string {
+	switch p.Kind {
+	case periodQuarter:
+		return fmt.Sprintf("Q%d_%d", p.Num, p.Year)
+	case periodWeek:
+		return fmt.Sprintf("KW%02d_%d", p.Num, p.Year)
+	default:
+		return fmt.Sprintf("%02d_%d", p.Num, p.Year)
+	}
+}
+
+// includes reports whether date lies within the period.
+func (p Period) includes(date time.Time) bool {
+	return !date.Before(p.Start()) && !date.After(p.End())
+}
+
+// previous returns the period of the same kind before p.
+func (p Period) previous() Period {
+	return currentPeriod(p.Kind, p.Start().AddDate(0, 0, -1))
+}
+
+// months returns the calendar months the period overlaps, in order.
+func (p Period) months() []Period {
+	var months []Period
+	end := p.End()
+	for d := p.Start(); !d.After(end); d = d.AddDate(0, 1, 1-d.Day()) {
+		months = append(months, monthPeriod(d.Year(), d.Month()))
+	}
+	return months
+}
+
+// parsePeriodKey is the inverse of Period.Key.
+func parsePeriodKey(key string) (Period, bool) {
+	m := periodKeyRegex.FindStringSubmatch(key)
+	if m == nil {
+		return Period{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	num, _ := strconv.Atoi(m[3])
+	switch m[2] {
+	case "Q":
+		return Period{Kind: periodQuarter, Year: year, Num: num}, true
+	case "W":
+		return Period{Kind: periodWeek, Year: year, Num: num}, true
+	default:
+		return monthPeriod(year, time.Month(num)), true
+	}
+}
+
+// currentPeriod returns the period of the given kind containing t.
+func currentPeriod(kind string, t time.Time) Period {
+	switch kind {
+	case periodQuarter:
+		return Period{Kind: periodQuarter, Year: t.Year(), Num: int(t.Month()-1)/3 + 1}
+	case periodWeek:
+		year, week := t.ISOWeek()
+		return Period{Kind: periodWeek, Year: year, Num: week}
+	default:
+		return monthPeriod(t.Year(), t.Month())
+	}
+}
+
+// weeksInYear returns the number of ISO weeks (52 or 53) of a year.
+func weeksInYear(year int) int {
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
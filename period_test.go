@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeriod(t *testing.T) {
+	tests := []struct {
+		p                  Period
+		start, end         string
+		key, label, prefix string
+	}{
+		{monthPeriod(2026, 2), "2026-02-01", "2026-02-28", "2026-02", "02/2026", "02_2026"},
+		{Period{periodQuarter, 2026, 4}, "2026-10-01", "2026-12-31", "2026-Q4", "Q4/2026", "Q4_2026"},
+		{Period{periodWeek, 2026, 9}, "2026-02-23", "2026-03-01", "2026-W09", "KW 09/2026", "KW09_2026"},
+		// ISO week 1 of 2026 starts in December 2025, week 53 of 2020 ends in January 2021
+		{Period{periodWeek, 2026, 1}, "2025-12-29", "2026-01-04", "2026-W01", "KW 01/2026", "KW01_2026"},
+		{Period{periodWeek, 2020, 53}, "2020-12-28", "2021-01-03", "2020-W53", "KW 53/2020", "KW53_2020"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := tt.p.Start().Format(isoDate); got != tt.start {
+				t.Errorf("Start() = %s, want %s", got, tt.start)
+			}
+			if got := tt.p.End().Format(isoDate); got != tt.end {
+				t.Errorf("End() = %s, want %s", got, tt.end)
+			}
+			if tt.p.Key() != tt.key || tt.p.Label() != tt.label || tt.p.filePrefix() != tt.prefix {
+				t.Errorf("Key/Label/filePrefix = %q, %q, %q", tt.p.Key(), tt.p.Label(), tt.p.filePrefix())
+			}
+			if p, ok := parsePeriodKey(tt.key); !ok || p != tt.p {
+				t.Errorf("parsePeriodKey(%q) = %+v, %v", tt.key, p, ok)
+			}
+		})
+	}
+
+	months := Period{periodWeek, 2026, 1}.months()
+	if len(months) != 2 || months[0].Key() != "2025-12" || months[1].Key() != "2026-01" {
+		t.Errorf("months() = %+v", months)
+	}
+	for p, want := range map[Period]string{monthPeriod(2026, 1): "2025-12", {periodQuarter, 2026, 1}: "2025-Q4", {periodWeek, 2021, 1}: "2020-W53"} {
+		if got := p.previous().Key(); got != want {
+			t.Errorf("%s.previous() = %s, want %s", p.Key(), got, want)
+		}
+	}
+	if weeksInYear(2020) != 53 || weeksInYear(2026) != 53 || weeksInYear(2025) != 52 {
+		t.Error("weeksInYear() wrong")
+	}
+}
+
+func TestCurrentPeriod(t *testing.T) {
+	now := time.Date(2027, time.January, 2, 12, 0, 0, 0, time.UTC)
+	if p := currentPeriod(periodQuarter, now); p.Key() != "2027-Q1" {
+		t.Errorf("quarter = %s", p.Key())
+	}
+	if p := currentPeriod(periodWeek, now); p.Key() != "2026-W53" {
+		t.Errorf("week = %s", p.Key())
+	}
+	if p := currentPeriod(periodMonth, now); p.Key() != "2027-01" {
+		t.Errorf("month = %s", p.Key())
+	}
+}
+
+func TestParseArgsPeriod(t *testing.T) {
+	tests := []struct {
+		args []string
+		want Period
+	}{
+		{[]string{"3/2026"}, monthPeriod(2026, 3)},
+		{[]string{"--period", "quarter", "Q2/2026"}, Period{periodQuarter, 2026, 2}},
+		{[]string{"--period", "week", "KW9/2026"}, Period{periodWeek, 2026, 9}},
+		{[]string{"--period", "week", "KW09/2026"}, Period{periodWeek, 2026, 9}},
+	}
+	for _, tt := range tests {
+		got, err := parseArgs(tt.args).period()
+		if err != nil || got != tt.want {
+			t.Errorf("period(%v) = %+v, %v, want %+v", tt.args, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseArgs([]string{"--period", "year"}).period(); err == nil {
+		t.Error("period() expected error for unknown kind")
+	}
+	if _, err := parseArgs([]string{"--period", "week", "KW53/2025"}).period(); err == nil {
+		t.Error("period() expected error for KW 53 in a 52-week year")
+	}
+	if got, _ := parseArgs([]string{"--period", "quarter"}).period(); got != currentPeriod(periodQuarter, time.Now()) {
+		t.Errorf("default quarter = %+v", got)
+	}
+}
+
+func TestGenerateQuarterReport(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	report, err := generateReport(cfg, Period{periodQuarter, 2026, 1})
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// The quarter covers exactly the workdays of its three months
+	workdays := 0
+	for month := time.January; month <= time.March; month++ {
+		r, err := generateReport(cfg, monthPeriod(2026, month))
+		if err != nil {
+			t.Fatalf("generateReport() error = %v", err)
+		}
+		workdays += r.Workdays
+	}
+	if report.Workdays != workdays {
+		t.Errorf("Workdays = %d, want %d", report.Workdays, workdays)
+	}
+	if !strings.HasPrefix(report.KmDocID, "RK-2026-Q1-") {
+		t.Errorf("KmDocID = %q", report.KmDocID)
+	}
+	if report.Attachments[0].Filename != "Q1_2026_Reisekosten_Kilometergelderstattung.pdf" {
+		t.Errorf("filename = %q", report.Attachments[0].Filename)
+	}
+}
+
+func TestGenerateWeekReportAcrossMonths(t *testing.T) {
+	dir := t.TempDir()
+	override := `excludedDates:
+  - 2026-04-01
+expenses:
+  - date: 2026-04-02
+    description: Parkgebuehren
+    amount: 8
+  - date: 2026-04-20
+    description: Maut
+    amount: 5
+`
+	os.WriteFile(filepath.Join(dir, "2026-04.yaml"), []byte(override), 0644)
+	cfg := &Config{Overrides: dir, Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	// KW 14/2026: Mar 30 - Apr 5, Good Friday on Apr 3
+	report, err := generateReport(cfg, Period{periodWeek, 2026, 14})
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	dates := strings.Join(report.Customers[0].Dates, ",")
+	if dates != "30.03.2026,31.03.2026,02.04.2026" {
+		t.Errorf("dates = %s", dates)
+	}
+	if report.ExpenseTotal != 8 || !strings.HasPrefix(report.ExpenseDocID, "RK-2026-W14-") {
+		t.Errorf("ExpenseTotal = %v, ExpenseDocID = %q", report.ExpenseTotal, report.ExpenseDocID)
+	}
+}
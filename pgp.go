@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// ---------------------------------------------------------------------------
+// PGP Encryption
+// ---------------------------------------------------------------------------
+
+// PGPConfig maps recipient email addresses to their OpenPGP public key files.
+type PGPConfig struct {
+	Keys map[string]string `yaml:"keys,omitempty"` // recipient address -> public key file (armored or binary)
+}
+
+// readPublicKey loads an OpenPGP public key ring from an armored or binary file.
+func readPublicKey(path string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	var keys openpgp.EntityList
+	if block, aerr := armor.Decode(bytes.NewReader(data)); aerr == nil {
+		keys, err = openpgp.ReadKeyRing(block.Body)
+	} else {
+		keys, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found in %s", path)
+	}
+	return keys, nil
+}
+
+// recipientKeys returns the public keys for all recipients. If no keys are
+// configured, encryption is disabled and nil is returned. A recipient without
+// a key is an error, so documents are never sent unencrypted by accident.
+func (p PGPConfig) recipientKeys(recipients []string) (openpgp.EntityList, error) {
+	if len(p.Keys) == 0 {
+		return nil, nil
+	}
+
+	// Lookup is case-insensitive on the address
+	byAddr := make(map[string]string, len(p.Keys))
+	for addr, path := range p.Keys {
+		byAddr[strings.ToLower(strings.TrimSpace(addr))] = path
+	}
+
+	var all openpgp.EntityList
+	for _, r := range recipients {
+		path, ok := byAddr[strings.ToLower(strings.TrimSpace(r))]
+		if !ok {
+			return nil, fmt.Errorf("no PGP public key configured for recipient %s", r)
+		}
+		keys, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, keys...)
+	}
+	return all, nil
+}
+
+// encryptAttachments encrypts every attachment to the recipients' public keys.
+// Encrypted files get a .pgp suffix. Without configured keys the attachments
+// are returned unchanged.
+func encryptAttachments(cfg *Config, recipients []string, attachments []Attachment) ([]Attachment, error) {
+	keys, err := cfg.PGP.recipientKeys(recipients)
+	if err != nil || keys == nil {
+		return attachments, err
+	}
+
+	encrypted := make([]Attachment, len(attachments))
+	for i, a := range attachments {
+		var buf bytes.Buffer
+		w, err := openpgp.Encrypt(&buf, keys, nil, &openpgp.FileHints{IsBinary: true, FileName: a.Filename}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", a.Filename, err)
+		}
+		if _, err := w.Write(a.Data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		encrypted[i] = Attachment{Filename: a.Filename + ".pgp", Data: buf.Bytes()}
+	}
+	return encrypted, nil
+}
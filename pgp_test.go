@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// writeTestKey generates a key pair and writes the armored public key to a file.
+func writeTestKey(t *testing.T, dir, email string) *openpgp.Entity {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("Test", "", email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, _ := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	entity.Serialize(w)
+	w.Close()
+	os.WriteFile(filepath.Join(dir, email+".asc"), buf.Bytes(), 0644)
+	return entity
+}
+
+func TestEncryptAttachments(t *testing.T) {
+	dir := t.TempDir()
+	entity := writeTestKey(t, dir, "boss@example.com")
+
+	cfg := &Config{PGP: PGPConfig{Keys: map[string]string{
+		"Boss@Example.com": filepath.Join(dir, "boss@example.com.asc"),
+	}}}
+
+	got, err := encryptAttachments(cfg, []string{"boss@example.com"}, []Attachment{{Filename: "a.pdf", Data: []byte("%PDF-1.3")}})
+	if err != nil {
+		t.Fatalf("encryptAttachments() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Filename != "a.pdf.pgp" {
+		t.Fatalf("unexpected attachments: %+v", got)
+	}
+
+	md, err := openpgp.ReadMessage(bytes.NewReader(got[0].Data), openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatalf("decrypt error = %v", err)
+	}
+	plain, _ := io.ReadAll(md.UnverifiedBody)
+	if string(plain) != "%PDF-1.3" {
+		t.Errorf("decrypted = %q, want %%PDF-1.3", plain)
+	}
+}
+
+func TestEncryptAttachmentsMissingKey(t *testing.T) {
+	cfg := &Config{PGP: PGPConfig{Keys: map[string]string{"other@example.com": "/nonexistent.asc"}}}
+	if _, err := encryptAttachments(cfg, []string{"boss@example.com"}, nil); err == nil {
+		t.Error("encryptAttachments() expected error for recipient without key")
+	}
+}
+
+func TestEncryptAttachmentsDisabled(t *testing.T) {
+	in := []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}
+	got, err := encryptAttachments(&Config{}, []string{"boss@example.com"}, in)
+	if err != nil || len(got) != 1 || got[0].Filename != "a.pdf" {
+		t.Errorf("encryptAttachments() without keys = %+v, %v", got, err)
+	}
+}
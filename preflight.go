@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Pre-flight Checks
+// ---------------------------------------------------------------------------
+
+const defaultMaxConsecutiveDays = 10
+
+// PreflightConfig sets the thresholds for warnings about suspicious reports.
+type PreflightConfig struct {
+	MaxConsecutiveDays int     `yaml:"maxConsecutiveDays,omitempty"` // trips in a row at one customer (default 10)
+	MaxTotal           float64 `yaml:"maxTotal,omitempty"`           // EUR per report (0 = no check)
+	MaxDistance        int     `yaml:"maxDistance,omitempty"`        // one-way km per customer (0 = no check)
+}
+
+// maxConsecutiveDays returns the configured streak limit or the default.
+func (p PreflightConfig) maxConsecutiveDays() int {
+	if p.MaxConsecutiveDays > 0 {
+		return p.MaxConsecutiveDays
+	}
+	return defaultMaxConsecutiveDays
+}
+
+// preflight checks a generated report for suspicious outcomes and logs a
+// warning for each. customers are the customers selected for the run.
+func preflight(cfg *Config, p Period, customers []Customer, report *Report) []string {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		slog.Warn("preflight: " + msg)
+		warnings = append(warnings, msg)
+	}
+
+	days := make(map[string]int, len(report.Customers))
+	for _, c := range report.Customers {
+		days[c.Customer.ID] = len(c.Dates)
+	}
+	for _, c := range customers {
+		if days[c.ID] == 0 {
+			warn("customer %s (%s) got no days", c.ID, c.Name)
+		}
+		if cfg.Preflight.MaxDistance > 0 && c.Distance > cfg.Preflight.MaxDistance {
+			warn("customer %s (%s) is %d km away (more than %d km)", c.ID, c.Name, c.Distance, cfg.Preflight.MaxDistance)
+		}
+	}
+
+	if cfg.Preflight.MaxTotal > 0 && report.Total() > cfg.Preflight.MaxTotal {
+		warn("total %s EUR exceeds %s EUR", formatAmount(report.Total()), formatAmount(cfg.Preflight.MaxTotal))
+	}
+	if report.Budget != nil {
+		for _, w := range budgetWarnings(cfg.Budget, report.Budget) {
+			warn("%s", w)
+		}
+	}
+
+	// Long streaks at a customer already visited in the two previous months
+	// approach the three-month rule (Dreimonatsfrist) for meal allowances
+	limit := cfg.Preflight.maxConsecutiveDays()
+	streaks := longestStreaks(report)
+	for _, c := range report.Customers {
+		id := c.Customer.ID
+		if streaks[id] <= limit {
+			continue
+		}
+		if precedingMonthsWithVisits(cfg, p, id, 2) == 2 {
+			warn("customer %s has %d trips in a row and was visited in the two previous months: check the three-month rule (Dreimonatsfrist) for Verpflegungsmehraufwand", id, streaks[id])
+		}
+	}
+	return warnings
+}
+
+// longestStreaks returns, per customer ID, the longest run of trips in a row
+// without a trip to another customer in between.
+func longestStreaks(report *Report) map[string]int {
+	type trip struct{ date, id string }
+	var trips []trip
+	for _, c := range report.Customers {
+		for _, d := range c.Dates {
+			t, _ := time.Parse("02.01.2006", d)
+			trips = append(trips, trip{t.Format(isoDate), c.Customer.ID})
+		}
+	}
+	sort.Slice(trips, func(i, j int) bool { return trips[i].date < trips[j].date })
+
+	streaks := make(map[string]int)
+	run := 0
+	for i, t := range trips {
+		if i > 0 && trips[i-1].id == t.id {
+			run++
+		} else {
+			run = 1
+		}
+		if run > streaks[t.id] {
+			streaks[t.id] = run
+		}
+	}
+	return streaks
+}
+
+// precedingMonthsWithVisits counts how many of the n months before the period
+// have archived trips to the customer, stopping at the first month without.
+func precedingMonthsWithVisits(cfg *Config, p Period, customerID string, n int) int {
+	count := 0
+	month := p.Start()
+	for i := 0; i < n; i++ {
+		month = month.AddDate(0, -1, 0)
+		s, err := loadArchivedMonth(cfg, month.Year(), month.Month())
+		if err != nil || s == nil || !s.visited(customerID) {
+			break
+		}
+		count++
+	}
+	return count
+}
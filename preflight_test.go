@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreflightWarnings(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Preflight: PreflightConfig{MaxTotal: 500, MaxDistance: 150},
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 200, Province: "BW"},
+			{ID: "2", Name: "Globex", Distance: 50, Province: "BW", Schedule: Schedule{Weekdays: []string{"sun"}}},
+		},
+	}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	want := []string{
+		"customer 1 (Acme) is 200 km away (more than 150 km)",
+		"customer 2 (Globex) got no days",
+		"total 1480,00 EUR exceeds 500,00 EUR",
+	}
+	if strings.Join(report.Warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Warnings = %q, want %q", report.Warnings, want)
+	}
+	if s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil); len(s.Warnings) != 3 {
+		t.Errorf("summary warnings = %v", s.Warnings)
+	}
+}
+
+func TestPreflightThreeMonthRule(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	hasWarning := func(report *Report) bool {
+		for _, w := range report.Warnings {
+			if strings.Contains(w, "Dreimonatsfrist") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Only January is archived: no warning yet
+	archiveMonths(t, cfg, 2026, time.January)
+	report, err := generateReport(cfg, monthPeriod(2026, 3))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if hasWarning(report) {
+		t.Errorf("unexpected warning: %v", report.Warnings)
+	}
+
+	archiveMonths(t, cfg, 2026, time.February)
+	report, err = generateReport(cfg, monthPeriod(2026, 3))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if !hasWarning(report) {
+		t.Errorf("missing three-month warning: %v", report.Warnings)
+	}
+
+	// A higher threshold silences it
+	cfg.Preflight.MaxConsecutiveDays = 30
+	report, _ = generateReport(cfg, monthPeriod(2026, 3))
+	if hasWarning(report) {
+		t.Errorf("unexpected warning: %v", report.Warnings)
+	}
+}
+
+func TestLongestStreaks(t *testing.T) {
+	report := &Report{Customers: []CustomerReport{
+		{Customer: Customer{ID: "1"}, Dates: []string{"02.02.2026", "03.02.2026", "04.02.2026", "09.02.2026"}},
+		{Customer: Customer{ID: "2"}, Dates: []string{"05.02.2026", "06.02.2026"}},
+	}}
+	got := longestStreaks(report)
+	if got["1"] != 3 || got["2"] != 2 {
+		t.Errorf("longestStreaks() = %v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+// ---------------------------------------------------------------------------
+// Claim Purpose
+// ---------------------------------------------------------------------------
+
+// Purposes of the documents, which decide their wording, recipient and the
+// items that may be claimed
+const (
+	purposeReimbursement = "reimbursement" // tax-free reimbursement by the employer (default)
+	purposeTaxDeduction  = "taxDeduction"  // documentation of Werbungskosten for the tax return
+)
+
+// taxDeductionSubject is the default subject of the report mail in
+// taxDeduction mode.
+const taxDeductionSubject = "Werbungskosten-Nachweis Reisekosten {{.Period}}"
+
+// taxDeductionBody is the HTML body of the report mail in taxDeduction mode.
+const taxDeductionBody = "Nachweis der Reisekosten als Werbungskosten fuer die Steuererklaerung anbei.<br>"
+
+// purpose returns the configured purpose, reimbursement by default.
+func (c *Config) purpose() string {
+	if c.Purpose != "" {
+		return c.Purpose
+	}
+	return purposeReimbursement
+}
+
+// taxDeduction reports whether the documents are Werbungskosten documentation
+// instead of a claim to the employer.
+func (c *Config) taxDeduction() bool {
+	return c.Purpose == purposeTaxDeduction
+}
+
+// kmTitle returns the title of the kilometer document. Werbungskosten are
+// not reimbursed, so the document is named after the costs.
+func (c *Config) kmTitle() string {
+	if c.taxDeduction() {
+		return "Fahrtkosten"
+	}
+	return "Kilometergelderstattung"
+}
+
+// purposeLine returns the Zweck line of the document headers.
+func (c *Config) purposeLine() string {
+	if c.taxDeduction() {
+		return "Werbungskosten (Anlage N), keine Erstattung durch den Arbeitgeber"
+	}
+	return "Steuerfreie Erstattung durch den Arbeitgeber"
+}
+
+// recipient returns the address the reports are sent to: email.to, or in
+// taxDeduction mode the tax advisor and without one yourself (email.from),
+// since the employer does not receive the documentation.
+func (c *Config) recipient() string {
+	if !c.taxDeduction() {
+		return c.Email.To
+	}
+	if c.TaxAdvisor.Email != "" {
+		return c.TaxAdvisor.Email
+	}
+	return c.Email.From
+}
+
+// claimable returns the customers as they may be claimed. Werbungskosten
+// are limited to the statutory flat rates, so customer-specific rates agreed
+// with the employer are dropped in taxDeduction mode.
+func (c *Config) claimable(customers []Customer) []Customer {
+	if !c.taxDeduction() {
+		return customers
+	}
+	claimed := make([]Customer, len(customers))
+	for i, cust := range customers {
+		cust.KmRate, cust.PerDiemRate = 0, 0
+		claimed[i] = cust
+	}
+	return claimed
+}
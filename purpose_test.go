@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaxDeductionPurpose(t *testing.T) {
+	cfg := &Config{
+		Purpose:   purposeTaxDeduction,
+		Email:     EmailConfig{From: "me@example.com", To: "hr@example.com"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW", KmRate: 0.50, Timesheet: true}},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Only the statutory rate is deductible, timesheets are not created
+	if report.KmTotal != 600 || len(report.Attachments) != 2 {
+		t.Errorf("KmTotal = %v, attachments = %d, want 600 and 2", report.KmTotal, len(report.Attachments))
+	}
+	if km := pdfText(t, report.Attachments[0].Data); !strings.Contains(km, "FAHRTKOSTEN 02/2026") || !strings.Contains(km, "Zweck:                Werbungskosten \\(Anlage N\\)") {
+		t.Errorf("Fahrtkosten header misses title or purpose")
+	}
+
+	if got := cfg.recipient(); got != "me@example.com" {
+		t.Errorf("recipient() = %q, want email.from", got)
+	}
+	cfg.TaxAdvisor.Email = "stb@example.com"
+	if got := cfg.recipient(); got != "stb@example.com" {
+		t.Errorf("recipient() = %q, want taxAdvisor.email", got)
+	}
+	if subject, _ := reportSubject(cfg, p, report); subject != "Werbungskosten-Nachweis Reisekosten 02/2026" {
+		t.Errorf("reportSubject() = %q", subject)
+	}
+	if body := reportBody(newRunSummary(cfg, p, report, nil)); !strings.HasPrefix(body, taxDeductionBody) {
+		t.Errorf("reportBody() = %q", body)
+	}
+
+	cfg.Purpose = ""
+	if got := cfg.recipient(); got != "hr@example.com" {
+		t.Errorf("recipient() = %q, want email.to", got)
+	}
+	if got := cfg.claimable(cfg.Customers); got[0].KmRate != 0.50 {
+		t.Errorf("claimable() drops the customer rate in reimbursement mode")
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/rickar/cal/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Rate Data Updates
+// ---------------------------------------------------------------------------
+
+// defaultRateDataDir holds the versions downloaded by update-rates.
+const defaultRateDataDir = "rates"
+
+// maxRateFileSize limits the download of a rate table or its signature.
+const maxRateFileSize = 1 << 20
+
+// rateVersionRegex matches the version of a rate table, e.g. 2026.1.
+var rateVersionRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// RateDataConfig configures update-rates: where the signed rate tables are
+// published and which downloaded version is used.
+type RateDataConfig struct {
+	URL     string `yaml:"url,omitempty"`     // YAML rate table, signed by the detached signature at url + ".asc"
+	Key     string `yaml:"key,omitempty"`     // OpenPGP public key file of the publisher (armored or binary)
+	Dir     string `yaml:"dir,omitempty"`     // directory of the downloaded versions (default: rates)
+	Version string `yaml:"version,omitempty"` // pinned version (default: the latest downloaded)
+}
+
+// dir returns the directory of the downloaded versions.
+func (r RateDataConfig) dir() string {
+	if r.Dir != "" {
+		return r.Dir
+	}
+	return defaultRateDataDir
+}
+
+// rateTable is a published version of the statutory rates and of the public
+// holidays missing from the built-in calendars.
+type rateTable struct {
+	Version   string        `yaml:"version"`             // e.g. 2026.1
+	Published string        `yaml:"published,omitempty"` // YYYY-MM-DD
+	Rates     []Rates       `yaml:"rates"`
+	Holidays  []rateHoliday `yaml:"holidays,omitempty"`
+}
+
+// rateHoliday is a public holiday of some states on a single day, e.g. a
+// one-off holiday declared by a state.
+type rateHoliday struct {
+	Date      string   `yaml:"date"` // YYYY-MM-DD
+	Name      string   `yaml:"name"`
+	Provinces []string `yaml:"provinces"` // state abbreviations (BW, BY, ...)
+}
+
+// parseRateTable decodes and checks a rate table.
+func parseRateTable(data []byte) (*rateTable, error) {
+	var t rateTable
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("failed to parse rate table: %w", err)
+	}
+
+	var errs []error
+	if !rateVersionRegex.MatchString(t.Version) {
+		errs = append(errs, fmt.Errorf("version: invalid version %q (use e.g. 2026.1)", t.Version))
+	}
+	if len(t.Rates) == 0 {
+		errs = append(errs, errors.New("rates: required"))
+	}
+	for i, r := range t.Rates {
+		if r.From < 2000 || r.KmRate <= 0 || r.PerDiemPartial <= 0 || r.PerDiemFull <= 0 {
+			errs = append(errs, fmt.Errorf("rates[%d]: from, kmRate, perDiemPartial and perDiemFull are required", i))
+		}
+	}
+	for i, h := range t.Holidays {
+		if _, err := time.Parse(isoDate, h.Date); err != nil {
+			errs = append(errs, fmt.Errorf("holidays[%d].date: invalid date %q (use YYYY-MM-DD)", i, h.Date))
+		}
+		if h.Name == "" {
+			errs = append(errs, fmt.Errorf("holidays[%d].name: required", i))
+		}
+		for _, p := range h.Provinces {
+			if _, ok := provinceHolidays[p]; !ok {
+				errs = append(errs, fmt.Errorf("holidays[%d].provinces: invalid province %q", i, p))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid rate table: %w", err)
+	}
+	return &t, nil
+}
+
+// verifyRateTable checks the detached signature of a rate table against the
+// publisher's key.
+func verifyRateTable(keyFile string, data, signature []byte) error {
+	keys, err := readPublicKey(keyFile)
+	if err != nil {
+		return err
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keys, bytes.NewReader(data), bytes.NewReader(signature), nil); err != nil {
+		return fmt.Errorf("rate table signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// versionLess compares two versions number by number, so 2026.10 follows
+// 2026.9.
+func versionLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// fetchRateFile downloads a rate table or its signature.
+func fetchRateFile(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("download of %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRateFileSize))
+}
+
+// updateRates downloads the published rate table, verifies its signature and
+// stores it with the signature as <version>.yaml in the rate data directory.
+// Stored versions are never replaced, so earlier reports stay reproducible.
+func updateRates(cfg *Config, w io.Writer) error {
+	r := cfg.RateData
+	if r.URL == "" || r.Key == "" {
+		return errors.New("rateData.url and rateData.key are required to update the rates")
+	}
+	data, err := fetchRateFile(r.URL)
+	if err != nil {
+		return err
+	}
+	signature, err := fetchRateFile(r.URL + ".asc")
+	if err != nil {
+		return err
+	}
+	if err := verifyRateTable(r.Key, data, signature); err != nil {
+		return err
+	}
+	t, err := parseRateTable(data)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir(), t.Version+".yaml")
+	if _, err := os.Stat(path); err == nil {
+		fmt.Fprintf(w, "rate data %s is up to date (%s)\n", t.Version, path)
+		return nil
+	}
+	if err := os.MkdirAll(r.dir(), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".asc", signature, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	slog.Info("rate data stored", "version", t.Version, "path", path, "rates", len(t.Rates), "holidays", len(t.Holidays))
+	fmt.Fprintf(w, "rate data %s stored in %s (%d rates, %d holidays)\n", t.Version, path, len(t.Rates), len(t.Holidays))
+	if r.Version != "" && r.Version != t.Version {
+		fmt.Fprintf(w, "rateData.version pins %s, set it to %s to use the new rates\n", r.Version, t.Version)
+	}
+	return nil
+}
+
+// loadRateTable reads the pinned or the latest downloaded rate table, or nil
+// if none has been downloaded. With a key configured the signature is
+// checked again, so an edited file is never used.
+func loadRateTable(cfg *Config) (*rateTable, error) {
+	r := cfg.RateData
+	files, err := filepath.Glob(filepath.Join(r.dir(), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, f := range files {
+		if v := strings.TrimSuffix(filepath.Base(f), ".yaml"); rateVersionRegex.MatchString(v) {
+			versions = append(versions, v)
+		}
+	}
+	version := r.Version
+	switch {
+	case version != "" && !contains(versions, version):
+		return nil, fmt.Errorf("rate data %s pinned by rateData.version is not in %s (run update-rates)", version, r.dir())
+	case version == "" && len(versions) == 0:
+		return nil, nil
+	case version == "":
+		sort.Slice(versions, func(i, j int) bool { return versionLess(versions[i], versions[j]) })
+		version = versions[len(versions)-1]
+	}
+
+	path := filepath.Join(r.dir(), version+".yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate data: %w", err)
+	}
+	if r.Key != "" {
+		signature, err := os.ReadFile(path + ".asc")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rate data signature: %w", err)
+		}
+		if err := verifyRateTable(r.Key, data, signature); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	t, err := parseRateTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if t.Version != version {
+		return nil, fmt.Errorf("%s: contains version %s", path, t.Version)
+	}
+	slog.Debug("rate data loaded", "version", t.Version, "path", path)
+	return t, nil
+}
+
+// addHolidays adds the table's holidays to the calendars of the customers in
+// their states. A nil table adds nothing.
+func (t *rateTable) addHolidays(calendars []*cal.BusinessCalendar, customers []Customer) {
+	if t == nil {
+		return
+	}
+	for _, h := range t.Holidays {
+		date, _ := time.Parse(isoDate, h.Date)
+		holiday := &cal.Holiday{
+			Name:      h.Name,
+			Type:      cal.ObservancePublic,
+			StartYear: date.Year(),
+			EndYear:   date.Year(),
+			Month:     date.Month(),
+			Day:       date.Day(),
+			Func:      cal.CalcDayOfMonth,
+		}
+		for i, c := range customers {
+			if contains(h.Provinces, c.Province) {
+				calendars[i].AddHoliday(holiday)
+			}
+		}
+	}
+}
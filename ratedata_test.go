@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const testRateTable = `version: "2027.1"
+published: 2026-12-01
+rates:
+  - from: 2027
+    kmRate: 0.32
+    perDiemPartial: 15
+    perDiemFull: 30
+    overnight: 20
+holidays:
+  - date: 2026-03-10
+    name: Landesjubilaeum
+    provinces: [BW]
+`
+
+// serveRateTable serves a rate table and its armored detached signature.
+func serveRateTable(t *testing.T, entity *openpgp.Entity, data, served string) *httptest.Server {
+	t.Helper()
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, entity, strings.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rates.yaml":
+			w.Write([]byte(served))
+		case "/rates.yaml.asc":
+			w.Write(sig.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUpdateRates(t *testing.T) {
+	dir := t.TempDir()
+	entity := writeTestKey(t, dir, "rates@example.com")
+	srv := serveRateTable(t, entity, testRateTable, testRateTable)
+	cfg := &Config{RateData: RateDataConfig{
+		URL: srv.URL + "/rates.yaml",
+		Key: filepath.Join(dir, "rates@example.com.asc"),
+		Dir: filepath.Join(dir, "rates"),
+	}}
+
+	var out bytes.Buffer
+	if err := updateRates(cfg, &out); err != nil {
+		t.Fatalf("updateRates() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "rate data 2027.1 stored") {
+		t.Errorf("output = %q", out.String())
+	}
+	for _, name := range []string{"2027.1.yaml", "2027.1.yaml.asc"} {
+		if _, err := os.Stat(filepath.Join(dir, "rates", name)); err != nil {
+			t.Errorf("%s not stored: %v", name, err)
+		}
+	}
+
+	out.Reset()
+	if err := updateRates(cfg, &out); err != nil || !strings.Contains(out.String(), "up to date") {
+		t.Errorf("second updateRates() = %q, %v", out.String(), err)
+	}
+
+	// The stored version is verified again and extends the statutory rates
+	table, err := loadRateTable(cfg)
+	if err != nil || table == nil {
+		t.Fatalf("loadRateTable() = %v, %v", table, err)
+	}
+	cfg.Downloaded = table
+	if r, _ := cfg.ratesFor(2027); r.KmRate != 0.32 || r.PerDiemPartial != 15 {
+		t.Errorf("ratesFor(2027) = %+v, want downloaded rates", r)
+	}
+	if r, _ := cfg.ratesFor(2026); r.KmRate != 0.30 {
+		t.Errorf("ratesFor(2026) = %+v, want statutory rates", r)
+	}
+	cfg.Rates = []Rates{{From: 2027, KmRate: 0.35, PerDiemPartial: 15, PerDiemFull: 30}}
+	if r, _ := cfg.ratesFor(2027); r.KmRate != 0.35 {
+		t.Errorf("ratesFor(2027) = %+v, want config rates", r)
+	}
+
+	// An edited file is rejected
+	path := filepath.Join(dir, "rates", "2027.1.yaml")
+	os.WriteFile(path, []byte(strings.Replace(testRateTable, "0.32", "0.42", 1)), 0644)
+	if _, err := loadRateTable(cfg); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("loadRateTable() of an edited file error = %v", err)
+	}
+}
+
+func TestUpdateRatesInvalidSignature(t *testing.T) {
+	dir := t.TempDir()
+	entity := writeTestKey(t, dir, "rates@example.com")
+	srv := serveRateTable(t, entity, testRateTable, strings.Replace(testRateTable, "0.32", "0.42", 1))
+	cfg := &Config{RateData: RateDataConfig{
+		URL: srv.URL + "/rates.yaml",
+		Key: filepath.Join(dir, "rates@example.com.asc"),
+		Dir: filepath.Join(dir, "rates"),
+	}}
+
+	if err := updateRates(cfg, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "signature is invalid") {
+		t.Errorf("updateRates() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.RateData.Dir); !os.IsNotExist(err) {
+		t.Errorf("rate data stored despite an invalid signature")
+	}
+
+	cfg.RateData.URL = srv.URL + "/missing.yaml"
+	if err := updateRates(cfg, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("updateRates() of a missing file error = %v", err)
+	}
+}
+
+func TestLoadRateTableVersions(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{RateData: RateDataConfig{Dir: dir}}
+	if table, err := loadRateTable(cfg); table != nil || err != nil {
+		t.Errorf("loadRateTable() without data = %v, %v", table, err)
+	}
+
+	for _, v := range []string{"2026.9", "2026.10", "2025.3"} {
+		data := strings.Replace(testRateTable, "2027.1", v, 1)
+		os.WriteFile(filepath.Join(dir, v+".yaml"), []byte(data), 0644)
+	}
+	if table, err := loadRateTable(cfg); err != nil || table.Version != "2026.10" {
+		t.Errorf("loadRateTable() = %v, %v, want the latest version 2026.10", table, err)
+	}
+
+	cfg.RateData.Version = "2026.9"
+	if table, err := loadRateTable(cfg); err != nil || table.Version != "2026.9" {
+		t.Errorf("loadRateTable() = %v, %v, want the pinned version", table, err)
+	}
+	cfg.RateData.Version = "2027.1"
+	if _, err := loadRateTable(cfg); err == nil || !strings.Contains(err.Error(), "update-rates") {
+		t.Errorf("loadRateTable() of a missing pinned version error = %v", err)
+	}
+}
+
+func TestParseRateTable(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{testRateTable, ""},
+		{strings.Replace(testRateTable, `"2027.1"`, "latest", 1), "invalid version"},
+		{strings.Replace(testRateTable, "kmRate: 0.32", "kmRate: 0", 1), "rates[0]"},
+		{strings.Replace(testRateTable, "2026-03-10", "10.03.2026", 1), "holidays[0].date"},
+		{strings.Replace(testRateTable, "[BW]", "[XX]", 1), "invalid province"},
+		{testRateTable + "foreign: true\n", "field foreign not found"},
+	}
+	for _, tt := range tests {
+		_, err := parseRateTable([]byte(tt.data))
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("parseRateTable() error = %v, want %q", err, tt.wantErr)
+		}
+	}
+}
+
+func TestRateTableHolidays(t *testing.T) {
+	table, err := parseRateTable([]byte(testRateTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}}
+	report, err := generateReport(cfg, monthPeriod(2026, 3))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 22 {
+		t.Fatalf("Workdays = %d, want 22", report.Workdays)
+	}
+
+	cfg.Downloaded = table
+	if report, err = generateReport(cfg, monthPeriod(2026, 3)); err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 21 || contains(report.Customers[0].Dates, "10.03.2026") {
+		t.Errorf("Workdays = %d, dates = %v, want 21 without the downloaded holiday", report.Workdays, report.Customers[0].Dates)
+	}
+
+	// Customers in other states keep the day
+	cfg.Customers[0].Province = "BY"
+	if report, _ = generateReport(cfg, monthPeriod(2026, 3)); report.Workdays != 22 {
+		t.Errorf("Workdays in BY = %d, want 22", report.Workdays)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ---------------------------------------------------------------------------
+// Statutory Rates
+// ---------------------------------------------------------------------------
+
+// Rates are the reimbursement rates valid from a given year on.
+type Rates struct {
+	From           int     `yaml:"from"`           // first year the rates apply to
+	KmRate         float64 `yaml:"kmRate"`         // EUR per km (Pkw)
+	PerDiemPartial float64 `yaml:"perDiemPartial"` // meal allowance for more than 8h, arrival and departure days
+	PerDiemFull    float64 `yaml:"perDiemFull"`    // meal allowance for a full 24h day
+	Overnight      float64 `yaml:"overnight"`      // tax-free overnight flat rate
+
+	CommuteRate    float64 `yaml:"commuteRate,omitempty"`    // Entfernungspauschale per one-way km up to 20 km (default 0.30)
+	CommuteRateFar float64 `yaml:"commuteRateFar,omitempty"` // Entfernungspauschale from the 21st km (default commuteRate)
+
+	BikeRate float64      `yaml:"-"` // EUR per km by bike, bikeRate of the config (there is no statutory rate)
+	Actual   []actualRate `yaml:"-"` // individual km rates of the vehicles with actual costs
+}
+
+// statutoryRates lists the German rates (§ 9 Abs. 4a EStG, R 9.7 LStR) since
+// the 2014 travel expense reform, in ascending order.
+var statutoryRates = []Rates{
+	{From: 2014, KmRate: 0.30, PerDiemPartial: 12, PerDiemFull: 24, Overnight: 20, CommuteRate: 0.30, CommuteRateFar: 0.30},
+	{From: 2020, KmRate: kmRatePerKm, PerDiemPartial: verpflegungRate, PerDiemFull: 28, Overnight: 20, CommuteRate: 0.30, CommuteRateFar: 0.30},
+	{From: 2021, KmRate: kmRatePerKm, PerDiemPartial: verpflegungRate, PerDiemFull: 28, Overnight: 20, CommuteRate: 0.30, CommuteRateFar: 0.35},
+	{From: 2022, KmRate: kmRatePerKm, PerDiemPartial: verpflegungRate, PerDiemFull: 28, Overnight: 20, CommuteRate: 0.30, CommuteRateFar: 0.38},
+}
+
+// ratesFor returns the rates valid in year. Entries from the config's rates
+// list take precedence over the rate data of update-rates, which takes
+// precedence over the built-in table for the same starting year.
+func (c *Config) ratesFor(year int) (Rates, error) {
+	table := make(map[int]Rates, len(statutoryRates)+len(c.Rates))
+	for _, r := range statutoryRates {
+		table[r.From] = r
+	}
+	if c.Downloaded != nil {
+		for _, r := range c.Downloaded.Rates {
+			table[r.From] = r
+		}
+	}
+	for _, r := range c.Rates {
+		table[r.From] = r
+	}
+
+	years := make([]int, 0, len(table))
+	for y := range table {
+		years = append(years, y)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+	for _, y := range years {
+		if y <= year {
+			r := table[y]
+			r.BikeRate = c.BikeRate
+			return r, nil
+		}
+	}
+	return Rates{}, fmt.Errorf("no rates known for %d (earliest: %d); add them to the rates list in the config", year, years[len(years)-1])
+}
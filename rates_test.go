@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRatesFor(t *testing.T) {
+	cfg := &Config{}
+	tests := []struct {
+		year        int
+		partial     float64
+		full        float64
+		wantErr     bool
+		description string
+	}{
+		{2013, 0, 0, true, "before the table"},
+		{2019, 12, 24, false, "2014 rates"},
+		{2020, 14, 28, false, "2020 rates"},
+		{2026, 14, 28, false, "latest rates"},
+	}
+	for _, tt := range tests {
+		r, err := cfg.ratesFor(tt.year)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ratesFor(%d) error = %v", tt.description, tt.year, err)
+			continue
+		}
+		if r.PerDiemPartial != tt.partial || r.PerDiemFull != tt.full {
+			t.Errorf("%s: ratesFor(%d) = %+v", tt.description, tt.year, r)
+		}
+	}
+
+	// Config entries extend and override the built-in table
+	cfg.Rates = []Rates{{From: 2027, KmRate: 0.32, PerDiemPartial: 15, PerDiemFull: 30, Overnight: 20}}
+	if r, _ := cfg.ratesFor(2028); r.KmRate != 0.32 {
+		t.Errorf("ratesFor(2028) = %+v, want config rates", r)
+	}
+	if r, _ := cfg.ratesFor(2026); r.KmRate != 0.30 {
+		t.Errorf("ratesFor(2026) = %+v, want statutory rates", r)
+	}
+}
+
+func TestGenerateReportHistoricalRates(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{{ID: "1", Name: "Acme", Distance: 10, Province: "BW"}}}
+
+	// March 2019 uses the rates valid from 2014
+	report, err := generateReport(cfg, monthPeriod(2019, 3))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Customers[0].VerpRate != 12 || report.VerpTotal != 12*float64(report.Workdays) {
+		t.Errorf("VerpRate = %v, VerpTotal = %v, want 12 per day", report.Customers[0].VerpRate, report.VerpTotal)
+	}
+
+	if _, err := generateReport(cfg, monthPeriod(2012, 3)); err == nil || !strings.Contains(err.Error(), "no rates known for 2012") {
+		t.Errorf("generateReport(2012) error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Exporters and Deliverers
+// ---------------------------------------------------------------------------
+
+// Exporter produces an additional output of a generated report, e.g. a
+// calendar. It is called for every delivered report and does nothing unless
+// its own config section is set.
+type Exporter interface {
+	Export(cfg *Config, p Period, report *Report) error
+}
+
+// Deliverer sends a mail through one delivery channel, selected by
+// email.provider.
+type Deliverer interface {
+	Deliver(cfg *Config, m Mail) error
+}
+
+// ExporterFunc adapts a function to the Exporter interface.
+type ExporterFunc func(cfg *Config, p Period, report *Report) error
+
+func (f ExporterFunc) Export(cfg *Config, p Period, report *Report) error { return f(cfg, p, report) }
+
+// DelivererFunc adapts a function to the Deliverer interface.
+type DelivererFunc func(cfg *Config, m Mail) error
+
+func (f DelivererFunc) Deliver(cfg *Config, m Mail) error { return f(cfg, m) }
+
+var (
+	exporters  = map[string]Exporter{}
+	deliverers = map[string]Deliverer{}
+)
+
+// registerExporter makes an exporter available under a name. Exporters
+// register themselves from an init function in their own file.
+func registerExporter(name string, e Exporter) {
+	if _, ok := exporters[name]; ok {
+		panic("exporter registered twice: " + name)
+	}
+	exporters[name] = e
+}
+
+// registerDeliverer makes a delivery channel available as email.provider.
+// Deliverers register themselves from an init function in their own file.
+func registerDeliverer(name string, d Deliverer) {
+	if _, ok := deliverers[name]; ok {
+		panic("deliverer registered twice: " + name)
+	}
+	deliverers[name] = d
+}
+
+// deliverer returns the delivery channel of the configured provider
+// (default: smtp).
+func deliverer(cfg *Config) (Deliverer, error) {
+	provider := cfg.Email.Provider
+	if provider == "" {
+		provider = "smtp"
+	}
+	d, ok := deliverers[provider]
+	if !ok {
+		return nil, permanent(fmt.Errorf("unknown email provider %q (use %s)", provider, registryNames(deliverers)))
+	}
+	return d, nil
+}
+
+// exportReport runs all registered exporters in name order. Export failures
+// are only reported as warnings.
+func exportReport(cfg *Config, p Period, report *Report) {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := exporters[name].Export(cfg, p, report); err != nil {
+			slog.Warn("export failed", "exporter", name, "error", err)
+		}
+	}
+}
+
+// registryNames lists the registered names for error messages,
+// e.g. "eml, maildir or smtp".
+func registryNames[T any](registry map[string]T) string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var sent []string
+	registerDeliverer("test", DelivererFunc(func(cfg *Config, m Mail) error {
+		sent = append(sent, m.Subject)
+		return nil
+	}))
+	var exported []string
+	registerExporter("test", ExporterFunc(func(cfg *Config, p Period, report *Report) error {
+		exported = append(exported, p.Key())
+		return errors.New("upload failed") // only logged
+	}))
+	t.Cleanup(func() {
+		delete(deliverers, "test")
+		delete(exporters, "test")
+	})
+
+	cfg := &Config{Email: EmailConfig{Provider: "test"}}
+	if err := sendEmail(cfg, Mail{Subject: "Hallo"}); err != nil || len(sent) != 1 || sent[0] != "Hallo" {
+		t.Errorf("sendEmail() = %v, sent %v", err, sent)
+	}
+	exportReport(cfg, monthPeriod(2026, 2), &Report{})
+	if len(exported) != 1 || exported[0] != "2026-02" {
+		t.Errorf("exported = %v", exported)
+	}
+
+	cfg.Email.Provider = "carrier-pigeon"
+	_, err := deliverer(cfg)
+	if err == nil || !strings.Contains(err.Error(), "eml, gmail, graph, maildir, mailgun, sendgrid, smtp or test") {
+		t.Errorf("deliverer() error = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Archive Retention
+// ---------------------------------------------------------------------------
+
+// RetentionConfig limits how long archived reports are kept.
+type RetentionConfig struct {
+	Years int `yaml:"years,omitempty"` // full calendar years kept after the current one, e.g. 10 (§ 147 AO)
+}
+
+// pruneCandidates returns the archive entries (summaries and document
+// directories) of periods whose retention has expired at now. The retention
+// starts at the end of the period's calendar year.
+func pruneCandidates(cfg *Config, now time.Time) ([]string, error) {
+	if cfg.Retention.Years <= 0 {
+		return nil, errors.New("no retention configured (set retention.years)")
+	}
+	entries, err := os.ReadDir(cfg.ArchiveDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	oldest := now.Year() - cfg.Retention.Years
+	var paths []string
+	for _, e := range entries {
+		key, _, _ := strings.Cut(e.Name(), ".")
+		p, ok := parsePeriodKey(key)
+		if ok && p.Year < oldest {
+			paths = append(paths, filepath.Join(cfg.ArchiveDir(), e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// prune lists the expired archive entries on out and deletes them after
+// confirmation on in, or right away with yes.
+func prune(cfg *Config, now time.Time, yes bool, in io.Reader, out io.Writer) error {
+	paths, err := pruneCandidates(cfg, now)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(out, "nothing to prune, all archived reports are from %d or later\n", now.Year()-cfg.Retention.Years)
+		return nil
+	}
+
+	fmt.Fprintf(out, "archived reports before %d to delete:\n", now.Year()-cfg.Retention.Years)
+	for _, path := range paths {
+		fmt.Fprintf(out, "  %s\n", path)
+	}
+	if !yes {
+		fmt.Fprintf(out, "delete %d entries? [y/N] ", len(paths))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Fprintln(out, "aborted, nothing deleted")
+			return nil
+		}
+	}
+
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to prune archive: %w", err)
+		}
+	}
+	slog.Info("archive pruned", "entries", len(paths), "retention_years", cfg.Retention.Years)
+	audit(cfg, "prune", Period{}, nil, fmt.Sprintf("%d entries before %d", len(paths), now.Year()-cfg.Retention.Years))
+	return nil
+}
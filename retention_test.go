@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Archive: dir, Retention: RetentionConfig{Years: 10}}
+	for _, name := range []string{"2015-12.json", "2015-Q4.json", "2016-01.json", "2026-02.json", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600)
+	}
+	os.MkdirAll(filepath.Join(dir, "2015-12"), 0o700)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := prune(cfg, now, false, strings.NewReader("n\n"), &out); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "delete 3 entries?") || !strings.Contains(out.String(), "aborted") {
+		t.Errorf("output = %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2015-12.json")); err != nil {
+		t.Error("prune() deleted without confirmation")
+	}
+
+	out.Reset()
+	if err := prune(cfg, now, true, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("prune(yes) error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "2016-01.json,2026-02.json,notes.txt" {
+		t.Errorf("archive after prune = %v", names)
+	}
+
+	if err := prune(&Config{Archive: dir}, now, true, nil, &out); err == nil {
+		t.Error("prune() expected error without retention.years")
+	}
+}
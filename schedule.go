@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Customer Schedules
+// ---------------------------------------------------------------------------
+
+// Schedule restricts the days on which a customer is visited, e.g. standing
+// appointments. An empty schedule allows every workday.
+type Schedule struct {
+	Weekdays     []string `yaml:"weekdays,omitempty"`     // only on these days (mon, tue, wed, thu, fri)
+	NotWeekdays  []string `yaml:"notWeekdays,omitempty"`  // never on these days
+	WeeksOfMonth []int    `yaml:"weeksOfMonth,omitempty"` // only in these weeks of the month (1-5, week 1 = days 1-7)
+	MaxPerWeek   int      `yaml:"maxPerWeek,omitempty"`   // at most this many days per calendar week (0 = unlimited)
+}
+
+// weekdayNames maps the weekday names used in schedules.
+var weekdayNames = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// onWeekday reports whether date falls on one of the named weekdays.
+func onWeekday(names []string, date time.Time) bool {
+	for _, n := range names {
+		if weekdayNames[strings.ToLower(n)] == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// weekOfMonth returns 1 for days 1-7, 2 for days 8-14 and so on, so that
+// "first week" means the first occurrence of each weekday.
+func weekOfMonth(date time.Time) int {
+	return (date.Day()-1)/7 + 1
+}
+
+// appointment reports whether the schedule names specific days, which then
+// take precedence over customers without such a schedule.
+func (s Schedule) appointment() bool {
+	return len(s.Weekdays) > 0 || len(s.WeeksOfMonth) > 0
+}
+
+// weekVisits counts the days assigned to each customer per ISO week.
+type weekVisits map[[3]int]int
+
+// add records a visit of customer i on date.
+func (v weekVisits) add(i int, date time.Time) {
+	year, week := date.ISOWeek()
+	v[[3]int{i, year, week}]++
+}
+
+// count returns the visits of customer i in the week containing date.
+func (v weekVisits) count(i int, date time.Time) int {
+	year, week := date.ISOWeek()
+	return v[[3]int{i, year, week}]
+}
+
+// pickCustomer returns the customer due on date, preferring customers with a
+// standing appointment on that day. Customers that reached their weekly cap
+// are skipped. It returns -1 if no customer is eligible.
+func pickCustomer(d *dayDistributor, customers []Customer, visits weekVisits, date time.Time) int {
+	allowed := func(i int) bool {
+		s := customers[i].Schedule
+		return s.allows(date) && (s.MaxPerWeek == 0 || visits.count(i, date) < s.MaxPerWeek)
+	}
+	if i := d.nextEligible(func(i int) bool { return allowed(i) && customers[i].Schedule.appointment() }); i >= 0 {
+		return i
+	}
+	return d.nextEligible(allowed)
+}
+
+// newOfficeDistributor spreads office days evenly over the workdays so that
+// share percent of them get no trip: next returns 1 for an office day. It
+// returns nil if office days are disabled.
+func newOfficeDistributor(share int) *dayDistributor {
+	if share <= 0 {
+		return nil
+	}
+	return newDayDistributor([]int{100 - share, share})
+}
+
+// allows reports whether the schedule permits a visit on date.
+func (s Schedule) allows(date time.Time) bool {
+	if len(s.Weekdays) > 0 && !onWeekday(s.Weekdays, date) {
+		return false
+	}
+	if onWeekday(s.NotWeekdays, date) {
+		return false
+	}
+	if len(s.WeeksOfMonth) > 0 {
+		week := weekOfMonth(date)
+		for _, w := range s.WeeksOfMonth {
+			if w == week {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleAllows(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Schedule
+		date string
+		want bool
+	}{
+		{"empty", Schedule{}, "2026-02-02", true},
+		{"weekday", Schedule{Weekdays: []string{"tue", "Thu"}}, "2026-02-05", true},
+		{"other weekday", Schedule{Weekdays: []string{"tue", "thu"}}, "2026-02-04", false},
+		{"never on mondays", Schedule{NotWeekdays: []string{"mon"}}, "2026-02-02", false},
+		{"first week", Schedule{WeeksOfMonth: []int{1, 3}}, "2026-02-07", true},
+		{"second week", Schedule{WeeksOfMonth: []int{1, 3}}, "2026-02-08", false},
+		{"third week", Schedule{WeeksOfMonth: []int{1, 3}}, "2026-02-21", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, _ := time.Parse(isoDate, tt.date)
+			if got := tt.s.allows(date); got != tt.want {
+				t.Errorf("allows(%s) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateReportWithSchedules(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{NotWeekdays: []string{"mon"}}},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW", Schedule: Schedule{Weekdays: []string{"tue"}, WeeksOfMonth: []int{1, 3}}},
+	}}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Globex gets the first and third Tuesday, Mondays stay unassigned
+	if got := strings.Join(report.Customers[1].Dates, ","); got != "03.02.2026,17.02.2026" {
+		t.Errorf("Globex dates = %s", got)
+	}
+	for _, d := range report.Customers[0].Dates {
+		if date, _ := time.Parse("02.01.2006", d); date.Weekday() == time.Monday {
+			t.Errorf("Acme visited on Monday %s", d)
+		}
+	}
+	if report.Workdays != 16 {
+		t.Errorf("Workdays = %d, want 16 (20 minus 4 Mondays)", report.Workdays)
+	}
+}
+
+func TestGenerateReportWithWeeklyCaps(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{MaxPerWeek: 2}},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW", Schedule: Schedule{MaxPerWeek: 1}},
+	}}
+
+	// February 2026 has four full weeks; two days per week stay unassigned
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 12 || len(report.Customers[0].Dates) != 8 || len(report.Customers[1].Dates) != 4 {
+		t.Errorf("Workdays = %d, Acme = %v, Globex = %v", report.Workdays, report.Customers[0].Dates, report.Customers[1].Dates)
+	}
+
+	// Without a cap on Globex it takes the remaining days
+	cfg.Customers[1].Schedule.MaxPerWeek = 0
+	report, err = generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 20 || len(report.Customers[0].Dates) != 8 {
+		t.Errorf("Workdays = %d, Acme = %v", report.Workdays, report.Customers[0].Dates)
+	}
+}
+
+func TestGenerateReportWithOfficeDays(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), OfficeShare: 40, Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+	}}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if report.Workdays != 12 || report.OfficeDays != 8 {
+		t.Errorf("Workdays = %d, OfficeDays = %d, want 12 and 8", report.Workdays, report.OfficeDays)
+	}
+	// Office days are spread out: never two in a row
+	if got := report.Customers[0].Dates[:3]; strings.Join(got, ",") != "02.02.2026,04.02.2026,06.02.2026" {
+		t.Errorf("first trips = %v", got)
+	}
+
+	// Standing appointments are never turned into office days
+	cfg.Customers = append(cfg.Customers, Customer{ID: "2", Name: "Globex", Distance: 50, Province: "BW",
+		Schedule: Schedule{Weekdays: []string{"tue"}}})
+	report, err = generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if len(report.Customers[1].Dates) != 4 || report.Workdays+report.OfficeDays != 20 {
+		t.Errorf("Globex = %v, Workdays = %d, OfficeDays = %d", report.Customers[1].Dates, report.Workdays, report.OfficeDays)
+	}
+}
+
+func TestParseConfigSchedule(t *testing.T) {
+	data := `email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BY
+    schedule:
+      weekdays: [tue, thursday]
+      weeksOfMonth: [1, 6]
+      maxPerWeek: 8
+`
+	_, err := parseConfig("config.yaml", []byte(data), "")
+	if err == nil {
+		t.Fatal("parseConfig() expected error")
+	}
+	for _, want := range []string{
+		`line 11: customers[0].schedule.weekdays[1]: invalid weekday "thursday"`,
+		"line 12: customers[0].schedule.weeksOfMonth[1]: must be between 1 and 5",
+		"line 13: customers[0].schedule.maxPerWeek: must be between 0 and 7",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() missing %q:\n%v", want, err)
+		}
+	}
+}
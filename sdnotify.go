@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"os"
+)
+
+// ---------------------------------------------------------------------------
+// systemd Notification
+// ---------------------------------------------------------------------------
+
+// sdNotify sends state (e.g. READY=1 or STATUS=...) to systemd when serve
+// mode runs as a unit with Type=notify. Without $NOTIFY_SOCKET it does
+// nothing; a failure is only logged.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	if err := notifySocket(socket, state); err != nil {
+		slog.Warn("sd_notify failed", "socket", socket, "error", err)
+	}
+}
+
+// notifySocket writes state to the datagram socket of the service manager.
+// A leading @ names a socket in the abstract namespace.
+func notifySocket(socket, state string) error {
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestSDNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	sdNotify("READY=1\nSTATUS=waiting for the next report")
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "READY=1\nSTATUS=waiting for the next report" {
+		t.Errorf("received %q, %v", buf[:n], err)
+	}
+
+	if err := notifySocket(filepath.Join(t.TempDir(), "missing.sock"), "READY=1"); err == nil {
+		t.Error("notifySocket(missing socket) error = nil")
+	}
+	if got := (&metrics{done: "2026-02"}).status(); got != "report 2026-02 done, waiting for the next report" {
+		t.Errorf("status() = %q", got)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Secrets (Vault, SOPS)
+// ---------------------------------------------------------------------------
+
+// vaultPrefix marks config values that are read from HashiCorp Vault,
+// e.g. "vault:secret/reisekosten#smtp_pass".
+const vaultPrefix = "vault:"
+
+// sopsDecrypt decrypts a SOPS-encrypted file (variable for tests).
+var sopsDecrypt = func(path string) ([]byte, error) {
+	cmd := exec.Command("sops", "--decrypt", "--output-type", "yaml", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sops --decrypt failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// isSOPSFile reports whether data is a SOPS-encrypted YAML document, which
+// carries a top-level "sops" metadata key.
+func isSOPSFile(data []byte) bool {
+	var doc struct {
+		SOPS *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+	return yaml.Unmarshal(data, &doc) == nil && doc.SOPS != nil && doc.SOPS.MAC != ""
+}
+
+// vaultClient reads secrets from the KV version 2 engine of a Vault server.
+type vaultClient struct {
+	addr      string
+	token     string
+	namespace string
+	cache     map[string]map[string]any // secret path -> data
+}
+
+// newVaultClient configures the client from VAULT_ADDR, VAULT_TOKEN (or
+// ~/.vault-token as written by "vault login") and VAULT_NAMESPACE.
+func newVaultClient() (*vaultClient, error) {
+	c := &vaultClient{
+		addr:      strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		cache:     make(map[string]map[string]any),
+	}
+	if c.addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	if c.token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				c.token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if c.token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token does not exist")
+	}
+	return c, nil
+}
+
+// lookup resolves a reference of the form "<mount>/<path>#<key>".
+func (c *vaultClient) lookup(ref string) (string, error) {
+	secretPath, key, ok := strings.Cut(ref, "#")
+	mount, path, hasPath := strings.Cut(secretPath, "/")
+	if !ok || key == "" || !hasPath || path == "" {
+		return "", fmt.Errorf("invalid vault reference %q (use vault:<mount>/<path>#<key>)", vaultPrefix+ref)
+	}
+
+	data, ok := c.cache[secretPath]
+	if !ok {
+		var err error
+		if data, err = c.read(mount, path); err != nil {
+			return "", err
+		}
+		c.cache[secretPath] = data
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %q", secretPath, key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s key %q is not a string", secretPath, key)
+	}
+	return s, nil
+}
+
+// read fetches the latest version of a KV v2 secret.
+func (c *vaultClient) read(mount, path string) (map[string]any, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", c.addr, mount, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault read %s/%s failed (HTTP %d): %s", mount, path, resp.StatusCode, strings.Join(body.Errors, "; "))
+	}
+	return body.Data.Data, nil
+}
+
+// resolveSecrets replaces all "vault:" references in the string fields of
+// cfg with the referenced secrets. Vault is only contacted if references exist.
+func resolveSecrets(cfg *Config) error {
+	var client *vaultClient
+	resolved := 0
+	resolve := func(s string) (string, error) {
+		if !strings.HasPrefix(s, vaultPrefix) {
+			return s, nil
+		}
+		if client == nil {
+			var err error
+			if client, err = newVaultClient(); err != nil {
+				return "", err
+			}
+		}
+		resolved++
+		return client.lookup(strings.TrimPrefix(s, vaultPrefix))
+	}
+
+	if err := walkStrings(reflect.ValueOf(cfg).Elem(), resolve); err != nil {
+		return err
+	}
+	if resolved > 0 {
+		slog.Debug("secrets resolved from vault", "count", resolved)
+	}
+	return nil
+}
+
+// walkStrings calls fn for every string reachable from v (struct fields,
+// slices, pointers and map values) and stores the result.
+func walkStrings(v reflect.Value, fn func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := fn(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return walkStrings(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				if err := walkStrings(v.Field(i), fn); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			s, err := fn(iter.Value().String())
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(s).Convert(v.Type().Elem()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeVault starts a Vault KV v2 stub serving secret/reisekosten.
+func fakeVault(t *testing.T) *int {
+	t.Helper()
+
+	reads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/reisekosten" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		reads++
+		w.Write([]byte(`{"data":{"data":{"smtp_pass":"geheim","zip":"zip-pw"},"metadata":{"version":3}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "s.token")
+	return &reads
+}
+
+func TestResolveSecrets(t *testing.T) {
+	reads := fakeVault(t)
+	cfg := &Config{
+		SMTP:  SMTPConfig{Host: "smtp.example.com", Pass: "vault:secret/reisekosten#smtp_pass"},
+		Zip:   ZipConfig{Password: "vault:secret/reisekosten#zip"},
+		Email: EmailConfig{Headers: map[string]string{"X-Token": "vault:secret/reisekosten#smtp_pass"}},
+	}
+
+	if err := resolveSecrets(cfg); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+	if cfg.SMTP.Pass != "geheim" || cfg.Zip.Password != "zip-pw" || cfg.Email.Headers["X-Token"] != "geheim" {
+		t.Errorf("secrets not resolved: %+v", cfg)
+	}
+	if cfg.SMTP.Host != "smtp.example.com" {
+		t.Errorf("Host = %q, plain values must be kept", cfg.SMTP.Host)
+	}
+	if *reads != 1 {
+		t.Errorf("vault reads = %d, want 1 (cached)", *reads)
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	fakeVault(t)
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"vault:secret/reisekosten#missing", `has no key "missing"`},
+		{"vault:secret/other#pass", "HTTP 404"},
+		{"vault:secret/reisekosten", "invalid vault reference"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{SMTP: SMTPConfig{Pass: tt.ref}}
+		err := resolveSecrets(cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("resolveSecrets(%q) error = %v, want %q", tt.ref, err, tt.want)
+		}
+	}
+
+	// Without references Vault is not needed
+	t.Setenv("VAULT_ADDR", "")
+	if err := resolveSecrets(&Config{SMTP: SMTPConfig{Pass: "plain"}}); err != nil {
+		t.Errorf("resolveSecrets() without references error = %v", err)
+	}
+}
+
+func TestLoadConfigSOPS(t *testing.T) {
+	encrypted := `email:
+    from: ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]
+sops:
+    age:
+        - recipient: age1example
+    mac: ENC[AES256_GCM,data:xyz,iv:uvw,tag:rst,type:str]
+    version: 3.9.0
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(encrypted), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	old := sopsDecrypt
+	t.Cleanup(func() { sopsDecrypt = old })
+	var decrypted string
+	sopsDecrypt = func(p string) ([]byte, error) {
+		decrypted = p
+		return []byte(`email:
+  provider: eml
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BW
+`), nil
+	}
+
+	cfg, err := loadConfig("config.yaml", path, "")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if decrypted != path || cfg.Email.From != "me@example.com" {
+		t.Errorf("config not decrypted: path %q, from %q", decrypted, cfg.Email.From)
+	}
+	if isSOPSFile([]byte("email:\n  from: me@example.com\n")) {
+		t.Error("isSOPSFile() = true for plain config")
+	}
+}
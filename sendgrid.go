@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// SendGrid Delivery
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("sendgrid", DelivererFunc(sendSendGrid))
+}
+
+// sendgridSendURL is the SendGrid v3 mail send endpoint (variable for tests).
+var sendgridSendURL = "https://api.sendgrid.com/v3/mail/send"
+
+type sendgridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendgridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendgridAttachment struct {
+	Content     string `json:"content"`
+	Filename    string `json:"filename"`
+	Type        string `json:"type"`
+	Disposition string `json:"disposition"`
+}
+
+type sendgridPersonalization struct {
+	To []sendgridAddress `json:"to"`
+}
+
+type sendgridRequest struct {
+	Personalizations []sendgridPersonalization `json:"personalizations"`
+	From             sendgridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendgridContent         `json:"content"`
+	Attachments      []sendgridAttachment      `json:"attachments,omitempty"`
+	ReplyTo          *sendgridAddress          `json:"reply_to,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+// sendgridError formats the error list returned by the SendGrid API.
+func sendgridError(status int, body []byte) error {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+			Field   string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Errors) == 0 {
+		return httpStatusError(status, fmt.Errorf("sendgrid: send failed (HTTP %d): %s", status, strings.TrimSpace(string(body))))
+	}
+
+	msgs := make([]string, len(resp.Errors))
+	for i, e := range resp.Errors {
+		msgs[i] = e.Message
+		if e.Field != "" {
+			msgs[i] = e.Field + ": " + e.Message
+		}
+	}
+	return httpStatusError(status, fmt.Errorf("sendgrid: send failed (HTTP %d): %s", status, strings.Join(msgs, "; ")))
+}
+
+// sendSendGrid sends the generated PDFs via the SendGrid v3 HTTP API.
+func sendSendGrid(cfg *Config, m Mail) error {
+	if cfg.SendGrid.APIKey == "" {
+		return permanent(fmt.Errorf("sendgrid provider requires apiKey"))
+	}
+
+	reqBody := sendgridRequest{
+		Personalizations: []sendgridPersonalization{{To: []sendgridAddress{{Email: m.recipient(cfg)}}}},
+		From:             sendgridAddress{Email: cfg.Email.From},
+		Subject:          m.Subject,
+		Content:          []sendgridContent{{Type: "text/html", Value: m.body()}},
+		Headers:          mailHeaders(cfg, m),
+	}
+	// SendGrid rejects Reply-To as a custom header
+	if replyTo, ok := reqBody.Headers["Reply-To"]; ok {
+		reqBody.ReplyTo = &sendgridAddress{Email: replyTo}
+		delete(reqBody.Headers, "Reply-To")
+	}
+	for _, a := range m.Attachments {
+		reqBody.Attachments = append(reqBody.Attachments, sendgridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+			Filename:    a.Filename,
+			Type:        a.ContentType(),
+			Disposition: "attachment",
+		})
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sendgridSendURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.SendGrid.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid: send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return sendgridError(resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Serve Mode
+// ---------------------------------------------------------------------------
+
+const (
+	defaultServeListen = ":9110"
+	defaultServeDay    = 1
+)
+
+// serveCheckInterval is how often serve mode checks whether a report is due
+// (variable for tests).
+var serveCheckInterval = 5 * time.Minute
+
+// ServeConfig configures the long-running serve mode.
+type ServeConfig struct {
+	Listen string `yaml:"listen,omitempty"` // address for /metrics and /healthz (default :9110)
+	Day    int    `yaml:"day,omitempty"`    // day of month on which the previous month is reported (default 1)
+	Hour   int    `yaml:"hour,omitempty"`   // hour of day from which the report is due (default 0)
+}
+
+// listenAddr returns the configured listen address or the default.
+func (s ServeConfig) listenAddr() string {
+	if s.Listen != "" {
+		return s.Listen
+	}
+	return defaultServeListen
+}
+
+// due reports whether the report for the previous month is due at now.
+func (s ServeConfig) due(now time.Time) bool {
+	day := s.Day
+	if day <= 0 {
+		day = defaultServeDay
+	}
+	return now.Day() > day || (now.Day() == day && now.Hour() >= s.Hour)
+}
+
+// previousMonth returns the month before the one containing t.
+func previousMonth(t time.Time) (int, time.Month) {
+	y, m, _ := t.Date()
+	prev := time.Date(y, m-1, 1, 0, 0, 0, 0, time.UTC)
+	return prev.Year(), prev.Month()
+}
+
+// metrics collects run results for the /metrics and /healthz endpoints.
+type metrics struct {
+	mu          sync.Mutex
+	runs        int
+	failures    int
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastErr     error
+	done        string // period ("YYYY-MM") whose report was delivered or queued
+	workdays    int
+	kmTotal     float64
+	verpTotal   float64
+}
+
+// record stores the result of a run for the given period.
+func (m *metrics) record(year int, month time.Month, report *Report, err error, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.runs++
+	m.lastRun = at
+	m.lastErr = err
+
+	var queued *QueuedError
+	if err == nil || errors.As(err, &queued) {
+		// A queued message is delivered by flush, so don't generate it again
+		m.done = periodKey(year, month)
+	}
+	if err != nil {
+		m.failures++
+		return
+	}
+	m.lastSuccess = at
+	m.workdays = report.Workdays
+	m.kmTotal = report.KmTotal
+	m.verpTotal = report.VerpTotal
+}
+
+// isDone reports whether the period has already been handled in this process.
+func (m *metrics) isDone(year int, month time.Month) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.done == periodKey(year, month)
+}
+
+// markDone records a period delivered by an earlier process without counting
+// a run. delivered seeds the last success timestamp after a restart.
+func (m *metrics) markDone(year int, month time.Month, delivered time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.done = periodKey(year, month)
+	if m.lastSuccess.IsZero() {
+		m.lastSuccess = delivered
+	}
+}
+
+// status describes the last run for the service manager.
+func (m *metrics) status() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.lastErr != nil:
+		return "last run failed: " + m.lastErr.Error()
+	case m.done != "":
+		return "report " + m.done + " done, waiting for the next report"
+	default:
+		return "waiting for the next report"
+	}
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unix := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	}
+	metric := func(name, help, typ string, value any) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, typ, name, value)
+	}
+
+	metric("reisekosten_runs_total", "Number of report runs.", "counter", m.runs)
+	metric("reisekosten_run_failures_total", "Number of failed report runs.", "counter", m.failures)
+	metric("reisekosten_last_run_timestamp_seconds", "Unix time of the last report run.", "gauge", unix(m.lastRun))
+	metric("reisekosten_last_success_timestamp_seconds", "Unix time of the last successfully delivered report.", "gauge", unix(m.lastSuccess))
+	metric("reisekosten_workdays", "Workdays in the last delivered report.", "gauge", m.workdays)
+	metric("reisekosten_km_total_euros", "Kilometergeld total of the last delivered report.", "gauge", formatFloat(m.kmTotal))
+	metric("reisekosten_verpflegung_total_euros", "Verpflegungsmehraufwand total of the last delivered report.", "gauge", formatFloat(m.verpTotal))
+}
+
+// formatFloat formats an amount for the exposition format.
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%.2f", f)
+}
+
+// newServeMux returns the HTTP handler exposing /metrics and /healthz.
+// /healthz fails while the most recent run has failed.
+func newServeMux(m *metrics) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.write(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		err := m.lastErr
+		m.mu.Unlock()
+		if err != nil {
+			http.Error(w, "last run failed: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// scheduledRun generates and delivers the previous month's report if it is
+// due and has not been delivered yet. It returns the error of the run, or
+// errLocked if another run holds the lock; nothing due is no error.
+func scheduledRun(cfg *Config, m *metrics, now time.Time) error {
+	if !cfg.Serve.due(now) {
+		slog.Debug("no report due", "day", now.Format(isoDate))
+		return nil
+	}
+	year, month := previousMonth(now)
+	if m.isDone(year, month) {
+		return nil
+	}
+	if err := cfg.checkEmployment(monthPeriod(year, month)); err != nil {
+		slog.Info("no report due", "reason", err)
+		m.markDone(year, month, time.Time{})
+		return nil
+	}
+
+	if settledMonth(cfg, m, year, month) {
+		return nil
+	}
+
+	// A run that held the lock meanwhile may have delivered the month
+	lock, err := acquireLock(cfg.LockFile())
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	if settledMonth(cfg, m, year, month) {
+		return nil
+	}
+
+	slog.Info("generating report", "period", fmt.Sprintf("%02d/%d", month, year), "customers", len(cfg.Customers))
+	report, err := run(cfg, monthPeriod(year, month))
+	notifyRun(cfg, monthPeriod(year, month), report, err)
+	m.record(year, month, report, err, now)
+	return err
+}
+
+// settledMonth reports whether the month's report waits for approval or was
+// delivered already according to the state file, and marks it done.
+func settledMonth(cfg *Config, m *metrics, year int, month time.Month) bool {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return false
+	}
+	if state.pendingFor(monthPeriod(year, month)) {
+		slog.Debug("report waiting for approval", "period", periodKey(year, month))
+		m.markDone(year, month, time.Time{})
+		return true
+	}
+	if key := periodKey(year, month); state.delivered(key) {
+		slog.Debug("report already delivered", "period", key)
+		delivered := state.Delivered[key]
+		if fi, err := os.Stat(cfg.StateFile()); err == nil && delivered.IsZero() {
+			delivered = fi.ModTime()
+		}
+		m.markDone(year, month, delivered)
+		return true
+	}
+	return false
+}
+
+// serve runs the scheduler and the metrics endpoint until ctx is cancelled.
+func serve(ctx context.Context, cfg *Config) error {
+	ln, err := net.Listen("tcp", cfg.Serve.listenAddr())
+	if err != nil {
+		return err
+	}
+
+	m := &metrics{}
+	mux := newServeMux(m)
+	if cfg.Approval.To != "" {
+		mux.HandleFunc("/approve", approvalHandler(cfg, "approve"))
+		mux.HandleFunc("/reject", approvalHandler(cfg, "reject"))
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", ln.Addr().String())
+
+	if cfg.GRPC.Listen != "" {
+		grpcSrv, err := startGRPC(cfg)
+		if err != nil {
+			srv.Close()
+			return err
+		}
+		defer grpcSrv.GracefulStop()
+	}
+
+	// Tell systemd (Type=notify) that the service is up
+	sdNotify("READY=1\nSTATUS=waiting for the next report")
+
+	ticker := time.NewTicker(serveCheckInterval)
+	defer ticker.Stop()
+	for {
+		switch err := scheduledRun(cfg, m, cfg.now()); {
+		case errors.Is(err, errLocked):
+			slog.Warn("scheduled run postponed", "error", err)
+		case err != nil:
+			slog.Error("run failed", "error", err)
+		}
+		sdNotify("STATUS=" + m.status())
+		select {
+		case <-ctx.Done():
+			sdNotify("STOPPING=1")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		case <-ticker.C:
+		}
+	}
+}
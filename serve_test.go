@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeConfigDue(t *testing.T) {
+	s := ServeConfig{Day: 3, Hour: 8}
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 3, 3, 7, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := s.due(tt.now); got != tt.want {
+			t.Errorf("due(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+
+	if y, m := previousMonth(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)); y != 2025 || m != 12 {
+		t.Errorf("previousMonth() = %d/%d, want 12/2025", m, y)
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	m := &metrics{}
+	srv := httptest.NewServer(newServeMux(m))
+	defer srv.Close()
+
+	at := time.Unix(1772323200, 0)
+	m.record(2026, 2, &Report{Workdays: 20, KmTotal: 450, VerpTotal: 280}, nil, at)
+
+	body := get(t, srv.URL+"/metrics", http.StatusOK)
+	for _, want := range []string{
+		"reisekosten_runs_total 1\n",
+		"reisekosten_run_failures_total 0\n",
+		"reisekosten_last_success_timestamp_seconds 1772323200\n",
+		"reisekosten_km_total_euros 450.00\n",
+		"# TYPE reisekosten_runs_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %q:\n%s", want, body)
+		}
+	}
+	get(t, srv.URL+"/healthz", http.StatusOK)
+
+	// A failed run makes /healthz fail but keeps the last success
+	m.record(2026, 3, nil, errors.New("smtp down"), at.Add(time.Hour))
+	if body := get(t, srv.URL+"/healthz", http.StatusServiceUnavailable); !strings.Contains(body, "smtp down") {
+		t.Errorf("/healthz = %q, want error", body)
+	}
+	body = get(t, srv.URL+"/metrics", http.StatusOK)
+	if !strings.Contains(body, "reisekosten_run_failures_total 1\n") || !strings.Contains(body, "reisekosten_last_success_timestamp_seconds 1772323200\n") {
+		t.Errorf("unexpected metrics after failure:\n%s", body)
+	}
+	if m.isDone(2026, 3) {
+		t.Error("failed period marked as done")
+	}
+}
+
+func TestScheduledRun(t *testing.T) {
+	calls := fakeSendGrid(t, 0)
+	noSleep(t)
+	dir := t.TempDir()
+	cfg := retryTestConfig(filepath.Join(dir, "outbox"))
+	cfg.State = filepath.Join(dir, "state.json")
+	cfg.Customers = []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}
+	cfg.Serve = ServeConfig{Day: 3}
+	m := &metrics{}
+
+	// Not due yet
+	scheduledRun(cfg, m, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC))
+	// Due: delivers February
+	scheduledRun(cfg, m, time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC))
+	// Already done in this process
+	scheduledRun(cfg, m, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	if *calls != 1 || m.runs != 1 {
+		t.Fatalf("calls = %d, runs = %d, want 1", *calls, m.runs)
+	}
+
+	// A restarted process finds the delivered month in the state file
+	m = &metrics{}
+	scheduledRun(cfg, m, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	if *calls != 1 || m.runs != 0 || !m.isDone(2026, 2) {
+		t.Errorf("calls = %d, runs = %d after restart, want no new run", *calls, m.runs)
+	}
+	if m.lastSuccess.IsZero() {
+		t.Error("last success not restored from state file")
+	}
+}
+
+func TestScheduledRunStorage(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Delivery:  deliveryStorage,
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Output:    OutputConfig{Dir: filepath.Join(dir, "out")},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+		Serve:     ServeConfig{Day: 3},
+	}
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	if err := scheduledRun(cfg, &metrics{}, now); err != nil {
+		t.Fatalf("scheduledRun() error = %v", err)
+	}
+	archived, _ := loadArchivedPeriod(cfg, "2026-02")
+
+	// A restarted process finds the stored month in the state file and
+	// neither regenerates nor rewrites it
+	m := &metrics{}
+	if err := scheduledRun(cfg, m, now.Add(time.Hour)); err != nil {
+		t.Fatalf("scheduledRun() after restart error = %v", err)
+	}
+	if m.runs != 0 || !m.isDone(2026, 2) || m.lastSuccess.IsZero() {
+		t.Errorf("runs = %d, done = %v after restart, want no new run", m.runs, m.isDone(2026, 2))
+	}
+	again, _ := loadArchivedPeriod(cfg, "2026-02")
+	if archived == nil || again == nil || again.documentID("Kilometergelderstattung") != archived.documentID("Kilometergelderstattung") {
+		t.Error("archive rewritten after restart")
+	}
+}
+
+func TestScheduledRunQueued(t *testing.T) {
+	calls := fakeSendGrid(t, 100)
+	noSleep(t)
+	dir := t.TempDir()
+	cfg := retryTestConfig(filepath.Join(dir, "outbox"))
+	cfg.State = filepath.Join(dir, "state.json")
+	cfg.Archive = filepath.Join(dir, "archive")
+	cfg.Overrides = filepath.Join(dir, "overrides")
+	cfg.Customers = []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}}
+	cfg.Serve = ServeConfig{Day: 3}
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	var queued *QueuedError
+	if err := scheduledRun(cfg, &metrics{}, now); !errors.As(err, &queued) {
+		t.Fatalf("scheduledRun() error = %v, want *QueuedError", err)
+	}
+	state, _ := loadState(cfg.StateFile())
+	if !state.delivered("2026-02") || state.Queued["2026-02"].IsZero() {
+		t.Fatalf("state after queueing = %+v", state)
+	}
+
+	// The next timer run is a new process: the queued month is not issued again
+	m := &metrics{}
+	if err := scheduledRun(cfg, m, now.Add(time.Hour)); err != nil || m.runs != 0 {
+		t.Errorf("second scheduledRun() = %v, runs = %d, want no new run", err, m.runs)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "outbox", "*.json")); len(files) != 1 || *calls != 3 {
+		t.Errorf("outbox = %v, calls = %d, want one queued mail", files, *calls)
+	}
+
+	// Once flushed, the month is delivered and no longer queued
+	*calls = 100 // the transport recovers
+	if err := flushOutbox(cfg); err != nil {
+		t.Fatalf("flushOutbox() error = %v", err)
+	}
+	if state, _ := loadState(cfg.StateFile()); !state.delivered("2026-02") || len(state.Queued) != 0 {
+		t.Errorf("state after flush = %+v", state)
+	}
+}
+
+// get fetches url, checks the status code and returns the body.
+func get(t *testing.T, url string, wantStatus int) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Errorf("GET %s status = %d, want %d", url, resp.StatusCode, wantStatus)
+	}
+	return string(body)
+}
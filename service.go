@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ---------------------------------------------------------------------------
+// Windows Service
+// ---------------------------------------------------------------------------
+
+// serviceName is the name of the Windows service running serve mode.
+const serviceName = "reisekosten"
+
+// serviceLogFile receives the log of the service, which has no console.
+const serviceLogFile = "reisekosten-service.log"
+
+// absConfigPath returns the absolute path of the config file given with
+// --config, or of the one found on the search path.
+func absConfigPath(configPath string) (string, error) {
+	if configPath == "" {
+		found, err := findConfigFile("config.yaml")
+		if err != nil {
+			return "", err
+		}
+		configPath = found
+	}
+	return filepath.Abs(configPath)
+}
+
+// enterConfigDir changes the working directory to the directory of the
+// config file and returns the file's absolute path. A service starts in the
+// system directory (C:\Windows\System32); this way the relative paths of the
+// config (state, archive, overrides, outbox) resolve next to the config file.
+func enterConfigDir(configPath string) (string, error) {
+	path, err := absConfigPath(configPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chdir(filepath.Dir(path)); err != nil {
+		return "", fmt.Errorf("failed to enter the config directory: %w", err)
+	}
+	return path, nil
+}
+
+// serviceArgs returns the command line the service runs serve mode with.
+func serviceArgs(configPath, profile string) ([]string, error) {
+	path, err := absConfigPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"serve", "--config", path}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	return args, nil
+}
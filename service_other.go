@@ -0,0 +1,29 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// errNoService is returned by the service commands outside Windows.
+var errNoService = errors.New("services are only available on Windows, use systemd or launchd to run serve mode")
+
+// runningAsService reports whether the process was started by the Windows
+// service manager, which it never is outside Windows.
+func runningAsService() bool {
+	return false
+}
+
+// runService is only available on Windows.
+func runService(*Config) error {
+	return errNoService
+}
+
+// installService is only available on Windows.
+func installService(configPath, profile string) error {
+	return errNoService
+}
+
+// removeService is only available on Windows.
+func removeService() error {
+	return errNoService
+}
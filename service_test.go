@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServiceConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("customers: []\n"), 0644)
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(wd) })
+	os.Chdir(dir)
+
+	// The service gets the absolute path of the config found in the current directory
+	args, err := serviceArgs("", "gmbh")
+	if err != nil {
+		t.Fatalf("serviceArgs() error = %v", err)
+	}
+	if !filepath.IsAbs(args[2]) || !reflect.DeepEqual(args[3:], []string{"--profile", "gmbh"}) {
+		t.Errorf("serviceArgs() = %v", args)
+	}
+
+	// Started elsewhere, relative paths resolve next to the config file
+	os.Chdir(t.TempDir())
+	path, err := enterConfigDir(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("enterConfigDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Base(path)); err != nil {
+		t.Errorf("working directory is not the config directory: %v", err)
+	}
+
+	if runningAsService() {
+		t.Error("runningAsService() = true in a test")
+	}
+}
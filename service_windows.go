@@ -0,0 +1,112 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// runningAsService reports whether the process was started by the Windows
+// service manager.
+func runningAsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// serviceHandler runs serve mode until the service manager stops it.
+type serviceHandler struct {
+	cfg *Config
+}
+
+// Execute implements svc.Handler.
+func (h serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, h.cfg) }()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				slog.Error("serve failed", "error", err)
+				return false, 1
+			}
+			return false, 0
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				slog.Info("service stopping")
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
+
+// runService runs serve mode as the Windows service.
+func runService(cfg *Config) error {
+	return svc.Run(serviceName, serviceHandler{cfg: cfg})
+}
+
+// installService registers the Windows service running serve mode with the
+// given config file and profile, started automatically with the system.
+func installService(configPath, profile string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args, err := serviceArgs(configPath, profile)
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "Reisekosten",
+		Description: "Generates and delivers the monthly travel expense reports (reisekosten serve)",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to install service: %w", err)
+	}
+	defer s.Close()
+	slog.Info("service installed", "name", serviceName, "args", args)
+	return nil
+}
+
+// removeService deletes the Windows service. A running service is removed
+// once it has stopped.
+func removeService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to remove service: %w", err)
+	}
+	slog.Info("service removed", "name", serviceName)
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// sevDesk Accounting
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerAccounting("sevdesk", AccountingFunc(postSevDesk))
+}
+
+// sevdeskAPI is the base URL of the sevDesk API (variable for tests).
+var sevdeskAPI = "https://my.sevdesk.de/api/v1"
+
+type sevdeskRef struct {
+	ID         int    `json:"id"`
+	ObjectName string `json:"objectName"`
+}
+
+type sevdeskVoucher struct {
+	ObjectName  string `json:"objectName"`
+	MapAll      bool   `json:"mapAll"`
+	VoucherDate string `json:"voucherDate"` // DD.MM.YYYY
+	Description string `json:"description"` // voucher number
+	Status      int    `json:"status"`      // 50: draft, 100: unpaid
+	TaxType     string `json:"taxType"`
+	CreditDebit string `json:"creditDebit"` // C: expense
+	VoucherType string `json:"voucherType"` // VOU: normal voucher
+}
+
+type sevdeskVoucherPos struct {
+	ObjectName     string     `json:"objectName"`
+	MapAll         bool       `json:"mapAll"`
+	AccountingType sevdeskRef `json:"accountingType"`
+	TaxRate        float64    `json:"taxRate"`
+	Net            bool       `json:"net"`
+	SumGross       float64    `json:"sumGross"`
+	Comment        string     `json:"comment"`
+}
+
+type sevdeskVoucherPosSave struct {
+	VoucherPos sevdeskVoucherPos `json:"voucherPos"`
+}
+
+type sevdeskSaveVoucher struct {
+	Voucher        sevdeskVoucher          `json:"voucher"`
+	VoucherPosSave []sevdeskVoucherPosSave `json:"voucherPosSave"`
+	Filename       string                  `json:"filename,omitempty"` // temporary file of the uploaded document
+}
+
+// sevdeskRequest calls the sevDesk API and decodes the "objects" of the
+// response into out.
+func sevdeskRequest(cfg *Config, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest(http.MethodPost, sevdeskAPI+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", cfg.Accounting.APIKey)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s failed (HTTP %d): %s", path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var wrapped struct {
+		Objects json.RawMessage `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return fmt.Errorf("invalid response of %s: %w", path, err)
+	}
+	return json.Unmarshal(wrapped.Objects, out)
+}
+
+// postSevDesk uploads the document and saves it as a voucher with one
+// position booked on the configured accounting type.
+func postSevDesk(cfg *Config, v Voucher) error {
+	accountingType, err := strconv.Atoi(v.Category)
+	if err != nil {
+		return fmt.Errorf("accounting type %q is no sevDesk ID", v.Category)
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("file", v.File.Filename)
+	if err != nil {
+		return err
+	}
+	fw.Write(v.File.Data)
+	mw.Close()
+	var upload struct {
+		Filename string `json:"filename"`
+	}
+	if err := sevdeskRequest(cfg, "/Voucher/Factory/uploadTempFile", mw.FormDataContentType(), &form, &upload); err != nil {
+		return err
+	}
+
+	save := sevdeskSaveVoucher{
+		Voucher: sevdeskVoucher{
+			ObjectName:  "Voucher",
+			MapAll:      true,
+			VoucherDate: v.Date.Format("02.01.2006"),
+			Description: v.DocID,
+			Status:      50,
+			TaxType:     "default",
+			CreditDebit: "C",
+			VoucherType: "VOU",
+		},
+		VoucherPosSave: []sevdeskVoucherPosSave{{VoucherPos: sevdeskVoucherPos{
+			ObjectName:     "VoucherPos",
+			MapAll:         true,
+			AccountingType: sevdeskRef{ID: accountingType, ObjectName: "AccountingType"},
+			Net:            false,
+			SumGross:       v.Amount,
+			Comment:        "Reisekosten - " + v.Title,
+		}}},
+		Filename: upload.Filename,
+	}
+	payload, err := json.Marshal(save)
+	if err != nil {
+		return err
+	}
+	var saved json.RawMessage
+	return sevdeskRequest(cfg, "/Voucher/Factory/saveVoucher", "application/json", bytes.NewReader(payload), &saved)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Signature Block
+// ---------------------------------------------------------------------------
+
+// pdfImageMark encloses the file of an image line, see withImage.
+const pdfImageMark = "\x1e"
+
+// pdfImageLines is the height of an image line in text lines.
+const pdfImageLines = 3
+
+// withImage returns a line that createPDF replaces by the image file, with
+// alt as its alternate text for screen readers.
+func withImage(file, alt string) string {
+	return pdfImageMark + file + pdfImageMark + alt
+}
+
+// imageLine returns the file and alternate text of a line made by withImage.
+func imageLine(line string) (file, alt string, ok bool) {
+	rest, ok := strings.CutPrefix(line, pdfImageMark)
+	if !ok {
+		return "", "", false
+	}
+	file, alt, ok = strings.Cut(rest, pdfImageMark)
+	return file, alt, ok
+}
+
+// SignatureConfig adds a signature block ("Ort, Datum, Unterschrift") to the
+// end of the expense documents, for employers that require signed forms.
+type SignatureConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"` // print the block with empty fields to sign by hand
+	Place   string `yaml:"place,omitempty"`   // Ort printed with the document date, e.g. Stuttgart
+	Name    string `yaml:"name,omitempty"`    // name printed below the signature line
+	Image   string `yaml:"image,omitempty"`   // scanned signature (PNG or JPEG) placed above the line
+}
+
+// active reports whether the documents get a signature block.
+func (s SignatureConfig) active() bool {
+	return s.Enabled || s.Place != "" || s.Name != "" || s.Image != ""
+}
+
+// signatureLine is the line to sign on.
+var signatureLine = strings.Repeat("_", 40)
+
+// buildSignatureBlock returns the signature block printed after the footer
+// of a document dated dateString (DD.MM.YYYY), or "" if none is configured.
+// Without a place, place and date are left to be filled in by hand.
+func buildSignatureBlock(s SignatureConfig, dateString string) string {
+	if !s.active() {
+		return ""
+	}
+	var b strings.Builder
+
+	b.WriteString("\n\n")
+	if s.Place != "" {
+		b.WriteString(fmt.Sprintf("Ort, Datum:  %s, %s\n", umlautReplacer.Replace(s.Place), localDate(dateString)))
+	} else {
+		b.WriteString(fmt.Sprintf("Ort, Datum:  %s\n", signatureLine))
+	}
+	b.WriteString("\n")
+	alt := "Unterschrift"
+	if s.Name != "" {
+		alt += " " + umlautReplacer.Replace(s.Name)
+	}
+	if s.Image != "" {
+		b.WriteString(withImage(s.Image, alt) + "\n")
+	} else {
+		b.WriteString(strings.Repeat("\n", pdfImageLines))
+	}
+	b.WriteString(signatureLine + "\n")
+	b.WriteString(alt + "\n")
+
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSignatureBlock(t *testing.T) {
+	if got := buildSignatureBlock(SignatureConfig{}, "27.02.2026"); got != "" {
+		t.Errorf("buildSignatureBlock() without config = %q", got)
+	}
+
+	got := buildSignatureBlock(SignatureConfig{Place: "Muenchen", Name: "Max Mustermann"}, "27.02.2026")
+	for _, want := range []string{"Ort, Datum:  Muenchen, 27.02.2026\n", signatureLine + "\nUnterschrift Max Mustermann\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("signature block missing %q:\n%s", want, got)
+		}
+	}
+
+	// Signed by hand: place and date are left empty
+	got = buildSignatureBlock(SignatureConfig{Enabled: true}, "27.02.2026")
+	if !strings.Contains(got, "Ort, Datum:  "+signatureLine+"\n") || strings.Contains(got, "27.02.2026") {
+		t.Errorf("signature block to sign by hand:\n%s", got)
+	}
+
+	got = buildSignatureBlock(SignatureConfig{Image: "sig.png", Name: "Max"}, "27.02.2026")
+	if file, alt, ok := imageLine(strings.Split(got, "\n")[4]); !ok || file != "sig.png" || alt != "Unterschrift Max" {
+		t.Errorf("image line = %q, %q, %v:\n%q", file, alt, ok, got)
+	}
+}
+
+func TestSignatureImage(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewGray(image.Rect(0, 0, 120, 40))
+	for x := 10; x < 110; x++ {
+		img.Set(x, 20+x%7, color.Black)
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	path := filepath.Join(dir, "unterschrift.png")
+	os.WriteFile(path, buf.Bytes(), 0644)
+
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		Signature: SignatureConfig{Place: "Stuttgart", Name: "Max Mustermann", Image: path},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	for _, a := range report.Attachments {
+		text := pdfText(t, a.Data)
+		if !strings.Contains(text, "(Ort, Datum:  Stuttgart, 27.02.2026)Tj") || !strings.Contains(text, "(Unterschrift Max Mustermann)Tj") {
+			t.Errorf("%s has no signature block", a.Filename)
+		}
+		for _, want := range []string{"/Subtype /Image", "/S /Figure /P", "/Alt (Unterschrift Max Mustermann)"} {
+			if !bytes.Contains(a.Data, []byte(want)) {
+				t.Errorf("%s missing %q", a.Filename, want)
+			}
+		}
+	}
+
+	cfg.Signature.Image = filepath.Join(dir, "missing.png")
+	if _, err := generateReport(cfg, monthPeriod(2026, 2)); err == nil {
+		t.Errorf("generateReport() with a missing signature image succeeded")
+	}
+}
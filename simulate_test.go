@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Overrides: filepath.Join(dir, "overrides"),
+		Archive:   filepath.Join(dir, "archive"),
+		Audit:     filepath.Join(dir, "audit.jsonl"),
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+			{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+		},
+	}
+	p := monthPeriod(2026, 2)
+
+	s := simulation{Customer: "Initech:20", Weights: "1=2,2=1,sim=1", OfficeShare: "25"}
+	var out strings.Builder
+	if err := simulate(cfg, p, s, &out); err != nil {
+		t.Fatalf("simulate() error = %v", err)
+	}
+	// 20 workdays, a quarter in the office: 15 trips shared 2:1:1
+	for _, want := range []string{"Workdays                 20      15", "Office days              0       5", "Acme days                10      7", "Initech km               0       80"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output misses %q:\n%s", want, out.String())
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("simulate wrote %d files", len(entries))
+	}
+	if len(cfg.Customers) != 2 || cfg.OfficeShare != 0 || cfg.Weights != nil {
+		t.Errorf("simulate changed the config: %+v", cfg)
+	}
+
+	for _, s := range []simulation{{Customer: "Initech"}, {Customer: "Initech:-5"}, {Weights: "1=x"}, {OfficeShare: "100"}} {
+		if err := simulate(cfg, p, s, &out); err == nil {
+			t.Errorf("simulate(%+v) error = nil", s)
+		}
+	}
+
+	a := parseArgs([]string{"simulate", "--add-customer", "Initech:20:BY", "--office-share", "20", "3/2026"})
+	if a.Command != "simulate" || a.Simulation.Customer != "Initech:20:BY" || a.Simulation.OfficeShare != "20" || a.Month != 3 || a.Year != 2026 {
+		t.Errorf("parseArgs(simulate) = %+v", a)
+	}
+}
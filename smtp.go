@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// SMTP Transport
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerDeliverer("smtp", DelivererFunc(sendSMTP))
+}
+
+// SMTP TLS modes
+const (
+	smtpTLSAuto     = "auto"     // implicit TLS on port 465, otherwise opportunistic STARTTLS
+	smtpTLSStartTLS = "starttls" // require STARTTLS, fail if the server does not offer it
+	smtpTLSImplicit = "implicit" // TLS from the first byte (SMTPS)
+	smtpTLSNone     = "none"     // plain connection, no TLS at all
+)
+
+// tlsVersions maps configurable minimum TLS versions to crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsMode returns the effective TLS mode, resolving "auto" based on the port.
+func (s SMTPConfig) tlsMode() (string, error) {
+	switch s.TLS {
+	case "", smtpTLSAuto:
+		if s.Port == 465 {
+			return smtpTLSImplicit, nil
+		}
+		return smtpTLSAuto, nil
+	case smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+		return s.TLS, nil
+	default:
+		return "", fmt.Errorf("invalid smtp tls mode %q (use auto, starttls, implicit or none)", s.TLS)
+	}
+}
+
+// buildTLSConfig creates the TLS client configuration from the SMTP settings.
+func buildTLSConfig(s SMTPConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName: s.Host,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if s.MinTLSVersion != "" {
+		v, ok := tlsVersions[s.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid smtp minTLSVersion %q (use 1.0, 1.1, 1.2 or 1.3)", s.MinTLSVersion)
+		}
+		tlsCfg.MinVersion = v
+	}
+
+	if s.CAFile != "" {
+		pem, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read smtp caFile: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in smtp caFile %q", s.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	// Discouraged: only for internal relays with self-signed certificates
+	tlsCfg.InsecureSkipVerify = s.InsecureSkipVerify
+	if s.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification disabled", "host", s.Host)
+	}
+
+	return tlsCfg, nil
+}
+
+// dialSMTP connects and authenticates to the configured SMTP server,
+// applying the configured TLS mode.
+func dialSMTP(s SMTPConfig) (*smtpSender, error) {
+	mode, err := s.tlsMode()
+	if err != nil {
+		return nil, permanent(err)
+	}
+	tlsCfg, err := buildTLSConfig(s)
+	if err != nil {
+		return nil, permanent(err)
+	}
+
+	addr := net.JoinHostPort(s.Host, fmt.Sprint(s.Port))
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if mode == smtpTLSImplicit {
+		conn = tls.Client(conn, tlsCfg)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if mode == smtpTLSAuto || mode == smtpTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsCfg); err != nil {
+				c.Close()
+				return nil, fmt.Errorf("STARTTLS failed: %w", err)
+			}
+		} else if mode == smtpTLSStartTLS {
+			c.Close()
+			return nil, permanent(errors.New("smtp server does not offer STARTTLS (required by tls: starttls)"))
+		}
+	}
+
+	sender := &smtpSender{Client: c}
+	if s.User != "" {
+		if ok, auths := c.Extension("AUTH"); ok {
+			var auth smtp.Auth
+			auth, sender.auth = selectSMTPAuth(s, auths)
+			if err := c.Auth(auth); err != nil {
+				c.Close()
+				// 5xx replies reject the credentials, 4xx are temporary
+				var reply *textproto.Error
+				if errors.As(err, &reply) && reply.Code >= 500 {
+					return nil, permanent(err)
+				}
+				return nil, err
+			}
+		}
+	}
+
+	return sender, nil
+}
+
+// selectSMTPAuth picks the authentication mechanism from the advertised list
+// and returns it with its name.
+func selectSMTPAuth(s SMTPConfig, auths string) (smtp.Auth, string) {
+	switch {
+	case strings.Contains(auths, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(s.User, s.Pass), "CRAM-MD5"
+	case strings.Contains(auths, "LOGIN") && !strings.Contains(auths, "PLAIN"):
+		return &loginAuth{username: s.User, password: s.Pass}, "LOGIN"
+	default:
+		return smtp.PlainAuth("", s.User, s.Pass, s.Host), "PLAIN"
+	}
+}
+
+// smtpSender adapts an smtp.Client to gomail.SendCloser.
+type smtpSender struct {
+	*smtp.Client
+	auth   string   // authentication mechanism used, empty without authentication
+	notify []string // DSN NOTIFY conditions (RFC 3461), empty to disable
+}
+
+// cmd sends a raw SMTP command and checks the reply code.
+func (c *smtpSender) cmd(expectCode int, format string, args ...any) error {
+	id, err := c.Text.Cmd(format, args...)
+	if err != nil {
+		return err
+	}
+	c.Text.StartResponse(id)
+	defer c.Text.EndResponse(id)
+	_, _, err = c.Text.ReadResponse(expectCode)
+	return err
+}
+
+func (c *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
+	dsn := false
+	if len(c.notify) > 0 {
+		if dsn, _ = c.Extension("DSN"); !dsn {
+			slog.Warn("smtp server does not support delivery status notifications (DSN)")
+		}
+	}
+
+	if dsn {
+		if err := c.cmd(250, "MAIL FROM:<%s> RET=HDRS", from); err != nil {
+			return err
+		}
+		notify := strings.ToUpper(strings.Join(c.notify, ","))
+		for _, addr := range to {
+			if err := c.cmd(25, "RCPT TO:<%s> NOTIFY=%s", addr, notify); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := c.Mail(from); err != nil {
+			return err
+		}
+		for _, addr := range to {
+			if err := c.Rcpt(addr); err != nil {
+				return err
+			}
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := msg.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *smtpSender) Close() error {
+	return c.Quit()
+}
+
+// loginAuth implements the LOGIN authentication mechanism (not provided by net/smtp).
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("refusing LOGIN authentication over unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch {
+	case bytes.EqualFold(fromServer, []byte("Username:")):
+		return []byte(a.username), nil
+	case bytes.EqualFold(fromServer, []byte("Password:")):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// smtpTranscript records a session with the fake SMTP server.
+type smtpTranscript struct {
+	Commands []string
+	Data     string
+}
+
+// fakeSMTPServer starts a minimal plaintext SMTP server advertising the given
+// EHLO extensions. The transcript is sent to the returned channel after DATA.
+func fakeSMTPServer(t *testing.T, extensions ...string) (host string, port int, session <-chan smtpTranscript) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan smtpTranscript, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var tr smtpTranscript
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			tr.Commands = append(tr.Commands, strings.TrimSpace(line))
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				lines := append([]string{"localhost"}, extensions...)
+				for i, l := range lines {
+					sep := "-"
+					if i == len(lines)-1 {
+						sep = " "
+					}
+					reply("250" + sep + l)
+				}
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				tr.Data = b.String()
+				ch <- tr
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func TestSMTPTLSMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SMTPConfig
+		expected string
+		wantErr  bool
+	}{
+		{"default on 587", SMTPConfig{Port: 587}, smtpTLSAuto, false},
+		{"default on 465", SMTPConfig{Port: 465}, smtpTLSImplicit, false},
+		{"explicit starttls", SMTPConfig{Port: 465, TLS: "starttls"}, smtpTLSStartTLS, false},
+		{"explicit none", SMTPConfig{Port: 25, TLS: "none"}, smtpTLSNone, false},
+		{"invalid", SMTPConfig{TLS: "ssl"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.tlsMode()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tlsMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("tlsMode() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := buildTLSConfig(SMTPConfig{Host: "smtp.example.com"})
+		if err != nil {
+			t.Fatalf("buildTLSConfig() error = %v", err)
+		}
+		if cfg.ServerName != "smtp.example.com" || cfg.MinVersion != tls.VersionTLS12 || cfg.InsecureSkipVerify {
+			t.Errorf("unexpected defaults: %+v", cfg)
+		}
+	})
+
+	t.Run("min version and insecure", func(t *testing.T) {
+		defer slog.SetDefault(slog.Default())
+		var out bytes.Buffer
+		setupLogger(&out, "text", slog.LevelInfo)
+
+		cfg, err := buildTLSConfig(SMTPConfig{Host: "relay.internal", MinTLSVersion: "1.3", InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("buildTLSConfig() error = %v", err)
+		}
+		if cfg.MinVersion != tls.VersionTLS13 || !cfg.InsecureSkipVerify {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+		if log := out.String(); !strings.Contains(log, "level=WARN") || !strings.Contains(log, "TLS certificate verification disabled") || !strings.Contains(log, "host=relay.internal") {
+			t.Errorf("log = %q", log)
+		}
+	})
+
+	t.Run("invalid min version", func(t *testing.T) {
+		if _, err := buildTLSConfig(SMTPConfig{MinTLSVersion: "2.0"}); err == nil {
+			t.Error("buildTLSConfig() expected error for invalid version")
+		}
+	})
+
+	t.Run("missing CA file", func(t *testing.T) {
+		if _, err := buildTLSConfig(SMTPConfig{CAFile: "/nonexistent/ca.pem"}); err == nil {
+			t.Error("buildTLSConfig() expected error for missing CA file")
+		}
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		os.WriteFile(caFile, []byte("not a certificate"), 0644)
+		if _, err := buildTLSConfig(SMTPConfig{CAFile: caFile}); err == nil {
+			t.Error("buildTLSConfig() expected error for empty CA bundle")
+		}
+	})
+}
+
+func TestDialSMTPRequiresStartTLS(t *testing.T) {
+	host, port, _ := fakeSMTPServer(t, "AUTH PLAIN")
+
+	_, err := dialSMTP(SMTPConfig{Host: host, Port: port, TLS: smtpTLSStartTLS})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("dialSMTP() error = %v, want STARTTLS error", err)
+	}
+}
+
+func TestSendSMTPPlain(t *testing.T) {
+	host, port, session := fakeSMTPServer(t)
+
+	cfg := &Config{
+		SMTP:  SMTPConfig{Host: host, Port: port, TLS: smtpTLSNone},
+		Email: EmailConfig{From: "me@example.com", To: "boss@example.com"},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff", Attachments: []Attachment{{Filename: "a.pdf", Data: []byte("%PDF")}}}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	msg := (<-session).Data
+	for _, want := range []string{"Subject: Betreff", `filename="a.pdf"`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q", want)
+		}
+	}
+}
+
+func TestSendSMTPDSN(t *testing.T) {
+	host, port, session := fakeSMTPServer(t, "DSN")
+
+	cfg := &Config{
+		SMTP:  SMTPConfig{Host: host, Port: port, TLS: smtpTLSNone},
+		Email: EmailConfig{From: "me@example.com", To: "boss@example.com", DSN: []string{"success", "failure"}},
+	}
+	if err := sendEmail(cfg, Mail{Subject: "Betreff"}); err != nil {
+		t.Fatalf("sendEmail() error = %v", err)
+	}
+
+	tr := <-session
+	want := []string{"MAIL FROM:<me@example.com> RET=HDRS", "RCPT TO:<boss@example.com> NOTIFY=SUCCESS,FAILURE"}
+	for _, w := range want {
+		found := false
+		for _, c := range tr.Commands {
+			found = found || c == w
+		}
+		if !found {
+			t.Errorf("command %q not sent, got %q", w, tr.Commands)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Config Snapshot
+// ---------------------------------------------------------------------------
+
+// snapshotSuffix is appended to the period key of the archived snapshot,
+// e.g. archive/2026-02.config.yaml.
+const snapshotSuffix = ".config.yaml"
+
+// configSnapshot is everything a report was derived from, archived next to
+// its summary so an old report can be explained and regenerated after the
+// live configuration has changed. Credentials are not included.
+type configSnapshot struct {
+	Period           string          `yaml:"period"`
+	Generated        time.Time       `yaml:"generated"`
+	ConfigHash       string          `yaml:"configHash"`         // as in the audit log
+	Rates            Rates           `yaml:"rates"`              // rates of the period's year
+	RateData         string          `yaml:"rateData,omitempty"` // version of the rate data of update-rates
+	Customers        []Customer      `yaml:"customers"`
+	Filter           RunFilter       `yaml:"filter,omitempty"`
+	Overrides        periodOverrides `yaml:"overrides,omitempty"` // month key -> override file
+	ChristmasWeekOff bool            `yaml:"christmasWeekOff"`
+	OfficeShare      int             `yaml:"officeShare,omitempty"`
+	EmploymentStart  string          `yaml:"employmentStart,omitempty"`
+	EmploymentEnd    string          `yaml:"employmentEnd,omitempty"`
+	Cap              CapConfig       `yaml:"cap,omitempty"`
+}
+
+// newConfigSnapshot captures the configuration a period's report is
+// generated from.
+func newConfigSnapshot(cfg *Config, p Period, generated time.Time) (*configSnapshot, error) {
+	rates, err := cfg.ratesFor(p.Year)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := loadPeriodOverrides(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	s := &configSnapshot{
+		Period:           p.Key(),
+		Generated:        generated,
+		ConfigHash:       configHash(cfg),
+		Rates:            rates,
+		Customers:        cfg.Customers,
+		Filter:           cfg.Filter,
+		ChristmasWeekOff: cfg.ChristmasWeekOffEnabled(),
+		OfficeShare:      cfg.OfficeShare,
+		EmploymentStart:  cfg.EmploymentStart,
+		EmploymentEnd:    cfg.EmploymentEnd,
+		Cap:              cfg.Cap,
+		Overrides:        overrides,
+	}
+	if cfg.Downloaded != nil {
+		s.RateData = cfg.Downloaded.Version
+	}
+	return s, nil
+}
+
+// archiveSnapshot writes the config snapshot of a period to the archive.
+func archiveSnapshot(cfg *Config, p Period) error {
+	s, err := newConfigSnapshot(cfg, p, cfg.now())
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cfg.ArchiveDir(), p.Key()+snapshotSuffix)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config snapshot: %w", err)
+	}
+	return nil
+}
+
+// loadConfigSnapshot returns the archived config snapshot of a period key,
+// or nil if the period was archived without one.
+func loadConfigSnapshot(cfg *Config, key string) (*configSnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(cfg.ArchiveDir(), key+snapshotSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config snapshot: %w", err)
+	}
+	var s configSnapshot
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse config snapshot %s: %w", key+snapshotSuffix, err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:     EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:       EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:     filepath.Join(dir, "state.json"),
+		Archive:   filepath.Join(dir, "archive"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Rates:     []Rates{{From: 2026, KmRate: 0.38, PerDiemPartial: 14, PerDiemFull: 28, Overnight: 20}},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+		Filter:    RunFilter{SkipDays: []string{"2026-02-13"}},
+	}
+	os.MkdirAll(cfg.Overrides, 0o700)
+	os.WriteFile(filepath.Join(cfg.Overrides, "2026-02.yaml"), []byte("absences:\n  - from: 2026-02-16\n    to: 2026-02-20\n    reason: Urlaub\n"), 0o600)
+
+	if _, err := run(cfg, monthPeriod(2026, time.February)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	// The live config changes after the report was archived
+	cfg.Customers[0].Distance = 50
+
+	s, err := loadConfigSnapshot(cfg, "2026-02")
+	if err != nil || s == nil {
+		t.Fatalf("loadConfigSnapshot() = %v, %v", s, err)
+	}
+	if s.Rates.KmRate != 0.38 || len(s.Customers) != 1 || s.Customers[0].Distance != 100 || !s.ChristmasWeekOff {
+		t.Errorf("snapshot = %+v", s)
+	}
+	if len(s.Filter.SkipDays) != 1 || len(s.Overrides["2026-02"].Absences) != 1 || s.Overrides["2026-02"].Absences[0].Reason != "Urlaub" {
+		t.Errorf("snapshot absences = %+v, %+v", s.Filter, s.Overrides["2026-02"])
+	}
+	if s.ConfigHash == "" || s.ConfigHash == configHash(cfg) {
+		t.Errorf("snapshot configHash = %q", s.ConfigHash)
+	}
+
+	if s, err := loadConfigSnapshot(cfg, "2026-01"); s != nil || err != nil {
+		t.Errorf("loadConfigSnapshot(2026-01) = %v, %v", s, err)
+	}
+}
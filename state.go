@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Persistent State
+// ---------------------------------------------------------------------------
+
+const defaultStateFile = "reisekosten-state.json"
+
+// filterHeader is set on every report mail so recipients can filter on it.
+const filterHeader = "X-Reisekosten"
+
+// State holds data that must survive between runs.
+type State struct {
+	MessageIDs map[string]string          `json:"messageIds,omitempty"` // period key (YYYY-MM, YYYY-Qn, YYYY-Wnn) -> Message-ID of the report mail
+	Delivered  map[string]time.Time       `json:"delivered,omitempty"`  // period key -> time the report was stored or posted without mail
+	Queued     map[string]time.Time       `json:"queued,omitempty"`     // period key -> time the report mail was queued in the outbox
+	Approvals  map[string]pendingApproval `json:"approvals,omitempty"`  // token -> report waiting for approval
+	Closed     map[string]time.Time       `json:"closed,omitempty"`     // month key (YYYY-MM) -> time the month was closed
+	Unclaimed  []unclaimedDay             `json:"unclaimed,omitempty"`  // trips dropped by a cap, not yet claimed as Nachtrag
+}
+
+// StateFile returns the path of the state file. Profiles get their own
+// state file by default.
+func (c *Config) StateFile() string {
+	if c.State != "" {
+		return c.State
+	}
+	if c.Profile != "" {
+		return strings.TrimSuffix(defaultStateFile, ".json") + "-" + c.Profile + ".json"
+	}
+	return defaultStateFile
+}
+
+// loadState reads the state file. A missing file yields an empty state.
+func loadState(path string) (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return s, nil
+}
+
+// save writes the state file atomically.
+func (s *State) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// periodKey returns the state key for a month (YYYY-MM).
+func periodKey(year int, month time.Month) string {
+	return fmt.Sprintf("%d-%02d", year, month)
+}
+
+// newMessageID generates a unique Message-ID in the domain of the sender address.
+func newMessageID(from string, p Period) string {
+	domain := "reisekosten.local"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = strings.Trim(from[i+1:], "> ")
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("<reisekosten.%s.%s@%s>", p.Key(), hex.EncodeToString(b), domain)
+}
+
+// threadHeaders returns the headers that thread a report mail with the
+// reports of the previous periods of the same kind and year.
+func (s *State) threadHeaders(from string, p Period) map[string]string {
+	headers := map[string]string{
+		"Message-ID": newMessageID(from, p),
+		filterHeader: p.Key(),
+	}
+
+	var keys []string
+	for key := range s.MessageIDs {
+		if prev, ok := parsePeriodKey(key); ok && prev.Kind == p.Kind && prev.Year == p.Year && prev.Num < p.Num {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return headers
+	}
+	sort.Strings(keys)
+
+	refs := make([]string, len(keys))
+	for i, key := range keys {
+		refs[i] = s.MessageIDs[key]
+	}
+	headers["In-Reply-To"] = refs[len(refs)-1]
+	headers["References"] = strings.Join(refs, " ")
+	return headers
+}
+
+// recordMessageID remembers the Message-ID of a period's report mail.
+func (s *State) recordMessageID(p Period, id string) {
+	if s.MessageIDs == nil {
+		s.MessageIDs = make(map[string]string)
+	}
+	s.MessageIDs[p.Key()] = id
+}
+
+// recordQueued remembers that a period's report mail waits in the outbox.
+// The period counts as delivered, flush removes the marker once it is sent.
+func (s *State) recordQueued(p Period, at time.Time) {
+	if s.Queued == nil {
+		s.Queued = make(map[string]time.Time)
+	}
+	s.Queued[p.Key()] = at
+}
+
+// recordDelivered remembers that a period's report was delivered without
+// mail (delivery storage, api or none).
+func (s *State) recordDelivered(p Period, at time.Time) {
+	if s.Delivered == nil {
+		s.Delivered = make(map[string]time.Time)
+	}
+	s.Delivered[p.Key()] = at
+}
+
+// delivered reports whether the report of a period was mailed, stored or
+// posted before.
+func (s *State) delivered(key string) bool {
+	return s.MessageIDs[key] != "" || !s.Delivered[key].IsZero()
+}
+
+// checkOverlap refuses a period that overlaps a delivered period of another
+// kind, e.g. Q1/2026 once 02/2026 went out, so no day is claimed twice.
+func checkOverlap(cfg *Config, p Period) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]bool, len(state.MessageIDs)+len(state.Delivered))
+	for key := range state.MessageIDs {
+		keys[key] = true
+	}
+	for key := range state.Delivered {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		q, ok := parsePeriodKey(key)
+		if !ok || q.Kind == p.Kind || !state.delivered(key) {
+			continue
+		}
+		if !q.Start().After(p.End()) && !p.Start().After(q.End()) {
+			return fmt.Errorf("%s overlaps %s, which was delivered already", p.Label(), q.Label())
+		}
+	}
+	return nil
+}
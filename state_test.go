@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadStateMissing(t *testing.T) {
+	s, err := loadState(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("loadState() error = %v", err)
+	}
+	if len(s.MessageIDs) != 0 {
+		t.Errorf("expected empty state, got %+v", s)
+	}
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "state.json")
+
+	s := &State{}
+	s.recordMessageID(monthPeriod(2026, 1), "<a@example.com>")
+	if err := s.save(path); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := loadState(path)
+	if err != nil {
+		t.Fatalf("loadState() error = %v", err)
+	}
+	if loaded.MessageIDs["2026-01"] != "<a@example.com>" {
+		t.Errorf("MessageIDs = %v", loaded.MessageIDs)
+	}
+}
+
+func TestThreadHeaders(t *testing.T) {
+	s := &State{MessageIDs: map[string]string{
+		"2025-12": "<dec@example.com>",
+		"2026-02": "<feb@example.com>",
+		"2026-01": "<jan@example.com>",
+		"2026-04": "<apr@example.com>",
+	}}
+
+	h := s.threadHeaders("me@example.com", monthPeriod(2026, 3))
+
+	if !strings.HasPrefix(h["Message-ID"], "<reisekosten.2026-03.") || !strings.HasSuffix(h["Message-ID"], "@example.com>") {
+		t.Errorf("Message-ID = %q", h["Message-ID"])
+	}
+	if h["In-Reply-To"] != "<feb@example.com>" {
+		t.Errorf("In-Reply-To = %q, want <feb@example.com>", h["In-Reply-To"])
+	}
+	if h["References"] != "<jan@example.com> <feb@example.com>" {
+		t.Errorf("References = %q", h["References"])
+	}
+	if h[filterHeader] != "2026-03" {
+		t.Errorf("%s = %q, want 2026-03", filterHeader, h[filterHeader])
+	}
+
+	// First report of a year starts a new thread
+	h = s.threadHeaders("me@example.com", monthPeriod(2027, 1))
+	if _, ok := h["In-Reply-To"]; ok {
+		t.Errorf("unexpected In-Reply-To for first month: %q", h["In-Reply-To"])
+	}
+
+	// Quarterly reports are only threaded with earlier quarters
+	s.MessageIDs["2026-Q1"] = "<q1@example.com>"
+	h = s.threadHeaders("me@example.com", Period{periodQuarter, 2026, 2})
+	if h["References"] != "<q1@example.com>" || h[filterHeader] != "2026-Q2" {
+		t.Errorf("quarter headers = %v", h)
+	}
+}
+
+func TestCheckOverlap(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		State:     filepath.Join(dir, "state.json"),
+		Overrides: filepath.Join(dir, "overrides"),
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	state := &State{}
+	state.recordDelivered(monthPeriod(2026, time.February), time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC))
+	if err := state.save(cfg.StateFile()); err != nil {
+		t.Fatal(err)
+	}
+
+	q1 := Period{Kind: periodQuarter, Year: 2026, Num: 1}
+	if _, err := run(cfg, q1); err == nil || !strings.Contains(err.Error(), "Q1/2026 overlaps 02/2026") {
+		t.Errorf("run(Q1/2026) error = %v", err)
+	}
+	if err := checkOverlap(cfg, Period{Kind: periodWeek, Year: 2026, Num: 9}); err == nil {
+		t.Error("checkOverlap(KW 09/2026) expected error")
+	}
+	for _, p := range []Period{monthPeriod(2026, time.February), {Kind: periodQuarter, Year: 2026, Num: 2}, {Kind: periodWeek, Year: 2026, Num: 10}} {
+		if err := checkOverlap(cfg, p); err != nil {
+			t.Errorf("checkOverlap(%s) error = %v", p.Label(), err)
+		}
+	}
+}
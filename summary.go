@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ---------------------------------------------------------------------------
+// Run Summary (--json)
+// ---------------------------------------------------------------------------
+
+type customerSummary struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Days     int      `json:"days"`
+	Dates    []string `json:"dates"`
+	Distance int      `json:"distanceKm"`
+	Km       int      `json:"km"`
+	KmRate   float64  `json:"kmRate"`
+	KmAmount float64  `json:"kmAmount"`
+
+	Distances     []int              `json:"distancesKm,omitempty"`   // km driven per date, only if they differ from distanceKm
+	HalfDays      []string           `json:"halfDays,omitempty"`      // dates of half-day trips without meal allowance
+	MealsProvided []string           `json:"mealsProvided,omitempty"` // dates with provided meals, without meal allowance
+	Vehicles      []string           `json:"vehicles,omitempty"`      // vehicle per date, only if a trip was by bike
+	BikeRate      float64            `json:"bikeRate,omitempty"`      // km rate of the trips by bike
+	ActualRates   map[string]float64 `json:"actualRates,omitempty"`   // vehicle -> individual km rate from actual costs
+	Ticket        string             `json:"ticket,omitempty"`        // ticket type of a customer reached by public transport
+	TicketPrice   float64            `json:"ticketPrice,omitempty"`   // EUR per visit day instead of the km
+
+	VerpflegungRate   float64 `json:"verpflegungRate"`
+	VerpflegungAmount float64 `json:"verpflegungAmount"`
+
+	Project    string `json:"project,omitempty"`
+	CostCenter string `json:"costCenter,omitempty"`
+}
+
+// distance returns the km driven on the n-th date.
+func (c customerSummary) distance(n int) int {
+	if n < len(c.Distances) {
+		return c.Distances[n]
+	}
+	return c.Distance
+}
+
+type documentSummary struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"id"`
+	Filename string  `json:"filename"`
+	Bytes    int     `json:"bytes"`
+	Amount   float64 `json:"amount"`
+	SHA256   string  `json:"sha256,omitempty"`
+}
+
+type deliverySummary struct {
+	Status   string `json:"status"` // sent, queued, failed, posted or stored
+	Provider string `json:"provider"`
+	Outbox   string `json:"outbox,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// runSummary is the machine-readable result of a run printed with --json.
+type runSummary struct {
+	Period           string             `json:"period"`            // YYYY-MM, YYYY-Qn or YYYY-Wnn
+	Purpose          string             `json:"purpose,omitempty"` // reimbursement or taxDeduction
+	Workdays         int                `json:"workdays"`
+	OfficeDays       int                `json:"officeDays,omitempty"`
+	Warnings         []string           `json:"warnings,omitempty"` // pre-flight warnings
+	Customers        []customerSummary  `json:"customers"`
+	KmTotal          float64            `json:"kmTotal"`
+	VerpflegungTotal float64            `json:"verpflegungTotal"`
+	ExpensesTotal    float64            `json:"expensesTotal"`
+	Total            float64            `json:"total"`
+	CommuteTotal     float64            `json:"commuteTotal,omitempty"` // Entfernungspauschale, not part of total
+	Budget           *budgetStatus      `json:"budget,omitempty"`       // year-to-date usage of the annual targets
+	Unclaimed        []unclaimedDay     `json:"unclaimed,omitempty"`    // trips dropped by the caps, recorded for a later Nachtrag
+	LateClaims       []unclaimedDay     `json:"lateClaims,omitempty"`   // unclaimed days of earlier reports claimed as Nachtraege
+	CarryOver        map[string]float64 `json:"carryOver,omitempty"`    // customer ID -> EUR over the customer's cap carried to the next month
+	Documents        []documentSummary  `json:"documents"`
+	Delivery         deliverySummary    `json:"delivery"`
+}
+
+// visited reports whether the customer got at least one day.
+func (s *runSummary) visited(customerID string) bool {
+	for _, c := range s.Customers {
+		if c.ID == customerID && c.Days > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// customer returns the summary of a customer, or a zero summary if the
+// customer is not part of the run.
+func (s *runSummary) customer(id string) customerSummary {
+	for _, c := range s.Customers {
+		if c.ID == id {
+			return c
+		}
+	}
+	return customerSummary{}
+}
+
+// documentID returns the Beleg-Nr. of the run's document of the given type,
+// or "" if the run has none.
+func (s *runSummary) documentID(docType string) string {
+	for _, d := range s.Documents {
+		if d.Type == docType {
+			return d.ID
+		}
+	}
+	return ""
+}
+
+// newRunSummary builds the summary of a run. The report may be nil if
+// generation failed.
+func newRunSummary(cfg *Config, p Period, report *Report, runErr error) runSummary {
+	s := runSummary{
+		Period:    p.Key(),
+		Purpose:   cfg.purpose(),
+		Customers: []customerSummary{},
+		Documents: []documentSummary{},
+		Delivery:  deliverySummary{Status: "sent", Provider: cfg.Email.Provider},
+	}
+	if s.Delivery.Provider == "" {
+		s.Delivery.Provider = "smtp"
+	}
+	switch mode := cfg.DeliveryMode(); mode {
+	case deliveryAPI:
+		s.Delivery = deliverySummary{Status: "posted", Provider: cfg.Accounting.Provider}
+	case deliveryNone, deliveryStorage:
+		s.Delivery = deliverySummary{Status: "stored", Provider: mode}
+	}
+
+	var queued *QueuedError
+	switch {
+	case errors.As(runErr, &queued):
+		s.Delivery.Status = "queued"
+		s.Delivery.Outbox = queued.Path
+		s.Delivery.Error = queued.Err.Error()
+	case runErr != nil:
+		s.Delivery.Status = "failed"
+		s.Delivery.Error = runErr.Error()
+	}
+
+	if report == nil {
+		return s
+	}
+
+	s.Workdays = report.Workdays
+	s.OfficeDays = report.OfficeDays
+	s.Warnings = report.Warnings
+	s.KmTotal = roundCents(report.KmTotal)
+	s.VerpflegungTotal = roundCents(report.VerpTotal)
+	s.ExpensesTotal = roundCents(report.ExpenseTotal)
+	s.Total = roundCents(report.Total())
+	s.CommuteTotal = roundCents(report.CommuteTotal)
+	s.Budget = report.Budget
+	s.CarryOver = report.CarryOver
+	s.Unclaimed, s.LateClaims = report.Unclaimed, report.LateClaims
+
+	for _, c := range report.Customers {
+		cs := customerSummary{
+			ID:       c.Customer.ID,
+			Name:     c.Customer.Name,
+			Days:     len(c.Dates),
+			Dates:    c.Dates,
+			Distance: c.Customer.Distance,
+			Km:       c.km(),
+			KmRate:   c.KmRate,
+			KmAmount: roundCents(c.KmAmount),
+
+			VerpflegungRate:   c.VerpRate,
+			VerpflegungAmount: roundCents(c.VerpAmount),
+
+			Project:    c.Customer.Project,
+			CostCenter: c.Customer.CostCenter,
+
+			HalfDays:      c.HalfDays,
+			MealsProvided: c.MealsProvided,
+			Vehicles:      c.Vehicles,
+			BikeRate:      c.BikeRate,
+			ActualRates:   c.ActualRates,
+		}
+		if t := c.Customer.Ticket; t.active() {
+			cs.Distance, cs.Ticket, cs.TicketPrice = 0, t.Type, t.Price
+		}
+		for n := range c.Dates {
+			if c.distance(n) != c.Customer.Distance {
+				cs.Distances = c.Distances
+				break
+			}
+		}
+		s.Customers = append(s.Customers, cs)
+	}
+
+	for _, a := range report.Attachments {
+		s.Documents = append(s.Documents, documentSummary{
+			Type:     a.Type,
+			ID:       a.DocID,
+			Filename: a.Filename,
+			Bytes:    len(a.Data),
+			Amount:   roundCents(a.Amount),
+			SHA256:   sha256Hex(a.Data),
+		})
+	}
+	return s
+}
+
+// roundCents rounds an amount to whole cents.
+func roundCents(amount float64) float64 {
+	return float64(int64(amount*100+0.5)) / 100
+}
+
+// writeRunSummary writes the summary as indented JSON.
+func writeRunSummary(w io.Writer, s runSummary) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
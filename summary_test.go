@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewRunSummary(t *testing.T) {
+	cfg := &Config{SMTP: SMTPConfig{Host: "smtp.example.com"}, Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW"},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+	}}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil)
+	if s.Period != "2026-02" || s.Workdays != 20 {
+		t.Errorf("Period/Workdays = %s/%d, want 2026-02/20", s.Period, s.Workdays)
+	}
+	if len(s.Customers) != 2 || s.Customers[0].Days != 10 || s.Customers[0].Km != 1000 {
+		t.Errorf("unexpected customers: %+v", s.Customers)
+	}
+	if s.KmTotal != 450 || s.VerpflegungTotal != 280 || s.Total != 730 {
+		t.Errorf("totals = %v/%v/%v, want 450/280/730", s.KmTotal, s.VerpflegungTotal, s.Total)
+	}
+	if len(s.Documents) != 2 || s.Documents[0].ID != report.KmDocID || s.Documents[1].ID != report.VerpDocID {
+		t.Errorf("unexpected documents: %+v", s.Documents)
+	}
+	if s.Delivery.Status != "sent" || s.Delivery.Provider != "smtp" {
+		t.Errorf("Delivery = %+v, want sent via smtp", s.Delivery)
+	}
+
+	var buf bytes.Buffer
+	if err := writeRunSummary(&buf, s); err != nil {
+		t.Fatalf("writeRunSummary() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if decoded["period"] != "2026-02" {
+		t.Errorf("period = %v, want 2026-02", decoded["period"])
+	}
+}
+
+func TestNewRunSummaryDeliveryStatus(t *testing.T) {
+	cfg := &Config{Email: EmailConfig{Provider: "sendgrid"}}
+
+	queued := &QueuedError{Path: "outbox/x.json", Attempts: 3, Err: errors.New("503")}
+	s := newRunSummary(cfg, monthPeriod(2026, 2), &Report{}, queued)
+	if s.Delivery.Status != "queued" || s.Delivery.Outbox != "outbox/x.json" || s.Delivery.Error != "503" {
+		t.Errorf("Delivery = %+v, want queued", s.Delivery)
+	}
+
+	s = newRunSummary(cfg, monthPeriod(2026, 2), nil, errors.New("no customers"))
+	if s.Delivery.Status != "failed" || s.Customers == nil || s.Documents == nil {
+		t.Errorf("Delivery = %+v, want failed with empty lists", s.Delivery)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ---------------------------------------------------------------------------
+// Tagged PDF (Accessibility)
+// ---------------------------------------------------------------------------
+
+// pdfLanguage is the natural language of the documents. Their text is German
+// whatever the locale and language settings.
+const pdfLanguage = "de-DE"
+
+// pdfCellWidth is wide enough to prevent line wrapping (text uses spaces for
+// alignment).
+const pdfCellWidth = 300
+
+// markedContent is a line written as a marked-content sequence of a page.
+type markedContent struct {
+	page int // 1-based
+	mcid int
+}
+
+// structElem is a heading, paragraph or figure of the structure tree, made
+// of one or more lines.
+type structElem struct {
+	tag   string // H1, H2, P or Figure
+	alt   string // alternate text of a figure
+	lines []markedContent
+}
+
+// taggedPDF writes the text of a document line by line as marked content
+// and adds the structure tree when the document is complete, so screen
+// readers can follow the headings and paragraphs. Rules drawn with -, = or _
+// are marked as artifacts and skipped by screen readers.
+type taggedPDF struct {
+	*fpdf.Fpdf
+	maxY   float64
+	elems  []structElem
+	mcids  map[int]int // page -> number of marked-content sequences
+	title  string      // first H1, or the first line of text
+	titled bool        // title is an H1
+}
+
+// newTaggedPDF starts an A4 document on its first page.
+func newTaggedPDF() *taggedPDF {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Courier", "", pdfFontSize)
+	pdf.AddPage()
+
+	// Calculate available page height
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, marginBottom := pdf.GetMargins()
+	return &taggedPDF{Fpdf: pdf, maxY: pageHeight - marginBottom, mcids: make(map[int]int)}
+}
+
+// fits reports whether text fits on the rest of the current page.
+func (t *taggedPDF) fits(text string) bool {
+	return t.GetY()+textHeight(text) <= t.maxY
+}
+
+// textHeight returns the height of the lines of text, an image line made by
+// withImage taking pdfImageLines lines.
+func textHeight(text string) float64 {
+	lines := strings.Count(text, "\n") + 1 + strings.Count(text, pdfImageMark)/2*(pdfImageLines-1)
+	return float64(lines) * pdfLineHeight
+}
+
+// mark returns the next marked-content sequence of the current page.
+func (t *taggedPDF) mark() markedContent {
+	m := markedContent{page: t.PageNo(), mcid: t.mcids[t.PageNo()]}
+	t.mcids[m.page]++
+	return m
+}
+
+// ruleKind returns '=', '-' or '_' for a line drawn with that character, 0
+// for any other line.
+func ruleKind(line string) byte {
+	s := strings.TrimSpace(line)
+	if s == "" || strings.Trim(s, s[:1]) != "" || !strings.Contains("=-_", s[:1]) {
+		return 0
+	}
+	return s[0]
+}
+
+// write adds text like a MultiCell of its lines. Consecutive lines of text
+// form a paragraph; a single line between two rules of the same kind is a
+// heading, H1 between = and H2 between - rules. An image line is a figure.
+func (t *taggedPDF) write(text string) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	plain := func(line string) bool {
+		return strings.TrimSpace(line) != "" && ruleKind(line) == 0 && !strings.HasPrefix(line, pdfImageMark)
+	}
+	for i := 0; i < len(lines); {
+		if strings.TrimSpace(lines[i]) == "" {
+			t.line("", "")
+			i++
+			continue
+		}
+		if ruleKind(lines[i]) != 0 {
+			t.line("/Artifact BMC", lines[i])
+			i++
+			continue
+		}
+		if _, alt, ok := imageLine(lines[i]); ok {
+			t.breakPage(lines[i])
+			m := t.mark()
+			t.line(fmt.Sprintf("/Figure <</MCID %d>> BDC", m.mcid), lines[i])
+			t.elems = append(t.elems, structElem{tag: "Figure", alt: alt, lines: []markedContent{m}})
+			i++
+			continue
+		}
+		j := i
+		for j < len(lines) && plain(lines[j]) {
+			j++
+		}
+		e := structElem{tag: "P"}
+		if j == i+1 && i > 0 && j < len(lines) && ruleKind(lines[i-1]) == ruleKind(lines[j]) {
+			e.tag = map[byte]string{'=': "H1", '-': "H2"}[ruleKind(lines[j])]
+		}
+		if t.title == "" || e.tag == "H1" && !t.titled {
+			t.title = strings.TrimSpace(lines[i])
+			t.titled = e.tag == "H1"
+		}
+		for _, line := range lines[i:j] {
+			t.breakPage(line)
+			m := t.mark()
+			t.line(fmt.Sprintf("/%s <</MCID %d>> BDC", e.tag, m.mcid), line)
+			e.lines = append(e.lines, m)
+		}
+		t.elems = append(t.elems, e)
+		i = j
+	}
+}
+
+// breakPage starts a new page if the line does not fit, as the automatic
+// page break would, but before a marked-content sequence is opened.
+func (t *taggedPDF) breakPage(line string) {
+	if !t.fits(line) {
+		t.AddPage()
+	}
+}
+
+// line writes a line, enclosed in a marked-content sequence if begin is set.
+// The text of a line made by withLink links to its URL, a line made by
+// withImage is replaced by the image.
+func (t *taggedPDF) line(begin, text string) {
+	t.breakPage(text)
+	if begin != "" {
+		t.RawWriteStr(begin)
+	}
+	if path, _, ok := imageLine(text); ok {
+		h := pdfImageLines * pdfLineHeight
+		t.ImageOptions(path, t.GetX()+t.GetCellMargin(), t.GetY(), 0, h, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+		t.Ln(h)
+	} else {
+		width := float64(pdfCellWidth)
+		text, link, _ := strings.Cut(text, pdfLinkSep)
+		if link != "" {
+			width = t.GetStringWidth(text) + 2*t.GetCellMargin()
+		}
+		t.CellFormat(width, pdfLineHeight, text, "", 1, "", false, 0, link)
+	}
+	if begin != "" {
+		t.RawWriteStr("EMC")
+	}
+}
+
+// output returns the finished document with its language, title and
+// structure tree.
+func (t *taggedPDF) output() ([]byte, error) {
+	t.SetLang(pdfLanguage)
+	t.SetTitle(t.title, false)
+	t.SetXmpMetadata(xmpMetadata(t.title))
+	var buf bytes.Buffer
+	if err := t.Output(&buf); err != nil {
+		return nil, err
+	}
+	return t.addStructTree(buf.Bytes())
+}
+
+// xmpMetadata returns the XMP packet with the document's title and language.
+func xmpMetadata(title string) []byte {
+	var esc bytes.Buffer
+	xml.EscapeText(&esc, []byte(title))
+	return []byte(`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">` + esc.String() + `</rdf:li></rdf:Alt></dc:title>
+<dc:language><rdf:Bag><rdf:li>` + pdfLanguage + `</rdf:li></rdf:Bag></dc:language>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="r"?>`)
+}
+
+var (
+	pdfTrailerRegex  = regexp.MustCompile(`trailer\n<<\n/Size (\d+)\n/Root (\d+) 0 R\n/Info (\d+) 0 R\n>>\nstartxref\n(\d+)\n%%EOF\n$`)
+	pdfKidsRegex     = regexp.MustCompile(`\n1 0 obj\n<</Type /Pages\n/Kids \[([^\]]*)\]`)
+	pdfMetadataRegex = regexp.MustCompile(`\n(\d+) 0 obj\n<< /Type /Metadata`)
+)
+
+// pdfString returns s as a PDF literal string.
+func pdfString(s string) string {
+	return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+}
+
+// pdfDict returns the dictionary of object n without its closing >>. Only
+// used for page and catalog objects, which have no stream.
+func pdfDict(data []byte, n int) (string, error) {
+	start := bytes.Index(data, []byte(fmt.Sprintf("\n%d 0 obj\n", n)))
+	if start < 0 {
+		return "", fmt.Errorf("object %d not found", n)
+	}
+	start += len(fmt.Sprintf("\n%d 0 obj\n", n))
+	end := bytes.Index(data[start:], []byte("\nendobj"))
+	if end < 0 || !bytes.HasSuffix(data[start:start+end], []byte(">>")) {
+		return "", fmt.Errorf("object %d is not a dictionary", n)
+	}
+	return string(data[start : start+end-2]), nil
+}
+
+// addStructTree appends the structure tree to the document as an incremental
+// update: the structure elements and the parent tree, the pages with their
+// StructParents and the catalog marked as tagged.
+func (t *taggedPDF) addStructTree(data []byte) ([]byte, error) {
+	trailer := pdfTrailerRegex.FindSubmatch(data)
+	kids := pdfKidsRegex.FindSubmatch(data)
+	metadata := pdfMetadataRegex.FindSubmatch(data)
+	if trailer == nil || kids == nil || metadata == nil {
+		return nil, errors.New("failed to tag PDF: unexpected document layout")
+	}
+	num := func(b []byte) int {
+		n, _ := strconv.Atoi(string(b))
+		return n
+	}
+	size, catalog, info, prev := num(trailer[1]), num(trailer[2]), num(trailer[3]), num(trailer[4])
+	var pages []int
+	for i, f := range strings.Fields(string(kids[1])) {
+		if i%3 == 0 {
+			pages = append(pages, num([]byte(f)))
+		}
+	}
+
+	// Object numbers of the update
+	root, doc := size, size+1
+	parentTree := size + 2 + len(t.elems)
+	elem := func(i int) int { return size + 2 + i }
+
+	out := bytes.NewBuffer(data)
+	offsets := make(map[int]int)
+	obj := func(n int, body string) {
+		offsets[n] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", n, body)
+	}
+
+	obj(root, fmt.Sprintf("<</Type /StructTreeRoot /K %d 0 R /ParentTree %d 0 R /ParentTreeNextKey %d>>", doc, parentTree, len(pages)))
+	var k strings.Builder
+	for i := range t.elems {
+		fmt.Fprintf(&k, " %d 0 R", elem(i))
+	}
+	obj(doc, fmt.Sprintf("<</Type /StructElem /S /Document /P %d 0 R /K [%s]>>", root, strings.TrimSpace(k.String())))
+	parents := make([][]int, len(pages)) // page index -> element of each MCID
+	for i, e := range t.elems {
+		var mcr strings.Builder
+		for _, m := range e.lines {
+			fmt.Fprintf(&mcr, "<</Type /MCR /Pg %d 0 R /MCID %d>>", pages[m.page-1], m.mcid)
+			parents[m.page-1] = append(parents[m.page-1], elem(i))
+		}
+		var alt string
+		if e.alt != "" {
+			alt = " /Alt " + pdfString(e.alt)
+		}
+		obj(elem(i), fmt.Sprintf("<</Type /StructElem /S /%s /P %d 0 R%s /K [%s]>>", e.tag, doc, alt, mcr.String()))
+	}
+	var nums strings.Builder
+	for p, elems := range parents {
+		fmt.Fprintf(&nums, "%d [", p)
+		for _, e := range elems {
+			fmt.Fprintf(&nums, " %d 0 R", e)
+		}
+		nums.WriteString("] ")
+	}
+	obj(parentTree, fmt.Sprintf("<</Nums [%s]>>", strings.TrimSpace(nums.String())))
+
+	for i, n := range pages {
+		dict, err := pdfDict(data, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to tag PDF: %w", err)
+		}
+		obj(n, fmt.Sprintf("%s\n/StructParents %d\n/Tabs /S>>", dict, i))
+	}
+	dict, err := pdfDict(data, catalog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tag PDF: %w", err)
+	}
+	obj(catalog, fmt.Sprintf("%s/Version /1.7\n/MarkInfo <</Marked true>>\n/StructTreeRoot %d 0 R\n/Metadata %s 0 R\n/ViewerPreferences <</DisplayDocTitle true>>\n>>",
+		dict, root, metadata[1]))
+
+	// Cross-reference section of the changed objects
+	xref := out.Len()
+	out.WriteString("xref\n")
+	for n := 0; n <= parentTree; n++ {
+		if _, ok := offsets[n]; !ok {
+			continue
+		}
+		last := n
+		for offsets[last+1] > 0 {
+			last++
+		}
+		fmt.Fprintf(out, "%d %d\n", n, last-n+1)
+		for ; n <= last; n++ {
+			fmt.Fprintf(out, "%010d 00000 n \n", offsets[n])
+		}
+	}
+	fmt.Fprintf(out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n/Info %d 0 R\n/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n",
+		parentTree+1, catalog, info, prev, xref)
+	return out.Bytes(), nil
+}
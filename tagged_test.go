@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRuleKind(t *testing.T) {
+	tests := []struct {
+		line string
+		want byte
+	}{
+		{lineDouble, '='},
+		{lineSingle, '-'},
+		{"  -----", '-'},
+		{signatureLine, '_'},
+		{"-=-=", 0},
+		{"Von:    Stuttgart", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := ruleKind(tt.line); got != tt.want {
+			t.Errorf("ruleKind(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestCreatePDFTagged(t *testing.T) {
+	header := buildDocumentHeader("RK-2026-02-TEST", "02/2026", "28.02.2026", "01.02.2026", "28.02.2026", "Kilometergelderstattung", "", SevDeskConfig{})
+	block := buildCustomerHeader(Customer{ID: "1", Name: "Acme", From: "Stuttgart", To: "Karlsruhe"})
+	blocks := make([]string, 20) // more than a page
+	for i := range blocks {
+		blocks[i] = block
+	}
+	data, err := createPDF(header, blocks, buildDocumentFooter(600))
+	if err != nil {
+		t.Fatalf("createPDF() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"/Lang (de-DE)",
+		"/Title (KILOMETERGELDERSTATTUNG 02/2026)",
+		"<rdf:li xml:lang=\"x-default\">KILOMETERGELDERSTATTUNG 02/2026</rdf:li>",
+		"/MarkInfo <</Marked true>>",
+		"/ViewerPreferences <</DisplayDocTitle true>>",
+		"/S /Document",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF missing %q", want)
+		}
+	}
+	if n := bytes.Count(data, []byte("/S /H1 ")); n != 1 {
+		t.Errorf("PDF has %d H1, want 1", n)
+	}
+	if n := bytes.Count(data, []byte("/S /H2 ")); n != len(blocks) {
+		t.Errorf("PDF has %d H2, want one per customer header", n)
+	}
+
+	// Every page belongs to the parent tree, every marked line to an element
+	pages := pdfPageCount(t, data)
+	if pages < 2 {
+		t.Fatalf("PDF has %d pages, want at least 2", pages)
+	}
+	for i := 0; i < pages; i++ {
+		if !bytes.Contains(data, []byte(fmt.Sprintf("/StructParents %d\n", i))) {
+			t.Errorf("page %d has no StructParents", i)
+		}
+	}
+	text := pdfText(t, data)
+	if marked, refs := strings.Count(text, "<</MCID"), bytes.Count(data, []byte("/Type /MCR")); marked != refs {
+		t.Errorf("%d marked lines, %d referenced by the structure tree", marked, refs)
+	}
+
+	// Rules are artifacts
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "-----") && (i == 0 || lines[i-1] != "/Artifact BMC") {
+			t.Errorf("rule not marked as artifact: %q", line)
+		}
+	}
+
+	// The cross-reference section of the update points to its objects
+	xref := data[bytes.LastIndex(data, []byte("\nxref\n"))+6 : bytes.LastIndex(data, []byte("trailer"))]
+	entries := regexp.MustCompile(`(?m)^(\d+) (\d+)\n((?:\d{10} 00000 n \n)+)`).FindAllSubmatch(xref, -1)
+	if len(entries) == 0 {
+		t.Fatalf("no cross-reference entries:\n%s", xref)
+	}
+	for _, e := range entries {
+		first, _ := strconv.Atoi(string(e[1]))
+		for i, line := range strings.Split(strings.TrimSpace(string(e[3])), "\n") {
+			offset, _ := strconv.Atoi(line[:10])
+			if want := fmt.Sprintf("%d 0 obj\n", first+i); !bytes.HasPrefix(data[offset:], []byte(want)) {
+				t.Errorf("xref offset %d of object %d points to %q", offset, first+i, data[offset:offset+10])
+			}
+		}
+	}
+}
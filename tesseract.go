@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Tesseract OCR
+// ---------------------------------------------------------------------------
+
+func init() {
+	registerOCR("tesseract", OCRFunc(tesseractOCR))
+}
+
+// tesseractOCR recognizes a receipt image with a local tesseract binary.
+func tesseractOCR(cfg *Config, path string) (string, error) {
+	command, lang := cfg.OCR.Command, cfg.OCR.Language
+	if command == "" {
+		command = "tesseract"
+	}
+	if lang == "" {
+		lang = "deu"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command, path, "stdout", "-l", lang)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/go-gomail/gomail"
+)
+
+// ---------------------------------------------------------------------------
+// Mail Test
+// ---------------------------------------------------------------------------
+
+// testMailSubject and testMailBody make up the message sent by test-mail.
+const (
+	testMailSubject = "Reisekosten Testnachricht"
+	testMailBody    = "Diese Nachricht wurde mit <code>reisekosten test-mail</code> gesendet. Die Mail-Konfiguration funktioniert.<br>"
+)
+
+// testMail checks the mail configuration before the report is due. With the
+// smtp provider it connects, authenticates and prints the TLS and
+// authentication details, then sends a test message to email.to or, without
+// send, only verifies the session with RSET. Other providers can only be
+// tested by sending.
+func testMail(cfg *Config, send bool, w io.Writer) error {
+	if send && (cfg.Email.From == "" || cfg.Email.To == "") {
+		return errors.New("email.from and email.to are required to send a test mail")
+	}
+	m := Mail{Subject: testMailSubject, Body: testMailBody}
+
+	if provider := cfg.Email.Provider; provider != "" && provider != "smtp" {
+		if !send {
+			return fmt.Errorf("email provider %s can only be tested by sending a mail", provider)
+		}
+		if err := sendEmail(cfg, m); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "test mail sent to %s via %s\n", cfg.Email.To, provider)
+		return nil
+	}
+
+	s := cfg.SMTP
+	if s.Host == "" {
+		return errors.New("smtp.host is not configured")
+	}
+	mode, err := s.tlsMode()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "server:      %s (tls: %s)\n", net.JoinHostPort(s.Host, fmt.Sprint(s.Port)), mode)
+
+	sender, err := dialSMTP(s)
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+
+	if state, ok := sender.TLSConnectionState(); ok {
+		how := "STARTTLS"
+		if mode == smtpTLSImplicit {
+			how = "implicit TLS"
+		}
+		fmt.Fprintf(w, "tls:         %s, %s, %s\n", how, tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+		if len(state.PeerCertificates) > 0 {
+			cert := state.PeerCertificates[0]
+			verified := "verified"
+			if s.InsecureSkipVerify {
+				verified = "NOT verified (insecureSkipVerify)"
+			}
+			fmt.Fprintf(w, "certificate: %s, issued by %s, valid until %s, %s\n",
+				cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format(isoDate), verified)
+		}
+	} else {
+		fmt.Fprintln(w, "tls:         none, the connection is not encrypted")
+	}
+
+	switch {
+	case sender.auth != "":
+		fmt.Fprintf(w, "auth:        %s as %s\n", sender.auth, s.User)
+	case s.User != "":
+		fmt.Fprintln(w, "auth:        none, the server offers no AUTH")
+	default:
+		fmt.Fprintln(w, "auth:        none, smtp.user is not set")
+	}
+
+	if !send {
+		if err := sender.Reset(); err != nil {
+			return fmt.Errorf("RSET failed: %w", err)
+		}
+		fmt.Fprintln(w, "session verified (RSET), no mail sent")
+		return nil
+	}
+	if err := gomail.Send(sender, buildMessage(cfg, m)); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "test mail sent to %s\n", cfg.Email.To)
+	return nil
+}
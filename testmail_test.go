@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestMail(t *testing.T) {
+	host, port, session := fakeSMTPServer(t)
+	cfg := &Config{
+		SMTP:  SMTPConfig{Host: host, Port: port, TLS: smtpTLSNone},
+		Email: EmailConfig{From: "me@example.com", To: "boss@example.com"},
+	}
+
+	var out strings.Builder
+	if err := testMail(cfg, true, &out); err != nil {
+		t.Fatalf("testMail() error = %v", err)
+	}
+	if msg := (<-session).Data; !strings.Contains(msg, "Subject: "+testMailSubject) {
+		t.Errorf("test message missing subject:\n%s", msg)
+	}
+	for _, want := range []string{"tls:         none", "auth:        none, smtp.user is not set", "test mail sent to boss@example.com"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// Without sending the session is only verified
+	host, port, _ = fakeSMTPServer(t)
+	cfg.SMTP = SMTPConfig{Host: host, Port: port, TLS: smtpTLSNone, User: "me"}
+	out.Reset()
+	if err := testMail(cfg, false, &out); err != nil {
+		t.Fatalf("testMail(no send) error = %v", err)
+	}
+	for _, want := range []string{"auth:        none, the server offers no AUTH", "session verified (RSET)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	cfg.Email.Provider = "sendgrid"
+	if err := testMail(cfg, false, &out); err == nil {
+		t.Error("testMail(sendgrid, no send) error = nil")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Public Transport Tickets
+// ---------------------------------------------------------------------------
+
+// Ticket is the fare claimed per visit day of a customer reached by public
+// transport, instead of the kilometers.
+type Ticket struct {
+	Type  string  `yaml:"type"`  // printed in the entry, e.g. Deutschlandticket (Anteil)
+	Price float64 `yaml:"price"` // EUR per visit day
+}
+
+// active reports whether the customer is reached by public transport.
+func (t Ticket) active() bool {
+	return t.Type != "" || t.Price > 0
+}
+
+// kmAmount returns the Kilometergeld of the trip: the ticket price of a
+// customer reached by public transport, otherwise the km at the trip's rate.
+// The entry and its detour line are rounded to cents individually, as
+// printed.
+func (t tripDay) kmAmount(c Customer, rates Rates) float64 {
+	if c.Ticket.active() {
+		return c.Ticket.Price
+	}
+	kmRate := t.kmRate(c, rates)
+	return roundCents(float64(t.distance-t.detour.Km)*kmRate) + roundCents(float64(t.detour.Km)*kmRate)
+}
+
+// tripAmount returns the Kilometergeld of the customer's n-th trip.
+func (c CustomerReport) tripAmount(n int) float64 {
+	if c.Customer.Ticket.active() {
+		return c.Customer.Ticket.Price
+	}
+	return roundCents(float64(c.distance(n)) * c.rate(n))
+}
+
+// tripAmount returns the Kilometergeld of the customer's n-th trip.
+func (c customerSummary) tripAmount(n int) float64 {
+	if c.Ticket != "" {
+		return c.TicketPrice
+	}
+	return roundCents(float64(c.distance(n)) * c.rate(n))
+}
+
+// buildTicketEntry creates the entry of a trip by public transport with the
+// ticket type below the amount. Non-empty details are printed below.
+func buildTicketEntry(dateString string, t Ticket, details ...string) string {
+	var b strings.Builder
+
+	label := "Fahrkarte"
+	amountStr := formatAmount(t.Price) + " EUR"
+
+	b.WriteString(fmt.Sprintf("  %s  (Hin- und Rueckfahrt)\n", dateString))
+	b.WriteString(fmt.Sprintf("    %s%s\n", label, rightAlign(amountStr, 45-len(label))))
+	writeEntryDetails(&b, append([]string{"Ticket: " + umlautReplacer.Replace(t.Type)}, details...))
+
+	return b.String()
+}
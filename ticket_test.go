@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTicketCustomer(t *testing.T) {
+	cfg := &Config{
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{Weekdays: []string{"mon", "tue"}}},
+			{ID: "2", Name: "Stadtwerke", Province: "BW", Ticket: Ticket{Type: "Deutschlandticket (Anteil)", Price: 2.90}, Schedule: Schedule{Weekdays: []string{"wed", "thu", "fri"}}},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// 12 visit days by public transport claim the ticket, no km
+	c := report.Customers[1]
+	if c.KmAmount != 34.80 || c.km() != 0 || report.KmTotal != 8*30+34.80 {
+		t.Errorf("KmAmount = %v, km = %d, KmTotal = %v", c.KmAmount, c.km(), report.KmTotal)
+	}
+	if c.VerpAmount != 12*verpflegungRate || c.distanceGroups() != nil {
+		t.Errorf("VerpAmount = %v, distanceGroups() = %v", c.VerpAmount, c.distanceGroups())
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Fahrkarte                            2,90 EUR", "Ticket: Deutschlandticket \\(Anteil\\)"} {
+		if !strings.Contains(km, want) {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+	s := newRunSummary(cfg, p, report, nil)
+	if cs := s.Customers[1]; cs.TicketPrice != 2.90 || cs.tripAmount(0) != 2.90 || cs.Distances != nil {
+		t.Errorf("summary = %+v", cs)
+	}
+	if lines := invoiceLines(c, nil); len(lines) != 2 || lines[0].name != "Fahrkarte" || lines[0].amount() != 34.80 {
+		t.Errorf("invoiceLines() = %+v", lines)
+	}
+}
+
+func TestTripKmAmount(t *testing.T) {
+	c := Customer{ID: "1", Name: "Acme", Distance: 5, KmRate: 0.305}
+	// The entry (10 km, 3,05 EUR) and the detour line (7 km, 2,13 EUR) are
+	// rounded separately, as printed and summed in the report
+	trip := tripDay{date: "2026-02-02", distance: 17, detour: Detour{Km: 7}}
+	if got := trip.kmAmount(c, Rates{}); got != 5.18 {
+		t.Errorf("kmAmount() = %v, want 5.18", got)
+	}
+	c.Ticket = Ticket{Price: 2.90}
+	if got := trip.kmAmount(c, Rates{}); got != 2.90 {
+		t.Errorf("kmAmount() with ticket = %v, want 2.90", got)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Trip Times
+// ---------------------------------------------------------------------------
+
+// Default departure and return of a trip
+const (
+	defaultDeparture = "07:00"
+	defaultReturn    = "17:00"
+)
+
+// perDiemMinutes is the absence a day must exceed for the meal allowance
+// (more than 8 hours, § 9 Abs. 4a EStG).
+const perDiemMinutes = 8 * 60
+
+// clockRegex matches a time of day as HH:MM.
+var clockRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// TripTimes are the departure and return times of a trip. Empty fields fall
+// back to the next level: day (override file), customer, config, default.
+type TripTimes struct {
+	Departure string `yaml:"departure,omitempty"` // HH:MM (default 07:00)
+	Return    string `yaml:"return,omitempty"`    // HH:MM (default 17:00)
+}
+
+// merge returns t with the fields set in o replaced.
+func (t TripTimes) merge(o TripTimes) TripTimes {
+	if o.Departure != "" {
+		t.Departure = o.Departure
+	}
+	if o.Return != "" {
+		t.Return = o.Return
+	}
+	return t
+}
+
+// resolved returns t with the defaults for empty fields.
+func (t TripTimes) resolved() TripTimes {
+	return TripTimes{Departure: defaultDeparture, Return: defaultReturn}.merge(t)
+}
+
+// minutes returns the absence in minutes.
+func (t TripTimes) minutes() int {
+	r := t.resolved()
+	return clockMinutes(r.Return) - clockMinutes(r.Departure)
+}
+
+// perDiemEligible reports whether the absence exceeds 8 hours.
+func (t TripTimes) perDiemEligible() bool {
+	return t.minutes() > perDiemMinutes
+}
+
+// String formats the times as printed in the entries, e.g. "07:00 - 17:00".
+func (t TripTimes) String() string {
+	r := t.resolved()
+	return r.Departure + " - " + r.Return
+}
+
+// check validates the format of the set fields and, if both are set, their
+// order.
+func (t TripTimes) check() error {
+	for _, s := range []string{t.Departure, t.Return} {
+		if s != "" && !clockRegex.MatchString(s) {
+			return fmt.Errorf("invalid time %q (use HH:MM)", s)
+		}
+	}
+	if t.Departure != "" && t.Return != "" && t.minutes() <= 0 {
+		return fmt.Errorf("return %s is not after departure %s", t.Return, t.Departure)
+	}
+	return nil
+}
+
+// clockMinutes returns the minutes since midnight of an HH:MM time.
+func clockMinutes(s string) int {
+	if len(s) != 5 {
+		return 0
+	}
+	h, _ := strconv.Atoi(s[:2])
+	m, _ := strconv.Atoi(s[3:])
+	return h*60 + m
+}
+
+// tripTimes returns the times of the trip to customer c on date: the day's
+// times of the override file, then the customer's, then the configured ones.
+func (c *Config) tripTimes(ov *MonthOverride, customer Customer, date time.Time) TripTimes {
+	return c.Times.merge(customer.Times).merge(ov.Times[date.Format(isoDate)])
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTripTimes(t *testing.T) {
+	tests := []struct {
+		times    TripTimes
+		want     string
+		eligible bool
+		valid    bool
+	}{
+		{TripTimes{}, "07:00 - 17:00", true, true},
+		{TripTimes{Departure: "09:00"}, "09:00 - 17:00", false, true},
+		{TripTimes{Departure: "08:00", Return: "16:01"}, "08:00 - 16:01", true, true},
+		{TripTimes{Return: "25:00"}, "07:00 - 25:00", true, false},
+		{TripTimes{Departure: "18:00", Return: "08:00"}, "18:00 - 08:00", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.times.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.times, got, tt.want)
+		}
+		if got := tt.times.perDiemEligible(); got != tt.eligible {
+			t.Errorf("%+v.perDiemEligible() = %v, want %v", tt.times, got, tt.eligible)
+		}
+		if err := tt.times.check(); (err == nil) != tt.valid {
+			t.Errorf("%+v.check() error = %v", tt.times, err)
+		}
+	}
+}
+
+func TestTripTimesThreshold(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("times:\n  2026-02-03:\n    return: \"14:00\"\n"), 0644)
+	cfg := &Config{
+		Overrides: dir,
+		Times:     TripTimes{Departure: "07:30"},
+		Customers: []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Tuesday 3 is under 8 hours: kilometers, but no meal allowance entry
+	c := report.Customers[0]
+	if len(c.HalfDays) != 1 || c.HalfDays[0] != "03.02.2026" || report.KmTotal != 600 || report.VerpTotal != 19*verpflegungRate {
+		t.Errorf("half days = %v, KmTotal = %v, VerpTotal = %v", c.HalfDays, report.KmTotal, report.VerpTotal)
+	}
+	verp := pdfText(t, report.Attachments[1].Data)
+	if strings.Contains(verp, "03.02.2026") {
+		t.Errorf("Verpflegungsmehraufwand lists the day under 8 hours")
+	}
+	if !strings.Contains(verp, "Mo, 02.02.2026  \\(07:30 - 17:00\\)") {
+		t.Errorf("Verpflegungsmehraufwand does not print the configured times")
+	}
+}
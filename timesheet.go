@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Timesheet (Stundennachweis)
+// ---------------------------------------------------------------------------
+
+// timesheetHoursPerDay is the working time recorded for each trip day.
+const timesheetHoursPerDay = 8.0
+
+// Timesheet is the hours sheet generated for a customer with timesheet: true.
+type Timesheet struct {
+	CustomerID string
+	DocID      string
+	Hours      float64
+}
+
+// formatHours formats hours with German decimal separator (e.g. "8,00 h").
+func formatHours(hours float64) string {
+	return formatAmount(hours) + " h"
+}
+
+// buildTimesheetHeader creates the header of a customer's hours sheet.
+func buildTimesheetHeader(docID, period, dateString string, c Customer) string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("STUNDENNACHWEIS %s", period)
+	padding := (lineWidth - len(header)) / 2
+	b.WriteString(lineDouble + "\n")
+	b.WriteString(fmt.Sprintf("%s%s\n", strings.Repeat(" ", padding), header))
+	b.WriteString(lineDouble + "\n\n")
+
+	b.WriteString(fmt.Sprintf("Beleg-Nr.:            %s\n", docID))
+	b.WriteString(fmt.Sprintf("Datum:                %s\n", dateString))
+	b.WriteString(fmt.Sprintf("Kunde:                %s) %s\n", c.ID, c.Name))
+	b.WriteString(fmt.Sprintf("Einsatzort:           %s\n", c.To))
+	b.WriteString("\n")
+
+	b.WriteString(lineSingle + "\n")
+	b.WriteString(fmt.Sprintf("  %-12s%-45s%s\n", "Datum", "Projekt", rightAlign("Stunden", 16)))
+	b.WriteString(lineSingle + "\n")
+
+	return b.String()
+}
+
+// buildTimesheetEntry creates a single line of the hours sheet.
+func buildTimesheetEntry(dateString, project string, hours float64) string {
+	return fmt.Sprintf("  %-12s%-45s%s", dateString, project, rightAlign(formatHours(hours), 16))
+}
+
+// buildTimesheetFooter creates the footer with the total hours.
+func buildTimesheetFooter(totalHours float64) string {
+	var b strings.Builder
+
+	b.WriteString(lineSingle + "\n")
+	b.WriteString(fmt.Sprintf("GESAMTSTUNDEN:%s\n", rightAlign(formatHours(totalHours), 61)))
+	b.WriteString(lineDouble + "\n")
+
+	return b.String()
+}
+
+// createTimesheet generates the hours sheet PDF for the days assigned to a
+// customer. The project code is used as project, or the trip reason if none
+// is set.
+func createTimesheet(p Period, docID, dateString string, c CustomerReport) (Timesheet, Attachment, error) {
+	ts := Timesheet{CustomerID: c.Customer.ID, DocID: docID}
+
+	project := c.Customer.Project
+	if project == "" {
+		project = c.Customer.Reason
+	}
+	blocks := make([]string, 0, len(c.Dates))
+	for _, d := range c.Dates {
+		blocks = append(blocks, buildTimesheetEntry(d, project, timesheetHoursPerDay))
+		ts.Hours += timesheetHoursPerDay
+	}
+
+	data, err := createPDF(buildTimesheetHeader(ts.DocID, p.Label(), dateString, c.Customer), blocks, buildTimesheetFooter(ts.Hours))
+	if err != nil {
+		return Timesheet{}, Attachment{}, err
+	}
+	filename := fmt.Sprintf("%s_Stundennachweis_%s.pdf", p.filePrefix(), outboxSlug(c.Customer.ID))
+	return ts, Attachment{Filename: filename, Data: data, Type: "Stundennachweis", DocID: ts.DocID}, nil
+}
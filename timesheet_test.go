@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTimesheetEntry(t *testing.T) {
+	entry := buildTimesheetEntry("02.02.2026", "Projektarbeit", 8)
+	if len(entry) != lineWidth || !strings.HasPrefix(entry, "  02.02.2026  Projektarbeit") || !strings.HasSuffix(entry, "8,00 h") {
+		t.Errorf("entry = %q", entry)
+	}
+	if footer := buildTimesheetFooter(160); !strings.Contains(footer, "GESAMTSTUNDEN:") || !strings.Contains(footer, "160,00 h") {
+		t.Errorf("footer = %q", footer)
+	}
+}
+
+func TestGenerateReportTimesheet(t *testing.T) {
+	cfg := &Config{Overrides: t.TempDir(), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Reason: "Projektarbeit", Timesheet: true},
+		{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+	}}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if len(report.Timesheets) != 1 || report.Timesheets[0].CustomerID != "1" || report.Timesheets[0].Hours != 80 {
+		t.Fatalf("Timesheets = %+v", report.Timesheets)
+	}
+	if len(report.Attachments) != 3 || report.Attachments[2].Filename != "02_2026_Stundennachweis_1.pdf" {
+		t.Fatalf("attachments = %d", len(report.Attachments))
+	}
+
+	s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil)
+	if len(s.Documents) != 3 || s.Documents[2].Type != "Stundennachweis" || s.Documents[2].ID != report.Timesheets[0].DocID {
+		t.Errorf("documents = %+v", s.Documents)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Unclaimed Days (Nachtraege)
+// ---------------------------------------------------------------------------
+
+// unclaimedDay is a trip dropped by a cap. It stays in the state until a
+// later report claims it as a Nachtrag.
+type unclaimedDay struct {
+	Date     string `json:"date"`     // YYYY-MM-DD
+	Customer string `json:"customer"` // customer ID
+	Period   string `json:"period"`   // key of the report that dropped the trip
+}
+
+// lateClaimDetail returns the entry line of a Nachtrag, or "" for a trip of
+// the period.
+func lateClaimDetail(key string) string {
+	if from, ok := parsePeriodKey(key); ok {
+		return "Nachtrag aus " + from.Label()
+	}
+	return ""
+}
+
+// droppedTrips returns the trips of before that are missing from after. With
+// carried the customers whose cap carries the amount instead are left out.
+// Nachtraege dropped again stay pending and are left out as well.
+func droppedTrips(before, after []tripDay, customers []Customer, p Period, carried bool) []unclaimedDay {
+	kept := make(map[string]bool, len(after))
+	for _, t := range after {
+		kept[t.date] = true
+	}
+	var dropped []unclaimedDay
+	for _, t := range before {
+		if kept[t.date] || t.lateClaim != "" || (carried && customers[t.customer].Cap.OnExceed == capCarry) {
+			continue
+		}
+		d, _ := time.Parse("02.01.2006", t.date)
+		dropped = append(dropped, unclaimedDay{Date: d.Format(isoDate), Customer: customers[t.customer].ID, Period: p.Key()})
+	}
+	return dropped
+}
+
+// pendingUnclaimed returns the unclaimed days of the state recorded by
+// reports of earlier periods.
+func pendingUnclaimed(cfg *Config, p Period) ([]unclaimedDay, error) {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return nil, err
+	}
+	start := p.Start().Format(isoDate)
+	var pending []unclaimedDay
+	for _, d := range state.Unclaimed {
+		if d.Date < start && d.Period != p.Key() {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+// lateClaims returns the pending days of the run's customers as trips with
+// the settings of their month, labeled as Nachtraege, and the days they
+// claim. Days of customers not in the run stay pending.
+func (c *Config) lateClaims(customers []Customer, pending []unclaimedDay) ([]tripDay, []unclaimedDay, error) {
+	index := make(map[string]int, len(customers))
+	for i, cust := range customers {
+		index[cust.ID] = i
+	}
+	loaded := make(map[string]*MonthOverride)
+	var trips []tripDay
+	var claimed []unclaimedDay
+	for _, d := range pending {
+		ci, ok := index[d.Customer]
+		if !ok {
+			continue
+		}
+		date, err := time.Parse(isoDate, d.Date)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid unclaimed day %q in the state file", d.Date)
+		}
+		t, err := c.lateTrip(customers, ci, date, loaded)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.lateClaim, t.lateNote = d.Period, lateClaimDetail(d.Period)
+		trips = append(trips, t)
+		claimed = append(claimed, d)
+	}
+	return trips, claimed, nil
+}
+
+// unclaimedWarning returns the pre-flight warning offering the pending days,
+// or "" if there are none.
+func unclaimedWarning(pending []unclaimedDay) string {
+	if len(pending) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d unclaimed days of earlier reports, claim them as Nachtraege with --include-unclaimed", len(pending))
+}
+
+// claimedDays returns the days of claims whose trip is still in the report.
+func claimedDays(claims []unclaimedDay, trips []tripDay) []unclaimedDay {
+	kept := make(map[string]bool, len(trips))
+	for _, t := range trips {
+		kept[t.date] = true
+	}
+	var claimed []unclaimedDay
+	for _, d := range claims {
+		if date, _ := time.Parse(isoDate, d.Date); kept[date.Format("02.01.2006")] {
+			claimed = append(claimed, d)
+		}
+	}
+	return claimed
+}
+
+// settleUnclaimed records the days the report dropped and removes those it
+// claimed. Days recorded by an earlier run of the same period are replaced.
+func (s *State) settleUnclaimed(p Period, report *Report) {
+	claimed := make(map[unclaimedDay]bool, len(report.LateClaims))
+	for _, d := range report.LateClaims {
+		claimed[d] = true
+	}
+	var days []unclaimedDay
+	for _, d := range s.Unclaimed {
+		if d.Period != p.Key() && !claimed[d] {
+			days = append(days, d)
+		}
+	}
+	days = append(days, report.Unclaimed...)
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	s.Unclaimed = days
+	if len(report.Unclaimed) > 0 || len(report.LateClaims) > 0 {
+		slog.Info("unclaimed days updated", "dropped", len(report.Unclaimed), "claimed", len(report.LateClaims), "pending", len(days))
+	}
+}
+
+// recordStored marks a report delivered without mail in the state file and
+// settles its unclaimed days, so scheduled runs do not deliver it again.
+func recordStored(cfg *Config, p Period, report *Report) error {
+	state, err := loadState(cfg.StateFile())
+	if err != nil {
+		return err
+	}
+	state.settleUnclaimed(p, report)
+	state.recordDelivered(p, time.Now())
+	return state.save(cfg.StateFile())
+}
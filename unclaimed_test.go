@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnclaimedDays(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{State: filepath.Join(dir, "state.json"), Overrides: filepath.Join(dir, "overrides"), Customers: []Customer{
+		{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Cap: CustomerCap{Total: 500, OnExceed: capTrim}},
+	}}
+
+	// 9 of the 20 days in February exceed the cap
+	feb := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, feb)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	if len(report.Unclaimed) != 9 || report.Unclaimed[0] != (unclaimedDay{Date: "2026-02-17", Customer: "1", Period: "2026-02"}) {
+		t.Fatalf("Unclaimed = %+v", report.Unclaimed)
+	}
+	if err := recordStored(cfg, feb, report); err != nil {
+		t.Fatalf("recordStored() error = %v", err)
+	}
+
+	// March offers them, and claims them as Nachtraege when asked
+	cfg.Customers[0].Cap = CustomerCap{}
+	mar := monthPeriod(2026, 3)
+	if report, err = generateReport(cfg, mar); err != nil {
+		t.Fatalf("generateReport(March) error = %v", err)
+	}
+	if report.Workdays != 22 || len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "9 unclaimed days") {
+		t.Errorf("March: workdays = %d, warnings = %q", report.Workdays, report.Warnings)
+	}
+	cfg.IncludeUnclaimed = true
+	if report, err = generateReport(cfg, mar); err != nil {
+		t.Fatalf("generateReport(March, include) error = %v", err)
+	}
+	if report.Workdays != 31 || len(report.LateClaims) != 9 || report.KmTotal != 31*30 {
+		t.Errorf("March with Nachtraege: workdays = %d, claims = %d, km = %v", report.Workdays, len(report.LateClaims), report.KmTotal)
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	if !strings.Contains(km, "Nachtrag aus 02/2026") || !strings.Contains(km, "27.02.2026") {
+		t.Error("Kilometergelderstattung misses the Nachtraege")
+	}
+	if err := recordStored(cfg, mar, report); err != nil {
+		t.Fatalf("recordStored(March) error = %v", err)
+	}
+	if state, _ := loadState(cfg.State); len(state.Unclaimed) != 0 {
+		t.Errorf("state after claiming = %+v", state.Unclaimed)
+	}
+}
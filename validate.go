@@ -0,0 +1,573 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Config Validation
+// ---------------------------------------------------------------------------
+
+// configProblem is a single validation finding.
+type configProblem struct {
+	File string // config file or overlay, empty if unknown
+	Line int    // line in File, 0 if unknown
+	Msg  string // description including the field path
+}
+
+// ConfigErrors lists all problems found in a configuration file and its overlays.
+type ConfigErrors struct {
+	Path     string
+	Problems []configProblem
+}
+
+func (e *ConfigErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d configuration problem(s)", e.Path, len(e.Problems))
+	for _, p := range e.Problems {
+		switch {
+		case p.Line > 0 && p.File != "" && p.File != e.Path:
+			fmt.Fprintf(&b, "\n  %s line %d: %s", p.File, p.Line, p.Msg)
+		case p.Line > 0:
+			fmt.Fprintf(&b, "\n  line %d: %s", p.Line, p.Msg)
+		default:
+			fmt.Fprintf(&b, "\n  %s", p.Msg)
+		}
+	}
+	return b.String()
+}
+
+// validator collects problems and resolves field paths to their position.
+type validator struct {
+	root     *yaml.Node
+	origins  map[*yaml.Node]string // node -> file it was read from
+	problems []configProblem
+}
+
+// problemAt records a problem located at node.
+func (v *validator) problemAt(node *yaml.Node, msg string) {
+	p := configProblem{Msg: msg}
+	if node != nil {
+		p.File, p.Line = v.origins[node], node.Line
+	}
+	v.problems = append(v.problems, p)
+}
+
+// addf records a problem for the field at the dotted path (e.g. "customers.1.distance").
+func (v *validator) addf(path, format string, args ...any) {
+	v.problemAt(v.lookup(path), displayPath(path)+": "+fmt.Sprintf(format, args...))
+}
+
+// lookup returns the node at path, using the key node for mapping entries.
+// For missing fields the closest existing parent is returned.
+func (v *validator) lookup(path string) *yaml.Node {
+	node := v.root
+	var found *yaml.Node
+	for _, seg := range strings.Split(path, ".") {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == seg {
+					found = node.Content[i]
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(seg); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+				found = next
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return found
+}
+
+// checkKnownKeys reports mapping keys that have no corresponding field in t,
+// which usually are typos.
+func (v *validator) checkKnownKeys(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			ft, ok := fields[key.Value]
+			if !ok {
+				v.problemAt(key, fmt.Sprintf("unknown key %q in %s", key.Value, t.Name()))
+				continue
+			}
+			v.checkKnownKeys(node.Content[i+1], ft)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			v.checkKnownKeys(item, t.Elem())
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			v.checkKnownKeys(node.Content[i], t.Elem())
+		}
+	}
+}
+
+// displayPath turns "customers.1.distance" into "customers[1].distance".
+func displayPath(path string) string {
+	var b strings.Builder
+	for i, seg := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(seg); err == nil {
+			b.WriteString("[" + seg + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(seg)
+	}
+	return b.String()
+}
+
+// required records a problem if value is empty.
+func (v *validator) required(path, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(path, "required")
+	}
+}
+
+// address records a problem if value is not a valid email address.
+func (v *validator) address(path, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := mail.ParseAddress(value); err != nil {
+		v.addf(path, "invalid email address %q", value)
+	}
+}
+
+// oneOf records a problem if value is not one of the allowed values.
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf(path, "invalid value %q (use %s)", value, strings.Join(allowed[1:], ", "))
+}
+
+// validate checks the decoded configuration for semantic problems.
+func (v *validator) validate(cfg *Config) {
+	// Delivery
+	v.oneOf("delivery", cfg.Delivery, "", deliveryNone, deliveryEmail, deliveryAPI, deliveryStorage)
+	switch cfg.DeliveryMode() {
+	case deliveryAPI:
+		v.required("accounting.provider", cfg.Accounting.Provider)
+	case deliveryStorage:
+		v.required("output.dir", cfg.Output.Dir)
+	}
+	// Mail is needed to deliver reports, approval requests and the tax advisor bundle
+	mail := cfg.DeliveryMode() == deliveryEmail || cfg.Approval.To != "" || cfg.TaxAdvisor.Email != ""
+
+	// Email
+	if mail {
+		v.required("email.from", cfg.Email.From)
+		if !cfg.taxDeduction() {
+			v.required("email.to", cfg.Email.To)
+		}
+	}
+	v.address("email.from", cfg.Email.From)
+	v.address("email.to", cfg.Email.To)
+	v.address("email.replyTo", cfg.Email.ReplyTo)
+	v.address("taxAdvisor.email", cfg.TaxAdvisor.Email)
+	v.address("approval.to", cfg.Approval.To)
+	if cfg.Email.MaxSizeMB < 0 {
+		v.addf("email.maxSizeMB", "must not be negative")
+	}
+	if _, err := reportSubject(cfg, Period{}, &Report{}); err != nil {
+		v.addf("email.subject", "%v", errors.Unwrap(err))
+	}
+	sample := newOutputFile(2026, monthPeriod(2026, 2), "02_2026_Reisekosten_Kilometergelderstattung.pdf", "RK-2026-02-TEST")
+	if _, err := cfg.Output.path(sample); err != nil {
+		v.addf("output.filename", "%v", errors.Unwrap(err))
+	}
+	for i, d := range cfg.Email.DSN {
+		v.oneOf(fmt.Sprintf("email.dsn.%d", i), d, "", "success", "failure", "delay", "never")
+	}
+	for i, f := range cfg.Email.AttachData {
+		if _, ok := dataAttachments[f]; !ok {
+			v.addf(fmt.Sprintf("email.attachData.%d", i), "unknown format %q (use %s)", f, registryNames(dataAttachments))
+		}
+	}
+
+	// Provider credentials
+	switch cfg.Email.Provider {
+	case "", "smtp":
+		if !mail {
+			break
+		}
+		v.required("smtp.host", cfg.SMTP.Host)
+		if cfg.SMTP.Port <= 0 || cfg.SMTP.Port > 65535 {
+			v.addf("smtp.port", "must be between 1 and 65535")
+		}
+		if _, err := cfg.SMTP.tlsMode(); err != nil {
+			v.addf("smtp.tls", "invalid value %q (use auto, starttls, implicit or none)", cfg.SMTP.TLS)
+		}
+		if _, ok := tlsVersions[cfg.SMTP.MinTLSVersion]; cfg.SMTP.MinTLSVersion != "" && !ok {
+			v.addf("smtp.minTLSVersion", "invalid value %q (use 1.0, 1.1, 1.2 or 1.3)", cfg.SMTP.MinTLSVersion)
+		}
+	case "graph":
+		v.required("graph.tenantId", cfg.Graph.TenantID)
+		v.required("graph.clientId", cfg.Graph.ClientID)
+		v.required("graph.clientSecret", cfg.Graph.ClientSecret)
+	case "gmail":
+		v.required("gmail.clientId", cfg.Gmail.ClientID)
+		v.required("gmail.clientSecret", cfg.Gmail.ClientSecret)
+		v.required("gmail.refreshToken", cfg.Gmail.RefreshToken)
+	case "sendgrid":
+		v.required("sendgrid.apiKey", cfg.SendGrid.APIKey)
+	case "mailgun":
+		v.required("mailgun.domain", cfg.Mailgun.Domain)
+		v.required("mailgun.apiKey", cfg.Mailgun.APIKey)
+		v.oneOf("mailgun.region", strings.ToLower(cfg.Mailgun.Region), "", "us", "eu")
+	case "maildir":
+		v.required("maildir.path", cfg.Maildir.Path)
+	default:
+		if _, ok := deliverers[cfg.Email.Provider]; !ok {
+			v.addf("email.provider", "unknown provider %q (use %s)", cfg.Email.Provider, registryNames(deliverers))
+		}
+	}
+
+	// Notifications
+	for i, n := range cfg.Notify {
+		path := fmt.Sprintf("notify.%d", i)
+		switch n.Type {
+		case "ntfy", "slack", "teams":
+			v.required(path+".url", n.URL)
+		case "pushover":
+			v.required(path+".token", n.Token)
+			v.required(path+".user", n.User)
+		default:
+			v.addf(path+".type", "unknown type %q (use ntfy, pushover, slack or teams)", n.Type)
+		}
+		v.oneOf(path+".on", n.On, "", "always", "failure")
+	}
+	if u := cfg.Webhook.URL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		v.addf("webhook.url", "must be an http(s) URL")
+	}
+
+	// General
+	if r := cfg.RateData; r.URL != "" || r.Key != "" {
+		if !strings.HasPrefix(r.URL, "http://") && !strings.HasPrefix(r.URL, "https://") {
+			v.addf("rateData.url", "must be an http(s) URL")
+		}
+		v.required("rateData.key", r.Key)
+	}
+	if r := cfg.RateData.Version; r != "" && !rateVersionRegex.MatchString(r) {
+		v.addf("rateData.version", "invalid version %q (use e.g. 2026.1)", r)
+	}
+	if b := cfg.Backup; b.Type != "" || b.URL != "" {
+		switch b.Type {
+		case "webdav":
+			if !strings.HasPrefix(b.URL, "http://") && !strings.HasPrefix(b.URL, "https://") {
+				v.addf("backup.url", "must be an http(s) URL")
+			}
+		case "rclone":
+			v.required("backup.url", b.URL)
+		default:
+			v.addf("backup.type", "unknown type %q (use webdav or rclone)", b.Type)
+		}
+		if len(b.Recipients) == 0 && b.Passphrase == "" {
+			v.addf("backup.recipients", "required (or set backup.passphrase)")
+		}
+		for i, r := range b.Recipients {
+			if !strings.HasPrefix(r, "age1") {
+				v.addf(fmt.Sprintf("backup.recipients.%d", i), "must be an age public key (age1...)")
+			}
+		}
+	}
+	if cfg.Retention.Years < 0 {
+		v.addf("retention.years", "must not be negative")
+	}
+	if cfg.Retry.Attempts < 0 {
+		v.addf("retry.attempts", "must not be negative")
+	}
+	if cfg.Serve.Day < 0 || cfg.Serve.Day > 28 {
+		v.addf("serve.day", "must be between 1 and 28")
+	}
+	if cfg.Serve.Hour < 0 || cfg.Serve.Hour > 23 {
+		v.addf("serve.hour", "must be between 0 and 23")
+	}
+	if (cfg.GRPC.CertFile == "") != (cfg.GRPC.KeyFile == "") {
+		v.addf("grpc", "certFile and keyFile must be set together")
+	}
+	if cfg.GRPC.ClientCAFile != "" && cfg.GRPC.CertFile == "" {
+		v.addf("grpc.clientCAFile", "requires certFile and keyFile")
+	}
+
+	for i, r := range cfg.Rates {
+		path := fmt.Sprintf("rates.%d", i)
+		if r.From < 2000 {
+			v.addf(path+".from", "must be a year")
+		}
+		if r.KmRate <= 0 || r.PerDiemPartial <= 0 || r.PerDiemFull <= 0 {
+			v.addf(path, "kmRate, perDiemPartial and perDiemFull must be positive")
+		}
+		if r.CommuteRate < 0 || r.CommuteRateFar < 0 {
+			v.addf(path, "commuteRate and commuteRateFar must not be negative")
+		}
+	}
+
+	if cfg.Preflight.MaxConsecutiveDays < 0 || cfg.Preflight.MaxTotal < 0 || cfg.Preflight.MaxDistance < 0 {
+		v.addf("preflight", "thresholds must not be negative")
+	}
+	if cfg.Cap.Total < 0 || cfg.Cap.Kilometer < 0 || cfg.Cap.Verpflegung < 0 {
+		v.addf("cap", "limits must not be negative")
+	}
+	v.oneOf("cap.onExceed", cfg.Cap.OnExceed, "", "fail", "trim")
+	v.oneOf("language", cfg.Language, "", "de", "en")
+	if _, ok := locales[cfg.Locale]; cfg.Locale != "" && !ok {
+		v.addf("locale", "unknown locale %q (use %s)", cfg.Locale, registryNames(locales))
+	}
+	if _, ok := mapProviders[cfg.MapLinks]; cfg.MapLinks != "" && !ok {
+		v.addf("mapLinks", "unknown map %q (use %s)", cfg.MapLinks, registryNames(mapProviders))
+	}
+	switch strings.ToLower(filepath.Ext(cfg.Signature.Image)) {
+	case "", ".png", ".jpg", ".jpeg":
+	default:
+		v.addf("signature.image", "must be a PNG or JPEG file")
+	}
+	switch strings.ToLower(filepath.Ext(cfg.Form.Template)) {
+	case "", ".yaml", ".yml":
+	default:
+		v.addf("form.template", "must be a YAML template descriptor")
+	}
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		v.addf("timezone", "unknown timezone %q (use an IANA name such as Europe/Berlin)", cfg.Timezone)
+	}
+	v.oneOf("order", cfg.Order, "", orderCustomer, orderChronological)
+	v.oneOf("distanceMode", cfg.DistanceMode, "", distanceRoundTrip, distanceOneWay)
+	v.oneOf("purpose", cfg.Purpose, "", purposeReimbursement, purposeTaxDeduction)
+	if cfg.BikeRate < 0 {
+		v.addf("bikeRate", "must not be negative")
+	}
+	if err := cfg.Times.check(); err != nil {
+		v.addf("times", "%v", err)
+	}
+	for _, list := range []struct {
+		path string
+		days []string
+	}{{"departure.officeWeekdays", cfg.Departure.OfficeWeekdays}, {"halfDayWeekdays", cfg.HalfDayWeekdays}} {
+		for i, d := range list.days {
+			if _, ok := weekdayNames[strings.ToLower(d)]; !ok {
+				v.addf(fmt.Sprintf("%s.%d", list.path, i), "invalid weekday %q (use mon, tue, wed, thu, fri, sat or sun)", d)
+			}
+		}
+	}
+	// Receipt OCR
+	if o := cfg.OCR; o.Provider != "" {
+		if _, ok := ocrBackends[o.Provider]; !ok {
+			v.addf("ocr.provider", "unknown provider %q (use %s)", o.Provider, registryNames(ocrBackends))
+		}
+		if o.Provider == "api" {
+			v.required("ocr.url", o.URL)
+		}
+	}
+	// Actual vehicle costs
+	vehicles := make([]string, 0, len(cfg.ActualCosts))
+	for vehicle := range cfg.ActualCosts {
+		vehicles = append(vehicles, vehicle)
+	}
+	sort.Strings(vehicles)
+	for _, vehicle := range vehicles {
+		a, path := cfg.ActualCosts[vehicle], "actualCosts."+vehicle
+		if vehicle != vehicleCar && !byBike(vehicle) {
+			v.addf(path, "invalid vehicle %q (use car, bike or ebike)", vehicle)
+		}
+		if a.Km <= 0 {
+			v.addf(path+".km", "must be positive")
+		}
+		if a.Costs < 0 {
+			v.addf(path+".costs", "must not be negative")
+		}
+	}
+	// Annual budget
+	if cfg.Budget.Km < 0 {
+		v.addf("budget.km", "must not be negative")
+	}
+	if cfg.Budget.PerDiem < 0 {
+		v.addf("budget.perDiem", "must not be negative")
+	}
+	if cfg.Budget.WarnAt < 0 {
+		v.addf("budget.warnAt", "must not be negative")
+	}
+	// GPX tracks
+	if cfg.GPX.Radius < 0 {
+		v.addf("gpx.radius", "must not be negative")
+	}
+	// Accounting system
+	if a := cfg.Accounting; a.Provider != "" {
+		if _, ok := accountingDrivers[a.Provider]; !ok {
+			v.addf("accounting.provider", "unknown provider %q (use %s)", a.Provider, registryNames(accountingDrivers))
+		}
+		v.required("accounting.apiKey", a.APIKey)
+		if a.Provider == "buchhaltungsbutler" {
+			v.required("accounting.apiClient", a.APIClient)
+			v.required("accounting.apiSecret", a.APISecret)
+		}
+		for title := range a.Categories {
+			if !voucherTypes[title] {
+				v.addf("accounting.categories", "unknown document %q (use %s)", title, registryNames(voucherTypes))
+			}
+		}
+		if a.Provider == "sevdesk" || a.Provider == "lexoffice" {
+			for _, title := range []string{"Kilometergelderstattung", "Verpflegungsmehraufwand", "Reisenebenkosten"} {
+				if a.category(title) == "" {
+					v.addf("accounting.category", "required by %s (or set accounting.categories.%s)", a.Provider, title)
+					break
+				}
+			}
+		}
+	}
+	for title := range cfg.SevDesk.Categories {
+		switch title {
+		case "Kilometergelderstattung", "Verpflegungsmehraufwand", "Reisenebenkosten":
+		default:
+			v.addf("sevDesk.categories", "unknown document %q (use Kilometergelderstattung, Verpflegungsmehraufwand, Reisenebenkosten)", title)
+		}
+	}
+	if cfg.OfficeShare < 0 || cfg.OfficeShare > 99 {
+		v.addf("officeShare", "must be a percentage between 0 and 99")
+	}
+
+	for _, d := range []struct{ path, value string }{
+		{"employmentStart", cfg.EmploymentStart},
+		{"employmentEnd", cfg.EmploymentEnd},
+	} {
+		if _, err := time.Parse(isoDate, d.value); d.value != "" && err != nil {
+			v.addf(d.path, "invalid date %q (use YYYY-MM-DD)", d.value)
+		}
+	}
+	if cfg.EmploymentStart != "" && cfg.EmploymentEnd != "" && cfg.EmploymentEnd < cfg.EmploymentStart {
+		v.addf("employmentEnd", "must not be before employmentStart")
+	}
+
+	// Customers
+	if len(cfg.Customers) == 0 {
+		v.addf("customers", "no customers configured")
+	}
+	seen := make(map[string]int)
+	xrechnung := false
+	for i, c := range cfg.Customers {
+		path := fmt.Sprintf("customers.%d", i)
+		v.required(path+".id", c.ID)
+		v.required(path+".name", c.Name)
+		if first, ok := seen[c.ID]; ok && c.ID != "" {
+			v.addf(path+".id", "duplicate customer id %q (already used by customers[%d])", c.ID, first)
+		} else {
+			seen[c.ID] = i
+		}
+		if c.Distance <= 0 && !c.Ticket.active() {
+			v.addf(path+".distance", "must be positive")
+		}
+		if c.Ticket.active() {
+			v.required(path+".ticket.type", c.Ticket.Type)
+			if c.Ticket.Price <= 0 {
+				v.addf(path+".ticket.price", "must be positive")
+			}
+		}
+		if c.OfficeDistance < 0 {
+			v.addf(path+".officeDistance", "must not be negative")
+		}
+		if c.Location != "" {
+			if _, _, err := parseLocation(c.Location); err != nil {
+				v.addf(path+".location", "%v", err)
+			}
+		}
+		if err := c.Times.check(); err != nil {
+			v.addf(path+".times", "%v", err)
+		}
+		if c.KmRate < 0 {
+			v.addf(path+".kmRate", "must not be negative")
+		}
+		if c.PerDiemRate < 0 {
+			v.addf(path+".perDiemRate", "must not be negative")
+		}
+		v.oneOf(path+".vehicle", c.Vehicle, "", vehicleCar, vehicleBike, vehicleEBike)
+		if c.Cap.Total < 0 || c.Cap.Kilometer < 0 || c.Cap.Verpflegung < 0 {
+			v.addf(path+".cap", "limits must not be negative")
+		}
+		v.oneOf(path+".cap.onExceed", c.Cap.OnExceed, "", capWarn, capTrim, capCarry)
+		for j, r := range c.Reasons {
+			v.required(fmt.Sprintf("%s.reasons.%d", path, j), r)
+		}
+		for _, list := range []struct {
+			field string
+			days  []string
+		}{{"weekdays", c.Schedule.Weekdays}, {"notWeekdays", c.Schedule.NotWeekdays}} {
+			for j, d := range list.days {
+				if _, ok := weekdayNames[strings.ToLower(d)]; !ok {
+					v.addf(fmt.Sprintf("%s.schedule.%s.%d", path, list.field, j), "invalid weekday %q (use mon, tue, wed, thu, fri, sat or sun)", d)
+				}
+			}
+		}
+		for j, w := range c.Schedule.WeeksOfMonth {
+			if w < 1 || w > 5 {
+				v.addf(fmt.Sprintf("%s.schedule.weeksOfMonth.%d", path, j), "must be between 1 and 5")
+			}
+		}
+		if c.Schedule.MaxPerWeek < 0 || c.Schedule.MaxPerWeek > 7 {
+			v.addf(path+".schedule.maxPerWeek", "must be between 0 and 7")
+		}
+		if _, ok := provinceHolidays[c.Province]; !ok {
+			v.addf(path+".province", "invalid province %q (use a German state abbreviation, e.g. BW or BY)", c.Province)
+		}
+		if c.LeitwegID != "" {
+			if !leitwegIDRegex.MatchString(c.LeitwegID) {
+				v.addf(path+".leitwegId", "invalid Leitweg-ID %q (e.g. 991-12345-67)", c.LeitwegID)
+			}
+			v.required(path+".address.postalCode", c.Address.PostalCode)
+			v.required(path+".address.city", c.Address.City)
+			xrechnung = true
+		}
+	}
+
+	// Seller data of the XRechnungen (BR-DE rules)
+	if xrechnung {
+		x := cfg.XRechnung
+		v.required("xrechnung.name", x.Name)
+		v.required("xrechnung.address.postalCode", x.Address.PostalCode)
+		v.required("xrechnung.address.city", x.Address.City)
+		v.required("xrechnung.contact", x.Contact)
+		v.required("xrechnung.phone", x.Phone)
+		v.required("xrechnung.email", x.Email)
+		v.address("xrechnung.email", x.Email)
+		v.required("xrechnung.iban", x.IBAN)
+		if x.VATID == "" && x.TaxNumber == "" {
+			v.addf("xrechnung.vatId", "required (or set xrechnung.taxNumber)")
+		}
+	}
+	if cfg.XRechnung.VATRate < 0 || cfg.XRechnung.PaymentDays < 0 {
+		v.addf("xrechnung", "vatRate and paymentDays must not be negative")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigReportsAllProblems(t *testing.T) {
+	data := `smtp:
+  host: smtp.example.com
+  port: 587
+  tsl: starttls
+email:
+  from: not-an-address
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Acme
+    distance: 100
+    province: BW
+  - id: "1"
+    name: Globex
+    distance: -5
+    province: XX
+`
+	_, err := parseConfig("config.yaml", []byte(data), "")
+	var cfgErr *ConfigErrors
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("parseConfig() error = %v, want *ConfigErrors", err)
+	}
+
+	want := []configProblem{
+		{"config.yaml", 4, `unknown key "tsl" in SMTPConfig`},
+		{"config.yaml", 6, `email.from: invalid email address "not-an-address"`},
+		{"config.yaml", 13, `customers[1].id: duplicate customer id "1" (already used by customers[0])`},
+		{"config.yaml", 15, "customers[1].distance: must be positive"},
+		{"config.yaml", 16, `customers[1].province: invalid province "XX" (use a German state abbreviation, e.g. BW or BY)`},
+	}
+	if len(cfgErr.Problems) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(cfgErr.Problems), len(want), err)
+	}
+	for i, p := range cfgErr.Problems {
+		if p != want[i] {
+			t.Errorf("problem %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+	if !strings.Contains(err.Error(), "line 15: customers[1].distance: must be positive") {
+		t.Errorf("Error() = %q, want line context", err.Error())
+	}
+}
+
+func TestParseConfigProviderRequirements(t *testing.T) {
+	data := `email:
+  provider: mailgun
+  from: me@example.com
+  to: boss@example.com
+mailgun:
+  domain: mg.example.com
+  region: asia
+notify:
+  - type: pushover
+    token: abc
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BY
+`
+	_, err := parseConfig("config.yaml", []byte(data), "")
+	if err == nil {
+		t.Fatal("parseConfig() expected error")
+	}
+	for _, want := range []string{
+		"line 5: mailgun.apiKey: required",
+		`line 7: mailgun.region: invalid value "asia" (use us, eu)`,
+		"line 9: notify[0].user: required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestParseConfigValid(t *testing.T) {
+	data := `email:
+  provider: sendgrid
+  from: Max Mustermann <me@example.com>
+  to: boss@example.com
+sendgrid:
+  apiKey: key
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BY
+`
+	cfg, err := parseConfig("config.yaml", []byte(data), "")
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.SendGrid.APIKey != "key" {
+		t.Errorf("APIKey = %q, want key", cfg.SendGrid.APIKey)
+	}
+}
+
+func TestParseConfigProfiles(t *testing.T) {
+	data := `smtp:
+  host: smtp.example.com
+  port: 587
+  user: me@example.com
+email:
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Acme
+    distance: 10
+    province: BW
+profiles:
+  gmbh:
+    smtp:
+      user: gf@gmbh.example
+    email:
+      from: gf@gmbh.example
+      to: buchhaltung@gmbh.example
+    customers:
+      - id: "7"
+        name: Globex
+        distance: -1
+        province: BY
+  privat:
+    smtp:
+      usr: typo
+`
+	_, err := parseConfig("config.yaml", []byte(data), "gmbh")
+	if err == nil {
+		t.Fatal("parseConfig() expected error")
+	}
+	// Problems point into the profile and are reported for unused profiles too
+	for _, want := range []string{
+		"line 23: customers[0].distance: must be positive",
+		`line 27: unknown key "usr" in SMTPConfig`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() missing %q:\n%v", want, err)
+		}
+	}
+
+	data = strings.Replace(data, "distance: -1", "distance: 20", 1)
+	data = strings.Replace(data, "usr: typo", "user: privat@example.com", 1)
+	cfg, err := parseConfig("config.yaml", []byte(data), "gmbh")
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.SMTP.Host != "smtp.example.com" || cfg.SMTP.User != "gf@gmbh.example" {
+		t.Errorf("SMTP = %+v, want host from top level and user from profile", cfg.SMTP)
+	}
+	if cfg.Email.To != "buchhaltung@gmbh.example" || len(cfg.Customers) != 1 || cfg.Customers[0].ID != "7" {
+		t.Errorf("profile not applied: to %q, customers %+v", cfg.Email.To, cfg.Customers)
+	}
+	if cfg.StateFile() != "reisekosten-state-gmbh.json" || cfg.OutboxDir() != filepath.Join("outbox", "gmbh") {
+		t.Errorf("StateFile/OutboxDir = %s/%s, want per-profile defaults", cfg.StateFile(), cfg.OutboxDir())
+	}
+
+	cfg, err = parseConfig("config.yaml", []byte(data), "")
+	if err != nil || cfg.Email.To != "boss@example.com" {
+		t.Errorf("top level config = %v, %v", cfg, err)
+	}
+	if _, err := parseConfig("config.yaml", []byte(data), "missing"); err == nil || !strings.Contains(err.Error(), "available: gmbh, privat") {
+		t.Errorf("parseConfig() unknown profile error = %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import "time"
+
+// ---------------------------------------------------------------------------
+// Vehicles
+// ---------------------------------------------------------------------------
+
+// Vehicles a trip can be travelled with
+const (
+	vehicleCar   = "car"   // own car at the km rate (default)
+	vehicleBike  = "bike"  // bicycle at bikeRate
+	vehicleEBike = "ebike" // pedelec, a bicycle for tax purposes, at bikeRate
+)
+
+// vehicleNames are the German names of the vehicles printed in the entries.
+var vehicleNames = map[string]string{
+	vehicleBike:  "Fahrrad",
+	vehicleEBike: "E-Bike",
+}
+
+// byBike reports whether the vehicle is a bicycle or pedelec.
+func byBike(vehicle string) bool {
+	return vehicle == vehicleBike || vehicle == vehicleEBike
+}
+
+// vehicleOf returns the vehicle of the trip to customer on date: the day's
+// vehicle of the override file, then the customer's, otherwise the car.
+func vehicleOf(ov *MonthOverride, customer Customer, date time.Time) string {
+	if v, ok := ov.Vehicles[date.Format(isoDate)]; ok {
+		return v
+	}
+	if customer.Vehicle != "" {
+		return customer.Vehicle
+	}
+	return vehicleCar
+}
+
+// vehicleDetail returns the entry line naming a bike trip, or "" by car.
+func vehicleDetail(vehicle string) string {
+	if name, ok := vehicleNames[vehicle]; ok {
+		return "Verkehrsmittel: " + name
+	}
+	return ""
+}
+
+// kmRate returns the km rate of the trip by its vehicle.
+func (t tripDay) kmRate(c Customer, rates Rates) float64 {
+	return c.vehicleRate(t.vehicle, rates)
+}
+
+// vehicleRate returns the customer's km rate by vehicle: the individual rate
+// from actual costs, otherwise bikeRate by bike and the customer's rate by
+// car.
+func (c Customer) vehicleRate(vehicle string, rates Rates) float64 {
+	if r, ok := actualRatesFor(c, rates.Actual)[vehicle]; ok {
+		return r
+	}
+	if byBike(vehicle) {
+		return rates.BikeRate
+	}
+	return c.kmRate(rates)
+}
+
+// rate returns the km rate of the customer's n-th trip.
+func (c CustomerReport) rate(n int) float64 {
+	if n < len(c.Vehicles) && byBike(c.Vehicles[n]) {
+		if r, ok := c.ActualRates[c.Vehicles[n]]; ok {
+			return r
+		}
+		return c.BikeRate
+	}
+	return c.KmRate
+}
+
+// rate returns the km rate of the customer's n-th trip.
+func (c customerSummary) rate(n int) float64 {
+	if n < len(c.Vehicles) && byBike(c.Vehicles[n]) {
+		if r, ok := c.ActualRates[c.Vehicles[n]]; ok {
+			return r
+		}
+		return c.BikeRate
+	}
+	return c.KmRate
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBikeTrips(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "2026-02.yaml"), []byte("vehicles:\n  2026-02-03: ebike\n"), 0644)
+	cfg := &Config{
+		Overrides: dir,
+		BikeRate:  0.05,
+		Customers: []Customer{
+			{ID: "1", Name: "Acme", Distance: 100, Province: "BW", Schedule: Schedule{Weekdays: []string{"mon", "tue"}}},
+			{ID: "2", Name: "Nachbar", Distance: 10, Province: "BW", Vehicle: vehicleBike, Schedule: Schedule{Weekdays: []string{"wed", "thu", "fri"}}},
+		},
+	}
+	p := monthPeriod(2026, 2)
+	report, err := generateReport(cfg, p)
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+
+	// Acme: 7 trips by car and Tuesday 3 by e-bike; Nachbar: 12 trips by bike
+	acme, bike := report.Customers[0], report.Customers[1]
+	if acme.KmAmount != 7*30+5 || bike.KmAmount != 12*0.50 || report.KmTotal != 221 {
+		t.Errorf("KmAmount = %v and %v, KmTotal = %v", acme.KmAmount, bike.KmAmount, report.KmTotal)
+	}
+	if groups := acme.distanceGroups(); len(groups) != 2 || groups[1].rate != 0.05 || len(groups[1].dates) != 1 {
+		t.Errorf("distanceGroups() = %+v", groups)
+	}
+	km := pdfText(t, report.Attachments[0].Data)
+	for _, want := range []string{"Fahrkosten \\(100 km x 0,05 EUR\\)", "Verkehrsmittel: E-Bike", "Verkehrsmittel: Fahrrad"} {
+		if !strings.Contains(km, want) {
+			t.Errorf("Kilometergelderstattung misses %q", want)
+		}
+	}
+	if s := newRunSummary(cfg, p, report, nil); s.Customers[1].rate(0) != 0.05 || s.Customers[0].rate(0) != kmRatePerKm {
+		t.Errorf("summary rates = %v and %v", s.Customers[1].rate(0), s.Customers[0].rate(0))
+	}
+}
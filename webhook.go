@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Completion Webhook
+// ---------------------------------------------------------------------------
+
+// WebhookConfig posts the run summary to a downstream system after delivery.
+type WebhookConfig struct {
+	URL string `yaml:"url,omitempty"` // endpoint receiving the JSON payload
+}
+
+// archivePaths locates the archived files of a report.
+type archivePaths struct {
+	Summary   string   `json:"summary"`
+	Documents []string `json:"documents,omitempty"` // only with archiveDocuments
+}
+
+// webhookPayload is the run summary extended by the archive paths.
+type webhookPayload struct {
+	runSummary
+	Archive archivePaths `json:"archive"`
+}
+
+// newWebhookPayload builds the payload for an archived run summary.
+func newWebhookPayload(cfg *Config, s runSummary) webhookPayload {
+	dir := cfg.ArchiveDir()
+	paths := archivePaths{Summary: filepath.Join(dir, s.Period+".json")}
+	if cfg.ArchiveDocuments {
+		for _, d := range s.Documents {
+			paths.Documents = append(paths.Documents, filepath.Join(dir, s.Period, d.Filename))
+		}
+	}
+	return webhookPayload{runSummary: s, Archive: paths}
+}
+
+// postWebhook sends the payload of a delivered (or queued) report to the
+// configured webhook URL.
+func postWebhook(cfg *Config, s runSummary) error {
+	body, err := json.Marshal(newWebhookPayload(cfg, s))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, cfg.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	slog.Debug("webhook posted", "url", cfg.Webhook.URL, "period", s.Period)
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &Config{
+		Email:            EmailConfig{Provider: "eml", From: "me@example.com", To: "boss@example.com"},
+		EML:              EMLConfig{Dir: filepath.Join(dir, "mails")},
+		State:            filepath.Join(dir, "state.json"),
+		Archive:          filepath.Join(dir, "archive"),
+		ArchiveDocuments: true,
+		Overrides:        filepath.Join(dir, "overrides"),
+		Webhook:          WebhookConfig{URL: srv.URL},
+		Customers:        []Customer{{ID: "1", Name: "Acme", Distance: 100, Province: "BW"}},
+	}
+
+	report, err := run(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if got["period"] != "2026-02" || got["total"] != roundCents(report.Total()) {
+		t.Errorf("payload period/total = %v/%v", got["period"], got["total"])
+	}
+	docs, _ := got["documents"].([]any)
+	if len(docs) != 2 || docs[0].(map[string]any)["id"] != report.KmDocID {
+		t.Errorf("payload documents = %v", got["documents"])
+	}
+	archive, _ := got["archive"].(map[string]any)
+	paths, _ := archive["documents"].([]any)
+	if archive["summary"] != filepath.Join(dir, "archive", "2026-02.json") || len(paths) != 2 ||
+		paths[0] != filepath.Join(dir, "archive", "2026-02", report.Attachments[0].Filename) {
+		t.Errorf("payload archive = %v", got["archive"])
+	}
+}
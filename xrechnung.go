@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// ---------------------------------------------------------------------------
+// XRechnung (UBL)
+// ---------------------------------------------------------------------------
+
+// Identifiers of the XRechnung 3.0 standard (CIUS of EN 16931)
+const (
+	xrechnungCustomization = "urn:cen.eu:en16931:2017#compliant#urn:xeinkauf.de:kosit:xrechnung_3.0"
+	xrechnungProfile       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+	xrechnungVATRate       = 19.0
+	xrechnungPaymentDays   = 30
+	xrechnungExemption     = "Kein Ausweis von Umsatzsteuer, da Kleinunternehmer gemäß § 19 UStG"
+)
+
+// leitwegIDRegex matches a Leitweg-ID: coarse and fine addressing and a
+// two-digit check number, e.g. 991-12345-67.
+var leitwegIDRegex = regexp.MustCompile(`^[0-9]{2,12}(-[0-9A-Za-z]{1,30})?-[0-9]{2}$`)
+
+// Address is a postal address of an invoice party.
+type Address struct {
+	Street     string `yaml:"street,omitempty"`
+	PostalCode string `yaml:"postalCode,omitempty"`
+	City       string `yaml:"city,omitempty"`
+	Country    string `yaml:"country,omitempty"` // ISO 3166-1 code (default: DE)
+}
+
+// country returns the country code of the address (default: DE).
+func (a Address) country() string {
+	if a.Country != "" {
+		return a.Country
+	}
+	return "DE"
+}
+
+// XRechnungConfig holds the seller data of the e-invoices generated for
+// customers with a Leitweg-ID (public authorities).
+type XRechnungConfig struct {
+	Name          string  `yaml:"name,omitempty"` // seller name, e.g. your company
+	Address       Address `yaml:"address,omitempty"`
+	VATID         string  `yaml:"vatId,omitempty"`         // USt-IdNr., or
+	TaxNumber     string  `yaml:"taxNumber,omitempty"`     // Steuernummer
+	Contact       string  `yaml:"contact,omitempty"`       // contact person
+	Phone         string  `yaml:"phone,omitempty"`         // contact phone
+	Email         string  `yaml:"email,omitempty"`         // contact and electronic address of the seller
+	IBAN          string  `yaml:"iban,omitempty"`          // account for the SEPA credit transfer
+	BIC           string  `yaml:"bic,omitempty"`           // optional
+	VATRate       float64 `yaml:"vatRate,omitempty"`       // percent (default: 19)
+	SmallBusiness bool    `yaml:"smallBusiness,omitempty"` // no VAT (Kleinunternehmer, § 19 UStG)
+	PaymentDays   int     `yaml:"paymentDays,omitempty"`   // payment term (default: 30)
+}
+
+// vatRate returns the VAT percentage and UNCL5305 category of the invoices.
+func (x XRechnungConfig) vatRate() (float64, string) {
+	switch {
+	case x.SmallBusiness:
+		return 0, "E"
+	case x.VATRate > 0:
+		return x.VATRate, "S"
+	default:
+		return xrechnungVATRate, "S"
+	}
+}
+
+// paymentDays returns the payment term in days (default: 30).
+func (x XRechnungConfig) paymentDays() int {
+	if x.PaymentDays > 0 {
+		return x.PaymentDays
+	}
+	return xrechnungPaymentDays
+}
+
+// Invoice is the XRechnung generated for a customer with a Leitweg-ID.
+type Invoice struct {
+	CustomerID string
+	DocID      string
+	Total      float64 // gross amount including VAT
+}
+
+// invoiceLine is a position of an e-invoice.
+type invoiceLine struct {
+	name, description string
+	quantity          float64
+	unit              string // UN/ECE Rec 20, e.g. DAY or C62 (piece)
+	price             float64
+}
+
+// amount returns the net amount of the line.
+func (l invoiceLine) amount() float64 {
+	return roundCents(l.quantity * l.price)
+}
+
+// invoiceLines returns the positions re-billed to a customer: the
+// Kilometergeld and Verpflegungsmehraufwand of its trips and the additional
+// expenses booked on it.
+func invoiceLines(c CustomerReport, expenses []Expense) []invoiceLine {
+	var lines []invoiceLine
+	if t := c.Customer.Ticket; t.active() && len(c.Dates) > 0 {
+		lines = append(lines, invoiceLine{
+			name:        "Fahrkarte",
+			description: fmt.Sprintf("%s je Fahrt, %s - %s", t.Type, c.Dates[0], c.Dates[len(c.Dates)-1]),
+			quantity:    float64(len(c.Dates)),
+			unit:        "DAY",
+			price:       t.Price,
+		})
+	}
+	if len(c.Dates) > 0 {
+		for _, g := range c.distanceGroups() {
+			lines = append(lines, invoiceLine{
+				name:        "Kilometergeld",
+				description: fmt.Sprintf("%d km x %s EUR/km je Fahrt, %s - %s", g.distance, formatRate(g.rate), g.dates[0], g.dates[len(g.dates)-1]),
+				quantity:    float64(len(g.dates)),
+				unit:        "DAY",
+				price:       roundCents(float64(g.distance) * g.rate),
+			})
+		}
+	}
+	if days := c.fullDays(); days > 0 {
+		lines = append(lines, invoiceLine{
+			name:        "Verpflegungsmehraufwand",
+			description: fmt.Sprintf("Abwesenheit mehr als 8 Stunden, %s - %s", c.Dates[0], c.Dates[len(c.Dates)-1]),
+			quantity:    float64(days),
+			unit:        "DAY",
+			price:       c.VerpRate,
+		})
+	}
+	for _, e := range expenses {
+		if e.Customer == c.Customer.ID {
+			lines = append(lines, invoiceLine{
+				name:        e.Description,
+				description: "Reisenebenkosten vom " + formatISODate(e.Date),
+				quantity:    1,
+				unit:        "C62",
+				price:       e.Amount,
+			})
+		}
+	}
+	return lines
+}
+
+// createInvoice generates the XRechnung of the trips and expenses of a
+// customer with a Leitweg-ID. The invoice is dated on the last day of the
+// period.
+func createInvoice(cfg *Config, p Period, docID string, c CustomerReport, expenses []Expense) (Invoice, Attachment, error) {
+	lines := invoiceLines(c, expenses)
+	rate, category := cfg.XRechnung.vatRate()
+	issued := p.End()
+
+	var net float64
+	for _, l := range lines {
+		net += l.amount()
+	}
+	net = roundCents(net)
+	tax := roundCents(net * rate / 100)
+	gross := roundCents(net + tax)
+
+	seller := cfg.XRechnung
+	doc := ublInvoice{
+		XMLNS:           "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2",
+		CAC:             "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2",
+		CBC:             "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2",
+		CustomizationID: xrechnungCustomization,
+		ProfileID:       xrechnungProfile,
+		ID:              docID,
+		IssueDate:       issued.Format(isoDate),
+		DueDate:         issued.AddDate(0, 0, seller.paymentDays()).Format(isoDate),
+		TypeCode:        "380",
+		Note:            "Weiterberechnung von Reisekosten " + p.Label(),
+		Currency:        "EUR",
+		BuyerReference:  c.Customer.LeitwegID,
+		InvoicePeriod: ublPeriod{
+			StartDate: p.Start().Format(isoDate),
+			EndDate:   p.End().Format(isoDate),
+		},
+		Seller: ublSupplier{Party: ublParty{
+			EndpointID: ublEndpoint{Scheme: "EM", Value: seller.Email},
+			Name:       seller.Name,
+			Address:    newUBLAddress(seller.Address),
+			LegalName:  seller.Name,
+			Contact:    &ublContact{Name: seller.Contact, Phone: seller.Phone, Email: seller.Email},
+		}},
+		Buyer: ublCustomer{Party: ublParty{
+			EndpointID: ublEndpoint{Scheme: "0204", Value: c.Customer.LeitwegID},
+			Name:       c.Customer.Name,
+			Address:    newUBLAddress(c.Customer.Address),
+			LegalName:  c.Customer.Name,
+		}},
+		PaymentMeans: ublPaymentMeans{
+			Code:    "58",
+			Account: ublAccount{IBAN: seller.IBAN, Name: seller.Name},
+		},
+		PaymentTerms: ublNote{Note: fmt.Sprintf("Zahlbar innerhalb von %d Tagen ohne Abzug", seller.paymentDays())},
+		TaxTotal: ublTaxTotal{
+			TaxAmount: newUBLAmount(tax),
+			Subtotal: ublTaxSubtotal{
+				TaxableAmount: newUBLAmount(net),
+				TaxAmount:     newUBLAmount(tax),
+				Category:      newUBLTaxCategory(category, rate),
+			},
+		},
+		Totals: ublMonetaryTotal{
+			LineExtension: newUBLAmount(net),
+			TaxExclusive:  newUBLAmount(net),
+			TaxInclusive:  newUBLAmount(gross),
+			Payable:       newUBLAmount(gross),
+		},
+	}
+	if seller.BIC != "" {
+		doc.PaymentMeans.Account.Branch = &ublBranch{ID: seller.BIC}
+	}
+	if seller.VATID != "" {
+		doc.Seller.Party.TaxScheme = &ublPartyTaxScheme{CompanyID: seller.VATID, TaxScheme: ublTaxScheme{ID: "VAT"}}
+	} else {
+		doc.Seller.Party.TaxScheme = &ublPartyTaxScheme{CompanyID: seller.TaxNumber, TaxScheme: ublTaxScheme{ID: "FC"}}
+	}
+	if category == "E" {
+		doc.TaxTotal.Subtotal.Category.ExemptionReason = xrechnungExemption
+	}
+	for i, l := range lines {
+		doc.Lines = append(doc.Lines, ublLine{
+			ID:       fmt.Sprint(i + 1),
+			Quantity: ublQuantity{Unit: l.unit, Value: fmt.Sprintf("%g", l.quantity)},
+			Amount:   newUBLAmount(l.amount()),
+			Item: ublItem{
+				Description: l.description,
+				Name:        l.name,
+				Category:    newUBLTaxCategory(category, rate),
+			},
+			Price: ublPrice{Amount: newUBLAmount(l.price)},
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return Invoice{}, Attachment{}, err
+	}
+	data = append([]byte(xml.Header), data...)
+
+	inv := Invoice{CustomerID: c.Customer.ID, DocID: docID, Total: gross}
+	filename := fmt.Sprintf("%s_XRechnung_%s.xml", p.filePrefix(), outboxSlug(c.Customer.ID))
+	return inv, Attachment{Filename: filename, Data: data, Type: "XRechnung", DocID: docID, Amount: gross}, nil
+}
+
+// UBL 2.1 invoice elements, in the order required by the schema. The
+// namespace prefixes are part of the element names, since encoding/xml
+// cannot emit prefixed names itself.
+type ublInvoice struct {
+	XMLName         xml.Name         `xml:"Invoice"`
+	XMLNS           string           `xml:"xmlns,attr"`
+	CAC             string           `xml:"xmlns:cac,attr"`
+	CBC             string           `xml:"xmlns:cbc,attr"`
+	CustomizationID string           `xml:"cbc:CustomizationID"`
+	ProfileID       string           `xml:"cbc:ProfileID"`
+	ID              string           `xml:"cbc:ID"`
+	IssueDate       string           `xml:"cbc:IssueDate"`
+	DueDate         string           `xml:"cbc:DueDate"`
+	TypeCode        string           `xml:"cbc:InvoiceTypeCode"`
+	Note            string           `xml:"cbc:Note"`
+	Currency        string           `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference  string           `xml:"cbc:BuyerReference"`
+	InvoicePeriod   ublPeriod        `xml:"cac:InvoicePeriod"`
+	Seller          ublSupplier      `xml:"cac:AccountingSupplierParty"`
+	Buyer           ublCustomer      `xml:"cac:AccountingCustomerParty"`
+	PaymentMeans    ublPaymentMeans  `xml:"cac:PaymentMeans"`
+	PaymentTerms    ublNote          `xml:"cac:PaymentTerms"`
+	TaxTotal        ublTaxTotal      `xml:"cac:TaxTotal"`
+	Totals          ublMonetaryTotal `xml:"cac:LegalMonetaryTotal"`
+	Lines           []ublLine        `xml:"cac:InvoiceLine"`
+}
+
+type ublPeriod struct {
+	StartDate string `xml:"cbc:StartDate"`
+	EndDate   string `xml:"cbc:EndDate"`
+}
+
+type ublSupplier struct {
+	Party ublParty `xml:"cac:Party"`
+}
+
+type ublCustomer struct {
+	Party ublParty `xml:"cac:Party"`
+}
+
+type ublParty struct {
+	EndpointID ublEndpoint        `xml:"cbc:EndpointID"`
+	Name       string             `xml:"cac:PartyName>cbc:Name"`
+	Address    ublAddress         `xml:"cac:PostalAddress"`
+	TaxScheme  *ublPartyTaxScheme `xml:"cac:PartyTaxScheme,omitempty"`
+	LegalName  string             `xml:"cac:PartyLegalEntity>cbc:RegistrationName"`
+	Contact    *ublContact        `xml:"cac:Contact,omitempty"`
+}
+
+type ublEndpoint struct {
+	Scheme string `xml:"schemeID,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ublAddress struct {
+	Street     string `xml:"cbc:StreetName,omitempty"`
+	City       string `xml:"cbc:CityName"`
+	PostalCode string `xml:"cbc:PostalZone"`
+	Country    string `xml:"cac:Country>cbc:IdentificationCode"`
+}
+
+// newUBLAddress converts a configured address.
+func newUBLAddress(a Address) ublAddress {
+	return ublAddress{Street: a.Street, City: a.City, PostalCode: a.PostalCode, Country: a.country()}
+}
+
+type ublPartyTaxScheme struct {
+	CompanyID string       `xml:"cbc:CompanyID"`
+	TaxScheme ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublTaxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type ublContact struct {
+	Name  string `xml:"cbc:Name"`
+	Phone string `xml:"cbc:Telephone"`
+	Email string `xml:"cbc:ElectronicMail"`
+}
+
+type ublPaymentMeans struct {
+	Code    string     `xml:"cbc:PaymentMeansCode"`
+	Account ublAccount `xml:"cac:PayeeFinancialAccount"`
+}
+
+type ublAccount struct {
+	IBAN   string     `xml:"cbc:ID"`
+	Name   string     `xml:"cbc:Name"`
+	Branch *ublBranch `xml:"cac:FinancialInstitutionBranch,omitempty"`
+}
+
+type ublBranch struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type ublNote struct {
+	Note string `xml:"cbc:Note"`
+}
+
+type ublAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// newUBLAmount formats an amount in EUR with two decimals.
+func newUBLAmount(amount float64) ublAmount {
+	return ublAmount{Currency: "EUR", Value: fmt.Sprintf("%.2f", amount)}
+}
+
+type ublTaxTotal struct {
+	TaxAmount ublAmount      `xml:"cbc:TaxAmount"`
+	Subtotal  ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount ublAmount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     ublAmount      `xml:"cbc:TaxAmount"`
+	Category      ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxCategory struct {
+	ID              string       `xml:"cbc:ID"`
+	Percent         string       `xml:"cbc:Percent"`
+	ExemptionReason string       `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme       ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+// newUBLTaxCategory returns the VAT category with its percentage.
+func newUBLTaxCategory(category string, rate float64) ublTaxCategory {
+	return ublTaxCategory{ID: category, Percent: fmt.Sprintf("%.2f", rate), TaxScheme: ublTaxScheme{ID: "VAT"}}
+}
+
+type ublMonetaryTotal struct {
+	LineExtension ublAmount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusive  ublAmount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusive  ublAmount `xml:"cbc:TaxInclusiveAmount"`
+	Payable       ublAmount `xml:"cbc:PayableAmount"`
+}
+
+type ublLine struct {
+	ID       string      `xml:"cbc:ID"`
+	Quantity ublQuantity `xml:"cbc:InvoicedQuantity"`
+	Amount   ublAmount   `xml:"cbc:LineExtensionAmount"`
+	Item     ublItem     `xml:"cac:Item"`
+	Price    ublPrice    `xml:"cac:Price"`
+}
+
+type ublQuantity struct {
+	Unit  string `xml:"unitCode,attr"`
+	Value string `xml:",chardata"`
+}
+
+type ublItem struct {
+	Description string         `xml:"cbc:Description"`
+	Name        string         `xml:"cbc:Name"`
+	Category    ublTaxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type ublPrice struct {
+	Amount ublAmount `xml:"cbc:PriceAmount"`
+}
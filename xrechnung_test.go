@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateReportXRechnung(t *testing.T) {
+	cfg := &Config{
+		Overrides: t.TempDir(),
+		XRechnung: XRechnungConfig{
+			Name:    "Max Mustermann IT",
+			Address: Address{Street: "Hauptstr. 1", PostalCode: "70173", City: "Stuttgart"},
+			VATID:   "DE123456789",
+			Contact: "Max Mustermann", Phone: "+49 711 123456", Email: "max@example.com",
+			IBAN: "DE02120300000000202051",
+		},
+		Customers: []Customer{
+			{ID: "1", Name: "Stadt Musterhausen", Distance: 100, Province: "BW", LeitwegID: "08111-12345-67",
+				Address: Address{Street: "Rathausplatz 1", PostalCode: "71634", City: "Musterhausen"}},
+			{ID: "2", Name: "Globex", Distance: 50, Province: "BW"},
+		},
+	}
+
+	report, err := generateReport(cfg, monthPeriod(2026, 2))
+	if err != nil {
+		t.Fatalf("generateReport() error = %v", err)
+	}
+	// 10 trips: 10 x 30,00 + 10 x 14,00 = 440,00 net, 83,60 VAT
+	if len(report.Invoices) != 1 || report.Invoices[0].CustomerID != "1" || report.Invoices[0].Total != 523.6 {
+		t.Fatalf("Invoices = %+v", report.Invoices)
+	}
+	if len(report.Attachments) != 3 || report.Attachments[2].Filename != "02_2026_XRechnung_1.xml" {
+		t.Fatalf("attachments = %d", len(report.Attachments))
+	}
+
+	var doc struct {
+		BuyerReference string `xml:"BuyerReference"`
+		IssueDate      string `xml:"IssueDate"`
+		Payable        string `xml:"LegalMonetaryTotal>PayableAmount"`
+		Lines          []struct {
+			Quantity string `xml:"InvoicedQuantity"`
+			Amount   string `xml:"LineExtensionAmount"`
+		} `xml:"InvoiceLine"`
+	}
+	if err := xml.Unmarshal(report.Attachments[2].Data, &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if doc.BuyerReference != "08111-12345-67" || doc.IssueDate != "2026-02-28" || doc.Payable != "523.60" || len(doc.Lines) != 2 || doc.Lines[0].Amount != "300.00" {
+		t.Errorf("invoice = %+v", doc)
+	}
+	data := string(report.Attachments[2].Data)
+	for _, want := range []string{`<cbc:EndpointID schemeID="0204">08111-12345-67</cbc:EndpointID>`, `<cbc:CustomizationID>urn:cen.eu:en16931:2017#compliant#urn:xeinkauf.de:kosit:xrechnung_3.0</cbc:CustomizationID>`, `<cbc:Percent>19.00</cbc:Percent>`} {
+		if !strings.Contains(data, want) {
+			t.Errorf("XML missing %q", want)
+		}
+	}
+
+	s := newRunSummary(cfg, monthPeriod(2026, 2), report, nil)
+	if len(s.Documents) != 3 || s.Documents[2].Type != "XRechnung" || s.Documents[2].Amount != 523.6 {
+		t.Errorf("documents = %+v", s.Documents)
+	}
+}
+
+func TestParseConfigXRechnungRequirements(t *testing.T) {
+	data := `email:
+  from: me@example.com
+  to: boss@example.com
+customers:
+  - id: "1"
+    name: Stadt Musterhausen
+    distance: 100
+    province: BW
+    leitwegId: 08111-12345
+    address:
+      city: Musterhausen
+`
+	_, err := parseConfig("config.yaml", []byte(data), "")
+	var cfgErr *ConfigErrors
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("parseConfig() error = %v, want *ConfigErrors", err)
+	}
+	for _, want := range []string{
+		`customers[0].leitwegId: invalid Leitweg-ID "08111-12345"`,
+		"customers[0].address.postalCode: required",
+		"xrechnung.name: required",
+		"xrechnung.iban: required",
+		"xrechnung.vatId: required (or set xrechnung.taxNumber)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ---------------------------------------------------------------------------
+// Password-Protected ZIP (WinZip AES-256, AE-2)
+// ---------------------------------------------------------------------------
+
+const (
+	zipMethodAES      = 99     // compression method marking WinZip AES encryption
+	zipAESExtraID     = 0x9901 // extra field header ID for AES
+	zipAESKeyLen      = 32     // AES-256
+	zipAESSaltLen     = 16     // salt length for AES-256
+	zipAESIterations  = 1000   // PBKDF2 iterations mandated by the format
+	zipAESAuthCodeLen = 10     // truncated HMAC-SHA1
+)
+
+// ZipConfig configures bundling all attachments into one encrypted ZIP file.
+type ZipConfig struct {
+	Password string `yaml:"password,omitempty"` // enables the ZIP bundle
+}
+
+// zipAESKeys derives the encryption key, authentication key and password
+// verification value from password and salt.
+func zipAESKeys(password string, salt []byte) (encKey, authKey, verifier []byte) {
+	dk := pbkdf2.Key([]byte(password), salt, zipAESIterations, 2*zipAESKeyLen+2, sha1.New)
+	return dk[:zipAESKeyLen], dk[zipAESKeyLen : 2*zipAESKeyLen], dk[2*zipAESKeyLen:]
+}
+
+// zipAESCTR applies AES in the WinZip CTR variant (little-endian counter starting at 1).
+// Encryption and decryption are the same operation.
+func zipAESCTR(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	var counter, stream [aes.BlockSize]byte
+	for i := 0; i < len(data); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counter[:8], uint64(i/aes.BlockSize)+1)
+		block.Encrypt(stream[:], counter[:])
+		for j := i; j < i+aes.BlockSize && j < len(data); j++ {
+			out[j] = data[j] ^ stream[j-i]
+		}
+	}
+	return out, nil
+}
+
+// zipAESEncrypt deflates and encrypts data, returning the raw AE-2 file payload:
+// salt, password verifier, encrypted data and authentication code.
+func zipAESEncrypt(password string, data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, zipAESSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encKey, authKey, verifier := zipAESKeys(password, salt)
+
+	encrypted, err := zipAESCTR(encKey, compressed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(encrypted)
+
+	payload := make([]byte, 0, len(salt)+len(verifier)+len(encrypted)+zipAESAuthCodeLen)
+	payload = append(payload, salt...)
+	payload = append(payload, verifier...)
+	payload = append(payload, encrypted...)
+	payload = append(payload, mac.Sum(nil)[:zipAESAuthCodeLen]...)
+	return payload, nil
+}
+
+// zipAESExtra builds the AES extra field: AE-2, vendor "AE", AES-256, deflate.
+func zipAESExtra() []byte {
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], zipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7) // data size
+	binary.LittleEndian.PutUint16(extra[4:], 2) // AE-2 (no CRC)
+	copy(extra[6:], "AE")
+	extra[8] = 3 // AES-256
+	binary.LittleEndian.PutUint16(extra[9:], zip.Deflate)
+	return extra
+}
+
+// bundleZip packs all attachments into a single AES-256 encrypted ZIP archive.
+func bundleZip(password, filename string, attachments []Attachment) (Attachment, error) {
+	if password == "" {
+		return Attachment{}, fmt.Errorf("zip bundle requires a password")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, a := range attachments {
+		payload, err := zipAESEncrypt(password, a.Data)
+		if err != nil {
+			return Attachment{}, err
+		}
+
+		// CreateRaw does not derive the MS-DOS timestamp from Modified
+		now := time.Now()
+		fh := &zip.FileHeader{
+			Name:               a.Filename,
+			Method:             zipMethodAES,
+			Flags:              0x1, // encrypted
+			ModifiedDate:       uint16(now.Day() + int(now.Month())<<5 + (now.Year()-1980)<<9),
+			ModifiedTime:       uint16(now.Second()/2 + now.Minute()<<5 + now.Hour()<<11),
+			Extra:              zipAESExtra(),
+			CompressedSize64:   uint64(len(payload)),
+			UncompressedSize64: uint64(len(a.Data)),
+		}
+		w, err := zw.CreateRaw(fh)
+		if err != nil {
+			return Attachment{}, err
+		}
+		if _, err := w.Write(payload); err != nil {
+			return Attachment{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return Attachment{}, err
+	}
+
+	return Attachment{Filename: filename, Data: buf.Bytes()}, nil
+}
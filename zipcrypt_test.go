@@ -0,0 +1,87 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha1"
+	"io"
+	"testing"
+)
+
+// zipAESDecrypt reverses zipAESEncrypt for verification.
+func zipAESDecrypt(t *testing.T, password string, payload []byte) []byte {
+	t.Helper()
+
+	salt := payload[:zipAESSaltLen]
+	verifier := payload[zipAESSaltLen : zipAESSaltLen+2]
+	encrypted := payload[zipAESSaltLen+2 : len(payload)-zipAESAuthCodeLen]
+	authCode := payload[len(payload)-zipAESAuthCodeLen:]
+
+	encKey, authKey, wantVerifier := zipAESKeys(password, salt)
+	if !bytes.Equal(verifier, wantVerifier) {
+		t.Fatal("password verifier mismatch")
+	}
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(encrypted)
+	if !bytes.Equal(authCode, mac.Sum(nil)[:zipAESAuthCodeLen]) {
+		t.Fatal("authentication code mismatch")
+	}
+
+	compressed, err := zipAESCTR(encKey, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plain
+}
+
+func TestBundleZip(t *testing.T) {
+	data := bytes.Repeat([]byte("%PDF-1.3 Reisekosten "), 100)
+
+	bundle, err := bundleZip("geheim", "02_2026_Reisekosten.zip", []Attachment{
+		{Filename: "a.pdf", Data: data},
+		{Filename: "b.pdf", Data: []byte("short")},
+	})
+	if err != nil {
+		t.Fatalf("bundleZip() error = %v", err)
+	}
+	if bundle.Filename != "02_2026_Reisekosten.zip" {
+		t.Errorf("Filename = %q", bundle.Filename)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(bundle.Data), int64(len(bundle.Data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if len(zr.File) != 2 {
+		t.Fatalf("archive contains %d files, want 2", len(zr.File))
+	}
+
+	f := zr.File[0]
+	if f.Name != "a.pdf" || f.Method != zipMethodAES || f.Flags&0x1 == 0 {
+		t.Errorf("unexpected header: name=%q method=%d flags=%#x", f.Name, f.Method, f.Flags)
+	}
+	if f.UncompressedSize64 != uint64(len(data)) {
+		t.Errorf("UncompressedSize64 = %d, want %d", f.UncompressedSize64, len(data))
+	}
+
+	rc, err := f.OpenRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := io.ReadAll(rc)
+	if got := zipAESDecrypt(t, "geheim", payload); !bytes.Equal(got, data) {
+		t.Error("decrypted content does not match original")
+	}
+}
+
+func TestBundleZipRequiresPassword(t *testing.T) {
+	if _, err := bundleZip("", "x.zip", nil); err == nil {
+		t.Error("bundleZip() expected error without password")
+	}
+}